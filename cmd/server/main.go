@@ -2,21 +2,27 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
 	"pull-request-reviewer-assignment-service/internal/config"
 	"pull-request-reviewer-assignment-service/internal/database"
+	"pull-request-reviewer-assignment-service/internal/events"
 	"pull-request-reviewer-assignment-service/internal/handlers"
 	"pull-request-reviewer-assignment-service/internal/repository"
-	"pull-request-reviewer-assignment-service/internal/repository/postgres"
 	"pull-request-reviewer-assignment-service/internal/service"
 	"syscall"
 	"time"
 )
 
 func main() {
+	// версия, на которой нужно пометить чистой базу после упавшей миграции golang-migrate
+	forceMigrationVersion := flag.Int("force-migration-version", database.NoForceVersion,
+		"clear a dirty golang-migrate state by forcing this version, then apply pending migrations")
+	flag.Parse()
+
 	// загрузка конфигурации
 	cfg := config.Load()
 
@@ -34,62 +40,163 @@ func main() {
 
 	log.Println("Successfully connected to database")
 
-	// применяем миграции
-	if err := database.SimpleRunMigrations(db); err != nil {
-		log.Fatalf("Failed to run migrations: %v", err)
+	// подключаемся к реплике для чтения, если она настроена
+	readDB, err := database.ConnectReplica(cfg.Database)
+	if err != nil {
+		log.Fatalf("Read replica not available: %v", err)
+	}
+	if readDB != nil {
+		defer readDB.Close()
+		log.Printf("Using read replica %s for stats and listings", cfg.Database.ReadHost)
+	}
+
+	// проверяем базу до миграций, чтобы не создать схему сервиса в чужой базе при неверном DB_NAME;
+	// пустая база допустима, если миграции ее заполнят
+	if cfg.Database.SkipStartupCheck {
+		log.Println("Startup check skipped (SKIP_STARTUP_CHECK)")
+	} else if err := database.StartupCheck(db, !cfg.Handlers.ReadOnly); err != nil {
+		log.Fatalf("Database startup check failed: %v", err)
 	}
-	log.Println("Database migrations applied successfully")
-
-	// инициализируем репозитории
-	var teamRepo repository.TeamRepository
-	var userRepo repository.UserRepository
-	var prRepo repository.PRRepository
-	var reviewRepo repository.ReviewRepository
-	var statsRepo repository.StatsRepository
-
-	if db != nil {
-		// используем PostgreSQL репозитории
-		teamRepo = postgres.NewTeamRepository(db)
-		userRepo = postgres.NewUserRepository(db)
-		prRepo = postgres.NewPRRepository(db)
-		reviewRepo = postgres.NewReviewRepository(db)
-		statsRepo = postgres.NewStatsRepository(db)
-		log.Println("Using PostgreSQL repositories")
+
+	// применяем миграции; восстановление после упавшей миграции идет через golang-migrate.
+	// реплика в режиме только для чтения не меняет схему, миграции применяет основной экземпляр
+	if cfg.Handlers.ReadOnly {
+		log.Println("Migrations skipped (READ_ONLY)")
+	} else if *forceMigrationVersion != database.NoForceVersion {
+		if err := database.RunMigrations(db, *forceMigrationVersion); err != nil {
+			log.Fatalf("Failed to run migrations: %v", err)
+		}
+		log.Println("Database migrations applied successfully")
 	} else {
-		log.Println("Database not available - cannot start without database")
-		return
+		if err := database.SimpleRunMigrations(db); err != nil {
+			log.Fatalf("Failed to run migrations: %v", err)
+		}
+		log.Println("Database migrations applied successfully")
+	}
+
+	// инициализируем репозитории выбранного хранилища
+	repos, err := repository.NewRepositories(cfg.Storage, db, readDB)
+	if err != nil {
+		log.Fatalf("Failed to initialize repositories: %v", err)
+	}
+	log.Printf("Using %s repositories", cfg.Storage.Backend)
+
+	// инициализируем публикацию событий назначения; при заданных TEAM_WEBHOOK_HOSTS команды могут
+	// переопределить адрес через /team/setWebhook
+	if len(cfg.Events.WebhookHosts) > 0 {
+		log.Printf("Team webhooks enabled for hosts %v", cfg.Events.WebhookHosts)
+	}
+	publisher := events.NewPublisher(cfg.Events, repos.Team)
+	if cfg.Events.Endpoint != "" {
+		log.Printf("Publishing assignment events to %s", cfg.Events.Endpoint)
+	}
+
+	// политика количества ревьюверов должна укладываться в общий лимит на PR
+	if err := cfg.Service.ReviewerPolicy.Validate(cfg.Service.MaxReviewersPerPR); err != nil {
+		log.Fatalf("Invalid reviewer policy: %v", err)
 	}
 
 	// инициализируем сервисы
-	teamService := service.NewTeamService(teamRepo, userRepo)
-	userService := service.NewUserService(userRepo, prRepo, teamRepo, reviewRepo)
-	prService := service.NewPRService(prRepo, reviewRepo, userRepo, teamService)
-	statsService := service.NewStatsService(statsRepo)
+	teamService := service.NewTeamService(repos.Team, repos.User)
+	userService := service.NewUserService(repos.User, repos.PR, repos.Team, repos.Review, cfg.Service)
+	teamService.SetUserService(userService)
+	teamService.SetWebhookHosts(cfg.Events.WebhookHosts)
+	prService := service.NewPRService(repos.PR, repos.Review, repos.User, teamService, cfg.Service, publisher)
+	userService.SetAssignmentPause(prService.AssignmentPause())
+	userService.SetPRService(prService)
+	statsService := service.NewStatsService(repos.Stats, repos.Events, repos.Migrations)
 
 	// инициализируем ручки
-	teamHandler := handlers.NewTeamHandler(teamService)
-	userHandler := handlers.NewUserHandler(userService)
-	prHandler := handlers.NewPRHandler(prService)
+	teamHandler := handlers.NewTeamHandler(teamService, cfg.Handlers)
+	userHandler := handlers.NewUserHandler(userService, cfg.Handlers)
+	prHandler := handlers.NewPRHandler(prService, cfg.Handlers)
 	statsHandler := handlers.NewStatsHandler(statsService)
+	adminHandler := handlers.NewAdminHandler(prService, statsService)
+	readiness := handlers.NewReadiness()
 
 	mux := http.NewServeMux()
 
 	// регистрируем ручки
-	mux.HandleFunc("/health", healthHandler)
+	mux.HandleFunc("/health", handlers.Health)
+	mux.HandleFunc("/ready", readiness.Ready)
 	mux.HandleFunc("/team/add", teamHandler.AddTeam)
 	mux.HandleFunc("/team/get", teamHandler.GetTeam)
+	mux.HandleFunc("/team/validate", teamHandler.ValidateTeam)
+	mux.HandleFunc("/team/sync", userHandler.SyncTeam)
+	mux.HandleFunc("/team/update", teamHandler.UpdateTeam)
+	mux.HandleFunc("/team/delete", teamHandler.DeleteTeam)
+	mux.HandleFunc("/team/rebalance", userHandler.RebalanceTeam)
+	mux.HandleFunc("/team/assignmentConfig", prHandler.GetTeamAssignmentConfig)
+	mux.HandleFunc("/team/authoredPRs", prHandler.GetTeamAuthoredPRs)
+	mux.HandleFunc("/team/setStrategy", teamHandler.SetStrategy)
+	mux.HandleFunc("/team/setWebhook", teamHandler.SetWebhook)
+	mux.HandleFunc("/users/create", userHandler.CreateUser)
+	mux.HandleFunc("/users/transfer", userHandler.TransferUser)
 	mux.HandleFunc("/users/setIsActive", userHandler.SetUserActive)
+	mux.HandleFunc("/users/setExpertise", userHandler.SetExpertise)
+	mux.HandleFunc("/users/setReviewerGroup", userHandler.SetReviewerGroup)
+	mux.HandleFunc("/users/setWeeklyCapacity", userHandler.SetWeeklyCapacity)
+	mux.HandleFunc("/users/optOut", userHandler.OptOut)
 	mux.HandleFunc("/pullRequest/create", prHandler.CreatePR)
 	mux.HandleFunc("/pullRequest/merge", prHandler.MergePR)
+	mux.HandleFunc("/pullRequest/close", prHandler.ClosePR)
+	mux.HandleFunc("/pullRequest/reopen", prHandler.ReopenPR)
 	mux.HandleFunc("/pullRequest/reassign", prHandler.ReassignReviewer)
+	mux.HandleFunc("/pullRequest/addReviewer", prHandler.AddReviewer)
+	mux.HandleFunc("/pullRequest/bulkReassign", prHandler.BulkReassign)
+	mux.HandleFunc("/pullRequest/reviewerSuggestions", prHandler.GetReviewerSuggestions)
+	mux.HandleFunc("/pullRequest/isReviewer", prHandler.IsReviewer)
+	mux.HandleFunc("/pullRequest/batchGet", prHandler.BatchGetPRs)
+	mux.HandleFunc("/pullRequest/get", prHandler.GetPR)
+	mux.HandleFunc("/pullRequests/list", prHandler.ListPRs)
+	mux.HandleFunc("/pullRequest/reviewersAt", prHandler.GetReviewersAt)
 	mux.HandleFunc("/users/getReview", userHandler.GetUserReviewPRs)
+	mux.HandleFunc("/users/blocking", userHandler.GetBlockingPRs)
+	mux.HandleFunc("/users/myReviewers", userHandler.GetMyReviewers)
+	mux.HandleFunc("/users/statusHistory", userHandler.GetStatusHistory)
+	mux.HandleFunc("/users/teamHistory", userHandler.GetTeamHistory)
+	mux.HandleFunc("/users/loadComparison", userHandler.GetLoadComparison)
 	mux.HandleFunc("/stats/review-assignments", statsHandler.GetReviewStats)
+	mux.HandleFunc("/stats/unassigned", statsHandler.GetUnassignedUsers)
+	mux.HandleFunc("/stats/stuck", statsHandler.GetStuckPRs)
+	mux.HandleFunc("/stats/aging", statsHandler.GetPRAging)
+	mux.HandleFunc("/stats/byStatus", statsHandler.GetAssignmentsByStatus)
+	mux.HandleFunc("/stats/authors", statsHandler.GetAuthorStats)
+	mux.HandleFunc("/stats/churn", statsHandler.GetReassignmentChurn)
+	mux.HandleFunc("/stats/pairs", statsHandler.GetReviewerPairs)
+	mux.HandleFunc("/stats/assignmentSLA", statsHandler.GetAssignmentSLA)
+	mux.HandleFunc("/stats/compareTeams", statsHandler.CompareTeams)
+	mux.HandleFunc("/export/events.ndjson", statsHandler.ExportAssignmentEvents)
 	mux.HandleFunc("/users/bulk-deactivate", userHandler.BulkDeactivate)
-	mux.HandleFunc("/", homeHandler)
+	mux.HandleFunc("/users/bulkSetActive", userHandler.BulkSetActive)
+	mux.HandleFunc("/admin/simulate", adminHandler.Simulate)
+	mux.HandleFunc("/admin/backfillStats", adminHandler.BackfillStats)
+	mux.HandleFunc("/admin/purge", adminHandler.Purge)
+	mux.HandleFunc("/admin/reassignByPolicy", adminHandler.ReassignByPolicy)
+	mux.HandleFunc("/admin/drain", readiness.Drain)
+	mux.HandleFunc("/admin/migrations", adminHandler.GetMigrations)
+	mux.HandleFunc("/admin/pauseAssignment", adminHandler.PauseAssignment)
+	mux.HandleFunc("/", handlers.Home)
+
+	// реплики для аналитики обслуживают только чтение
+	var handler http.Handler = mux
+	if cfg.Handlers.ReadOnly {
+		handler = handlers.ReadOnlyMiddleware(handler)
+		log.Println("Read-only mode: mutating requests are rejected with 503 READ_ONLY")
+	}
+
+	// при необходимости требуем подпись запросов от внутренних сервисов
+	if cfg.Handlers.RequireSignedRequests {
+		if cfg.Handlers.SigningSecret == "" {
+			log.Fatal("REQUIRE_SIGNED_REQUESTS is enabled but REQUEST_SIGNING_SECRET is not set")
+		}
+		handler = handlers.NewRequestSigning(cfg.Handlers.SigningSecret, cfg.Handlers.SignatureMaxAge).Middleware(handler)
+		log.Printf("Signed requests required (max timestamp skew %s)", cfg.Handlers.SignatureMaxAge)
+	}
 
 	server := &http.Server{
 		Addr:    ":" + cfg.ServerPort,
-		Handler: mux,
+		Handler: handler,
 	}
 
 	// логируем эндпоинты
@@ -97,15 +204,65 @@ func main() {
 		log.Println("Server is ready to handle requests")
 		log.Println("Available endpoints:")
 		log.Println("   GET  /health")
+		log.Println("   GET  /ready")
 		log.Println("   POST /team/add")
 		log.Println("   GET  /team/get?team_name=...")
+		log.Println("   POST /team/validate")
+		log.Println("   POST /team/sync")
+		log.Println("   POST /team/update")
+		log.Println("   POST /team/delete")
+		log.Println("   POST /team/rebalance")
+		log.Println("   GET  /team/assignmentConfig?team_name=...")
+		log.Println("   GET  /team/authoredPRs?team_name=...&status=OPEN")
+		log.Println("   POST /team/setStrategy")
+		log.Println("   POST /team/setWebhook")
+		log.Println("   POST /users/create")
+		log.Println("   POST /users/transfer")
 		log.Println("   POST /users/setIsActive")
+		log.Println("   POST /users/setExpertise")
+		log.Println("   POST /users/setReviewerGroup")
+		log.Println("   POST /users/setWeeklyCapacity")
+		log.Println("   POST /users/optOut")
+		log.Println("   GET  /users/optOut?user_id=...")
 		log.Println("   POST /pullRequest/create")
 		log.Println("   POST /pullRequest/merge")
+		log.Println("   POST /pullRequest/close")
+		log.Println("   POST /pullRequest/reopen")
 		log.Println("   POST /pullRequest/reassign")
+		log.Println("   POST /pullRequest/addReviewer")
+		log.Println("   POST /pullRequest/bulkReassign")
+		log.Println("   GET  /pullRequest/reviewerSuggestions?pull_request_id=...")
+		log.Println("   GET  /pullRequest/isReviewer?pull_request_id=...&user_id=...")
+		log.Println("   POST /pullRequest/batchGet")
+		log.Println("   GET  /pullRequest/get?pull_request_id=...&expand=reviewers")
+		log.Println("   GET  /pullRequests/list?status=...&author_id=...&limit=50&offset=0")
+		log.Println("   GET  /pullRequest/reviewersAt?pull_request_id=...&at=...&since=...")
 		log.Println("   GET  /users/getReview?user_id=...")
+		log.Println("   GET  /users/blocking?user_id=...&older_than=24h")
+		log.Println("   GET  /users/myReviewers?author_id=...&status=OPEN")
+		log.Println("   GET  /users/statusHistory?user_id=...&from=...&to=...")
+		log.Println("   GET  /users/teamHistory?user_id=...")
+		log.Println("   GET  /users/loadComparison?user_id=...")
 		log.Println("   GET  /stats/review-assignments")
+		log.Println("   GET  /stats/unassigned?team_name=...")
+		log.Println("   GET  /stats/stuck")
+		log.Println("   GET  /stats/aging?status=OPEN&buckets=1d,3d,7d&team_name=...&by_team=true")
+		log.Println("   GET  /stats/byStatus?user_id=...&team_name=...")
+		log.Println("   GET  /stats/authors?limit=...&status=...&from=...&to=...")
+		log.Println("   GET  /stats/churn?from=...&to=...&team_name=...&limit=10")
+		log.Println("   GET  /stats/pairs?from=...&to=...&team_name=...")
+		log.Println("   GET  /stats/assignmentSLA?threshold=2&within=5m&from=...&to=...&team_name=...")
+		log.Println("   GET  /stats/compareTeams?a=...&b=...")
+		log.Println("   GET  /export/events.ndjson?from=...&to=...")
 		log.Println("   POST /users/bulk-deactivate")
+		log.Println("   POST /users/bulkSetActive")
+		log.Println("   POST /admin/simulate")
+		log.Println("   POST /admin/backfillStats")
+		log.Println("   POST /admin/purge")
+		log.Println("   POST /admin/reassignByPolicy")
+		log.Println("   POST /admin/drain")
+		log.Println("   GET  /admin/migrations")
+		log.Println("   POST /admin/pauseAssignment")
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			log.Fatalf("Server failed: %v", err)
 		}
@@ -124,46 +281,8 @@ func main() {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
 
-	log.Println("Server stopped gracefully")
-}
-
-// обработчик эндпоинта проверки healthy сервиса
-// принимает: HTTP запрос и writer для ответа на запросы проверки health
-// возвращает: JSON ответ со статусом, названием и версией сервиса
-func healthHandler(w http.ResponseWriter, r *http.Request) {
-	if r.URL.Path != "/health" {
-		http.NotFound(w, r)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	jsonResponse := `{"status":"healthy","service":"PR Reviewer Assignment Service","version":"1.0.0"}`
-	w.Write([]byte(jsonResponse))
-}
-
-// обработчик корневого эндпоинт
-// принимает: HTTP запрос и writer для ответа на запросы к корневому пути
-// возвращает: JSON с описанием сервиса, версией и списком доступных эндпоинтов
-func homeHandler(w http.ResponseWriter, r *http.Request) {
-	if r.URL.Path != "/" {
-		http.NotFound(w, r)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-
-	response := `{
-		"service": "PR Reviewer Service is running!",
-		"version": "1.0.0",
-		"endpoints": {
-			"health": "/health",
-			"teams": "/team/add, /team/get",
-			"users": "/users/setIsActive, /users/getReview",
-			"pull_requests": "/pullRequest/create, /pullRequest/merge, /pullRequest/reassign"
-		}
-	}`
+	// дожидаемся отправки оставшихся событий
+	publisher.Close()
 
-	w.Write([]byte(response))
+	log.Println("Server stopped gracefully")
 }
@@ -1,29 +1,94 @@
 package config
 
 import (
+	"log"
 	"os"
 	"pull-request-reviewer-assignment-service/internal/database"
+	"pull-request-reviewer-assignment-service/internal/events"
+	"pull-request-reviewer-assignment-service/internal/handlers"
+	"pull-request-reviewer-assignment-service/internal/models"
+	"pull-request-reviewer-assignment-service/internal/repository"
+	"pull-request-reviewer-assignment-service/internal/service"
+	"strconv"
+	"strings"
+	"time"
 )
 
-// структура приложения, содержащая настройки сервера и базы данных
+// структура приложения, содержащая настройки сервера, хранилища, базы данных, бизнес-логики, публикации событий и разбора запросов
 type Config struct {
 	ServerPort string
 	Database   database.Config
+	Storage    repository.Config
+	Service    service.Config
+	Events     events.Config
+	Handlers   handlers.Config
 }
 
 // загружает структуру приложения из переменных окружения с значениями по умолчанию
 // принимает: значения из переменных окружения или использует значения по умолчанию
 // возвращает: указатель на структуру Config с настройками сервера и базы данных
 func Load() *Config {
+	// лимит batchGet в сервисе и лимит массовых запросов при декодировании задаются одной переменной
+	maxBatchSize := getEnvInt("MAX_BATCH_SIZE", 100)
+
 	return &Config{
 		ServerPort: getEnv("PORT", "8080"),
 		Database: database.Config{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnv("DB_PORT", "5432"),
-			User:     getEnv("DB_USER", "postgres"),
-			Password: getEnv("DB_PASSWORD", "password"),
-			DBName:   getEnv("DB_NAME", "pr_reviewer"),
-			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+			Host:             getEnv("DB_HOST", "localhost"),
+			Port:             getEnv("DB_PORT", "5432"),
+			User:             getEnv("DB_USER", "postgres"),
+			Password:         getEnv("DB_PASSWORD", "password"),
+			DBName:           getEnv("DB_NAME", "pr_reviewer"),
+			SSLMode:          getEnv("DB_SSLMODE", "disable"),
+			ReadHost:         getEnv("DB_READ_HOST", ""),
+			ReadPort:         getEnv("DB_READ_PORT", ""),
+			SkipStartupCheck: getEnvBool("SKIP_STARTUP_CHECK", false),
+		},
+		Storage: repository.Config{
+			Backend: getEnv("STORAGE_BACKEND", repository.BackendPostgres),
+		},
+		Service: service.Config{
+			MinActivePerTeam:       getEnvInt("MIN_ACTIVE_PER_TEAM", 0),
+			AssignmentStrategy:     getEnv("ASSIGNMENT_STRATEGY", service.StrategyRandom),
+			MaxPRNameLength:        getEnvInt("MAX_PR_NAME_LENGTH", 200),
+			PRNameOverflow:         getEnv("PR_NAME_OVERFLOW", service.PRNameOverflowReject),
+			MaxBatchSize:           maxBatchSize,
+			SizeBasedReviewers:     getEnvBool("SIZE_BASED_REVIEWERS", false),
+			OwnershipAffinity:      getEnvBool("OWNERSHIP_AFFINITY", false),
+			RelatedAuthorAffinity:  getEnvBool("RELATED_AUTHOR_AFFINITY", false),
+			RelatedAuthorWindow:    getEnvDuration("RELATED_AUTHOR_WINDOW", 30*24*time.Hour),
+			ReassignMinInterval:    getEnvDuration("REASSIGN_MIN_INTERVAL", 0),
+			InactiveFallbackWindow: getEnvDuration("INACTIVE_FALLBACK_WINDOW", 0),
+			MaxReviewersPerPR:      getEnvInt("MAX_REVIEWERS_PER_PR", 10),
+			EnforceFairness:        getEnvBool("ENFORCE_FAIRNESS", false),
+			FairnessMaxDelta:       getEnvInt("FAIRNESS_MAX_DELTA", 1),
+			BulkConcurrency:        getEnvInt("BULK_CONCURRENCY", 4),
+			AllowInactiveAuthor:    getEnvBool("ALLOW_INACTIVE_AUTHOR", false),
+			SpreadAuthorReviews:    getEnvBool("SPREAD_AUTHOR_REVIEWS", false),
+			ManualAssignment:       getEnvBool("MANUAL_ASSIGNMENT", false),
+			ManualAssignmentTeams:  getEnvList("MANUAL_ASSIGNMENT_TEAMS"),
+			OneReviewerPerGroup:    getEnvBool("ONE_REVIEWER_PER_GROUP", false),
+			RequiredReviewerTeams:  getEnvMap("REQUIRED_REVIEWER_TEAMS"),
+			ReviewerPolicy: service.ReviewerPolicy{
+				MaxReviewers: getEnvInt("REVIEWER_POLICY_MAX", 2),
+				MinReviewers: getEnvInt("REVIEWER_POLICY_MIN", 0),
+			},
+			AssignmentRetries: getEnvInt("ASSIGNMENT_RETRIES", 3),
+			RecentAuthorPRs:   getEnvInt("RECENT_AUTHOR_PRS", 3),
+		},
+		Events: events.Config{
+			Endpoint:     getEnv("EVENTS_ENDPOINT", ""),
+			QueueSize:    getEnvInt("EVENTS_QUEUE_SIZE", 100),
+			WebhookHosts: getEnvList("TEAM_WEBHOOK_HOSTS"),
+		},
+		Handlers: handlers.Config{
+			MaxTeamMembers:        getEnvInt("MAX_TEAM_MEMBERS", 500),
+			MaxBulkItems:          maxBatchSize,
+			RequireSignedRequests: getEnvBool("REQUIRE_SIGNED_REQUESTS", false),
+			SigningSecret:         getEnv("REQUEST_SIGNING_SECRET", ""),
+			SignatureMaxAge:       getEnvDuration("SIGNATURE_MAX_AGE", 5*time.Minute),
+			TimestampFormat:       getEnv("TIMESTAMP_FORMAT", models.TimestampRFC3339Nano),
+			ReadOnly:              getEnvBool("READ_ONLY", false),
 		},
 	}
 }
@@ -37,3 +102,87 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// получает целочисленное значение переменной окружения или возвращает значение по умолчанию
+// принимает: ключ переменной окружения и значение по умолчанию
+// возвращает: значение переменной окружения или значение по умолчанию, если переменная не задана или невалидна
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Invalid integer value for %s: %q, using default %d", key, value, defaultValue)
+		return defaultValue
+	}
+	return parsed
+}
+
+// получает булево значение переменной окружения или возвращает значение по умолчанию
+// принимает: ключ переменной окружения и значение по умолчанию
+// возвращает: значение переменной окружения или значение по умолчанию, если переменная не задана или невалидна
+func getEnvBool(key string, defaultValue bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Invalid boolean value for %s: %q, using default %t", key, value, defaultValue)
+		return defaultValue
+	}
+	return parsed
+}
+
+// получает значение длительности из переменной окружения или возвращает значение по умолчанию
+// принимает: ключ переменной окружения и значение по умолчанию
+// возвращает: значение переменной окружения в формате time.ParseDuration или значение по умолчанию, если переменная не задана или невалидна
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Invalid duration value for %s: %q, using default %s", key, value, defaultValue)
+		return defaultValue
+	}
+	return parsed
+}
+
+// получает список значений из переменной окружения, разделенных запятыми
+// принимает: ключ переменной окружения
+// возвращает: слайс непустых значений без пробелов по краям (nil если переменная не задана)
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// получает соответствие ключей и значений из переменной окружения в формате key:value, разделенных запятыми
+// принимает: ключ переменной окружения
+// возвращает: map значений (nil если переменная не задана); элементы без двоеточия пропускаются с предупреждением
+func getEnvMap(key string) map[string]string {
+	var values map[string]string
+	for _, item := range getEnvList(key) {
+		name, value, ok := strings.Cut(item, ":")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !ok || name == "" || value == "" {
+			log.Printf("Invalid entry in %s: %q, expected key:value, skipping", key, item)
+			continue
+		}
+		if values == nil {
+			values = make(map[string]string)
+		}
+		values[name] = value
+	}
+	return values
+}
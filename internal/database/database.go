@@ -18,15 +18,44 @@ type Config struct {
 	Password string
 	DBName   string
 	SSLMode  string
+	// адрес реплики для чтения (пусто - чтение выполняется с основной базы)
+	ReadHost string
+	// порт реплики для чтения (пусто - используется Port)
+	ReadPort string
+	// пропустить проверку схемы при старте
+	SkipStartupCheck bool
 }
 
 // устанавливает подключение к базе данных с повторными попытками
 // принимает: конфигурацию подключения к базе данных
 // возвращает: подключение к БД или ошибку после исчерпания попыток
 func Connect(cfg Config) (*sql.DB, error) {
+	return connect(cfg.Host, cfg.Port, cfg)
+}
+
+// устанавливает подключение к реплике для чтения, если она настроена
+// принимает: конфигурацию подключения к базе данных
+// возвращает: подключение к реплике, nil если реплика не настроена, или ошибку после исчерпания попыток
+func ConnectReplica(cfg Config) (*sql.DB, error) {
+	if cfg.ReadHost == "" {
+		return nil, nil
+	}
+
+	port := cfg.ReadPort
+	if port == "" {
+		port = cfg.Port
+	}
+	return connect(cfg.ReadHost, port, cfg)
+}
+
+// устанавливает подключение к указанному серверу базы данных с повторными попытками
+// принимает: хост, порт и конфигурацию с учетными данными
+// возвращает: подключение к БД или ошибку после исчерпания попыток
+func connect(host, port string, cfg Config) (*sql.DB, error) {
+	// часовой пояс сессии UTC: время из БД читается в UTC независимо от настроек сервера БД
 	connStr := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName, cfg.SSLMode,
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s timezone=UTC",
+		host, port, cfg.User, cfg.Password, cfg.DBName, cfg.SSLMode,
 	)
 
 	var db *sql.DB
@@ -34,7 +63,7 @@ func Connect(cfg Config) (*sql.DB, error) {
 
 	maxAttempts := 10
 	for attempt := 1; attempt <= maxAttempts; attempt++ {
-		log.Printf("Attempting to connect to database (attempt %d/%d)...", attempt, maxAttempts)
+		log.Printf("Attempting to connect to database %s:%s (attempt %d/%d)...", host, port, attempt, maxAttempts)
 
 		db, err = sql.Open("postgres", connStr)
 		if err != nil {
@@ -2,6 +2,7 @@ package database
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"path/filepath"
@@ -11,21 +12,32 @@ import (
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 )
 
-// применяет миграции к базе данных
-// принимает: подключение к БД в качестве параметра
-// возвращает: ошибку в случае неудачи или nil при успешном выполнении
-func RunMigrations(db *sql.DB) error {
-	driver, err := postgres.WithInstance(db, &postgres.Config{})
-	if err != nil {
-		return fmt.Errorf("could not create migration driver: %w", err)
-	}
+// значение forceVersion, при котором грязное состояние миграций не сбрасывается
+const NoForceVersion = -1
 
+// применяет миграции к базе данных; если предыдущая миграция упала и оставила грязное состояние,
+// при заданном forceVersion помечает базу чистой на этой версии и повторяет применение
+// принимает: подключение к БД и версию для сброса грязного состояния (NoForceVersion - не сбрасывать)
+// возвращает: ошибку в случае неудачи или nil при успешном выполнении
+func RunMigrations(db *sql.DB, forceVersion int) error {
 	// получаем абсолютный путь к миграциям
 	migrationsPath, err := filepath.Abs("migrations")
 	if err != nil {
 		return fmt.Errorf("could not get absolute path to migrations: %w", err)
 	}
 
+	return runMigrations(db, migrationsPath, forceVersion)
+}
+
+// применяет миграции из указанной папки, см. RunMigrations
+// принимает: подключение к БД, абсолютный путь к папке миграций и версию для сброса грязного состояния
+// возвращает: ошибку в случае неудачи или nil при успешном выполнении
+func runMigrations(db *sql.DB, migrationsPath string, forceVersion int) error {
+	driver, err := postgres.WithInstance(db, &postgres.Config{})
+	if err != nil {
+		return fmt.Errorf("could not create migration driver: %w", err)
+	}
+
 	migrationSource := fmt.Sprintf("file://%s", migrationsPath)
 
 	m, err := migrate.NewWithDatabaseInstance(
@@ -37,8 +49,17 @@ func RunMigrations(db *sql.DB) error {
 		return fmt.Errorf("could not create migration instance: %w", err)
 	}
 
+	if err := recoverDirtyState(m, forceVersion); err != nil {
+		return err
+	}
+
 	// применяем миграции
 	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
+		var dirtyErr migrate.ErrDirty
+		if errors.As(err, &dirtyErr) {
+			log.Printf("Migration %d failed and left the database dirty; fix the cause and restart with --force-migration-version %d",
+				dirtyErr.Version, dirtyErr.Version-1)
+		}
 		return fmt.Errorf("could not run migrations: %w", err)
 	}
 
@@ -47,6 +68,54 @@ func RunMigrations(db *sql.DB) error {
 		return fmt.Errorf("could not get migration version: %w", err)
 	}
 
+	// ведем тот же учет, что и упрощенный раннер, чтобы /admin/migrations не зависел от способа запуска
+	if err == nil && !dirty {
+		if err := recordAppliedMigrations(db, migrationsPath, int(version)); err != nil {
+			return err
+		}
+	}
+
 	log.Printf("Migrations applied successfully. Version: %d, Dirty: %t", version, dirty)
 	return nil
 }
+
+// записывает в таблицу учета миграции, примененные golang-migrate до указанной версии
+// принимает: подключение к БД, путь к папке миграций и текущую версию schema_migrations
+// возвращает: ошибку создания таблицы учета или записи
+func recordAppliedMigrations(db *sql.DB, migrationsPath string, version int) error {
+	migrationFiles, err := listMigrationFiles(migrationsPath)
+	if err != nil {
+		return err
+	}
+	if err := ensureMigrationHistory(db); err != nil {
+		return err
+	}
+	return recordMigrationsUpTo(db, migrationFiles, version)
+}
+
+// проверяет, не осталась ли база в грязном состоянии после упавшей миграции, и при заданной версии сбрасывает его
+// принимает: экземпляр migrate и версию для сброса (NoForceVersion - только проверить)
+// возвращает: ошибку, если база грязная и версия не задана или сброс не удался
+func recoverDirtyState(m *migrate.Migrate, forceVersion int) error {
+	version, dirty, err := m.Version()
+	if err != nil && err != migrate.ErrNilVersion {
+		return fmt.Errorf("could not get migration version: %w", err)
+	}
+
+	if !dirty {
+		if forceVersion != NoForceVersion {
+			log.Printf("Database is not dirty (version %d), ignoring --force-migration-version %d", version, forceVersion)
+		}
+		return nil
+	}
+
+	if forceVersion == NoForceVersion {
+		return fmt.Errorf("database is dirty at migration version %d: check whether it was partially applied and restart with --force-migration-version set to the last fully applied version", version)
+	}
+
+	log.Printf("Database is dirty at migration version %d, forcing version %d and retrying", version, forceVersion)
+	if err := m.Force(forceVersion); err != nil {
+		return fmt.Errorf("could not force migration version %d: %w", forceVersion, err)
+	}
+	return nil
+}
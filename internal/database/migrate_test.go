@@ -0,0 +1,227 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// отдельная схема в тестовой БД, чтобы не трогать таблицы, с которыми работают E2E тесты
+const migrateTestSchema = "migrate_dirty_test"
+
+// открывает подключение к тестовой БД из docker-compose.e2e.yml с search_path на отдельную схему
+func openMigrateTestDatabase(t *testing.T) *sql.DB {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("Skipping migration tests against test database in short mode")
+	}
+
+	connStr := "host=localhost port=5434 user=postgres password=password dbname=pr_reviewer_e2e sslmode=disable"
+	admin, err := sql.Open("postgres", connStr)
+	require.NoError(t, err)
+	defer admin.Close()
+	if err := admin.Ping(); err != nil {
+		t.Skipf("Test database is not available: %v", err)
+	}
+
+	_, err = admin.Exec(fmt.Sprintf("DROP SCHEMA IF EXISTS %s CASCADE", migrateTestSchema))
+	require.NoError(t, err)
+	_, err = admin.Exec(fmt.Sprintf("CREATE SCHEMA %s", migrateTestSchema))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		cleanup, err := sql.Open("postgres", connStr)
+		if err == nil {
+			cleanup.Exec(fmt.Sprintf("DROP SCHEMA IF EXISTS %s CASCADE", migrateTestSchema))
+			cleanup.Close()
+		}
+	})
+
+	db, err := sql.Open("postgres", connStr+" search_path="+migrateTestSchema)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestRunMigrationsRecoversFromDirtyStateWithForce(t *testing.T) {
+	db := openMigrateTestDatabase(t)
+	migrationsPath, err := filepath.Abs("../../migrations")
+	require.NoError(t, err)
+
+	require.NoError(t, runMigrations(db, migrationsPath, NoForceVersion))
+
+	var latest int
+	require.NoError(t, db.QueryRow("SELECT version FROM schema_migrations").Scan(&latest))
+
+	// имитируем упавшую последнюю миграцию; миграции идемпотентны, поэтому ее можно применить повторно
+	_, err = db.Exec("UPDATE schema_migrations SET dirty = true")
+	require.NoError(t, err)
+
+	err = runMigrations(db, migrationsPath, NoForceVersion)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "dirty")
+
+	require.NoError(t, runMigrations(db, migrationsPath, latest-1))
+
+	var version int
+	var dirty bool
+	require.NoError(t, db.QueryRow("SELECT version, dirty FROM schema_migrations").Scan(&version, &dirty))
+	assert.Equal(t, latest, version)
+	assert.False(t, dirty)
+}
+
+func TestMigrationHistoryRecordedInOrder(t *testing.T) {
+	db := openMigrateTestDatabase(t)
+	migrationsPath, err := filepath.Abs("../../migrations")
+	require.NoError(t, err)
+
+	require.NoError(t, simpleRunMigrations(db, migrationsPath))
+
+	files, err := filepath.Glob(filepath.Join(migrationsPath, "*.up.sql"))
+	require.NoError(t, err)
+	assert.Equal(t, migrationFilenames(files), recordedMigrations(t, db))
+}
+
+func TestRunMigrationsRecordsMigrationHistory(t *testing.T) {
+	db := openMigrateTestDatabase(t)
+	migrationsPath, err := filepath.Abs("../../migrations")
+	require.NoError(t, err)
+
+	// запуск через golang-migrate (--force-migration-version) тоже заполняет учет
+	require.NoError(t, runMigrations(db, migrationsPath, NoForceVersion))
+
+	files, err := filepath.Glob(filepath.Join(migrationsPath, "*.up.sql"))
+	require.NoError(t, err)
+	assert.Equal(t, migrationFilenames(files), recordedMigrations(t, db))
+
+	// последующий запуск упрощенным раннером ничего не применяет повторно
+	require.NoError(t, simpleRunMigrations(db, migrationsPath))
+	assert.Equal(t, migrationFilenames(files), recordedMigrations(t, db))
+}
+
+// возвращает имена файлов миграций без пути
+func migrationFilenames(paths []string) []string {
+	names := make([]string, 0, len(paths))
+	for _, path := range paths {
+		names = append(names, filepath.Base(path))
+	}
+	return names
+}
+
+// возвращает имена файлов из таблицы учета в порядке версий
+func recordedMigrations(t *testing.T, db *sql.DB) []string {
+	t.Helper()
+	rows, err := db.Query(`SELECT filename FROM ` + migrationHistoryTable + ` ORDER BY version`)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	var filenames []string
+	for rows.Next() {
+		var filename string
+		require.NoError(t, rows.Scan(&filename))
+		filenames = append(filenames, filename)
+	}
+	require.NoError(t, rows.Err())
+	return filenames
+}
+
+func TestSimpleRunMigrationsUpgradesLegacySchema(t *testing.T) {
+	db := openMigrateTestDatabase(t)
+	migrationsPath, err := filepath.Abs("../../migrations")
+	require.NoError(t, err)
+
+	// база, созданная старым запуском миграций: только 001 и 002, без таблицы учета
+	for _, filename := range []string{"001_init_schema.up.sql", "002_add_indexes.up.sql"} {
+		content, err := os.ReadFile(filepath.Join(migrationsPath, filename))
+		require.NoError(t, err)
+		_, err = db.Exec(string(content))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, simpleRunMigrations(db, migrationsPath))
+
+	// таблица из 011 и колонка из последней миграции появились, значит применены все ожидающие файлы
+	var webhooks sql.NullString
+	require.NoError(t, db.QueryRow("SELECT to_regclass('team_webhooks')::text").Scan(&webhooks))
+	assert.True(t, webhooks.Valid)
+	var columns int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'pull_requests' AND column_name = 'closed_at'`).Scan(&columns))
+	assert.Equal(t, 1, columns)
+
+	files, err := filepath.Glob(filepath.Join(migrationsPath, "*.up.sql"))
+	require.NoError(t, err)
+	applied, err := appliedVersions(db)
+	require.NoError(t, err)
+	assert.Len(t, applied, len(files))
+
+	// повторный запуск ничего не применяет
+	require.NoError(t, simpleRunMigrations(db, migrationsPath))
+}
+
+func TestMigrationsAfterLegacyBaselineAreRerunnable(t *testing.T) {
+	files, err := filepath.Glob("../../migrations/*.up.sql")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	comment := regexp.MustCompile(`--[^\n]*`)
+	constraint := regexp.MustCompile(`ADD CONSTRAINT (\w+)`)
+	for _, path := range files {
+		filename := filepath.Base(path)
+		version, err := migrationVersion(filename)
+		require.NoError(t, err)
+		if version <= legacyBaselineFallback {
+			continue
+		}
+
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		sql := strings.ToUpper(comment.ReplaceAllString(string(content), ""))
+		for _, statement := range strings.Split(sql, ";") {
+			statement = strings.Join(strings.Fields(statement), " ")
+			switch {
+			case strings.HasPrefix(statement, "CREATE TABLE"), strings.HasPrefix(statement, "CREATE INDEX"),
+				strings.HasPrefix(statement, "CREATE UNIQUE INDEX"), strings.Contains(statement, "ADD COLUMN"):
+				assert.Contains(t, statement, "IF NOT EXISTS", "%s: %s", filename, statement)
+			case strings.HasPrefix(statement, "DROP"), strings.Contains(statement, "DROP CONSTRAINT"),
+				strings.Contains(statement, "DROP COLUMN"):
+				assert.Contains(t, statement, "IF EXISTS", "%s: %s", filename, statement)
+			case strings.HasPrefix(statement, "INSERT"):
+				assert.True(t, strings.Contains(statement, "NOT EXISTS") || strings.Contains(statement, "ON CONFLICT"),
+					"%s: backfill must skip existing rows: %s", filename, statement)
+			}
+			if match := constraint.FindStringSubmatch(statement); match != nil {
+				assert.Contains(t, sql, "DROP CONSTRAINT IF EXISTS "+match[1], "%s: %s", filename, statement)
+			}
+		}
+	}
+}
+
+func TestSimpleRunMigrationsRerunsLegacyDatabaseWithoutDuplicates(t *testing.T) {
+	db := openMigrateTestDatabase(t)
+	migrationsPath, err := filepath.Abs("../../migrations")
+	require.NoError(t, err)
+
+	// база, которую старый запуск довел до последней схемы, но без таблицы учета
+	require.NoError(t, simpleRunMigrations(db, migrationsPath))
+	_, err = db.Exec(`INSERT INTO teams (team_name) VALUES ('backend');
+		INSERT INTO users (user_id, username, team_name) VALUES ('u1', 'Alice', 'backend');
+		INSERT INTO team_membership_events (user_id, team_name, event_type) VALUES ('u1', 'backend', 'JOIN')`)
+	require.NoError(t, err)
+	_, err = db.Exec("DROP TABLE " + migrationHistoryTable)
+	require.NoError(t, err)
+
+	// миграции после legacyBaselineFallback применяются повторно
+	require.NoError(t, simpleRunMigrations(db, migrationsPath))
+
+	var events int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM team_membership_events WHERE user_id = 'u1'").Scan(&events))
+	assert.Equal(t, 1, events)
+}
@@ -7,71 +7,198 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 )
 
-// применяет миграции базы данных из папки migrations с проверкой существования таблиц
+// таблица учета примененных миграций; отличается от schema_migrations, которую ведет golang-migrate
+const migrationHistoryTable = "migration_history"
+
+// применяет миграции базы данных из папки migrations, которые еще не записаны в таблицу учета
 // принимает: подключение к базе данных для применения SQL-миграций
-// возвращает: ошибку в случае неудачи или nil при успешном выполнении/пропуске миграций
+// возвращает: ошибку в случае неудачи или nil при успешном выполнении/отсутствии новых миграций
 func SimpleRunMigrations(db *sql.DB) error {
-	migrationsPath := "migrations"
+	return simpleRunMigrations(db, "migrations")
+}
 
+// применяет миграции из указанной папки, см. SimpleRunMigrations
+// принимает: подключение к базе данных и путь к папке миграций
+// возвращает: ошибку в случае неудачи или nil при успешном выполнении/отсутствии новых миграций
+func simpleRunMigrations(db *sql.DB, migrationsPath string) error {
 	if _, err := os.Stat(migrationsPath); os.IsNotExist(err) {
 		log.Printf("Migrations directory does not exist: %s", migrationsPath)
 		return nil
 	}
 
+	migrationFiles, err := listMigrationFiles(migrationsPath)
+	if err != nil {
+		return err
+	}
+	if len(migrationFiles) == 0 {
+		log.Println("No migration files found")
+		return nil
+	}
+
 	log.Println("Checking database state...")
-	tablesExist, err := checkIfTablesExist(db)
+	if err := ensureMigrationHistory(db); err != nil {
+		return err
+	}
+	applied, err := appliedVersions(db)
 	if err != nil {
-		return fmt.Errorf("failed to check database state: %w", err)
+		return err
+	}
+
+	if len(applied) == 0 {
+		baseline, err := legacyBaselineVersion(db)
+		if err != nil {
+			return err
+		}
+		if baseline > 0 {
+			log.Printf("Database was created before migration tracking, treating migrations up to %d as applied", baseline)
+			if err := recordMigrationsUpTo(db, migrationFiles, baseline); err != nil {
+				return err
+			}
+			if applied, err = appliedVersions(db); err != nil {
+				return err
+			}
+		}
+	}
+
+	pending := 0
+	for _, filename := range migrationFiles {
+		version, err := migrationVersion(filename)
+		if err != nil {
+			return err
+		}
+		if applied[version] {
+			continue
+		}
+		pending++
+
+		content, err := os.ReadFile(filepath.Join(migrationsPath, filename))
+		if err != nil {
+			return fmt.Errorf("could not read migration file %s: %w", filename, err)
+		}
+
+		log.Printf("Applying migration: %s", filename)
+
+		// файл и запись в учет применяются вместе, чтобы упавшая миграция не считалась примененной
+		tx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("could not start migration %s: %w", filename, err)
+		}
+		if _, err := tx.Exec(string(content)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("could not execute migration %s: %w", filename, err)
+		}
+		if err := recordMigration(tx, filename); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("could not commit migration %s: %w", filename, err)
+		}
+
+		log.Printf("Applied migration: %s", filename)
 	}
 
-	if tablesExist {
-		log.Println("Database tables already exist, skipping migrations")
+	if pending == 0 {
+		log.Println("Database schema is up to date, no pending migrations")
 		return nil
 	}
+	log.Printf("Applied %d pending migrations", pending)
+	return nil
+}
 
+// возвращает имена файлов .up.sql из папки миграций в порядке применения
+// принимает: путь к папке миграций
+// возвращает: отсортированный слайс имен файлов или ошибку чтения папки
+func listMigrationFiles(migrationsPath string) ([]string, error) {
 	files, err := os.ReadDir(migrationsPath)
 	if err != nil {
-		return fmt.Errorf("could not read migrations directory: %w", err)
+		return nil, fmt.Errorf("could not read migrations directory: %w", err)
 	}
 
 	var migrationFiles []string
 	for _, file := range files {
-		if strings.HasSuffix(file.Name(), ".up.sql") && !strings.Contains(file.Name(), "down.sql") {
+		if strings.HasSuffix(file.Name(), ".up.sql") {
 			migrationFiles = append(migrationFiles, file.Name())
 		}
 	}
-
 	sort.Strings(migrationFiles)
+	return migrationFiles, nil
+}
 
-	if len(migrationFiles) == 0 {
-		log.Println("No migration files found")
-		return nil
+// создает таблицу учета примененных миграций, если ее еще нет
+// принимает: подключение к базе данных
+// возвращает: ошибку создания таблицы или nil
+func ensureMigrationHistory(db *sql.DB) error {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS ` + migrationHistoryTable + ` (
+		version INTEGER PRIMARY KEY,
+		filename VARCHAR(255) NOT NULL,
+		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`); err != nil {
+		return fmt.Errorf("could not create %s table: %w", migrationHistoryTable, err)
 	}
+	return nil
+}
 
-	log.Println("Starting database migrations...")
+// возвращает версии миграций, записанные в таблицу учета
+// принимает: подключение к базе данных
+// возвращает: множество примененных версий или ошибку запроса
+func appliedVersions(db *sql.DB) (map[int]bool, error) {
+	rows, err := db.Query(`SELECT version FROM ` + migrationHistoryTable)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query applied migrations: %w", err)
+	}
+	defer rows.Close()
 
-	for _, filename := range migrationFiles {
-		filePath := filepath.Join(migrationsPath, filename)
-		content, err := os.ReadFile(filePath)
-		if err != nil {
-			return fmt.Errorf("could not read migration file %s: %w", filename, err)
+	applied := make(map[int]bool)
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("failed to scan applied migration: %w", err)
 		}
+		applied[version] = true
+	}
+	return applied, rows.Err()
+}
 
-		log.Printf("Applying migration: %s", filename)
+// последняя миграция, которую применял старый запуск миграций до появления таблицы учета
+const legacyBaselineFallback = 2
 
-		_, err = db.Exec(string(content))
-		if err != nil {
-			return fmt.Errorf("could not execute migration %s: %w", filename, err)
+// определяет, до какой версии схема уже применена в базе без записей в таблице учета:
+// версия golang-migrate, если база велась им, иначе legacyBaselineFallback для базы, созданной старым запуском миграций.
+// Такая база могла получить и более поздние миграции, поэтому все миграции после legacyBaselineFallback обязаны
+// быть идемпотентными (IF NOT EXISTS, DROP ... IF EXISTS перед ADD CONSTRAINT, INSERT с NOT EXISTS), это проверяет
+// TestMigrationsAfterLegacyBaselineAreRerunnable
+// принимает: подключение к базе данных
+// возвращает: версию, до которой миграции считаются примененными (0 - пустая база), или ошибку
+func legacyBaselineVersion(db *sql.DB) (int, error) {
+	var table sql.NullString
+	if err := db.QueryRow("SELECT to_regclass('schema_migrations')::text").Scan(&table); err != nil {
+		return 0, fmt.Errorf("failed to check schema_migrations table: %w", err)
+	}
+	if table.Valid {
+		var version int
+		var dirty bool
+		err := db.QueryRow("SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+		if err != nil && err != sql.ErrNoRows {
+			return 0, fmt.Errorf("failed to read schema_migrations version: %w", err)
+		}
+		if err == nil && !dirty {
+			return version, nil
 		}
-
-		log.Printf("Applied migration: %s", filename)
 	}
 
-	log.Println("All migrations applied successfully")
-	return nil
+	tablesExist, err := checkIfTablesExist(db)
+	if err != nil {
+		return 0, fmt.Errorf("failed to check database state: %w", err)
+	}
+	if !tablesExist {
+		return 0, nil
+	}
+	return legacyBaselineFallback, nil
 }
 
 // проверяет существование всех основных таблиц базы данных
@@ -84,7 +211,7 @@ func checkIfTablesExist(db *sql.DB) (bool, error) {
 		var exists bool
 		query := `SELECT EXISTS (
 			SELECT FROM information_schema.tables 
-			WHERE table_schema = 'public' 
+			WHERE table_schema = current_schema()
 			AND table_name = $1
 		)`
 
@@ -103,3 +230,59 @@ func checkIfTablesExist(db *sql.DB) (bool, error) {
 
 	return true, nil
 }
+
+// выполняет запрос в базе или в транзакции
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// возвращает версию миграции из числового префикса имени файла
+// принимает: имя файла миграции
+// возвращает: номер версии или ошибку, если префикс не число
+func migrationVersion(filename string) (int, error) {
+	prefix, _, _ := strings.Cut(filename, "_")
+	version, err := strconv.Atoi(prefix)
+	if err != nil {
+		return 0, fmt.Errorf("migration %s has no numeric version prefix", filename)
+	}
+	return version, nil
+}
+
+// записывает примененную миграцию в таблицу учета; уже записанная версия не перезаписывается
+// принимает: подключение к базе данных или транзакцию и имя файла миграции
+// возвращает: ошибку если префикс не число или запись не удалась
+func recordMigration(db execer, filename string) error {
+	version, err := migrationVersion(filename)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO `+migrationHistoryTable+` (version, filename, applied_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (version) DO NOTHING
+	`, version, filename)
+	if err != nil {
+		return fmt.Errorf("could not record migration %s: %w", filename, err)
+	}
+	return nil
+}
+
+// записывает в таблицу учета все миграции с версией не выше указанной
+// принимает: подключение к базе данных, отсортированные имена файлов миграций и версию
+// возвращает: ошибку записи или nil
+func recordMigrationsUpTo(db *sql.DB, migrationFiles []string, upTo int) error {
+	for _, filename := range migrationFiles {
+		version, err := migrationVersion(filename)
+		if err != nil {
+			return err
+		}
+		if version > upTo {
+			break
+		}
+		if err := recordMigration(db, filename); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+)
+
+// проверяет до применения миграций, что база доступна и это база сервиса, а не чужая
+// принимает: подключение к базе данных и признак, что пустая база допустима (схему создадут миграции)
+// возвращает: ошибку с описанием проблемы, если база не отвечает или содержит чужие таблицы без схемы сервиса
+func StartupCheck(db *sql.DB, allowEmpty bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var one int
+	if err := db.QueryRowContext(ctx, "SELECT 1").Scan(&one); err != nil {
+		return fmt.Errorf("startup check failed: database is not responding: %w", err)
+	}
+
+	tablesExist, err := checkIfTablesExist(db)
+	if err != nil {
+		return fmt.Errorf("startup check failed: %w", err)
+	}
+	if !tablesExist {
+		// пустую базу заполнят миграции, а база с чужими таблицами означает неверный DB_NAME
+		var tableCount int
+		if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM information_schema.tables
+			WHERE table_schema = current_schema()`).Scan(&tableCount); err != nil {
+			return fmt.Errorf("startup check failed: failed to count tables: %w", err)
+		}
+		if tableCount > 0 || !allowEmpty {
+			return fmt.Errorf("startup check failed: required tables are missing, check that DB_NAME points to the service database")
+		}
+		log.Println("Startup check passed: database is empty, schema will be created by migrations")
+		return nil
+	}
+
+	log.Println("Startup check passed")
+	return nil
+}
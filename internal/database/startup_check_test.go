@@ -0,0 +1,114 @@
+package database
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// драйвер SQL, имитирующий базу с набором таблиц из имени подключения (через запятую)
+type schemaDriver struct{}
+
+func init() {
+	sql.Register("schema", schemaDriver{})
+}
+
+func (schemaDriver) Open(name string) (driver.Conn, error) {
+	tables := make(map[string]bool)
+	for _, table := range strings.Split(name, ",") {
+		if table != "" {
+			tables[table] = true
+		}
+	}
+	return &schemaConn{tables: tables}, nil
+}
+
+type schemaConn struct {
+	tables map[string]bool
+}
+
+func (c *schemaConn) Prepare(query string) (driver.Stmt, error) {
+	return &schemaStmt{conn: c, query: query}, nil
+}
+
+func (c *schemaConn) Close() error { return nil }
+func (c *schemaConn) Begin() (driver.Tx, error) {
+	return nil, fmt.Errorf("transactions are not supported")
+}
+
+type schemaStmt struct {
+	conn  *schemaConn
+	query string
+}
+
+func (s *schemaStmt) Close() error  { return nil }
+func (s *schemaStmt) NumInput() int { return -1 }
+
+func (s *schemaStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, fmt.Errorf("exec is not supported")
+}
+
+func (s *schemaStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if strings.Contains(s.query, "information_schema.tables") && len(args) == 0 {
+		return &singleValueRows{value: int64(len(s.conn.tables))}, nil
+	}
+	if strings.Contains(s.query, "information_schema.tables") {
+		return &singleValueRows{value: s.conn.tables[args[0].(string)]}, nil
+	}
+	return &singleValueRows{value: int64(1)}, nil
+}
+
+// результат запроса из одной строки с одним значением
+type singleValueRows struct {
+	value driver.Value
+	done  bool
+}
+
+func (r *singleValueRows) Columns() []string { return []string{"value"} }
+func (r *singleValueRows) Close() error      { return nil }
+
+func (r *singleValueRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = r.value
+	return nil
+}
+
+func TestStartupCheckPassesWithServiceSchema(t *testing.T) {
+	db, err := sql.Open("schema", "teams,users,pull_requests,pr_reviewers")
+	require.NoError(t, err)
+	defer db.Close()
+
+	assert.NoError(t, StartupCheck(db, true))
+}
+
+func TestStartupCheckFailsOnWrongSchema(t *testing.T) {
+	db, err := sql.Open("schema", "orders,customers")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = StartupCheck(db, true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "required tables are missing")
+}
+
+func TestStartupCheckAllowsEmptyDatabaseBeforeMigrations(t *testing.T) {
+	db, err := sql.Open("schema", "")
+	require.NoError(t, err)
+	defer db.Close()
+
+	assert.NoError(t, StartupCheck(db, true))
+
+	// без миграций (READ_ONLY) пустая база означает, что схемы сервиса нет
+	err = StartupCheck(db, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "required tables are missing")
+}
@@ -0,0 +1,256 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"pull-request-reviewer-assignment-service/internal/models"
+	"strings"
+	"sync"
+	"time"
+)
+
+// размер очереди событий по умолчанию
+const defaultQueueSize = 100
+
+// настройки публикации событий назначения
+type Config struct {
+	// адрес, на который отправляются события в JSON (пусто - публикация отключена)
+	Endpoint string
+	// размер очереди неотправленных событий, при переполнении события отбрасываются
+	QueueSize int
+	// хосты, на которые разрешено отправлять события по адресам команд (пусто - адреса команд не используются)
+	WebhookHosts []string
+}
+
+// источник адресов уведомлений команд
+type WebhookResolver interface {
+	// возвращает адрес команды пользователя или пустую строку, если у команды нет своего адреса
+	GetWebhookByUser(userID string) (string, error)
+}
+
+// публикатор событий с возможностью освободить ресурсы при остановке сервиса
+type Publisher interface {
+	Publish(event models.AssignmentEvent)
+	Close()
+}
+
+// создает публикатор событий согласно конфигурации
+// принимает: настройки публикации событий и источник адресов команд, которые важнее Endpoint для событий о PR
+// авторов этих команд (nil - только Endpoint)
+// возвращает: HTTP публикатор если задан адрес или включены адреса команд, иначе публикатор, отбрасывающий события
+func NewPublisher(cfg Config, webhooks WebhookResolver) Publisher {
+	// без разрешенных хостов адреса команд не используются
+	if len(cfg.WebhookHosts) == 0 {
+		webhooks = nil
+	}
+	if cfg.Endpoint == "" && webhooks == nil {
+		return NoopPublisher{}
+	}
+	return NewHTTPPublisher(cfg.Endpoint, cfg.QueueSize, webhooks, cfg.WebhookHosts)
+}
+
+// проверяет, что адрес команды - абсолютный http(s) адрес на одном из разрешенных хостов
+// принимает: адрес и список разрешенных хостов (без порта)
+// возвращает: true если на адрес можно отправлять события
+func WebhookAllowed(webhookURL string, hosts []string) bool {
+	parsed, err := url.Parse(webhookURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return false
+	}
+	for _, host := range hosts {
+		if strings.EqualFold(parsed.Hostname(), host) {
+			return true
+		}
+	}
+	return false
+}
+
+// публикатор, отбрасывающий все события
+type NoopPublisher struct{}
+
+// отбрасывает событие
+// принимает: событие назначения
+// возвращает: ничего
+func (NoopPublisher) Publish(models.AssignmentEvent) {}
+
+// ничего не делает
+// принимает: ничего
+// возвращает: ничего
+func (NoopPublisher) Close() {}
+
+// публикатор, передающий события в канал внутри процесса
+type ChannelPublisher struct {
+	events chan models.AssignmentEvent
+}
+
+// создает публикатор с буферизированным каналом событий
+// принимает: размер буфера канала
+// возвращает: указатель на ChannelPublisher
+func NewChannelPublisher(size int) *ChannelPublisher {
+	if size <= 0 {
+		size = defaultQueueSize
+	}
+	return &ChannelPublisher{events: make(chan models.AssignmentEvent, size)}
+}
+
+// возвращает канал для чтения опубликованных событий
+// принимает: ничего
+// возвращает: канал событий, закрывается вызовом Close
+func (p *ChannelPublisher) Events() <-chan models.AssignmentEvent {
+	return p.events
+}
+
+// отправляет событие в канал без блокировки, при заполненном буфере событие отбрасывается
+// принимает: событие назначения
+// возвращает: ничего
+func (p *ChannelPublisher) Publish(event models.AssignmentEvent) {
+	select {
+	case p.events <- event:
+	default:
+		log.Printf("Event queue is full, dropping %s event for PR %s", event.Type, event.PullRequestID)
+	}
+}
+
+// закрывает канал событий
+// принимает: ничего
+// возвращает: ничего
+func (p *ChannelPublisher) Close() {
+	close(p.events)
+}
+
+// публикатор, асинхронно отправляющий события POST запросом в JSON
+type HTTPPublisher struct {
+	endpoint     string
+	webhooks     WebhookResolver
+	webhookHosts []string
+	client       *http.Client
+	queue        chan models.AssignmentEvent
+	done         sync.WaitGroup
+	// защищает закрытие очереди от одновременной публикации
+	mu     sync.RWMutex
+	closed bool
+}
+
+// создает HTTP публикатор и запускает фоновую отправку событий
+// принимает: адрес получателя событий, размер очереди, источник адресов команд (nil - только endpoint)
+// и хосты, разрешенные для адресов команд
+// возвращает: указатель на HTTPPublisher
+func NewHTTPPublisher(endpoint string, queueSize int, webhooks WebhookResolver, webhookHosts []string) *HTTPPublisher {
+	if queueSize <= 0 {
+		queueSize = defaultQueueSize
+	}
+
+	p := &HTTPPublisher{
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: 5 * time.Second},
+		queue:    make(chan models.AssignmentEvent, queueSize),
+	}
+	if webhooks != nil {
+		p.webhooks = webhooks
+		p.webhookHosts = webhookHosts
+	}
+
+	p.done.Add(1)
+	go p.run()
+
+	return p
+}
+
+// ставит событие в очередь отправки без блокировки, при заполненной очереди или после Close событие отбрасывается
+// принимает: событие назначения
+// возвращает: ничего
+func (p *HTTPPublisher) Publish(event models.AssignmentEvent) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		log.Printf("Publisher is closed, dropping %s event for PR %s", event.Type, event.PullRequestID)
+		return
+	}
+	select {
+	case p.queue <- event:
+	default:
+		log.Printf("Event queue is full, dropping %s event for PR %s", event.Type, event.PullRequestID)
+	}
+}
+
+// останавливает прием событий и дожидается отправки оставшихся в очереди, повторный вызов ничего не делает
+// принимает: ничего
+// возвращает: ничего
+func (p *HTTPPublisher) Close() {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.queue)
+	}
+	p.mu.Unlock()
+
+	p.done.Wait()
+}
+
+// отправляет события из очереди до ее закрытия
+// принимает: ничего
+// возвращает: ничего
+func (p *HTTPPublisher) run() {
+	defer p.done.Done()
+
+	for event := range p.queue {
+		if err := p.send(event); err != nil {
+			log.Printf("Failed to publish %s event for PR %s: %v", event.Type, event.PullRequestID, err)
+		}
+	}
+}
+
+// возвращает адрес для события: адрес команды автора PR, а если его нет - общий адрес
+// принимает: событие назначения
+// возвращает: адрес получателя (пустая строка - событие некуда отправить)
+func (p *HTTPPublisher) endpointFor(event models.AssignmentEvent) string {
+	if p.webhooks == nil || event.AuthorID == "" {
+		return p.endpoint
+	}
+
+	webhookURL, err := p.webhooks.GetWebhookByUser(event.AuthorID)
+	if err != nil {
+		log.Printf("Failed to get team webhook for author %s, using global endpoint: %v", event.AuthorID, err)
+		return p.endpoint
+	}
+	if webhookURL == "" {
+		return p.endpoint
+	}
+	// адрес мог быть сохранен до сужения списка разрешенных хостов
+	if !WebhookAllowed(webhookURL, p.webhookHosts) {
+		log.Printf("Warning: team webhook of author %s is not on an allowed host, using global endpoint", event.AuthorID)
+		return p.endpoint
+	}
+	return webhookURL
+}
+
+// отправляет одно событие получателю
+// принимает: событие назначения
+// возвращает: ошибку сериализации, сети или неуспешного статуса ответа
+func (p *HTTPPublisher) send(event models.AssignmentEvent) error {
+	endpoint := p.endpointFor(event)
+	if endpoint == "" {
+		return nil
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	resp, err := p.client.Post(endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to send event: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package events
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pull-request-reviewer-assignment-service/internal/models"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChannelPublisherDropsWhenFull(t *testing.T) {
+	publisher := NewChannelPublisher(1)
+
+	publisher.Publish(models.AssignmentEvent{Type: models.EventPRCreated, PullRequestID: "pr-1"})
+	publisher.Publish(models.AssignmentEvent{Type: models.EventPRCreated, PullRequestID: "pr-2"})
+	publisher.Close()
+
+	var received []string
+	for event := range publisher.Events() {
+		received = append(received, event.PullRequestID)
+	}
+	assert.Equal(t, []string{"pr-1"}, received)
+}
+
+func TestHTTPPublisherPostsJSON(t *testing.T) {
+	var mu sync.Mutex
+	var received []models.AssignmentEvent
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event models.AssignmentEvent
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+
+		mu.Lock()
+		received = append(received, event)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	publisher := NewHTTPPublisher(server.URL, 10, nil, nil)
+	publisher.Publish(models.AssignmentEvent{
+		Type: models.EventReviewerReassigned, PullRequestID: "pr-1", AuthorID: "u1",
+		Reviewers: []string{"u3"}, OldReviewerID: "u2", NewReviewerID: "u3",
+	})
+	publisher.Close()
+
+	require.Len(t, received, 1)
+	assert.Equal(t, models.EventReviewerReassigned, received[0].Type)
+	assert.Equal(t, "u2", received[0].OldReviewerID)
+	assert.Equal(t, "u3", received[0].NewReviewerID)
+}
+
+func TestHTTPPublisherSurvivesFailingEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	publisher := NewHTTPPublisher(server.URL, 10, nil, nil)
+	publisher.Publish(models.AssignmentEvent{Type: models.EventPRMerged, PullRequestID: "pr-1"})
+	publisher.Close()
+}
+
+func TestHTTPPublisherDropsEventsAfterClose(t *testing.T) {
+	var mu sync.Mutex
+	var received []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event models.AssignmentEvent
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+
+		mu.Lock()
+		received = append(received, event.PullRequestID)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	publisher := NewHTTPPublisher(server.URL, 10, nil, nil)
+	publisher.Publish(models.AssignmentEvent{Type: models.EventPRCreated, PullRequestID: "pr-1"})
+	publisher.Close()
+
+	assert.NotPanics(t, func() {
+		publisher.Publish(models.AssignmentEvent{Type: models.EventPRCreated, PullRequestID: "pr-2"})
+		publisher.Close()
+	})
+	assert.Equal(t, []string{"pr-1"}, received)
+}
+
+func TestNewPublisherWithoutEndpointIsNoop(t *testing.T) {
+	publisher := NewPublisher(Config{}, nil)
+	assert.IsType(t, NoopPublisher{}, publisher)
+	publisher.Publish(models.AssignmentEvent{Type: models.EventPRCreated})
+	publisher.Close()
+}
+
+// адреса команд по авторам для тестов маршрутизации
+type authorWebhooks map[string]string
+
+func (w authorWebhooks) GetWebhookByUser(userID string) (string, error) {
+	return w[userID], nil
+}
+
+// принимает события и запоминает идентификаторы PR
+func recordingServer(t *testing.T) (*httptest.Server, func() []string) {
+	var mu sync.Mutex
+	var received []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event models.AssignmentEvent
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+
+		mu.Lock()
+		received = append(received, event.PullRequestID)
+		mu.Unlock()
+	}))
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return received
+	}
+}
+
+func TestHTTPPublisherRoutesToTeamWebhook(t *testing.T) {
+	global, globalReceived := recordingServer(t)
+	defer global.Close()
+	teamA, teamAReceived := recordingServer(t)
+	defer teamA.Close()
+
+	publisher := NewPublisher(Config{
+		Endpoint:     global.URL,
+		WebhookHosts: []string{"127.0.0.1"},
+	}, authorWebhooks{"author-a": teamA.URL})
+	publisher.Publish(models.AssignmentEvent{Type: models.EventPRCreated, PullRequestID: "pr-a", AuthorID: "author-a"})
+	publisher.Publish(models.AssignmentEvent{Type: models.EventPRCreated, PullRequestID: "pr-b", AuthorID: "author-b"})
+	publisher.Close()
+
+	assert.Equal(t, []string{"pr-a"}, teamAReceived())
+	assert.Equal(t, []string{"pr-b"}, globalReceived())
+}
+
+func TestHTTPPublisherWithoutGlobalEndpointSendsOnlyTeamEvents(t *testing.T) {
+	teamA, teamAReceived := recordingServer(t)
+	defer teamA.Close()
+
+	publisher := NewPublisher(Config{WebhookHosts: []string{"127.0.0.1"}}, authorWebhooks{"author-a": teamA.URL})
+	publisher.Publish(models.AssignmentEvent{Type: models.EventPRCreated, PullRequestID: "pr-a", AuthorID: "author-a"})
+	publisher.Publish(models.AssignmentEvent{Type: models.EventPRCreated, PullRequestID: "pr-b", AuthorID: "author-b"})
+	publisher.Close()
+
+	assert.Equal(t, []string{"pr-a"}, teamAReceived())
+}
+
+func TestNewPublisherWithoutWebhookHostsIgnoresTeamWebhooks(t *testing.T) {
+	// адреса команд без списка разрешенных хостов не включают публикацию
+	publisher := NewPublisher(Config{}, authorWebhooks{"author-a": "http://127.0.0.1:1"})
+	assert.IsType(t, NoopPublisher{}, publisher)
+	publisher.Close()
+}
+
+func TestHTTPPublisherSkipsTeamWebhookOnDisallowedHost(t *testing.T) {
+	global, globalReceived := recordingServer(t)
+	defer global.Close()
+	teamA, teamAReceived := recordingServer(t)
+	defer teamA.Close()
+
+	publisher := NewPublisher(Config{
+		Endpoint:     global.URL,
+		WebhookHosts: []string{"hooks.example.com"},
+	}, authorWebhooks{"author-a": teamA.URL})
+	publisher.Publish(models.AssignmentEvent{Type: models.EventPRCreated, PullRequestID: "pr-a", AuthorID: "author-a"})
+	publisher.Close()
+
+	assert.Empty(t, teamAReceived())
+	assert.Equal(t, []string{"pr-a"}, globalReceived())
+}
@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"pull-request-reviewer-assignment-service/internal/models"
+	"pull-request-reviewer-assignment-service/internal/service"
+	"time"
+)
+
+// структура обрабатывает административные HTTP запросы
+type AdminHandler struct {
+	prService    *service.PRService
+	statsService *service.StatsService
+}
+
+// создает и возвращает новый экземпляр AdminHandler
+// принимает: сервисы Pull Request'ов и статистики для внедрения зависимостей
+// возвращает: указатель на созданный AdminHandler
+func NewAdminHandler(prService *service.PRService, statsService *service.StatsService) *AdminHandler {
+	return &AdminHandler{
+		prService:    prService,
+		statsService: statsService,
+	}
+}
+
+// симулирует распределение назначений на синтетические PR команды без сохранения
+// принимает: HTTP запрос с JSON содержащим team_name, pr_count, strategy и опциональный seed
+// возвращает: JSON с гистограммой назначений по ревьюверам или ошибку
+func (h *AdminHandler) Simulate(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received POST /admin/simulate request")
+
+	if r.Method != http.MethodPost {
+		log.Printf("Method not allowed: %s", r.Method)
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request models.SimulationRequest
+	if err := decodeJSONBody(r, &request); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	log.Printf("Parsed request: team=%s, pr_count=%d, strategy=%s", request.TeamName, request.PRCount, request.Strategy)
+
+	// валидация
+	if request.TeamName == "" {
+		log.Printf("Missing team_name")
+		writeError(w, "INVALID_REQUEST", "team_name is required", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.prService.SimulateAssignments(request.TeamName, request.PRCount, request.Strategy, request.Seed)
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		if serviceErr, ok := err.(*service.ServiceError); ok {
+			switch serviceErr.Code {
+			case "NOT_FOUND":
+				writeError(w, "NOT_FOUND", serviceErr.Message, http.StatusNotFound)
+			case "INVALID_REQUEST":
+				writeError(w, "INVALID_REQUEST", serviceErr.Message, http.StatusBadRequest)
+			default:
+				writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+			}
+			return
+		}
+		writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Simulation completed for team %s", request.TeamName)
+	writeJSON(w, http.StatusOK, result)
+}
+
+// восстанавливает журнал назначений по текущим назначениям ревьюверов
+// принимает: HTTP запрос без тела
+// возвращает: JSON с количеством созданных событий или ошибку
+func (h *AdminHandler) BackfillStats(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received POST /admin/backfillStats request")
+
+	if r.Method != http.MethodPost {
+		log.Printf("Method not allowed: %s", r.Method)
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	response, err := h.statsService.BackfillAssignmentEvents()
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// приостанавливает или возобновляет назначение ревьюверов, например на время разбора инцидента
+// принимает: HTTP POST запрос с JSON содержащим paused
+// возвращает: JSON с новым состоянием паузы или ошибку валидации
+func (h *AdminHandler) PauseAssignment(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received POST /admin/pauseAssignment request")
+
+	if r.Method != http.MethodPost {
+		log.Printf("Method not allowed: %s", r.Method)
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request models.AssignmentPauseRequest
+	if err := decodeJSONBody(r, &request); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	if request.Paused == nil {
+		log.Printf("Missing paused")
+		writeError(w, "INVALID_REQUEST", "paused is required", http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, h.prService.SetAssignmentPaused(*request.Paused))
+}
+
+// возвращает миграции базы данных, примененные при запуске сервиса
+// принимает: HTTP запрос без параметров
+// возвращает: JSON со списком миграций в порядке версий (пустой, если учета миграций нет) или ошибку
+func (h *AdminHandler) GetMigrations(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received GET /admin/migrations request")
+
+	if r.Method != http.MethodGet {
+		log.Printf("Method not allowed: %s", r.Method)
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	response, err := h.statsService.GetAppliedMigrations()
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// удаляет замерженные PR старше указанного периода
+// принимает: HTTP запрос с JSON содержащим older_than (длительность, например 720h)
+// возвращает: JSON с количеством удаленных PR или ошибку валидации
+func (h *AdminHandler) Purge(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received POST /admin/purge request")
+
+	if r.Method != http.MethodPost {
+		log.Printf("Method not allowed: %s", r.Method)
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request models.PurgeRequest
+	if err := decodeJSONBody(r, &request); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	olderThan, err := time.ParseDuration(request.OlderThan)
+	if err != nil {
+		log.Printf("Invalid older_than: %s", request.OlderThan)
+		writeError(w, "INVALID_REQUEST", "older_than must be a duration like 720h", http.StatusBadRequest)
+		return
+	}
+
+	response, err := h.prService.PurgeMergedPRs(olderThan)
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		if serviceErr, ok := err.(*service.ServiceError); ok && serviceErr.Code == "INVALID_REQUEST" {
+			writeError(w, "INVALID_REQUEST", serviceErr.Message, http.StatusBadRequest)
+			return
+		}
+		writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// заново выбирает ревьюверов открытого PR по действующей стратегии и ограничениям назначения
+// принимает: HTTP запрос с JSON содержащим pull_request_id
+// возвращает: JSON с ревьюверами до и после переназначения или ошибку
+func (h *AdminHandler) ReassignByPolicy(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received POST /admin/reassignByPolicy request")
+
+	if r.Method != http.MethodPost {
+		log.Printf("Method not allowed: %s", r.Method)
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request models.PolicyReassignRequest
+	if err := decodeJSONBody(r, &request); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	if request.PullRequestID == "" {
+		log.Printf("Missing pull_request_id")
+		writeError(w, "INVALID_REQUEST", "pull_request_id is required", http.StatusBadRequest)
+		return
+	}
+
+	response, err := h.prService.ReassignByPolicy(request.PullRequestID)
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		if serviceErr, ok := err.(*service.ServiceError); ok {
+			switch serviceErr.Code {
+			case "NOT_FOUND":
+				writeError(w, "NOT_FOUND", serviceErr.Message, http.StatusNotFound)
+			case "PR_MERGED":
+				writeError(w, "PR_MERGED", serviceErr.Message, http.StatusConflict)
+			case "PR_CLOSED":
+				writeError(w, "PR_CLOSED", serviceErr.Message, http.StatusConflict)
+			case "ASSIGNMENT_PAUSED":
+				writeError(w, "ASSIGNMENT_PAUSED", serviceErr.Message, http.StatusServiceUnavailable)
+			default:
+				writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+			}
+			return
+		}
+		writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("PR %s reassigned by policy: %v -> %v", response.PullRequestID, response.Before, response.After)
+	writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,21 @@
+package handlers
+
+import "time"
+
+// ограничения на размер коллекций в телах запросов, проверяемые при декодировании, настройки подписи запросов, формата ответов и режима только для чтения
+type Config struct {
+	// максимальное количество участников в /team/add и /team/sync (0 - без ограничения)
+	MaxTeamMembers int
+	// максимальное количество элементов в /pullRequest/batchGet, /pullRequest/bulkReassign и /users/bulkSetActive (0 - без ограничения)
+	MaxBulkItems int
+	// требовать HMAC подпись всех запросов, кроме /health и /ready
+	RequireSignedRequests bool
+	// общий секрет для подписи запросов
+	SigningSecret string
+	// максимальное расхождение метки времени подписанного запроса с временем сервера
+	SignatureMaxAge time.Duration
+	// формат времени createdAt и mergedAt в ответах с PR: rfc3339, rfc3339nano или unix_millis
+	TimestampFormat string
+	// отклонять изменяющие запросы с 503 READ_ONLY (реплики для аналитики)
+	ReadOnly bool
+}
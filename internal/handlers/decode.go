@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// ограничение количества элементов массива в поле верхнего уровня тела запроса
+type collectionLimit struct {
+	field string
+	max   int
+}
+
+// декодирует JSON тело запроса в переданную структуру за один проход; размер тела ограничен maxRequestBodyBytes,
+// а элементы ограниченных коллекций считаются по мере чтения, поэтому лишние элементы не декодируются
+// принимает: HTTP запрос, указатель на структуру для заполнения и ограничения размеров коллекций
+// возвращает: ошибку с понятным клиенту сообщением или nil при успешном декодировании
+func decodeJSONBody(r *http.Request, dst interface{}, limits ...collectionLimit) error {
+	body := http.MaxBytesReader(nil, r.Body, maxRequestBodyBytes)
+	target := reflect.ValueOf(dst)
+	if len(limits) == 0 || target.Kind() != reflect.Ptr || target.Elem().Kind() != reflect.Struct {
+		return decodeJSON(body, dst)
+	}
+	return decodeObjectWithLimits(json.NewDecoder(body), target.Elem(), dst, limits)
+}
+
+// отвечает на ошибку разбора тела запроса: 413 PAYLOAD_TOO_LARGE для слишком большого тела, иначе 400 INVALID_REQUEST
+// принимает: ResponseWriter и ошибку decodeJSONBody
+// возвращает: ничего
+func writeDecodeError(w http.ResponseWriter, err error) {
+	log.Printf("Invalid JSON: %v", err)
+	var tooLarge *bodyTooLargeError
+	if errors.As(err, &tooLarge) {
+		writeError(w, "PAYLOAD_TOO_LARGE", err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	writeError(w, "INVALID_REQUEST", err.Error(), http.StatusBadRequest)
+}
+
+// тело запроса больше допустимого размера
+type bodyTooLargeError struct {
+	limit int64
+}
+
+func (e *bodyTooLargeError) Error() string {
+	return fmt.Sprintf("request body exceeds %d bytes", e.limit)
+}
+
+// декодирует JSON объект по полям: ограниченные коллекции читаются поэлементно с проверкой лимита,
+// остальные поля декодируются в структуру обычным образом
+// принимает: декодер тела, значение структуры, указатель на нее и ограничения коллекций
+// возвращает: ошибку с понятным клиенту сообщением или nil
+func decodeObjectWithLimits(decoder *json.Decoder, target reflect.Value, dst interface{}, limits []collectionLimit) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return decodeError(err)
+	}
+	if token != json.Delim('{') {
+		return fmt.Errorf("expected a JSON object, got %s", withArticle(tokenKind(token)))
+	}
+
+	rest := map[string]json.RawMessage{}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return decodeError(err)
+		}
+		key, _ := token.(string)
+
+		if limit, field, ok := limitedField(target, key, limits); ok {
+			if err := decodeLimitedArray(decoder, field, limit); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return decodeError(err)
+		}
+		rest[key] = raw
+	}
+	if _, err := decoder.Token(); err != nil {
+		return decodeError(err)
+	}
+
+	// остальные поля небольшие: тело целиком уже ограничено maxRequestBodyBytes
+	data, err := json.Marshal(rest)
+	if err != nil {
+		return decodeError(err)
+	}
+	if err := json.Unmarshal(data, dst); err != nil {
+		return decodeError(err)
+	}
+	return nil
+}
+
+// находит ограниченную коллекцию по ключу JSON объекта (без учета регистра, как encoding/json)
+// принимает: значение структуры, ключ и ограничения коллекций
+// возвращает: ограничение, поле-слайс структуры и true, если ключ относится к ограниченной коллекции
+func limitedField(target reflect.Value, key string, limits []collectionLimit) (collectionLimit, reflect.Value, bool) {
+	for _, limit := range limits {
+		if limit.max <= 0 || !strings.EqualFold(key, limit.field) {
+			continue
+		}
+		field, ok := jsonField(target, limit.field)
+		if ok && field.Kind() == reflect.Slice {
+			return limit, field, true
+		}
+	}
+	return collectionLimit{}, reflect.Value{}, false
+}
+
+// читает JSON массив поэлементно и останавливается, как только элементов становится больше лимита
+// принимает: декодер, установленный перед значением поля, поле-слайс структуры и ограничение
+// возвращает: ошибку с названием коллекции и лимитом, ошибку формата или nil
+func decodeLimitedArray(decoder *json.Decoder, field reflect.Value, limit collectionLimit) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return decodeError(err)
+	}
+	if token == nil {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+	if token != json.Delim('[') {
+		return fmt.Errorf("invalid type for field %s: expected array, got %s", limit.field, tokenKind(token))
+	}
+
+	elements := reflect.MakeSlice(field.Type(), 0, 0)
+	for decoder.More() {
+		if elements.Len() >= limit.max {
+			return fmt.Errorf("too many items in %s: limit is %d", limit.field, limit.max)
+		}
+		element := reflect.New(field.Type().Elem())
+		if err := decoder.Decode(element.Interface()); err != nil {
+			return decodeError(withFieldPrefix(err, limit.field))
+		}
+		elements = reflect.Append(elements, element.Elem())
+	}
+	if _, err := decoder.Token(); err != nil {
+		return decodeError(err)
+	}
+
+	field.Set(elements)
+	return nil
+}
+
+// дополняет путь поля в ошибке типа именем коллекции, в которой находится элемент
+// принимает: ошибку декодирования элемента и имя коллекции
+// возвращает: ошибку с полным путем поля или исходную ошибку
+func withFieldPrefix(err error, collection string) error {
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) {
+		return err
+	}
+	prefixed := *typeErr
+	prefixed.Field = collection
+	if typeErr.Field != "" {
+		prefixed.Field = collection + "." + typeErr.Field
+	}
+	return &prefixed
+}
+
+// возвращает название JSON значения по токену декодера
+// принимает: токен json.Decoder
+// возвращает: object, array, string, number, bool или null
+func tokenKind(token json.Token) string {
+	switch value := token.(type) {
+	case json.Delim:
+		if value == '[' {
+			return "array"
+		}
+		return "object"
+	case string:
+		return "string"
+	case float64, json.Number:
+		return "number"
+	case bool:
+		return "bool"
+	default:
+		return "null"
+	}
+}
+
+// находит поле структуры по имени из json тега
+// принимает: значение структуры и имя поля в JSON
+// возвращает: значение поля и true, если поле найдено
+func jsonField(value reflect.Value, name string) (reflect.Value, bool) {
+	for i := 0; i < value.NumField(); i++ {
+		tag, _, _ := strings.Cut(value.Type().Field(i).Tag.Get("json"), ",")
+		if tag == name {
+			return value.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// декодирует JSON из потока в переданную структуру
+// принимает: поток с JSON и указатель на структуру для заполнения
+// возвращает: ошибку с понятным клиенту сообщением или nil при успешном декодировании
+func decodeJSON(body io.Reader, dst interface{}) error {
+	if err := json.NewDecoder(body).Decode(dst); err != nil {
+		return decodeError(err)
+	}
+	return nil
+}
+
+// преобразует ошибку encoding/json в понятное клиенту сообщение
+// принимает: ошибку декодирования
+// возвращает: ошибку о пустом теле, превышении размера, несовпадении типа или некорректном JSON
+func decodeError(err error) error {
+	// пустое тело (или только пробелы) - отдельная ошибка, чтобы не путать с некорректным JSON
+	if errors.Is(err, io.EOF) {
+		return errors.New("request body is required")
+	}
+
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return &bodyTooLargeError{limit: tooLarge.Limit}
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		expected := jsonTypeName(typeErr.Type)
+		if typeErr.Field == "" {
+			return fmt.Errorf("expected a JSON %s, got %s", expected, withArticle(typeErr.Value))
+		}
+		return fmt.Errorf("invalid type for field %s: expected %s, got %s", typeErr.Field, expected, typeErr.Value)
+	}
+
+	return errors.New("Invalid JSON")
+}
+
+// возвращает название JSON типа, соответствующего Go типу
+// принимает: тип Go, в который выполнялось декодирование
+// возвращает: название JSON типа (object, array, string, number, boolean)
+func jsonTypeName(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map:
+		return "object"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	default:
+		return t.String()
+	}
+}
+
+// добавляет неопределенный артикль к названию JSON значения
+// принимает: название JSON значения из UnmarshalTypeError (array, string, number...)
+// возвращает: строку вида "an array" или "a string"
+func withArticle(value string) string {
+	if value == "" {
+		return value
+	}
+	switch value[0] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return "an " + value
+	default:
+		return "a " + value
+	}
+}
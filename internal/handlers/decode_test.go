@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"pull-request-reviewer-assignment-service/internal/models"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// выполняет POST запрос к обработчику и возвращает код и тело ошибки
+func postJSON(t *testing.T, handler http.HandlerFunc, path string, body interface{}) (int, models.ErrorResponse) {
+	t.Helper()
+	data, err := json.Marshal(body)
+	require.NoError(t, err)
+	return postRaw(t, handler, path, data)
+}
+
+// выполняет POST запрос с телом как есть и возвращает код и тело ошибки
+func postRaw(t *testing.T, handler http.HandlerFunc, path string, data []byte) (int, models.ErrorResponse) {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest(http.MethodPost, path, bytes.NewReader(data)))
+
+	var response models.ErrorResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	return recorder.Code, response
+}
+
+func TestAddTeamRejectsTooManyMembers(t *testing.T) {
+	members := make([]models.TeamMember, 4)
+	for i := range members {
+		members[i] = models.TeamMember{UserID: fmt.Sprintf("u%d", i), Username: "user", IsActive: true}
+	}
+	// сервис не нужен: лимит проверяется до обращения к нему
+	handler := NewTeamHandler(nil, Config{MaxTeamMembers: 3})
+
+	code, response := postJSON(t, handler.AddTeam, "/team/add", map[string]interface{}{
+		"team_name": "backend",
+		"members":   members,
+	})
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, "INVALID_REQUEST", response.Error.Code)
+	assert.Equal(t, "too many items in members: limit is 3", response.Error.Message)
+}
+
+func TestBulkSetActiveRejectsTooManyUsers(t *testing.T) {
+	handler := NewUserHandler(nil, Config{MaxBulkItems: 2})
+
+	code, response := postJSON(t, handler.BulkSetActive, "/users/bulkSetActive", map[string]interface{}{
+		"users": []map[string]interface{}{
+			{"user_id": "u1", "is_active": false},
+			{"user_id": "u2", "is_active": false},
+			{"user_id": "u3", "is_active": false},
+		},
+	})
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, "too many items in users: limit is 2", response.Error.Message)
+}
+
+func TestOversizedBodyIsRejectedWhileDecoding(t *testing.T) {
+	handler := NewPRHandler(nil, Config{})
+
+	body := `{"pull_request_id": "` + strings.Repeat("a", maxRequestBodyBytes) + `"}`
+	code, response := postRaw(t, handler.MergePR, "/pullRequest/merge", []byte(body))
+	// тот же ответ, что и у проверки подписи
+	assert.Equal(t, http.StatusRequestEntityTooLarge, code)
+	assert.Equal(t, "PAYLOAD_TOO_LARGE", response.Error.Code)
+	assert.Equal(t, fmt.Sprintf("request body exceeds %d bytes", maxRequestBodyBytes), response.Error.Message)
+
+	teamHandler := NewTeamHandler(nil, Config{MaxTeamMembers: 3})
+	body = `{"team_name": "` + strings.Repeat("a", maxRequestBodyBytes) + `", "members": []}`
+	code, response = postRaw(t, teamHandler.AddTeam, "/team/add", []byte(body))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, code)
+	assert.Equal(t, "PAYLOAD_TOO_LARGE", response.Error.Code)
+}
+
+func TestCollectionLimitStopsDecodingAtLimit(t *testing.T) {
+	handler := NewTeamHandler(nil, Config{MaxTeamMembers: 2})
+
+	// после лишнего элемента тело обрывается: превышение обнаружено раньше, чем декодер дочитал массив
+	body := `{"team_name": "backend", "members": [{"user_id": "u1"}, {"user_id": "u2"}, {"user_id": "u3"}, {"user_id": `
+	code, response := postRaw(t, handler.AddTeam, "/team/add", []byte(body))
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, "too many items in members: limit is 2", response.Error.Message)
+
+	_, response = postRaw(t, handler.AddTeam, "/team/add",
+		[]byte(`{"team_name": "backend", "members": [{"user_id": "u1", "is_active": "yes"}]}`))
+	assert.Equal(t, "invalid type for field members.is_active: expected boolean, got string", response.Error.Message)
+}
+
+func TestDecodeWithCollectionLimitsFillsAllFields(t *testing.T) {
+	body := `{"team_name": "backend", "Members": [{"user_id": "u1", "username": "Alice", "is_active": true}], "extra": 1}`
+	request := httptest.NewRequest(http.MethodPost, "/team/add", strings.NewReader(body))
+
+	var team models.Team
+	require.NoError(t, decodeJSONBody(request, &team, collectionLimit{"members", 2}))
+	assert.Equal(t, "backend", team.TeamName)
+	assert.Equal(t, []models.TeamMember{{UserID: "u1", Username: "Alice", IsActive: true}}, team.Members)
+}
+
+func TestCollectionLimitsKeepTypeErrors(t *testing.T) {
+	handler := NewTeamHandler(nil, Config{MaxTeamMembers: 3})
+
+	// не массив в ограниченном поле - сообщение о типе от основного декодирования
+	_, response := postJSON(t, handler.AddTeam, "/team/add", map[string]interface{}{
+		"team_name": "backend",
+		"members":   map[string]interface{}{"user_id": "u1"},
+	})
+	assert.Equal(t, "invalid type for field members: expected array, got object", response.Error.Message)
+
+	_, response = postJSON(t, handler.AddTeam, "/team/add", []string{"backend"})
+	assert.Equal(t, "expected a JSON object, got an array", response.Error.Message)
+}
+
+func TestEmptyBodyIsReportedSeparatelyFromMalformedJSON(t *testing.T) {
+	// сервис не нужен: тело проверяется до обращения к нему
+	handler := NewPRHandler(nil, Config{})
+
+	for _, body := range []string{"", "  \n"} {
+		code, response := postRaw(t, handler.MergePR, "/pullRequest/merge", []byte(body))
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, "INVALID_REQUEST", response.Error.Code)
+		assert.Equal(t, "request body is required", response.Error.Message)
+	}
+
+	code, response := postRaw(t, handler.MergePR, "/pullRequest/merge", []byte(`{"pull_request_id": `))
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, "Invalid JSON", response.Error.Message)
+
+	// то же для обработчиков с ограничением размеров коллекций
+	teamHandler := NewTeamHandler(nil, Config{MaxTeamMembers: 3})
+	_, response = postRaw(t, teamHandler.AddTeam, "/team/add", nil)
+	assert.Equal(t, "request body is required", response.Error.Message)
+}
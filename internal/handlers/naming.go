@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+	"pull-request-reviewer-assignment-service/internal/models"
+)
+
+// заголовок и параметр запроса для выбора соглашения об именовании полей ответа
+const (
+	fieldNamingHeader = "X-Field-Naming"
+	fieldNamingParam  = "field_naming"
+)
+
+// определяет соглашение об именовании полей ответа по заголовку X-Field-Naming или параметру field_naming
+// принимает: HTTP запрос
+// возвращает: FieldNamingSnake если запрошен snake_case, иначе FieldNamingCamel
+func fieldNamingFromRequest(r *http.Request) string {
+	naming := r.Header.Get(fieldNamingHeader)
+	if value := r.URL.Query().Get(fieldNamingParam); value != "" {
+		naming = value
+	}
+
+	if naming == models.FieldNamingSnake {
+		return models.FieldNamingSnake
+	}
+	return models.FieldNamingCamel
+}
+
+// применяет к PR ответа соглашение об именовании полей из запроса и настроенный формат времени
+// принимает: HTTP запрос, формат времени TIMESTAMP_FORMAT и PR для сериализации (nil пропускаются)
+// возвращает: ничего
+func applyResponseFormat(r *http.Request, timestampFormat string, prs ...*models.PullRequest) {
+	naming := fieldNamingFromRequest(r)
+	for _, pr := range prs {
+		if pr != nil {
+			pr.SetFieldNaming(naming)
+			pr.SetTimestampFormat(timestampFormat)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"pull-request-reviewer-assignment-service/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFieldNamingFromRequest(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/pullRequest/batchGet", nil)
+	assert.Equal(t, models.FieldNamingCamel, fieldNamingFromRequest(request))
+
+	request.Header.Set("X-Field-Naming", "snake_case")
+	assert.Equal(t, models.FieldNamingSnake, fieldNamingFromRequest(request))
+
+	// параметр запроса имеет приоритет над заголовком
+	request = httptest.NewRequest(http.MethodGet, "/pullRequest/batchGet?field_naming=camelCase", nil)
+	request.Header.Set("X-Field-Naming", "snake_case")
+	assert.Equal(t, models.FieldNamingCamel, fieldNamingFromRequest(request))
+
+	// неизвестное значение не меняет текущий формат
+	request = httptest.NewRequest(http.MethodGet, "/pullRequest/batchGet?field_naming=kebab", nil)
+	assert.Equal(t, models.FieldNamingCamel, fieldNamingFromRequest(request))
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"pull-request-reviewer-assignment-service/internal/service"
+	"strings"
+)
+
+// тип содержимого потоковой выдачи: один JSON объект на строку
+const ndjsonContentType = "application/x-ndjson"
+
+// количество записанных строк, после которого ответ сбрасывается клиенту
+const ndjsonFlushEvery = 100
+
+// проверяет, запросил ли клиент потоковую выдачу заголовком Accept: application/x-ndjson
+// принимает: HTTP запрос
+// возвращает: true если среди допустимых типов ответа есть application/x-ndjson
+func wantsNDJSON(r *http.Request) bool {
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, _ := strings.Cut(accepted, ";")
+		if strings.EqualFold(strings.TrimSpace(mediaType), ndjsonContentType) {
+			return true
+		}
+	}
+	return false
+}
+
+// пишет элементы в ответ построчно по мере их чтения из базы данных, не собирая весь список в памяти.
+// Статус 200 отправляется вместе с первой строкой, поэтому ошибка до первой строки возвращается обычным JSON с кодом 500
+// (400 для ошибки валидации INVALID_REQUEST), а ошибка после нее только обрывает поток и логируется
+// принимает: ResponseWriter, название выдачи для логов и функцию, передающую элементы обработчику по одному
+// возвращает: ничего, просто записывает поток в ResponseWriter
+func streamNDJSON[T any](w http.ResponseWriter, name string, stream func(fn func(T) error) error) {
+	encoder := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+	written := 0
+
+	err := stream(func(item T) error {
+		if written == 0 {
+			w.Header().Set("Content-Type", ndjsonContentType)
+			w.WriteHeader(http.StatusOK)
+		}
+		if err := encoder.Encode(item); err != nil {
+			return err
+		}
+		written++
+		if flusher != nil && written%ndjsonFlushEvery == 0 {
+			flusher.Flush()
+		}
+		return nil
+	})
+
+	if err != nil {
+		if written == 0 {
+			log.Printf("Failed to stream %s: %v", name, err)
+			var serviceErr *service.ServiceError
+			if errors.As(err, &serviceErr) && serviceErr.Code == "INVALID_REQUEST" {
+				writeError(w, "INVALID_REQUEST", serviceErr.Message, http.StatusBadRequest)
+				return
+			}
+			writeError(w, "INTERNAL_ERROR", "Failed to retrieve statistics", http.StatusInternalServerError)
+			return
+		}
+		log.Printf("Stream of %s interrupted after %d rows: %v", name, written, err)
+		return
+	}
+
+	if written == 0 {
+		w.Header().Set("Content-Type", ndjsonContentType)
+		w.WriteHeader(http.StatusOK)
+	}
+	if flusher != nil {
+		flusher.Flush()
+	}
+	log.Printf("Streamed %d %s", written, name)
+}
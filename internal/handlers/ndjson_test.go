@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"pull-request-reviewer-assignment-service/internal/service"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWantsNDJSON(t *testing.T) {
+	cases := map[string]bool{
+		"":                                       false,
+		"application/json":                       false,
+		"application/x-ndjson":                   true,
+		"application/json, application/x-ndjson": true,
+		"Application/X-NDJSON; q=0.9":            true,
+	}
+
+	for accept, expected := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/stats/stuck", nil)
+		req.Header.Set("Accept", accept)
+		assert.Equal(t, expected, wantsNDJSON(req), accept)
+	}
+}
+
+func TestStreamNDJSONWritesOneObjectPerLine(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	streamNDJSON(recorder, "items", func(fn func(map[string]int) error) error {
+		for i := 0; i < 3; i++ {
+			if err := fn(map[string]int{"n": i}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, ndjsonContentType, recorder.Header().Get("Content-Type"))
+	scanner := bufio.NewScanner(recorder.Body)
+	var numbers []int
+	for scanner.Scan() {
+		var item map[string]int
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &item), scanner.Text())
+		numbers = append(numbers, item["n"])
+	}
+	assert.Equal(t, []int{0, 1, 2}, numbers)
+}
+
+func TestStreamNDJSONErrorHandling(t *testing.T) {
+	// ошибка до первой строки возвращается обычным ответом с ошибкой
+	recorder := httptest.NewRecorder()
+	streamNDJSON(recorder, "items", func(fn func(int) error) error {
+		return errors.New("connection refused")
+	})
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "INTERNAL_ERROR")
+
+	// ошибка валидации до первой строки - 400
+	recorder = httptest.NewRecorder()
+	streamNDJSON(recorder, "items", func(fn func(int) error) error {
+		return service.NewServiceError("INVALID_REQUEST", "from must be before to")
+	})
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "INVALID_REQUEST")
+
+	// после первой строки статус уже отправлен, поток просто обрывается
+	recorder = httptest.NewRecorder()
+	streamNDJSON(recorder, "items", func(fn func(int) error) error {
+		if err := fn(1); err != nil {
+			return err
+		}
+		return errors.New("connection reset")
+	})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "1", strings.TrimSpace(recorder.Body.String()))
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"pull-request-reviewer-assignment-service/internal/models"
+	"strconv"
+)
+
+// ограничения размера страницы списков
+const (
+	defaultPageLimit = 100
+	maxPageLimit     = 1000
+)
+
+// разбирает параметры постраничной выдачи limit и offset
+// принимает: query параметры запроса
+// возвращает: страницу (limit по умолчанию 100, offset по умолчанию 0) и ошибки валидации параметров
+func parsePagination(query url.Values) (models.Pagination, fieldErrors) {
+	return parsePaginationLimits(query, defaultPageLimit, maxPageLimit)
+}
+
+// разбирает параметры постраничной выдачи limit и offset с ограничениями размера страницы конкретного списка
+// принимает: query параметры запроса, limit по умолчанию и максимальный limit
+// возвращает: страницу (offset по умолчанию 0) и ошибки валидации параметров
+func parsePaginationLimits(query url.Values, defaultLimit, maxLimit int) (models.Pagination, fieldErrors) {
+	page := models.Pagination{Limit: defaultLimit}
+	var errs fieldErrors
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxLimit {
+			errs.add("limit", fmt.Sprintf("limit must be an integer between 1 and %d", maxLimit))
+		} else {
+			page.Limit = limit
+		}
+	}
+
+	if value := query.Get("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			errs.add("offset", "offset must be a non-negative integer")
+		} else {
+			page.Offset = offset
+		}
+	}
+
+	return page, errs
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/url"
+	"pull-request-reviewer-assignment-service/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePaginationDefaultsAndCaps(t *testing.T) {
+	page, errs := parsePagination(url.Values{})
+	require.Empty(t, errs)
+	assert.Equal(t, models.Pagination{Limit: defaultPageLimit}, page)
+
+	page, errs = parsePagination(url.Values{"limit": {"1000"}, "offset": {"20"}})
+	require.Empty(t, errs)
+	assert.Equal(t, models.Pagination{Limit: 1000, Offset: 20}, page)
+}
+
+func TestParsePaginationRejectsInvalidValues(t *testing.T) {
+	cases := []struct {
+		query  url.Values
+		fields []string
+	}{
+		{url.Values{"limit": {"0"}}, []string{"limit"}},
+		{url.Values{"limit": {"1001"}}, []string{"limit"}},
+		{url.Values{"limit": {"ten"}}, []string{"limit"}},
+		{url.Values{"offset": {"-1"}}, []string{"offset"}},
+		{url.Values{"limit": {"-5"}, "offset": {"x"}}, []string{"limit", "offset"}},
+	}
+
+	for _, tc := range cases {
+		_, errs := parsePagination(tc.query)
+		var fields []string
+		for _, fieldErr := range errs {
+			fields = append(fields, fieldErr.Field)
+		}
+		assert.Equal(t, tc.fields, fields, tc.query.Encode())
+	}
+}
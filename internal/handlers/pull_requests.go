@@ -1,29 +1,38 @@
 package handlers
 
 import (
-	"encoding/json"
 	"log"
 	"net/http"
+	"pull-request-reviewer-assignment-service/internal/models"
 	"pull-request-reviewer-assignment-service/internal/service"
+	"strconv"
+	"strings"
 )
 
 // обработчик HTTP запросов для работы с Pull Request'ами
 type PRHandler struct {
 	prService *service.PRService
+	cfg       Config
 }
 
 // создает новый экземпляр обработчика Pull Request'ов с внедрением зависимостей
 // принимает: сервис для логики работы с Pull Request'ами
 // возвращает: инициализированный обработчик с установленными зависимостями
-func NewPRHandler(prService *service.PRService) *PRHandler {
+func NewPRHandler(prService *service.PRService, cfg Config) *PRHandler {
+	if cfg.TimestampFormat != "" && !models.IsKnownTimestampFormat(cfg.TimestampFormat) {
+		log.Printf("Unknown timestamp format %q, falling back to %s", cfg.TimestampFormat, models.TimestampRFC3339Nano)
+		cfg.TimestampFormat = models.TimestampRFC3339Nano
+	}
+
 	return &PRHandler{
 		prService: prService,
+		cfg:       cfg,
 	}
 }
 
 // обрабатывает HTTP запрос на создание нового Pull Request с автоназначением ревьюверов
-// принимает: HTTP запрос с данными Pull Request и response writer для формирования ответа
-// возвращает: JSON ответ с созданным PR или ошибку в случае неудачи
+// принимает: HTTP запрос с данными Pull Request и опциональными параметрами get_if_exists и trace и response writer для формирования ответа
+// возвращает: JSON ответ с созданным PR (и ходом назначения при trace=true), существующим PR при get_if_exists=true или ошибку в случае неудачи
 func (h *PRHandler) CreatePR(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Received POST /pullRequest/create request")
 
@@ -33,15 +42,34 @@ func (h *PRHandler) CreatePR(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var request struct {
-		PullRequestID   string `json:"pull_request_id"`
-		PullRequestName string `json:"pull_request_name"`
-		AuthorID        string `json:"author_id"`
+	// при get_if_exists=true дубликат возвращает существующий PR вместо ошибки
+	getIfExists := false
+	if value := r.URL.Query().Get("get_if_exists"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			log.Printf("Invalid get_if_exists parameter: %s", value)
+			writeError(w, "INVALID_REQUEST", "get_if_exists must be true or false", http.StatusBadRequest)
+			return
+		}
+		getIfExists = parsed
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		log.Printf("Invalid JSON: %v", err)
-		writeError(w, "INVALID_REQUEST", "Invalid JSON", http.StatusBadRequest)
+	// при trace=true вместе с PR возвращается ход назначения ревьюверов
+	withTrace := false
+	if value := r.URL.Query().Get("trace"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			log.Printf("Invalid trace parameter: %s", value)
+			writeError(w, "INVALID_REQUEST", "trace must be true or false", http.StatusBadRequest)
+			return
+		}
+		withTrace = parsed
+	}
+
+	var request models.CreatePRRequest
+
+	if err := decodeJSONBody(r, &request); err != nil {
+		writeDecodeError(w, err)
 		return
 	}
 
@@ -49,25 +77,28 @@ func (h *PRHandler) CreatePR(w http.ResponseWriter, r *http.Request) {
 		request.PullRequestID, request.PullRequestName, request.AuthorID)
 
 	// валидация
-	if request.PullRequestID == "" {
-		log.Printf("Missing pull_request_id")
-		writeError(w, "INVALID_REQUEST", "pull_request_id is required", http.StatusBadRequest)
-		return
-	}
-	if request.PullRequestName == "" {
-		log.Printf("Missing pull_request_name")
-		writeError(w, "INVALID_REQUEST", "pull_request_name is required", http.StatusBadRequest)
-		return
-	}
-	if request.AuthorID == "" {
-		log.Printf("Missing author_id")
-		writeError(w, "INVALID_REQUEST", "author_id is required", http.StatusBadRequest)
+	var errs fieldErrors
+	errs.required("pull_request_id", request.PullRequestID)
+	errs.required("pull_request_name", request.PullRequestName)
+	errs.required("author_id", request.AuthorID)
+	if writeValidationErrors(w, errs) {
 		return
 	}
 
 	// создаем PR через сервис
 	log.Printf("Calling PR service to create PR: %s", request.PullRequestID)
-	pr, err := h.prService.CreatePR(request.PullRequestID, request.PullRequestName, request.AuthorID)
+	var pr *models.PullRequest
+	var trace *models.AssignmentTrace
+	var err error
+	if withTrace {
+		pr, trace, err = h.prService.CreatePRWithTrace(&request)
+	} else {
+		pr, err = h.prService.CreatePR(&request)
+	}
+	if serviceErr, ok := err.(*service.ServiceError); ok && serviceErr.Code == "PR_EXISTS" && getIfExists {
+		h.writeExistingPR(w, r, request.PullRequestID)
+		return
+	}
 	if err != nil {
 		log.Printf("Service error: %v", err)
 		if serviceErr, ok := err.(*service.ServiceError); ok {
@@ -88,12 +119,129 @@ func (h *PRHandler) CreatePR(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Printf("PR created successfully: %s", request.PullRequestID)
+	applyResponseFormat(r, h.cfg.TimestampFormat, pr)
 	response := map[string]interface{}{
 		"pr": pr,
 	}
+	if trace != nil {
+		response["trace"] = trace
+	}
 	writeJSON(w, http.StatusCreated, response)
 }
 
+// возвращает существующий Pull Request в ответ на повторное создание
+// принимает: response writer, исходный запрос и идентификатор существующего PR
+// возвращает: ничего, записывает JSON с PR и статусом 200 или ошибку
+func (h *PRHandler) writeExistingPR(w http.ResponseWriter, r *http.Request, prID string) {
+	pr, err := h.prService.GetPR(prID)
+	if err != nil {
+		log.Printf("Failed to get existing PR %s: %v", prID, err)
+		if serviceErr, ok := err.(*service.ServiceError); ok && serviceErr.Code == "NOT_FOUND" {
+			writeError(w, "NOT_FOUND", serviceErr.Message, http.StatusNotFound)
+			return
+		}
+		writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("PR already exists, returning existing PR: %s", prID)
+	applyResponseFormat(r, h.cfg.TimestampFormat, pr)
+	response := map[string]interface{}{
+		"pr": pr,
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// возвращает Pull Request по идентификатору
+// принимает: HTTP GET запрос с параметром pull_request_id и опциональным expand=reviewers
+// (ревьюверы со временем назначения в поле reviewers)
+// возвращает: JSON с PR или ошибку
+func (h *PRHandler) GetPR(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received GET /pullRequest/get request")
+
+	if r.Method != http.MethodGet {
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	prID := query.Get("pull_request_id")
+	if prID == "" {
+		writeError(w, "INVALID_REQUEST", "pull_request_id parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	expandReviewers := false
+	for _, expand := range parseListParam(query.Get("expand")) {
+		if expand != "reviewers" {
+			writeError(w, "INVALID_REQUEST", "expand supports only reviewers", http.StatusBadRequest)
+			return
+		}
+		expandReviewers = true
+	}
+
+	var pr *models.PullRequest
+	var err error
+	if expandReviewers {
+		pr, err = h.prService.GetPRWithReviewers(prID)
+	} else {
+		pr, err = h.prService.GetPR(prID)
+	}
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		if serviceErr, ok := err.(*service.ServiceError); ok && serviceErr.Code == "NOT_FOUND" {
+			writeError(w, "NOT_FOUND", serviceErr.Message, http.StatusNotFound)
+			return
+		}
+		writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	applyResponseFormat(r, h.cfg.TimestampFormat, pr)
+	response := map[string]interface{}{
+		"pr": pr,
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// обрабатывает получение нескольких Pull Request за один запрос
+// принимает: HTTP запрос с JSON содержащим pull_request_ids
+// возвращает: JSON с картой идентификатора на PR (null для отсутствующих) или ошибку валидации
+func (h *PRHandler) BatchGetPRs(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received POST /pullRequest/batchGet request")
+
+	if r.Method != http.MethodPost {
+		log.Printf("Method not allowed: %s", r.Method)
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request models.BatchGetPRRequest
+	if err := decodeJSONBody(r, &request, collectionLimit{"pull_request_ids", h.cfg.MaxBulkItems}); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	prs, err := h.prService.BatchGetPRs(request.PullRequestIDs)
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		if serviceErr, ok := err.(*service.ServiceError); ok && serviceErr.Code == "INVALID_REQUEST" {
+			writeError(w, "INVALID_REQUEST", serviceErr.Message, http.StatusBadRequest)
+			return
+		}
+		writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	for _, pr := range prs {
+		applyResponseFormat(r, h.cfg.TimestampFormat, pr)
+	}
+	response := map[string]interface{}{
+		"pull_requests": prs,
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
 // обрабатывает запрос на слияние Pull Request
 // принимает: HTTP запрос с JSON содержащим pull_request_id
 // возвращает: JSON ответ с результатом операции или ошибку
@@ -110,9 +258,8 @@ func (h *PRHandler) MergePR(w http.ResponseWriter, r *http.Request) {
 		PullRequestID string `json:"pull_request_id"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		log.Printf("Invalid JSON: %v", err)
-		writeError(w, "INVALID_REQUEST", "Invalid JSON", http.StatusBadRequest)
+	if err := decodeJSONBody(r, &request); err != nil {
+		writeDecodeError(w, err)
 		return
 	}
 
@@ -136,6 +283,8 @@ func (h *PRHandler) MergePR(w http.ResponseWriter, r *http.Request) {
 				writeError(w, "NOT_FOUND", serviceErr.Message, http.StatusNotFound)
 			case "PR_MERGED":
 				writeError(w, "PR_MERGED", serviceErr.Message, http.StatusConflict)
+			case "REQUIRED_REVIEWER_MISSING":
+				writeError(w, "REQUIRED_REVIEWER_MISSING", serviceErr.Message, http.StatusConflict)
 			case "INVALID_REQUEST":
 				writeError(w, "INVALID_REQUEST", serviceErr.Message, http.StatusBadRequest)
 			default:
@@ -148,6 +297,176 @@ func (h *PRHandler) MergePR(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Printf("PR merged successfully: %s", request.PullRequestID)
+	applyResponseFormat(r, h.cfg.TimestampFormat, pr)
+	response := map[string]interface{}{
+		"pr": pr,
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// обрабатывает запрос на закрытие Pull Request без слияния
+// принимает: HTTP запрос с JSON содержащим pull_request_id
+// возвращает: JSON ответ с закрытым PR (повторное закрытие возвращает текущее состояние) или ошибку
+func (h *PRHandler) ClosePR(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received POST /pullRequest/close request")
+
+	if r.Method != http.MethodPost {
+		log.Printf("Method not allowed: %s", r.Method)
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		PullRequestID string `json:"pull_request_id"`
+	}
+
+	if err := decodeJSONBody(r, &request); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	if request.PullRequestID == "" {
+		log.Printf("Missing pull_request_id")
+		writeError(w, "INVALID_REQUEST", "pull_request_id is required", http.StatusBadRequest)
+		return
+	}
+
+	pr, err := h.prService.ClosePR(request.PullRequestID)
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		if serviceErr, ok := err.(*service.ServiceError); ok {
+			switch serviceErr.Code {
+			case "NOT_FOUND":
+				writeError(w, "NOT_FOUND", serviceErr.Message, http.StatusNotFound)
+			case "INVALID_REQUEST":
+				// запрос корректен, но конфликтует с текущим статусом PR (уже замержен)
+				writeError(w, "INVALID_REQUEST", serviceErr.Message, http.StatusConflict)
+			default:
+				writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+			}
+			return
+		}
+		writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("PR closed successfully: %s", request.PullRequestID)
+	applyResponseFormat(r, h.cfg.TimestampFormat, pr)
+	response := map[string]interface{}{
+		"pr": pr,
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// обрабатывает запрос на повторное открытие закрытого Pull Request
+// принимает: HTTP запрос с JSON содержащим pull_request_id
+// возвращает: JSON ответ с открытым PR (при отсутствии активных ревьюверов - с заново назначенными) или ошибку
+func (h *PRHandler) ReopenPR(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received POST /pullRequest/reopen request")
+
+	if r.Method != http.MethodPost {
+		log.Printf("Method not allowed: %s", r.Method)
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		PullRequestID string `json:"pull_request_id"`
+	}
+
+	if err := decodeJSONBody(r, &request); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	if request.PullRequestID == "" {
+		log.Printf("Missing pull_request_id")
+		writeError(w, "INVALID_REQUEST", "pull_request_id is required", http.StatusBadRequest)
+		return
+	}
+
+	pr, err := h.prService.ReopenPR(request.PullRequestID)
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		if serviceErr, ok := err.(*service.ServiceError); ok {
+			switch serviceErr.Code {
+			case "NOT_FOUND":
+				writeError(w, "NOT_FOUND", serviceErr.Message, http.StatusNotFound)
+			case "PR_MERGED":
+				writeError(w, "PR_MERGED", serviceErr.Message, http.StatusConflict)
+			default:
+				writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+			}
+			return
+		}
+		writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("PR reopened successfully: %s", request.PullRequestID)
+	applyResponseFormat(r, h.cfg.TimestampFormat, pr)
+	response := map[string]interface{}{
+		"pr": pr,
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// вручную добавляет ревьювера в Pull Request
+// принимает: HTTP запрос с JSON содержащим pull_request_id и user_id
+// возвращает: JSON ответ с обновленным PR или ошибку
+func (h *PRHandler) AddReviewer(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received POST /pullRequest/addReviewer request")
+
+	if r.Method != http.MethodPost {
+		log.Printf("Method not allowed: %s", r.Method)
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		PullRequestID string `json:"pull_request_id"`
+		UserID        string `json:"user_id"`
+	}
+
+	if err := decodeJSONBody(r, &request); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	var errs fieldErrors
+	errs.required("pull_request_id", request.PullRequestID)
+	errs.required("user_id", request.UserID)
+	if writeValidationErrors(w, errs) {
+		return
+	}
+
+	pr, err := h.prService.AddReviewer(request.PullRequestID, request.UserID)
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		if serviceErr, ok := err.(*service.ServiceError); ok {
+			switch serviceErr.Code {
+			case "NOT_FOUND":
+				writeError(w, "NOT_FOUND", serviceErr.Message, http.StatusNotFound)
+			case "PR_MERGED":
+				writeError(w, "PR_MERGED", serviceErr.Message, http.StatusConflict)
+			case "PR_CLOSED":
+				writeError(w, "PR_CLOSED", serviceErr.Message, http.StatusConflict)
+			case "ALREADY_ASSIGNED":
+				writeError(w, "ALREADY_ASSIGNED", serviceErr.Message, http.StatusConflict)
+			case "INVALID_REQUEST":
+				writeError(w, "INVALID_REQUEST", serviceErr.Message, http.StatusBadRequest)
+			case "ASSIGNMENT_PAUSED":
+				writeError(w, "ASSIGNMENT_PAUSED", serviceErr.Message, http.StatusServiceUnavailable)
+			default:
+				writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+			}
+			return
+		}
+		writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	applyResponseFormat(r, h.cfg.TimestampFormat, pr)
 	response := map[string]interface{}{
 		"pr": pr,
 	}
@@ -171,23 +490,18 @@ func (h *PRHandler) ReassignReviewer(w http.ResponseWriter, r *http.Request) {
 		OldUserID     string `json:"old_user_id"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		log.Printf("Invalid JSON: %v", err)
-		writeError(w, "INVALID_REQUEST", "Invalid JSON", http.StatusBadRequest)
+	if err := decodeJSONBody(r, &request); err != nil {
+		writeDecodeError(w, err)
 		return
 	}
 
 	log.Printf("Parsed request: pr_id=%s, old_user_id=%s", request.PullRequestID, request.OldUserID)
 
 	// валидация
-	if request.PullRequestID == "" {
-		log.Printf("Missing pull_request_id")
-		writeError(w, "INVALID_REQUEST", "pull_request_id is required", http.StatusBadRequest)
-		return
-	}
-	if request.OldUserID == "" {
-		log.Printf("Missing old_user_id")
-		writeError(w, "INVALID_REQUEST", "old_user_id is required", http.StatusBadRequest)
+	var errs fieldErrors
+	errs.required("pull_request_id", request.PullRequestID)
+	errs.required("old_user_id", request.OldUserID)
+	if writeValidationErrors(w, errs) {
 		return
 	}
 
@@ -202,10 +516,16 @@ func (h *PRHandler) ReassignReviewer(w http.ResponseWriter, r *http.Request) {
 				writeError(w, "NOT_FOUND", serviceErr.Message, http.StatusNotFound)
 			case "PR_MERGED":
 				writeError(w, "PR_MERGED", serviceErr.Message, http.StatusConflict)
+			case "PR_CLOSED":
+				writeError(w, "PR_CLOSED", serviceErr.Message, http.StatusConflict)
 			case "NOT_ASSIGNED":
 				writeError(w, "NOT_ASSIGNED", serviceErr.Message, http.StatusConflict)
 			case "NO_CANDIDATE":
 				writeError(w, "NO_CANDIDATE", serviceErr.Message, http.StatusConflict)
+			case "TOO_SOON":
+				writeError(w, "TOO_SOON", serviceErr.Message, http.StatusTooManyRequests)
+			case "ASSIGNMENT_PAUSED":
+				writeError(w, "ASSIGNMENT_PAUSED", serviceErr.Message, http.StatusServiceUnavailable)
 			case "INVALID_REQUEST":
 				writeError(w, "INVALID_REQUEST", serviceErr.Message, http.StatusBadRequest)
 			default:
@@ -218,9 +538,298 @@ func (h *PRHandler) ReassignReviewer(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Printf("Reviewer reassigned successfully: %s -> %s in PR: %s", request.OldUserID, newReviewerID, request.PullRequestID)
+	applyResponseFormat(r, h.cfg.TimestampFormat, pr)
 	response := map[string]interface{}{
 		"pr":          pr,
 		"replaced_by": newReviewerID,
 	}
 	writeJSON(w, http.StatusOK, response)
 }
+
+// заменяет ревьюверов по явным соответствиям {pull_request_id, old_user_id, new_user_id}
+// принимает: HTTP запрос с JSON содержащим mappings
+// возвращает: JSON с результатом по каждому соответствию (частичные ошибки не меняют код ответа) или ошибку валидации
+func (h *PRHandler) BulkReassign(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received POST /pullRequest/bulkReassign request")
+
+	if r.Method != http.MethodPost {
+		log.Printf("Method not allowed: %s", r.Method)
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request models.BulkReassignRequest
+	if err := decodeJSONBody(r, &request, collectionLimit{"mappings", h.cfg.MaxBulkItems}); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	var errs fieldErrors
+	if len(request.Mappings) == 0 {
+		errs.add("mappings", "mappings is required")
+	}
+	if writeValidationErrors(w, errs) {
+		return
+	}
+
+	if h.prService.AssignmentPaused() {
+		log.Printf("Reviewer assignment is paused, bulk reassign rejected")
+		writeError(w, "ASSIGNMENT_PAUSED", "reviewer assignment is paused", http.StatusServiceUnavailable)
+		return
+	}
+
+	response := h.prService.BulkReassign(r.Context(), request.Mappings)
+	writeJSON(w, http.StatusOK, response)
+}
+
+// возвращает кандидатов в ревьюверы PR с оценками, ничего не назначая
+// принимает: HTTP GET запрос с параметром pull_request_id
+// возвращает: JSON со списком кандидатов от лучшего к худшему или ошибку
+func (h *PRHandler) GetReviewerSuggestions(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received GET /pullRequest/reviewerSuggestions request")
+
+	if r.Method != http.MethodGet {
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	prID := r.URL.Query().Get("pull_request_id")
+	if prID == "" {
+		writeError(w, "INVALID_REQUEST", "pull_request_id parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	suggestions, err := h.prService.GetReviewerSuggestions(prID)
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		if serviceErr, ok := err.(*service.ServiceError); ok {
+			switch serviceErr.Code {
+			case "NOT_FOUND":
+				writeError(w, "NOT_FOUND", serviceErr.Message, http.StatusNotFound)
+			case "PR_MERGED":
+				writeError(w, "PR_MERGED", serviceErr.Message, http.StatusConflict)
+			case "PR_CLOSED":
+				writeError(w, "PR_CLOSED", serviceErr.Message, http.StatusConflict)
+			default:
+				writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+			}
+			return
+		}
+		writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"pull_request_id": prID,
+		"suggestions":     suggestions,
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// проверяет, является ли пользователь ревьювером PR
+// принимает: HTTP GET запрос с параметрами pull_request_id и user_id
+// возвращает: JSON с признаком назначения и ролью или ошибку NOT_FOUND если PR не существует
+func (h *PRHandler) IsReviewer(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received GET /pullRequest/isReviewer request")
+
+	if r.Method != http.MethodGet {
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	var errs fieldErrors
+	errs.required("pull_request_id", query.Get("pull_request_id"))
+	errs.required("user_id", query.Get("user_id"))
+	if writeValidationErrors(w, errs) {
+		return
+	}
+
+	check, err := h.prService.CheckReviewer(query.Get("pull_request_id"), query.Get("user_id"))
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		if serviceErr, ok := err.(*service.ServiceError); ok && serviceErr.Code == "NOT_FOUND" {
+			writeError(w, "NOT_FOUND", serviceErr.Message, http.StatusNotFound)
+			return
+		}
+		writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, check)
+}
+
+// возвращает состав ревьюверов PR на момент времени, восстановленный по журналу назначений
+// принимает: HTTP GET запрос с параметрами pull_request_id, at и опциональным since (RFC3339 или YYYY-MM-DD)
+// возвращает: JSON с ревьюверами на момент at и изменениями с момента since или ошибку
+func (h *PRHandler) GetReviewersAt(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received GET /pullRequest/reviewersAt request")
+
+	if r.Method != http.MethodGet {
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	var errs fieldErrors
+	errs.required("pull_request_id", query.Get("pull_request_id"))
+	at, err := parseTimeParam(query.Get("at"))
+	switch {
+	case err != nil:
+		errs.add("at", "at must be an RFC3339 timestamp or a YYYY-MM-DD date")
+	case at == nil:
+		errs.add("at", "at is required")
+	}
+	since, err := parseTimeParam(query.Get("since"))
+	if err != nil {
+		errs.add("since", "since must be an RFC3339 timestamp or a YYYY-MM-DD date")
+	}
+	if writeValidationErrors(w, errs) {
+		return
+	}
+
+	response, err := h.prService.GetReviewersAt(query.Get("pull_request_id"), *at, since)
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		if serviceErr, ok := err.(*service.ServiceError); ok {
+			switch serviceErr.Code {
+			case "NOT_FOUND":
+				writeError(w, "NOT_FOUND", serviceErr.Message, http.StatusNotFound)
+				return
+			case "INVALID_REQUEST":
+				writeError(w, "INVALID_REQUEST", serviceErr.Message, http.StatusBadRequest)
+				return
+			}
+		}
+		writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// возвращает PR, авторы которых состоят в команде
+// принимает: HTTP GET запрос с параметрами team_name, опциональным status (OPEN, MERGED или CLOSED), limit и offset
+// возвращает: JSON со страницей PR с ревьюверами или ошибку
+func (h *PRHandler) GetTeamAuthoredPRs(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received GET /team/authoredPRs request")
+
+	if r.Method != http.MethodGet {
+		log.Printf("Method not allowed: %s", r.Method)
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	teamName := r.URL.Query().Get("team_name")
+	if teamName == "" {
+		log.Printf("Missing team_name parameter")
+		writeError(w, "INVALID_REQUEST", "team_name parameter is required", http.StatusBadRequest)
+		return
+	}
+	status := strings.ToUpper(strings.TrimSpace(r.URL.Query().Get("status")))
+	page, errs := parsePagination(r.URL.Query())
+	if writeValidationErrors(w, errs) {
+		return
+	}
+
+	prs, page, err := h.prService.GetTeamAuthoredPRs(teamName, status, page)
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		if serviceErr, ok := err.(*service.ServiceError); ok {
+			switch serviceErr.Code {
+			case "NOT_FOUND":
+				writeError(w, "NOT_FOUND", serviceErr.Message, http.StatusNotFound)
+			case "INVALID_REQUEST":
+				writeError(w, "INVALID_REQUEST", serviceErr.Message, http.StatusBadRequest)
+			default:
+				writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+			}
+			return
+		}
+		writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	applyResponseFormat(r, h.cfg.TimestampFormat, prs...)
+	response := map[string]interface{}{
+		"team_name":     teamName,
+		"status":        status,
+		"pull_requests": prs,
+		"pagination":    page,
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// возвращает список Pull Request от новых к старым для дашбордов
+// принимает: HTTP GET запрос с опциональными параметрами status (OPEN, MERGED или CLOSED), author_id,
+// limit (по умолчанию 50, не больше 200) и offset
+// возвращает: JSON со страницей PR и метаданными страницы с общим количеством или ошибку
+func (h *PRHandler) ListPRs(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received GET /pullRequests/list request")
+
+	if r.Method != http.MethodGet {
+		log.Printf("Method not allowed: %s", r.Method)
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	page, errs := parsePaginationLimits(query, service.PRListDefaultLimit, service.PRListMaxLimit)
+	if writeValidationErrors(w, errs) {
+		return
+	}
+	filter := models.PRFilter{
+		Status:   strings.ToUpper(strings.TrimSpace(query.Get("status"))),
+		AuthorID: strings.TrimSpace(query.Get("author_id")),
+		Limit:    page.Limit,
+		Offset:   page.Offset,
+	}
+
+	prs, page, err := h.prService.ListPRs(filter)
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		if serviceErr, ok := err.(*service.ServiceError); ok && serviceErr.Code == "INVALID_REQUEST" {
+			writeError(w, "INVALID_REQUEST", serviceErr.Message, http.StatusBadRequest)
+			return
+		}
+		writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"pull_requests": prs,
+		"pagination":    page,
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// возвращает настройки назначения ревьюверов, действующие для команды
+// принимает: HTTP GET запрос с параметром team_name
+// возвращает: JSON с действующими настройками или ошибку
+func (h *PRHandler) GetTeamAssignmentConfig(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received GET /team/assignmentConfig request")
+
+	if r.Method != http.MethodGet {
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	teamName := r.URL.Query().Get("team_name")
+	if teamName == "" {
+		writeError(w, "INVALID_REQUEST", "team_name parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	config, err := h.prService.GetTeamAssignmentConfig(teamName)
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		if serviceErr, ok := err.(*service.ServiceError); ok && serviceErr.Code == "NOT_FOUND" {
+			writeError(w, "NOT_FOUND", serviceErr.Message, http.StatusNotFound)
+			return
+		}
+		writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, config)
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+)
+
+// методы, которые не изменяют данные и обслуживаются в режиме только для чтения
+var readOnlyMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+}
+
+// POST эндпоинты, которые не изменяют данные: симуляция и проверка команды ничего не сохраняют,
+// пакетное чтение PR только читает, а вывод из балансировки меняет только состояние процесса
+var readOnlyAllowedPaths = map[string]bool{
+	"/admin/simulate":       true,
+	"/admin/drain":          true,
+	"/team/validate":        true,
+	"/pullRequest/batchGet": true,
+}
+
+// оборачивает обработчик режимом только для чтения (READ_ONLY) для реплик аналитики
+// принимает: следующий обработчик
+// возвращает: обработчик, отвечающий 503 READ_ONLY на изменяющие запросы, кроме эндпоинтов из readOnlyAllowedPaths
+func ReadOnlyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if readOnlyMethods[r.Method] || readOnlyAllowedPaths[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		log.Printf("Rejected request %s %s: service is read-only", r.Method, r.URL.Path)
+		writeError(w, "READ_ONLY", "service is running in read-only mode", http.StatusServiceUnavailable)
+	})
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pull-request-reviewer-assignment-service/internal/models"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// создает обработчик в режиме только для чтения, который считает дошедшие до него запросы
+func newReadOnlyCounter(served *int) http.Handler {
+	return ReadOnlyMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*served++
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func TestReadOnlyRejectsWrites(t *testing.T) {
+	served := 0
+	handler := newReadOnlyCounter(&served)
+
+	for _, path := range []string{"/pullRequest/create", "/pullRequest/merge", "/team/add", "/users/setIsActive", "/admin/purge"} {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{}`)))
+		assert.Equal(t, http.StatusServiceUnavailable, recorder.Code, path)
+
+		var response models.ErrorResponse
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		assert.Equal(t, "READ_ONLY", response.Error.Code, path)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodDelete, "/pullRequest/get", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+	assert.Zero(t, served)
+}
+
+func TestReadOnlyServesReads(t *testing.T) {
+	served := 0
+	handler := newReadOnlyCounter(&served)
+
+	requests := []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/pullRequest/get?pull_request_id=pr-1", nil),
+		httptest.NewRequest(http.MethodGet, "/stats/review-assignments", nil),
+		httptest.NewRequest(http.MethodHead, "/health", nil),
+		// эндпоинты из списка разрешенных не изменяют данные
+		httptest.NewRequest(http.MethodPost, "/admin/simulate", strings.NewReader(`{}`)),
+		httptest.NewRequest(http.MethodPost, "/admin/drain", nil),
+		httptest.NewRequest(http.MethodPost, "/pullRequest/batchGet", strings.NewReader(`{"pull_request_ids":["pr-1"]}`)),
+	}
+	for _, req := range requests {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		assert.Equal(t, http.StatusOK, recorder.Code, req.URL.Path)
+	}
+
+	assert.Equal(t, len(requests), served)
+}
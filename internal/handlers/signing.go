@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// заголовки подписанного запроса
+const (
+	SignatureHeader = "X-Signature"
+	TimestampHeader = "X-Timestamp"
+)
+
+// максимальный размер тела запроса; подпись проверяется по телу целиком, поэтому оно читается в память
+const maxRequestBodyBytes = 1 << 20
+
+// пути, которые не требуют подписи, чтобы балансировщик мог проверять состояние сервиса
+var unsignedPaths = map[string]bool{
+	"/health": true,
+	"/ready":  true,
+}
+
+// проверка HMAC подписи запросов от внутренних сервисов
+type RequestSigning struct {
+	secret []byte
+	maxAge time.Duration
+	now    func() time.Time
+}
+
+// создает и возвращает новый экземпляр RequestSigning
+// принимает: общий секрет и максимальное расхождение метки времени запроса с текущим временем
+// возвращает: указатель на созданный RequestSigning
+func NewRequestSigning(secret string, maxAge time.Duration) *RequestSigning {
+	return &RequestSigning{
+		secret: []byte(secret),
+		maxAge: maxAge,
+		now:    time.Now,
+	}
+}
+
+// вычисляет подпись запроса
+// принимает: общий секрет, HTTP метод, путь с query параметрами, метку времени в секундах Unix и тело запроса
+// возвращает: HMAC-SHA256 в hex от строки "метод\nпуть\nметка\nтело"
+func SignRequest(secret, method, path, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(method + "\n" + path + "\n" + timestamp + "\n"))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// оборачивает обработчик проверкой подписи запроса
+// принимает: следующий обработчик
+// возвращает: обработчик, отвечающий 401 UNAUTHORIZED на запросы без подписи, с неверной подписью или устаревшей меткой времени
+// и 413 PAYLOAD_TOO_LARGE на тело больше maxRequestBodyBytes
+func (s *RequestSigning) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if unsignedPaths[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		timestamp := r.Header.Get(TimestampHeader)
+		signature := r.Header.Get(SignatureHeader)
+		if timestamp == "" || signature == "" {
+			writeError(w, "UNAUTHORIZED", "missing request signature", http.StatusUnauthorized)
+			return
+		}
+
+		// метка времени ограничивает окно, в котором перехваченный запрос можно повторить
+		seconds, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			writeError(w, "UNAUTHORIZED", "invalid request timestamp", http.StatusUnauthorized)
+			return
+		}
+		age := s.now().Sub(time.Unix(seconds, 0))
+		if age > s.maxAge || age < -s.maxAge {
+			log.Printf("Rejected request %s %s: timestamp is %s away from server time", r.Method, r.URL.Path, age)
+			writeError(w, "UNAUTHORIZED", "request timestamp is outside the allowed window", http.StatusUnauthorized)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBodyBytes))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeError(w, "PAYLOAD_TOO_LARGE", fmt.Sprintf("request body exceeds %d bytes", tooLarge.Limit), http.StatusRequestEntityTooLarge)
+				return
+			}
+			writeError(w, "INVALID_REQUEST", "failed to read request body", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		expected := SignRequest(string(s.secret), r.Method, r.URL.RequestURI(), timestamp, body)
+		if !hmac.Equal([]byte(expected), []byte(signature)) {
+			log.Printf("Rejected request %s %s: invalid signature", r.Method, r.URL.Path)
+			writeError(w, "UNAUTHORIZED", "invalid request signature", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
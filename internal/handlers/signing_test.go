@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"pull-request-reviewer-assignment-service/internal/models"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSigningSecret = "test-secret"
+
+// создает middleware с фиксированным временем и обработчиком, возвращающим тело запроса
+func newSignedEcho(now time.Time) http.Handler {
+	signing := NewRequestSigning(testSigningSecret, 5*time.Minute)
+	signing.now = func() time.Time { return now }
+	return signing.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write(body)
+	}))
+}
+
+// создает подписанный запрос с переданной меткой времени
+func signedRequest(body string, signedAt time.Time) *http.Request {
+	timestamp := strconv.FormatInt(signedAt.Unix(), 10)
+	req := httptest.NewRequest(http.MethodPost, "/pullRequest/create", strings.NewReader(body))
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, SignRequest(testSigningSecret, http.MethodPost, "/pullRequest/create", timestamp, []byte(body)))
+	return req
+}
+
+// проверяет, что ответ - ошибка UNAUTHORIZED
+func assertUnauthorized(t *testing.T, recorder *httptest.ResponseRecorder) {
+	t.Helper()
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	var response models.ErrorResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	assert.Equal(t, "UNAUTHORIZED", response.Error.Code)
+}
+
+func TestSignedRequestIsAccepted(t *testing.T) {
+	now := time.Now()
+	body := `{"pull_request_id":"pr-1"}`
+
+	recorder := httptest.NewRecorder()
+	newSignedEcho(now).ServeHTTP(recorder, signedRequest(body, now.Add(-time.Minute)))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	// обработчик получает тело целиком после проверки подписи
+	assert.Equal(t, body, recorder.Body.String())
+}
+
+func TestTamperedBodyIsRejected(t *testing.T) {
+	now := time.Now()
+	req := signedRequest(`{"pull_request_id":"pr-1"}`, now)
+	req.Body = io.NopCloser(strings.NewReader(`{"pull_request_id":"pr-2"}`))
+
+	recorder := httptest.NewRecorder()
+	newSignedEcho(now).ServeHTTP(recorder, req)
+
+	assertUnauthorized(t, recorder)
+}
+
+func TestStaleTimestampIsRejected(t *testing.T) {
+	now := time.Now()
+
+	recorder := httptest.NewRecorder()
+	newSignedEcho(now).ServeHTTP(recorder, signedRequest(`{}`, now.Add(-10*time.Minute)))
+
+	assertUnauthorized(t, recorder)
+}
+
+func TestUnsignedRequestIsRejectedExceptProbes(t *testing.T) {
+	handler := newSignedEcho(time.Now())
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/team/get?team_name=backend", nil))
+	assertUnauthorized(t, recorder)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestOversizedBodyIsRejectedBeforeSignatureCheck(t *testing.T) {
+	now := time.Now()
+	body := `{"pull_request_id":"` + strings.Repeat("a", maxRequestBodyBytes) + `"}`
+
+	recorder := httptest.NewRecorder()
+	newSignedEcho(now).ServeHTTP(recorder, signedRequest(body, now))
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+	var response models.ErrorResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	assert.Equal(t, "PAYLOAD_TOO_LARGE", response.Error.Code)
+}
@@ -3,7 +3,11 @@ package handlers
 import (
 	"log"
 	"net/http"
+	"pull-request-reviewer-assignment-service/internal/models"
 	"pull-request-reviewer-assignment-service/internal/service"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // структура обрабатывает HTTP запросы для получения статистики
@@ -21,7 +25,7 @@ func NewStatsHandler(statsService *service.StatsService) *StatsHandler {
 }
 
 // возвращает статистику по назначениям на код-ревью
-// принимает: HTTP GET запрос без параметров
+// принимает: HTTP GET запрос с опциональным параметром exclude_users (идентификаторы через запятую)
 // возвращает: JSON со статистикой назначений или ошибку
 func (h *StatsHandler) GetReviewStats(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Received GET /stats/review-assignments request")
@@ -31,7 +35,12 @@ func (h *StatsHandler) GetReviewStats(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	stats, err := h.statsService.GetReviewStats()
+	excludeUserIDs := parseListParam(r.URL.Query().Get("exclude_users"))
+	if len(excludeUserIDs) > 0 {
+		log.Printf("Excluding users from stats: %v", excludeUserIDs)
+	}
+
+	stats, err := h.statsService.GetReviewStats(excludeUserIDs)
 	if err != nil {
 		log.Printf("Failed to get stats: %v", err)
 		writeError(w, "INTERNAL_ERROR", "Failed to retrieve statistics", http.StatusInternalServerError)
@@ -41,3 +50,463 @@ func (h *StatsHandler) GetReviewStats(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Statistics retrieved: %d total assignments", stats.TotalAssignments)
 	writeJSON(w, http.StatusOK, stats)
 }
+
+// возвращает активных пользователей, которые ни разу не назначались ревьюверами
+// принимает: HTTP GET запрос с опциональными параметрами team_name, limit и offset;
+// с заголовком Accept: application/x-ndjson все пользователи передаются потоком без постраничной выдачи
+// возвращает: JSON со списком пользователей, NDJSON поток или ошибку
+func (h *StatsHandler) GetUnassignedUsers(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received GET /stats/unassigned request")
+
+	if r.Method != http.MethodGet {
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	teamName := strings.TrimSpace(r.URL.Query().Get("team_name"))
+
+	page, errs := parsePagination(r.URL.Query())
+	if writeValidationErrors(w, errs) {
+		return
+	}
+
+	response, err := h.statsService.GetUnassignedUsers(teamName, page)
+	if err != nil {
+		log.Printf("Failed to get unassigned users: %v", err)
+		writeError(w, "INTERNAL_ERROR", "Failed to retrieve statistics", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Found %d never assigned users", len(response.Users))
+	writeJSON(w, http.StatusOK, response)
+}
+
+// возвращает открытые PR без активных ревьюверов, которые никто не увидит в своих ревью
+// принимает: HTTP GET запрос с опциональными параметрами limit и offset;
+// с заголовком Accept: application/x-ndjson все PR передаются потоком без постраничной выдачи
+// возвращает: JSON со списком PR, NDJSON поток или ошибку
+func (h *StatsHandler) GetStuckPRs(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received GET /stats/stuck request")
+
+	if r.Method != http.MethodGet {
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if wantsNDJSON(r) {
+		streamNDJSON(w, "stuck PRs", func(fn func(models.StuckPR) error) error {
+			return h.statsService.StreamStuckPRs(r.Context(), fn)
+		})
+		return
+	}
+
+	page, errs := parsePagination(r.URL.Query())
+	if writeValidationErrors(w, errs) {
+		return
+	}
+
+	response, err := h.statsService.GetStuckPRs(page)
+	if err != nil {
+		log.Printf("Failed to get stuck PRs: %v", err)
+		writeError(w, "INTERNAL_ERROR", "Failed to retrieve statistics", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Found %d open PRs without active reviewers", len(response.PullRequests))
+	writeJSON(w, http.StatusOK, response)
+}
+
+// возвращает количество назначений ревьюверов по статусам PR
+// принимает: HTTP GET запрос с опциональными параметрами user_id, team_name, limit и offset
+// возвращает: JSON со статистикой по ревьюверам или ошибку
+func (h *StatsHandler) GetAssignmentsByStatus(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received GET /stats/byStatus request")
+
+	if r.Method != http.MethodGet {
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := strings.TrimSpace(r.URL.Query().Get("user_id"))
+	teamName := strings.TrimSpace(r.URL.Query().Get("team_name"))
+
+	page, errs := parsePagination(r.URL.Query())
+	if writeValidationErrors(w, errs) {
+		return
+	}
+
+	response, err := h.statsService.GetAssignmentsByStatus(userID, teamName, page)
+	if err != nil {
+		log.Printf("Failed to get assignments by status: %v", err)
+		writeError(w, "INTERNAL_ERROR", "Failed to retrieve statistics", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// возвращает авторов с наибольшим количеством созданных PR
+// принимает: HTTP GET запрос с опциональными параметрами limit, status, from и to (RFC3339 или YYYY-MM-DD)
+// возвращает: JSON со списком авторов по убыванию количества PR или ошибку
+func (h *StatsHandler) GetAuthorStats(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received GET /stats/authors request")
+
+	if r.Method != http.MethodGet {
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := models.AuthorStatsFilter{
+		Status: strings.ToUpper(strings.TrimSpace(query.Get("status"))),
+	}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			writeError(w, "INVALID_REQUEST", "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		filter.Limit = limit
+	}
+
+	var err error
+	if filter.From, err = parseTimeParam(query.Get("from")); err != nil {
+		writeError(w, "INVALID_REQUEST", "from must be an RFC3339 timestamp or a YYYY-MM-DD date", http.StatusBadRequest)
+		return
+	}
+	if filter.To, err = parseTimeParam(query.Get("to")); err != nil {
+		writeError(w, "INVALID_REQUEST", "to must be an RFC3339 timestamp or a YYYY-MM-DD date", http.StatusBadRequest)
+		return
+	}
+
+	response, err := h.statsService.GetAuthorStats(filter)
+	if err != nil {
+		log.Printf("Failed to get author stats: %v", err)
+		if serviceErr, ok := err.(*service.ServiceError); ok && serviceErr.Code == "INVALID_REQUEST" {
+			writeError(w, "INVALID_REQUEST", serviceErr.Message, http.StatusBadRequest)
+			return
+		}
+		writeError(w, "INTERNAL_ERROR", "Failed to retrieve statistics", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// возвращает количество PR по корзинам возраста для отслеживания SLA
+// принимает: HTTP GET запрос с опциональными параметрами status (по умолчанию OPEN), buckets (границы через запятую,
+// например 1d,3d,7d или 12h), team_name и by_team
+// возвращает: JSON с корзинами возраста или ошибку
+func (h *StatsHandler) GetPRAging(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received GET /stats/aging request")
+
+	if r.Method != http.MethodGet {
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := models.AgingFilter{
+		Status:   strings.ToUpper(strings.TrimSpace(query.Get("status"))),
+		TeamName: strings.TrimSpace(query.Get("team_name")),
+	}
+
+	for _, value := range parseListParam(query.Get("buckets")) {
+		boundary, err := parseAgeParam(value)
+		if err != nil {
+			writeError(w, "INVALID_REQUEST", "buckets must be durations like 1d, 3d or 12h", http.StatusBadRequest)
+			return
+		}
+		filter.Boundaries = append(filter.Boundaries, boundary)
+	}
+
+	if value := query.Get("by_team"); value != "" {
+		byTeam, err := strconv.ParseBool(value)
+		if err != nil {
+			writeError(w, "INVALID_REQUEST", "by_team must be true or false", http.StatusBadRequest)
+			return
+		}
+		filter.ByTeam = byTeam
+	}
+
+	response, err := h.statsService.GetPRAging(filter)
+	if err != nil {
+		log.Printf("Failed to get PR aging: %v", err)
+		if serviceErr, ok := err.(*service.ServiceError); ok && serviceErr.Code == "INVALID_REQUEST" {
+			writeError(w, "INVALID_REQUEST", serviceErr.Message, http.StatusBadRequest)
+			return
+		}
+		writeError(w, "INTERNAL_ERROR", "Failed to retrieve statistics", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// возвращает статистику перестановок ревьюверов за период
+// принимает: HTTP GET запрос с опциональными параметрами from и to (RFC3339 или YYYY-MM-DD), team_name и limit
+// возвращает: JSON с общим и средним количеством замен и PR с наибольшим количеством замен или ошибку
+func (h *StatsHandler) GetReassignmentChurn(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received GET /stats/churn request")
+
+	if r.Method != http.MethodGet {
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := models.ChurnFilter{
+		TeamName: strings.TrimSpace(query.Get("team_name")),
+	}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			writeError(w, "INVALID_REQUEST", "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		filter.Limit = limit
+	}
+
+	var err error
+	if filter.From, err = parseTimeParam(query.Get("from")); err != nil {
+		writeError(w, "INVALID_REQUEST", "from must be an RFC3339 timestamp or a YYYY-MM-DD date", http.StatusBadRequest)
+		return
+	}
+	if filter.To, err = parseTimeParam(query.Get("to")); err != nil {
+		writeError(w, "INVALID_REQUEST", "to must be an RFC3339 timestamp or a YYYY-MM-DD date", http.StatusBadRequest)
+		return
+	}
+
+	response, err := h.statsService.GetReassignmentChurn(filter)
+	if err != nil {
+		log.Printf("Failed to get reassignment churn: %v", err)
+		if serviceErr, ok := err.(*service.ServiceError); ok && serviceErr.Code == "INVALID_REQUEST" {
+			writeError(w, "INVALID_REQUEST", serviceErr.Message, http.StatusBadRequest)
+			return
+		}
+		writeError(w, "INTERNAL_ERROR", "Failed to retrieve statistics", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// возвращает долю PR, созданных за период, которые получили нужное количество ревьюверов вовремя
+// принимает: HTTP GET запрос с опциональными параметрами threshold (количество ревьюверов), within (длительность Go),
+// from и to (RFC3339 или YYYY-MM-DD) и team_name
+// возвращает: JSON с количеством PR, уложившихся и не уложившихся в срок, и долей соблюдения или ошибку
+func (h *StatsHandler) GetAssignmentSLA(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received GET /stats/assignmentSLA request")
+
+	if r.Method != http.MethodGet {
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := models.AssignmentSLAFilter{
+		TeamName: strings.TrimSpace(query.Get("team_name")),
+	}
+
+	if value := query.Get("threshold"); value != "" {
+		threshold, err := strconv.Atoi(value)
+		if err != nil || threshold <= 0 {
+			writeError(w, "INVALID_REQUEST", "threshold must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		filter.Threshold = threshold
+	}
+	if value := query.Get("within"); value != "" {
+		within, err := parseAgeParam(value)
+		if err != nil || within <= 0 {
+			writeError(w, "INVALID_REQUEST", "within must be a positive duration like 5m or 1h", http.StatusBadRequest)
+			return
+		}
+		filter.Within = within
+	}
+
+	var err error
+	if filter.From, err = parseTimeParam(query.Get("from")); err != nil {
+		writeError(w, "INVALID_REQUEST", "from must be an RFC3339 timestamp or a YYYY-MM-DD date", http.StatusBadRequest)
+		return
+	}
+	if filter.To, err = parseTimeParam(query.Get("to")); err != nil {
+		writeError(w, "INVALID_REQUEST", "to must be an RFC3339 timestamp or a YYYY-MM-DD date", http.StatusBadRequest)
+		return
+	}
+
+	response, err := h.statsService.GetAssignmentSLA(filter)
+	if err != nil {
+		log.Printf("Failed to get assignment SLA: %v", err)
+		if serviceErr, ok := err.(*service.ServiceError); ok && serviceErr.Code == "INVALID_REQUEST" {
+			writeError(w, "INVALID_REQUEST", serviceErr.Message, http.StatusBadRequest)
+			return
+		}
+		writeError(w, "INTERNAL_ERROR", "Failed to retrieve statistics", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Assignment SLA: %d of %d PRs staffed within %s", response.MetPRs, response.TotalPRs, response.Within)
+	writeJSON(w, http.StatusOK, response)
+}
+
+// сравнивает показатели нагрузки двух команд с разницей между ними
+// принимает: HTTP GET запрос с обязательными параметрами a и b - названиями команд
+// возвращает: JSON с показателями команд и разницей b относительно a или ошибку
+func (h *StatsHandler) CompareTeams(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received GET /stats/compareTeams request")
+
+	if r.Method != http.MethodGet {
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	teamA, teamB := strings.TrimSpace(query.Get("a")), strings.TrimSpace(query.Get("b"))
+	var errs fieldErrors
+	if teamA == "" {
+		errs.add("a", "a is required")
+	}
+	if teamB == "" {
+		errs.add("b", "b is required")
+	}
+	if writeValidationErrors(w, errs) {
+		return
+	}
+
+	response, err := h.statsService.CompareTeams(teamA, teamB)
+	if err != nil {
+		log.Printf("Failed to compare teams: %v", err)
+		if serviceErr, ok := err.(*service.ServiceError); ok && serviceErr.Code == "NOT_FOUND" {
+			writeError(w, "NOT_FOUND", serviceErr.Message, http.StatusNotFound)
+			return
+		}
+		writeError(w, "INTERNAL_ERROR", "Failed to retrieve statistics", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// возвращает пары ревьюверов, назначенных на одни и те же PR, и количество совместных ревью
+// принимает: HTTP GET запрос с опциональными параметрами from, to, team_name, limit и offset
+// возвращает: JSON с парами по убыванию количества совместных ревью или ошибку
+func (h *StatsHandler) GetReviewerPairs(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received GET /stats/pairs request")
+
+	if r.Method != http.MethodGet {
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := models.PairFilter{
+		TeamName: strings.TrimSpace(query.Get("team_name")),
+	}
+
+	var err error
+	if filter.From, err = parseTimeParam(query.Get("from")); err != nil {
+		writeError(w, "INVALID_REQUEST", "from must be an RFC3339 timestamp or a YYYY-MM-DD date", http.StatusBadRequest)
+		return
+	}
+	if filter.To, err = parseTimeParam(query.Get("to")); err != nil {
+		writeError(w, "INVALID_REQUEST", "to must be an RFC3339 timestamp or a YYYY-MM-DD date", http.StatusBadRequest)
+		return
+	}
+	page, errs := parsePagination(query)
+	if writeValidationErrors(w, errs) {
+		return
+	}
+
+	response, err := h.statsService.GetReviewerPairs(filter, page)
+	if err != nil {
+		log.Printf("Failed to get reviewer pairs: %v", err)
+		if serviceErr, ok := err.(*service.ServiceError); ok && serviceErr.Code == "INVALID_REQUEST" {
+			writeError(w, "INVALID_REQUEST", serviceErr.Message, http.StatusBadRequest)
+			return
+		}
+		writeError(w, "INTERNAL_ERROR", "Failed to retrieve statistics", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// разбирает границу корзины возраста
+// принимает: количество дней вида "3d" или длительность Go вида "12h"
+// возвращает: длительность или ошибку формата
+func parseAgeParam(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		count, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(count) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// выгружает журнал назначений ревьюверов для аналитики потоком NDJSON: одно событие на строку в порядке записи.
+// Для инкрементальной выгрузки клиент передает в from время последнего полученного события
+// и отбрасывает уже полученные event_id на границе
+// принимает: HTTP GET запрос с опциональными параметрами from и to (период [from, to) в RFC3339 или YYYY-MM-DD)
+// возвращает: NDJSON поток событий или ошибку
+func (h *StatsHandler) ExportAssignmentEvents(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received GET /export/events.ndjson request")
+
+	if r.Method != http.MethodGet {
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	var filter models.EventExportFilter
+	var err error
+	if filter.From, err = parseTimeParam(query.Get("from")); err != nil {
+		writeError(w, "INVALID_REQUEST", "from must be an RFC3339 timestamp or a YYYY-MM-DD date", http.StatusBadRequest)
+		return
+	}
+	if filter.To, err = parseTimeParam(query.Get("to")); err != nil {
+		writeError(w, "INVALID_REQUEST", "to must be an RFC3339 timestamp or a YYYY-MM-DD date", http.StatusBadRequest)
+		return
+	}
+
+	streamNDJSON(w, "assignment events", func(fn func(models.ExportedAssignmentEvent) error) error {
+		return h.statsService.StreamAssignmentEvents(r.Context(), filter, fn)
+	})
+}
+
+// разбирает значение query параметра с моментом времени
+// принимает: строку в формате RFC3339 или YYYY-MM-DD (дата означает начало дня UTC)
+// возвращает: указатель на время (nil для пустой строки) или ошибку формата
+func parseTimeParam(value string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		parsed, err = time.Parse(time.DateOnly, value)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+// разбирает значение query параметра со списком значений через запятую
+// принимает: строку вида "a, b,c" из query параметра
+// возвращает: слайс непустых значений без пробелов по краям
+func parseListParam(value string) []string {
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// сведения о сборке, подставляются при сборке через
+// -ldflags "-X pull-request-reviewer-assignment-service/internal/handlers.Version=... -X ...Commit=..."
+var (
+	Version = "1.0.0"
+	Commit  = "unknown"
+)
+
+// время запуска процесса для расчета uptime
+var startTime = time.Now()
+
+// возвращает сведения о сборке и времени работы процесса для /health и корневого эндпоинта
+// принимает: ничего
+// возвращает: map с версией, коммитом, версией Go и uptime в секундах
+func buildInfo() map[string]interface{} {
+	uptime := time.Since(startTime).Seconds()
+	return map[string]interface{}{
+		"version":        Version,
+		"commit":         Commit,
+		"go_version":     runtime.Version(),
+		"started_at":     startTime.UTC().Format(time.RFC3339),
+		"uptime_seconds": math.Round(uptime*1000) / 1000,
+	}
+}
+
+// готовность сервиса принимать новый трафик от балансировщика
+type Readiness struct {
+	draining atomic.Bool
+}
+
+// создает и возвращает новый экземпляр Readiness в состоянии готовности
+// принимает: ничего
+// возвращает: указатель на созданный Readiness
+func NewReadiness() *Readiness {
+	return &Readiness{}
+}
+
+// обработчик эндпоинта готовности для балансировщика
+// принимает: HTTP запрос и writer для ответа
+// возвращает: JSON со статусом ready и кодом 200 или draining и кодом 503 после вызова drain
+func (rd *Readiness) Ready(w http.ResponseWriter, r *http.Request) {
+	if rd.draining.Load() {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "draining"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ready"})
+}
+
+// переводит сервис в режим вывода из балансировки: /ready начинает отвечать 503,
+// а остальные запросы обслуживаются как обычно до получения SIGTERM
+// принимает: HTTP POST запрос
+// возвращает: JSON со статусом draining или ошибку
+func (rd *Readiness) Drain(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received POST /admin/drain request")
+
+	if r.Method != http.MethodPost {
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !rd.draining.Swap(true) {
+		log.Println("Draining: /ready now reports 503, still serving requests until shutdown")
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "draining"})
+}
+
+// обработчик эндпоинта проверки healthy сервиса
+// принимает: HTTP запрос и writer для ответа на запросы проверки health
+// возвращает: JSON ответ со статусом, названием, версией и коммитом сборки, версией Go и uptime сервиса
+func Health(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/health" {
+		NotFound(w, r)
+		return
+	}
+
+	response := buildInfo()
+	response["status"] = "healthy"
+	response["service"] = "PR Reviewer Assignment Service"
+	writeJSON(w, http.StatusOK, response)
+}
+
+// обработчик корневого эндпоинта, также принимает все запросы к незарегистрированным путям
+// принимает: HTTP запрос и writer для ответа на запросы к корневому пути
+// возвращает: JSON с описанием сервиса, сведениями о сборке, uptime и списком доступных эндпоинтов или JSON ошибку 404
+func Home(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		NotFound(w, r)
+		return
+	}
+
+	response := buildInfo()
+	response["service"] = "PR Reviewer Service is running!"
+	response["endpoints"] = map[string]string{
+		"health":        "/health",
+		"teams":         "/team/add, /team/get",
+		"users":         "/users/setIsActive, /users/getReview",
+		"pull_requests": "/pullRequest/create, /pullRequest/merge, /pullRequest/reassign",
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// отвечает стандартной JSON ошибкой для неизвестного пути
+// принимает: HTTP запрос и writer для ответа
+// возвращает: JSON ошибку NOT_FOUND со статусом 404
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Route not found: %s %s", r.Method, r.URL.Path)
+	writeError(w, "NOT_FOUND", "route not found: "+r.URL.Path, http.StatusNotFound)
+}
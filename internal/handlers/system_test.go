@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pull-request-reviewer-assignment-service/internal/models"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHomeReturnsJSONNotFoundForUnknownPath(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	Home(recorder, httptest.NewRequest(http.MethodGet, "/no/such/route", nil))
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+	var response models.ErrorResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	assert.Equal(t, "NOT_FOUND", response.Error.Code)
+	assert.Contains(t, response.Error.Message, "/no/such/route")
+}
+
+func TestHomeServesServiceDescription(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	Home(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	assert.Contains(t, response, "endpoints")
+	assert.Contains(t, response, "version")
+	assert.Contains(t, response, "commit")
+	assert.Contains(t, response, "uptime_seconds")
+}
+
+func TestHealthReportsBuildInfoAndGrowingUptime(t *testing.T) {
+	health := func() map[string]interface{} {
+		recorder := httptest.NewRecorder()
+		Health(recorder, httptest.NewRequest(http.MethodGet, "/health", nil))
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		return response
+	}
+
+	first := health()
+	assert.Equal(t, "healthy", first["status"])
+	assert.Equal(t, Version, first["version"])
+	assert.Equal(t, Commit, first["commit"])
+	assert.Equal(t, runtime.Version(), first["go_version"])
+	assert.NotEmpty(t, first["started_at"])
+
+	time.Sleep(10 * time.Millisecond)
+	second := health()
+	assert.Greater(t, second["uptime_seconds"], first["uptime_seconds"])
+	assert.Equal(t, first["started_at"], second["started_at"])
+}
+
+func TestDrainFlipsReadinessButKeepsServing(t *testing.T) {
+	readiness := NewReadiness()
+
+	recorder := httptest.NewRecorder()
+	readiness.Ready(recorder, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	readiness.Drain(recorder, httptest.NewRequest(http.MethodPost, "/admin/drain", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	readiness.Ready(recorder, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+	var response map[string]string
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	assert.Equal(t, "draining", response["status"])
+
+	// остальные обработчики продолжают работать
+	recorder = httptest.NewRecorder()
+	Health(recorder, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	// повторный drain идемпотентен
+	recorder = httptest.NewRecorder()
+	readiness.Drain(recorder, httptest.NewRequest(http.MethodPost, "/admin/drain", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestDrainRequiresPost(t *testing.T) {
+	readiness := NewReadiness()
+
+	recorder := httptest.NewRecorder()
+	readiness.Drain(recorder, httptest.NewRequest(http.MethodGet, "/admin/drain", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	readiness.Ready(recorder, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
@@ -11,14 +11,16 @@ import (
 // структура обрабатывает HTTP запросы связанные с управлением командами
 type TeamHandler struct {
 	teamService *service.TeamService
+	cfg         Config
 }
 
 // создает и возвращает новый экземпляр TeamHandler
 // принимает: сервис команд для внедрения зависимости
 // возвращает: указатель на созданный TeamHandler
-func NewTeamHandler(teamService *service.TeamService) *TeamHandler {
+func NewTeamHandler(teamService *service.TeamService, cfg Config) *TeamHandler {
 	return &TeamHandler{
 		teamService: teamService,
+		cfg:         cfg,
 	}
 }
 
@@ -35,24 +37,20 @@ func (h *TeamHandler) AddTeam(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var team models.Team
-	if err := json.NewDecoder(r.Body).Decode(&team); err != nil {
-		log.Printf("Invalid JSON: %v", err)
-		writeError(w, "INVALID_REQUEST", "Invalid JSON", http.StatusBadRequest)
+	if err := decodeJSONBody(r, &team, collectionLimit{"members", h.cfg.MaxTeamMembers}); err != nil {
+		writeDecodeError(w, err)
 		return
 	}
 
 	log.Printf("Parsed team: %s with %d members", team.TeamName, len(team.Members))
 
 	// валидация
-	if team.TeamName == "" {
-		log.Printf("Missing team_name")
-		writeError(w, "INVALID_REQUEST", "team_name is required", http.StatusBadRequest)
-		return
-	}
-
+	var errs fieldErrors
+	errs.required("team_name", team.TeamName)
 	if len(team.Members) == 0 {
-		log.Printf("No members provided")
-		writeError(w, "INVALID_REQUEST", "team must have at least one member", http.StatusBadRequest)
+		errs.add("members", "team must have at least one member")
+	}
+	if writeValidationErrors(w, errs) {
 		return
 	}
 
@@ -85,6 +83,232 @@ func (h *TeamHandler) AddTeam(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, response)
 }
 
+// частично обновляет состав команды: добавляет и обновляет перечисленных участников,
+// при deactivate_missing деактивирует отсутствующих в списке (они остаются в команде) с переназначением их открытых ревью
+// принимает: HTTP запрос с JSON содержащим team_name, members и опциональный deactivate_missing
+// возвращает: JSON с примененными изменениями и итоговым составом команды или ошибку валидации/выполнения
+func (h *TeamHandler) UpdateTeam(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received POST /team/update request")
+
+	if r.Method != http.MethodPost {
+		log.Printf("Method not allowed: %s", r.Method)
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request models.TeamUpdateRequest
+	if err := decodeJSONBody(r, &request, collectionLimit{"members", h.cfg.MaxTeamMembers}); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	var errs fieldErrors
+	errs.required("team_name", request.TeamName)
+	if len(request.Members) == 0 && !request.DeactivateMissing {
+		errs.add("members", "members are required")
+	}
+	if writeValidationErrors(w, errs) {
+		return
+	}
+
+	response, err := h.teamService.UpdateTeamMembers(request.TeamName, request.Members, request.DeactivateMissing)
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		if serviceErr, ok := err.(*service.ServiceError); ok {
+			switch serviceErr.Code {
+			case "NOT_FOUND":
+				writeError(w, "NOT_FOUND", serviceErr.Message, http.StatusNotFound)
+			case "INVALID_REQUEST":
+				writeError(w, "INVALID_REQUEST", serviceErr.Message, http.StatusBadRequest)
+			case "TEAM_TOO_SMALL":
+				writeError(w, "TEAM_TOO_SMALL", serviceErr.Message, http.StatusConflict)
+			case "ASSIGNMENT_PAUSED":
+				writeError(w, "ASSIGNMENT_PAUSED", serviceErr.Message, http.StatusServiceUnavailable)
+			default:
+				writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+			}
+			return
+		}
+		writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// проверяет данные команды так же, как /team/add, не создавая ее
+// принимает: HTTP POST запрос с JSON в формате /team/add
+// возвращает: JSON с признаком valid и списком ошибок по полям или ошибку
+func (h *TeamHandler) ValidateTeam(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received POST /team/validate request")
+
+	if r.Method != http.MethodPost {
+		log.Printf("Method not allowed: %s", r.Method)
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// размер команды проверяется вместе с остальными полями, а не при разборе тела
+	var team models.Team
+	if err := decodeJSONBody(r, &team); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	errs, err := h.teamService.ValidateTeam(&team, h.cfg.MaxTeamMembers)
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Team %s validated: %d errors", team.TeamName, len(errs))
+	writeJSON(w, http.StatusOK, models.TeamValidationResult{Valid: len(errs) == 0, Errors: errs})
+}
+
+// закрепляет за командой стратегию выбора ревьюверов
+// принимает: HTTP запрос с JSON содержащим team_name и strategy (пустая строка - глобальная стратегия)
+// возвращает: JSON с командой и стратегией или ошибку
+func (h *TeamHandler) SetStrategy(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received POST /team/setStrategy request")
+
+	if r.Method != http.MethodPost {
+		log.Printf("Method not allowed: %s", r.Method)
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request models.SetTeamStrategyRequest
+	if err := decodeJSONBody(r, &request); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	var errs fieldErrors
+	errs.required("team_name", request.TeamName)
+	if writeValidationErrors(w, errs) {
+		return
+	}
+
+	if err := h.teamService.SetAssignmentStrategy(request.TeamName, request.Strategy); err != nil {
+		log.Printf("Service error: %v", err)
+		if serviceErr, ok := err.(*service.ServiceError); ok {
+			switch serviceErr.Code {
+			case "NOT_FOUND":
+				writeError(w, "NOT_FOUND", serviceErr.Message, http.StatusNotFound)
+			case "INVALID_REQUEST":
+				writeError(w, "INVALID_REQUEST", serviceErr.Message, http.StatusBadRequest)
+			default:
+				writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+			}
+			return
+		}
+		writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"team_name":           request.TeamName,
+		"assignment_strategy": request.Strategy,
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// удаляет команду вместе с участниками, если никто из них не является автором или ревьювером открытого PR
+// принимает: HTTP запрос с JSON содержащим team_name и опциональный delete_prs
+// возвращает: JSON с названием команды и количеством удаленных участников или ошибку
+func (h *TeamHandler) DeleteTeam(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received POST /team/delete request")
+
+	if r.Method != http.MethodPost {
+		log.Printf("Method not allowed: %s", r.Method)
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request models.DeleteTeamRequest
+	if err := decodeJSONBody(r, &request); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	var errs fieldErrors
+	errs.required("team_name", request.TeamName)
+	if writeValidationErrors(w, errs) {
+		return
+	}
+
+	response, err := h.teamService.DeleteTeam(request.TeamName, request.DeletePRs)
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		if serviceErr, ok := err.(*service.ServiceError); ok {
+			switch serviceErr.Code {
+			case "NOT_FOUND":
+				writeError(w, "NOT_FOUND", serviceErr.Message, http.StatusNotFound)
+			case "TEAM_HAS_OPEN_PRS":
+				writeError(w, "TEAM_HAS_OPEN_PRS", serviceErr.Message, http.StatusConflict)
+			case "TEAM_HAS_PRS":
+				writeError(w, "TEAM_HAS_PRS", serviceErr.Message, http.StatusConflict)
+			default:
+				writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+			}
+			return
+		}
+		writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// устанавливает адрес уведомлений о назначениях для команды
+// принимает: HTTP запрос с JSON содержащим team_name и webhook_url (пустой - вернуться к глобальному адресу)
+// возвращает: JSON с командой и установленным адресом или ошибку
+func (h *TeamHandler) SetWebhook(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received POST /team/setWebhook request")
+
+	if r.Method != http.MethodPost {
+		log.Printf("Method not allowed: %s", r.Method)
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request models.SetTeamWebhookRequest
+	if err := decodeJSONBody(r, &request); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	var errs fieldErrors
+	errs.required("team_name", request.TeamName)
+	if writeValidationErrors(w, errs) {
+		return
+	}
+
+	if err := h.teamService.SetWebhook(request.TeamName, request.WebhookURL); err != nil {
+		log.Printf("Service error: %v", err)
+		if serviceErr, ok := err.(*service.ServiceError); ok {
+			switch serviceErr.Code {
+			case "NOT_FOUND":
+				writeError(w, "NOT_FOUND", serviceErr.Message, http.StatusNotFound)
+			case "INVALID_REQUEST":
+				writeError(w, "INVALID_REQUEST", serviceErr.Message, http.StatusBadRequest)
+			default:
+				writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+			}
+			return
+		}
+		writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"team_name":   request.TeamName,
+		"webhook_url": request.WebhookURL,
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
 // возвращает информацию о команде по её названию
 // принимает: HTTP GET запрос с параметром team_name в URL
 // возвращает: JSON с данными команды или ошибку если команда не найдена
@@ -1,24 +1,31 @@
 package handlers
 
 import (
-	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"pull-request-reviewer-assignment-service/internal/models"
 	"pull-request-reviewer-assignment-service/internal/service"
+	"strings"
+	"time"
 )
 
+// минимальный возраст PR по умолчанию для GET /users/blocking
+const defaultBlockingThreshold = 24 * time.Hour
+
 // обрабатывает HTTP запросы связанные с пользователями
 type UserHandler struct {
 	userService *service.UserService
+	cfg         Config
 }
 
 // создает и возвращает новый экземпляр UserHandler
 // принимает: сервис пользователей для внедрения зависимости
 // возвращает: указатель на созданный UserHandler
-func NewUserHandler(userService *service.UserService) *UserHandler {
+func NewUserHandler(userService *service.UserService, cfg Config) *UserHandler {
 	return &UserHandler{
 		userService: userService,
+		cfg:         cfg,
 	}
 }
 
@@ -39,9 +46,8 @@ func (h *UserHandler) SetUserActive(w http.ResponseWriter, r *http.Request) {
 		IsActive bool   `json:"is_active"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		log.Printf("Invalid JSON: %v", err)
-		writeError(w, "INVALID_REQUEST", "Invalid JSON", http.StatusBadRequest)
+	if err := decodeJSONBody(r, &request); err != nil {
+		writeDecodeError(w, err)
 		return
 	}
 
@@ -56,13 +62,15 @@ func (h *UserHandler) SetUserActive(w http.ResponseWriter, r *http.Request) {
 
 	// изменяем активность пользователя через сервис
 	log.Printf("Calling user service to update user: %s", request.UserID)
-	user, err := h.userService.SetUserActive(request.UserID, request.IsActive)
+	user, err := h.userService.SetUserActive(request.UserID, request.IsActive, actorFromRequest(r))
 	if err != nil {
 		log.Printf("Service error: %v", err)
 		if serviceErr, ok := err.(*service.ServiceError); ok {
 			switch serviceErr.Code {
 			case "NOT_FOUND":
 				writeError(w, "NOT_FOUND", serviceErr.Message, http.StatusNotFound)
+			case "TEAM_TOO_SMALL":
+				writeError(w, "TEAM_TOO_SMALL", serviceErr.Message, http.StatusConflict)
 			case "INVALID_REQUEST":
 				writeError(w, "INVALID_REQUEST", serviceErr.Message, http.StatusBadRequest)
 			default:
@@ -82,8 +90,8 @@ func (h *UserHandler) SetUserActive(w http.ResponseWriter, r *http.Request) {
 }
 
 // обрабатывает получение PR пользователя для ревью
-// принимает: HTTP GET запрос с параметром user_id в URL
-// возвращает: JSON со списком PR и идентификатором пользователя или ошибку
+// принимает: HTTP GET запрос с параметром user_id в URL и опциональными limit и offset
+// возвращает: JSON со страницей списка PR и идентификатором пользователя или ошибку
 func (h *UserHandler) GetUserReviewPRs(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Received GET /users/getReview request")
 
@@ -99,12 +107,21 @@ func (h *UserHandler) GetUserReviewPRs(w http.ResponseWriter, r *http.Request) {
 		writeError(w, "INVALID_REQUEST", "user_id parameter is required", http.StatusBadRequest)
 		return
 	}
+	page, errs := parsePagination(r.URL.Query())
+	if writeValidationErrors(w, errs) {
+		return
+	}
+	// без limit и offset список отдается целиком, как до появления постраничной выдачи
+	paged := r.URL.Query().Has("limit") || r.URL.Query().Has("offset")
+	if !paged {
+		page = models.Pagination{}
+	}
 
 	log.Printf("Getting PRs for user: %s", userID)
 
 	// получаем PR пользователя через сервис
 	log.Printf("Calling user service to get PRs for user: %s", userID)
-	prs, err := h.userService.GetUserReviewPRs(userID)
+	prs, page, err := h.userService.GetUserReviewPRs(userID, page)
 	if err != nil {
 		log.Printf("Service error: %v", err)
 		if serviceErr, ok := err.(*service.ServiceError); ok {
@@ -128,6 +145,314 @@ func (h *UserHandler) GetUserReviewPRs(w http.ResponseWriter, r *http.Request) {
 		"user_id":       userID,
 		"pull_requests": prs,
 	}
+	if paged {
+		response["pagination"] = page
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// обрабатывает получение открытых PR, которые давно ждут ревью пользователя
+// принимает: HTTP запрос с параметрами user_id, older_than (длительность, по умолчанию 24h), limit и offset
+// возвращает: JSON со списком PR от самого старого или ошибку валидации/поиска
+func (h *UserHandler) GetBlockingPRs(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received GET /users/blocking request")
+
+	if r.Method != http.MethodGet {
+		log.Printf("Method not allowed: %s", r.Method)
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		log.Printf("Missing user_id parameter")
+		writeError(w, "INVALID_REQUEST", "user_id parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	olderThan := defaultBlockingThreshold
+	if value := r.URL.Query().Get("older_than"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed < 0 {
+			log.Printf("Invalid older_than parameter: %s", value)
+			writeError(w, "INVALID_REQUEST", "older_than must be a non-negative duration like 24h or 90m", http.StatusBadRequest)
+			return
+		}
+		olderThan = parsed
+	}
+	page, errs := parsePagination(r.URL.Query())
+	if writeValidationErrors(w, errs) {
+		return
+	}
+
+	prs, page, err := h.userService.GetBlockingPRs(userID, olderThan, page)
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		if serviceErr, ok := err.(*service.ServiceError); ok && serviceErr.Code == "NOT_FOUND" {
+			writeError(w, "NOT_FOUND", serviceErr.Message, http.StatusNotFound)
+			return
+		}
+		writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"user_id":       userID,
+		"older_than":    olderThan.String(),
+		"pull_requests": prs,
+		"pagination":    page,
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// возвращает ревьюверов Pull Request автора, чтобы автор знал, кого просить о ревью
+// принимает: HTTP GET запрос с параметром author_id и опциональными status (OPEN, MERGED или CLOSED), limit и offset
+// возвращает: JSON со списком ревьюверов и количеством PR автора у каждого или ошибку
+func (h *UserHandler) GetMyReviewers(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received GET /users/myReviewers request")
+
+	if r.Method != http.MethodGet {
+		log.Printf("Method not allowed: %s", r.Method)
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	authorID := r.URL.Query().Get("author_id")
+	if authorID == "" {
+		log.Printf("Missing author_id parameter")
+		writeError(w, "INVALID_REQUEST", "author_id parameter is required", http.StatusBadRequest)
+		return
+	}
+	status := strings.ToUpper(strings.TrimSpace(r.URL.Query().Get("status")))
+	page, errs := parsePagination(r.URL.Query())
+	if writeValidationErrors(w, errs) {
+		return
+	}
+
+	reviewers, page, err := h.userService.GetAuthorReviewers(authorID, status, page)
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		if serviceErr, ok := err.(*service.ServiceError); ok {
+			switch serviceErr.Code {
+			case "NOT_FOUND":
+				writeError(w, "NOT_FOUND", serviceErr.Message, http.StatusNotFound)
+			case "INVALID_REQUEST":
+				writeError(w, "INVALID_REQUEST", serviceErr.Message, http.StatusBadRequest)
+			default:
+				writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+			}
+			return
+		}
+		writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"author_id":  authorID,
+		"status":     status,
+		"reviewers":  reviewers,
+		"pagination": page,
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// сравнивает нагрузку пользователя как ревьювера с нагрузкой его команды
+// принимает: HTTP GET запрос с параметром user_id
+// возвращает: JSON с количеством открытых ревью пользователя, средним и медианой команды и перцентилем или ошибку
+func (h *UserHandler) GetLoadComparison(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received GET /users/loadComparison request")
+
+	if r.Method != http.MethodGet {
+		log.Printf("Method not allowed: %s", r.Method)
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		log.Printf("Missing user_id parameter")
+		writeError(w, "INVALID_REQUEST", "user_id parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	comparison, err := h.userService.GetLoadComparison(userID)
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		if serviceErr, ok := err.(*service.ServiceError); ok && serviceErr.Code == "NOT_FOUND" {
+			writeError(w, "NOT_FOUND", serviceErr.Message, http.StatusNotFound)
+			return
+		}
+		writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, comparison)
+}
+
+// возвращает историю изменений активности пользователя
+// принимает: HTTP GET запрос с параметром user_id и опциональными from и to (RFC3339 или YYYY-MM-DD), limit и offset
+// возвращает: JSON со списком событий ACTIVATE/DEACTIVATE в хронологическом порядке или ошибку
+func (h *UserHandler) GetStatusHistory(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received GET /users/statusHistory request")
+
+	if r.Method != http.MethodGet {
+		log.Printf("Method not allowed: %s", r.Method)
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	userID := query.Get("user_id")
+	if userID == "" {
+		log.Printf("Missing user_id parameter")
+		writeError(w, "INVALID_REQUEST", "user_id parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	from, err := parseTimeParam(query.Get("from"))
+	if err != nil {
+		writeError(w, "INVALID_REQUEST", "from must be an RFC3339 timestamp or a YYYY-MM-DD date", http.StatusBadRequest)
+		return
+	}
+	to, err := parseTimeParam(query.Get("to"))
+	if err != nil {
+		writeError(w, "INVALID_REQUEST", "to must be an RFC3339 timestamp or a YYYY-MM-DD date", http.StatusBadRequest)
+		return
+	}
+	page, errs := parsePagination(query)
+	if writeValidationErrors(w, errs) {
+		return
+	}
+
+	events, page, err := h.userService.GetStatusHistory(userID, from, to, page)
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		if serviceErr, ok := err.(*service.ServiceError); ok {
+			switch serviceErr.Code {
+			case "NOT_FOUND":
+				writeError(w, "NOT_FOUND", serviceErr.Message, http.StatusNotFound)
+			case "INVALID_REQUEST":
+				writeError(w, "INVALID_REQUEST", serviceErr.Message, http.StatusBadRequest)
+			default:
+				writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+			}
+			return
+		}
+		writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"user_id":    userID,
+		"events":     events,
+		"pagination": page,
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// возвращает команды, в которых состоял пользователь, с временем вступления и выхода
+// принимает: HTTP GET запрос с параметром user_id
+// возвращает: JSON со списком периодов членства в хронологическом порядке или ошибку
+func (h *UserHandler) GetTeamHistory(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received GET /users/teamHistory request")
+
+	if r.Method != http.MethodGet {
+		log.Printf("Method not allowed: %s", r.Method)
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		log.Printf("Missing user_id parameter")
+		writeError(w, "INVALID_REQUEST", "user_id parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	history, err := h.userService.GetTeamHistory(userID)
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		if serviceErr, ok := err.(*service.ServiceError); ok && serviceErr.Code == "NOT_FOUND" {
+			writeError(w, "NOT_FOUND", serviceErr.Message, http.StatusNotFound)
+			return
+		}
+		writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"user_id": userID,
+		"teams":   history,
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// возвращает инициатора изменения для журналов из заголовка X-Actor
+// принимает: HTTP запрос
+// возвращает: значение заголовка без пробелов по краям или пустую строку
+func actorFromRequest(r *http.Request) string {
+	return strings.TrimSpace(r.Header.Get("X-Actor"))
+}
+
+// обрабатывает массовое изменение активности пользователей с явными состояниями
+// принимает: HTTP запрос с JSON содержащим users - список {user_id, is_active}
+// возвращает: JSON с результатом по каждому пользователю или ошибку валидации/выполнения
+func (h *UserHandler) BulkSetActive(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received POST /users/bulkSetActive request")
+
+	if r.Method != http.MethodPost {
+		log.Printf("Method not allowed: %s", r.Method)
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request models.BulkSetActiveRequest
+	if err := decodeJSONBody(r, &request, collectionLimit{"users", h.cfg.MaxBulkItems}); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	// валидация
+	var errs fieldErrors
+	if len(request.Users) == 0 {
+		errs.add("users", "users is required")
+	}
+	changes := make([]models.UserActiveChange, 0, len(request.Users))
+	seen := make(map[string]bool, len(request.Users))
+	for i, item := range request.Users {
+		field := fmt.Sprintf("users[%d]", i)
+		errs.required(field+".user_id", item.UserID)
+		if item.UserID != "" && seen[item.UserID] {
+			errs.add(field+".user_id", "duplicate user_id "+item.UserID)
+		}
+		seen[item.UserID] = true
+		if item.IsActive == nil {
+			errs.add(field+".is_active", field+".is_active is required")
+			continue
+		}
+		changes = append(changes, models.UserActiveChange{UserID: item.UserID, IsActive: *item.IsActive})
+	}
+	if writeValidationErrors(w, errs) {
+		return
+	}
+
+	response, err := h.userService.BulkSetActive(r.Context(), changes, actorFromRequest(r))
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		if serviceErr, ok := err.(*service.ServiceError); ok {
+			switch serviceErr.Code {
+			case "TEAM_TOO_SMALL":
+				writeError(w, "TEAM_TOO_SMALL", serviceErr.Message, http.StatusConflict)
+				return
+			case "ASSIGNMENT_PAUSED":
+				writeError(w, "ASSIGNMENT_PAUSED", serviceErr.Message, http.StatusServiceUnavailable)
+				return
+			}
+		}
+		writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
 	writeJSON(w, http.StatusOK, response)
 }
 
@@ -144,30 +469,284 @@ func (h *UserHandler) BulkDeactivate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var request models.BulkDeactivateRequest
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		log.Printf("Invalid JSON: %v", err)
-		writeError(w, "INVALID_REQUEST", "Invalid JSON", http.StatusBadRequest)
+	if err := decodeJSONBody(r, &request); err != nil {
+		writeDecodeError(w, err)
 		return
 	}
 
 	log.Printf("Parsed request: team=%s, users=%v", request.TeamName, request.UserIDs)
 
 	// валидация
+	var errs fieldErrors
+	errs.required("team_name", request.TeamName)
+	if len(request.UserIDs) == 0 {
+		errs.add("user_ids", "user_ids is required")
+	}
+	if writeValidationErrors(w, errs) {
+		return
+	}
+
+	// выполняем массовую деактивацию через сервис
+	log.Printf("Calling user service for bulk deactivation")
+	response, err := h.userService.BulkDeactivateUsers(r.Context(), request.TeamName, request.UserIDs, actorFromRequest(r))
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		if serviceErr, ok := err.(*service.ServiceError); ok {
+			switch serviceErr.Code {
+			case "NOT_FOUND":
+				writeError(w, "NOT_FOUND", serviceErr.Message, http.StatusNotFound)
+			case "TEAM_TOO_SMALL":
+				writeError(w, "TEAM_TOO_SMALL", serviceErr.Message, http.StatusConflict)
+			case "INVALID_REQUEST":
+				writeError(w, "INVALID_REQUEST", serviceErr.Message, http.StatusBadRequest)
+			case "ASSIGNMENT_PAUSED":
+				writeError(w, "ASSIGNMENT_PAUSED", serviceErr.Message, http.StatusServiceUnavailable)
+			default:
+				writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+			}
+			return
+		}
+		writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Bulk deactivation completed: %d users deactivated, %d PRs reassigned",
+		response.TotalProcessed, response.ReassignedCount)
+	writeJSON(w, http.StatusOK, response)
+}
+
+// создает пользователя в существующей команде
+// принимает: HTTP POST запрос с JSON содержащим user_id, username, team_name и опциональный is_active (по умолчанию true)
+// возвращает: JSON с созданным пользователем или ошибку
+func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received POST /users/create request")
+
+	if r.Method != http.MethodPost {
+		log.Printf("Method not allowed: %s", r.Method)
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request models.CreateUserRequest
+	if err := decodeJSONBody(r, &request); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	var errs fieldErrors
+	errs.required("user_id", request.UserID)
+	errs.required("username", request.Username)
+	errs.required("team_name", request.TeamName)
+	if writeValidationErrors(w, errs) {
+		return
+	}
+
+	isActive := true
+	if request.IsActive != nil {
+		isActive = *request.IsActive
+	}
+	user, err := h.userService.CreateUser(models.User{
+		UserID:   request.UserID,
+		Username: request.Username,
+		TeamName: request.TeamName,
+		IsActive: isActive,
+	})
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		if serviceErr, ok := err.(*service.ServiceError); ok {
+			switch serviceErr.Code {
+			case "NOT_FOUND":
+				writeError(w, "NOT_FOUND", serviceErr.Message, http.StatusNotFound)
+			case "USER_EXISTS":
+				writeError(w, "USER_EXISTS", serviceErr.Message, http.StatusConflict)
+			case "INVALID_REQUEST":
+				writeError(w, "INVALID_REQUEST", serviceErr.Message, http.StatusBadRequest)
+			default:
+				writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+			}
+			return
+		}
+		writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"user": user,
+	}
+	writeJSON(w, http.StatusCreated, response)
+}
+
+// переводит пользователя в другую команду, предварительно переназначая его ревью в открытых PR чужих команд
+// принимает: HTTP POST запрос с JSON содержащим user_id и team_name новой команды
+// возвращает: JSON с обновленным пользователем, прежней командой и переназначенными PR или ошибку
+func (h *UserHandler) TransferUser(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received POST /users/transfer request")
+
+	if r.Method != http.MethodPost {
+		log.Printf("Method not allowed: %s", r.Method)
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request models.TransferUserRequest
+	if err := decodeJSONBody(r, &request); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	var errs fieldErrors
+	errs.required("user_id", request.UserID)
+	errs.required("team_name", request.TeamName)
+	if writeValidationErrors(w, errs) {
+		return
+	}
+
+	response, err := h.userService.TransferUser(request.UserID, request.TeamName)
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		if serviceErr, ok := err.(*service.ServiceError); ok {
+			switch serviceErr.Code {
+			case "NOT_FOUND":
+				writeError(w, "NOT_FOUND", serviceErr.Message, http.StatusNotFound)
+			case "TEAM_TOO_SMALL":
+				writeError(w, "TEAM_TOO_SMALL", serviceErr.Message, http.StatusConflict)
+			case "ASSIGNMENT_PAUSED":
+				writeError(w, "ASSIGNMENT_PAUSED", serviceErr.Message, http.StatusServiceUnavailable)
+			default:
+				writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+			}
+			return
+		}
+		writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// обрабатывает синхронизацию состава команды с желаемым списком участников
+// принимает: HTTP запрос с JSON содержащим team_name и полный желаемый список members
+// возвращает: JSON с примененными изменениями и итоговым составом команды или ошибку валидации/выполнения
+func (h *UserHandler) SyncTeam(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received POST /team/sync request")
+
+	if r.Method != http.MethodPost {
+		log.Printf("Method not allowed: %s", r.Method)
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request models.TeamSyncRequest
+	if err := decodeJSONBody(r, &request, collectionLimit{"members", h.cfg.MaxTeamMembers}); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
 	if request.TeamName == "" {
 		log.Printf("Missing team_name")
 		writeError(w, "INVALID_REQUEST", "team_name is required", http.StatusBadRequest)
 		return
 	}
 
-	if len(request.UserIDs) == 0 {
-		log.Printf("No users provided")
-		writeError(w, "INVALID_REQUEST", "user_ids is required", http.StatusBadRequest)
+	response, err := h.userService.SyncTeam(request.TeamName, request.Members)
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		if serviceErr, ok := err.(*service.ServiceError); ok {
+			switch serviceErr.Code {
+			case "NOT_FOUND":
+				writeError(w, "NOT_FOUND", serviceErr.Message, http.StatusNotFound)
+			case "INVALID_REQUEST":
+				writeError(w, "INVALID_REQUEST", serviceErr.Message, http.StatusBadRequest)
+			case "TEAM_TOO_SMALL":
+				writeError(w, "TEAM_TOO_SMALL", serviceErr.Message, http.StatusConflict)
+			case "NOT_ASSIGNED":
+				writeError(w, "NOT_ASSIGNED", serviceErr.Message, http.StatusConflict)
+			case "ASSIGNMENT_PAUSED":
+				writeError(w, "ASSIGNMENT_PAUSED", serviceErr.Message, http.StatusServiceUnavailable)
+			default:
+				writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+			}
+			return
+		}
+		writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
-	// выполняем массовую деактивацию через сервис
-	log.Printf("Calling user service for bulk deactivation")
-	response, err := h.userService.BulkDeactivateUsers(request.TeamName, request.UserIDs)
+	writeJSON(w, http.StatusOK, response)
+}
+
+// выравнивает нагрузку ревьюверов команды, перераспределяя ревью в открытых PR
+// принимает: HTTP POST запрос с JSON телом содержащим team_name
+// возвращает: JSON с примененными заменами и нагрузкой до и после или ошибку
+func (h *UserHandler) RebalanceTeam(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received POST /team/rebalance request")
+
+	if r.Method != http.MethodPost {
+		log.Printf("Method not allowed: %s", r.Method)
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request models.TeamRebalanceRequest
+	if err := decodeJSONBody(r, &request); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	if request.TeamName == "" {
+		log.Printf("Missing team_name")
+		writeError(w, "INVALID_REQUEST", "team_name is required", http.StatusBadRequest)
+		return
+	}
+
+	response, err := h.userService.RebalanceTeam(r.Context(), request.TeamName)
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		if serviceErr, ok := err.(*service.ServiceError); ok {
+			switch serviceErr.Code {
+			case "NOT_FOUND":
+				writeError(w, "NOT_FOUND", serviceErr.Message, http.StatusNotFound)
+			case "NOT_ASSIGNED":
+				writeError(w, "NOT_ASSIGNED", serviceErr.Message, http.StatusConflict)
+			case "ASSIGNMENT_PAUSED":
+				writeError(w, "ASSIGNMENT_PAUSED", serviceErr.Message, http.StatusServiceUnavailable)
+			default:
+				writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+			}
+			return
+		}
+		writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// заменяет список компонентов, в которых пользователь является экспертом
+// принимает: HTTP POST запрос с JSON телом содержащим user_id и components
+// возвращает: JSON с сохраненной экспертизой или ошибку
+func (h *UserHandler) SetExpertise(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received POST /users/setExpertise request")
+
+	if r.Method != http.MethodPost {
+		log.Printf("Method not allowed: %s", r.Method)
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request models.SetExpertiseRequest
+	if err := decodeJSONBody(r, &request); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	var errs fieldErrors
+	errs.required("user_id", request.UserID)
+	if writeValidationErrors(w, errs) {
+		return
+	}
+
+	expertise, err := h.userService.SetComponentExpertise(request.UserID, request.Components)
 	if err != nil {
 		log.Printf("Service error: %v", err)
 		if serviceErr, ok := err.(*service.ServiceError); ok {
@@ -185,7 +764,190 @@ func (h *UserHandler) BulkDeactivate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	log.Printf("Bulk deactivation completed: %d users deactivated, %d PRs reassigned",
-		response.TotalProcessed, response.ReassignedCount)
+	response := map[string]interface{}{
+		"expertise": expertise,
+	}
 	writeJSON(w, http.StatusOK, response)
 }
+
+// включает пользователя в группу ревьюверов или исключает из групп
+// принимает: HTTP POST запрос с JSON телом содержащим user_id и group_id
+// возвращает: JSON с сохраненной группой пользователя или ошибку
+func (h *UserHandler) SetReviewerGroup(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received POST /users/setReviewerGroup request")
+
+	if r.Method != http.MethodPost {
+		log.Printf("Method not allowed: %s", r.Method)
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request models.SetReviewerGroupRequest
+	if err := decodeJSONBody(r, &request); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	var errs fieldErrors
+	errs.required("user_id", request.UserID)
+	if writeValidationErrors(w, errs) {
+		return
+	}
+
+	group, err := h.userService.SetReviewerGroup(request.UserID, request.GroupID)
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		if serviceErr, ok := err.(*service.ServiceError); ok && serviceErr.Code == "NOT_FOUND" {
+			writeError(w, "NOT_FOUND", serviceErr.Message, http.StatusNotFound)
+			return
+		}
+		writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"reviewer_group": group,
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// устанавливает или снимает недельную емкость ревью пользователя
+// принимает: HTTP POST запрос с JSON телом содержащим user_id и weekly_review_capacity (null - без ограничения)
+// возвращает: JSON с сохраненной емкостью пользователя или ошибку
+func (h *UserHandler) SetWeeklyCapacity(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received POST /users/setWeeklyCapacity request")
+
+	if r.Method != http.MethodPost {
+		log.Printf("Method not allowed: %s", r.Method)
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request models.SetWeeklyCapacityRequest
+	if err := decodeJSONBody(r, &request); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	var errs fieldErrors
+	errs.required("user_id", request.UserID)
+	if writeValidationErrors(w, errs) {
+		return
+	}
+
+	capacity, err := h.userService.SetWeeklyCapacity(request.UserID, request.WeeklyReviewCapacity)
+	if err != nil {
+		log.Printf("Service error: %v", err)
+		if serviceErr, ok := err.(*service.ServiceError); ok {
+			switch serviceErr.Code {
+			case "NOT_FOUND":
+				writeError(w, "NOT_FOUND", serviceErr.Message, http.StatusNotFound)
+			case "INVALID_REQUEST":
+				writeError(w, "INVALID_REQUEST", serviceErr.Message, http.StatusBadRequest)
+			default:
+				writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+			}
+			return
+		}
+		writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"weekly_capacity": capacity,
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// обрабатывает отказ пользователя от автоматического назначения ревьювером:
+// POST сохраняет окно отказа, GET возвращает текущее состояние
+// принимает: HTTP POST запрос с JSON содержащим user_id, until и reason или GET запрос с параметром user_id
+// возвращает: JSON с окном отказа пользователя или ошибку
+func (h *UserHandler) OptOut(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.setOptOut(w, r)
+	case http.MethodGet:
+		h.getOptOut(w, r)
+	default:
+		log.Printf("Method not allowed: %s", r.Method)
+		writeError(w, "METHOD_NOT_ALLOWED", "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// сохраняет окно отказа пользователя от автоматического назначения
+// принимает: HTTP запрос с JSON содержащим user_id, until (RFC3339) и необязательный reason
+// возвращает: JSON с сохраненным окном отказа или ошибку
+func (h *UserHandler) setOptOut(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received POST /users/optOut request")
+
+	var request models.OptOutRequest
+	if err := decodeJSONBody(r, &request); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	var errs fieldErrors
+	errs.required("user_id", request.UserID)
+	if request.Until == nil {
+		errs.add("until", "until is required")
+	}
+	if writeValidationErrors(w, errs) {
+		return
+	}
+
+	optOut, err := h.userService.SetOptOut(request.UserID, *request.Until, request.Reason)
+	if err != nil {
+		h.writeOptOutError(w, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"opt_out": optOut,
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// возвращает текущее окно отказа пользователя от автоматического назначения
+// принимает: HTTP запрос с параметром user_id в URL
+// возвращает: JSON с окном отказа или ошибку
+func (h *UserHandler) getOptOut(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received GET /users/optOut request")
+
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		log.Printf("Missing user_id parameter")
+		writeError(w, "INVALID_REQUEST", "user_id parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	optOut, err := h.userService.GetOptOut(userID)
+	if err != nil {
+		h.writeOptOutError(w, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"opt_out": optOut,
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// записывает ответ с ошибкой сервиса для /users/optOut
+// принимает: ResponseWriter и ошибку сервиса
+// возвращает: ничего
+func (h *UserHandler) writeOptOutError(w http.ResponseWriter, err error) {
+	log.Printf("Service error: %v", err)
+	if serviceErr, ok := err.(*service.ServiceError); ok {
+		switch serviceErr.Code {
+		case "NOT_FOUND":
+			writeError(w, "NOT_FOUND", serviceErr.Message, http.StatusNotFound)
+		case "INVALID_REQUEST":
+			writeError(w, "INVALID_REQUEST", serviceErr.Message, http.StatusBadRequest)
+		default:
+			writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+	writeError(w, "INTERNAL_ERROR", "Internal server error", http.StatusInternalServerError)
+}
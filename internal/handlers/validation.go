@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"pull-request-reviewer-assignment-service/internal/models"
+	"strings"
+)
+
+// код ошибки отдельного поля в details
+const validationFailedCode = models.ValidationFailedCode
+
+// накапливает ошибки валидации полей, чтобы вернуть клиенту все сразу
+type fieldErrors []models.FieldError
+
+// добавляет ошибку для поля
+// принимает: название поля и сообщение
+// возвращает: ничего
+func (e *fieldErrors) add(field, message string) {
+	*e = append(*e, models.FieldError{Field: field, Code: validationFailedCode, Message: message})
+}
+
+// добавляет ошибку, если обязательное строковое поле пустое
+// принимает: название поля и его значение
+// возвращает: ничего
+func (e *fieldErrors) required(field, value string) {
+	if value == "" {
+		e.add(field, field+" is required")
+	}
+}
+
+// записывает ответ 400 со всеми ошибками валидации, если они есть
+// принимает: response writer и накопленные ошибки
+// возвращает: true если ответ с ошибкой записан
+func writeValidationErrors(w http.ResponseWriter, errs fieldErrors) bool {
+	if len(errs) == 0 {
+		return false
+	}
+
+	messages := make([]string, 0, len(errs))
+	for _, fieldErr := range errs {
+		messages = append(messages, fieldErr.Message)
+	}
+	message := strings.Join(messages, "; ")
+	log.Printf("Error response: INVALID_REQUEST - %s (status: %d)", message, http.StatusBadRequest)
+
+	// код верхнего уровня остается INVALID_REQUEST для совместимости с существующими клиентами
+	writeJSON(w, http.StatusBadRequest, models.ErrorResponse{
+		Error: models.ErrorDetail{
+			Code:    "INVALID_REQUEST",
+			Message: message,
+			Details: errs,
+		},
+	})
+	return true
+}
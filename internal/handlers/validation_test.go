@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+	"pull-request-reviewer-assignment-service/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreatePRReportsAllMissingFields(t *testing.T) {
+	handler := NewPRHandler(nil, Config{})
+
+	code, response := postJSON(t, handler.CreatePR, "/pullRequest/create", map[string]interface{}{
+		"pull_request_name": "Feature",
+	})
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, "INVALID_REQUEST", response.Error.Code)
+	assert.Equal(t, "pull_request_id is required; author_id is required", response.Error.Message)
+
+	require.Len(t, response.Error.Details, 2)
+	assert.Equal(t, models.FieldError{
+		Field: "pull_request_id", Code: "VALIDATION_FAILED", Message: "pull_request_id is required",
+	}, response.Error.Details[0])
+	assert.Equal(t, "author_id", response.Error.Details[1].Field)
+}
+
+func TestSingleMissingFieldKeepsMessage(t *testing.T) {
+	handler := NewPRHandler(nil, Config{})
+
+	_, response := postJSON(t, handler.ReassignReviewer, "/pullRequest/reassign", map[string]interface{}{
+		"pull_request_id": "pr-1",
+	})
+	assert.Equal(t, "old_user_id is required", response.Error.Message)
+	require.Len(t, response.Error.Details, 1)
+	assert.Equal(t, "old_user_id", response.Error.Details[0].Field)
+}
@@ -0,0 +1,98 @@
+package models
+
+import "time"
+
+// типы записей журнала назначений
+const (
+	AssignmentEventAssign   = "ASSIGN"
+	AssignmentEventReassign = "REASSIGN"
+	AssignmentEventUnassign = "UNASSIGN"
+)
+
+// запись журнала назначений Pull Request
+type ReviewerAuditEvent struct {
+	EventType string
+	// назначенный ревьювер (для REASSIGN - новый, для UNASSIGN - снятый)
+	ReviewerID string
+	// замененный ревьювер для REASSIGN
+	PreviousReviewerID string
+	CreatedAt          time.Time
+}
+
+// запись журнала назначений в выгрузке для аналитики
+type ExportedAssignmentEvent struct {
+	EventID            int64     `json:"event_id"`
+	PullRequestID      string    `json:"pull_request_id"`
+	EventType          string    `json:"event_type"`
+	ReviewerID         string    `json:"reviewer_id"`
+	PreviousReviewerID string    `json:"previous_reviewer_id,omitempty"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+// период выгрузки журнала назначений [From, To); пустые границы не ограничивают период
+type EventExportFilter struct {
+	From *time.Time
+	To   *time.Time
+}
+
+// состав ревьюверов PR на момент времени, восстановленный по журналу назначений;
+// Added и Removed заполняются при сравнении с более ранним моментом since
+type ReviewersAtResponse struct {
+	PullRequestID string     `json:"pull_request_id"`
+	At            time.Time  `json:"at"`
+	Reviewers     []string   `json:"reviewers"`
+	Since         *time.Time `json:"since,omitempty"`
+	Added         []string   `json:"added,omitempty"`
+	Removed       []string   `json:"removed,omitempty"`
+}
+
+// результат восстановления журнала назначений
+type BackfillResponse struct {
+	CreatedEvents int64 `json:"created_events"`
+}
+
+// запрос на удаление старых замерженных PR
+type PurgeRequest struct {
+	OlderThan string `json:"older_than"`
+}
+
+// результат удаления старых замерженных PR
+type PurgeResponse struct {
+	PurgedCount int64 `json:"purged_count"`
+}
+
+// запрос на переназначение ревьюверов PR по текущей политике назначения
+type PolicyReassignRequest struct {
+	PullRequestID string `json:"pull_request_id"`
+}
+
+// результат переназначения ревьюверов PR по текущей политике с составом до и после
+type PolicyReassignResponse struct {
+	PullRequestID string   `json:"pull_request_id"`
+	Strategy      string   `json:"strategy"`
+	Before        []string `json:"before"`
+	After         []string `json:"after"`
+	Changed       bool     `json:"changed"`
+}
+
+// примененная миграция базы данных
+type AppliedMigration struct {
+	Version   int       `json:"version"`
+	Filename  string    `json:"filename"`
+	AppliedAt time.Time `json:"applied_at"`
+}
+
+// список примененных миграций в порядке версий
+type AppliedMigrationsResponse struct {
+	Migrations []AppliedMigration `json:"migrations"`
+}
+
+// запрос на приостановку или возобновление назначения ревьюверов
+type AssignmentPauseRequest struct {
+	Paused *bool `json:"paused"`
+}
+
+// состояние паузы назначения ревьюверов
+type AssignmentPauseState struct {
+	Paused bool `json:"paused"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "errors"
+
+// ErrPRNotOpen возвращается хранилищем, когда изменение ревьюверов отклонено, потому что PR уже не открыт
+var ErrPRNotOpen = errors.New("pull request is not open")
+
+// ErrReviewerLimitReached возвращается хранилищем, когда добавление ревьюверов превысило бы лимит ревьюверов PR
+var ErrReviewerLimitReached = errors.New("reviewer limit reached")
+
+// ErrReviewerNotAssigned возвращается хранилищем, когда заменяемый ревьювер уже снят с PR параллельным запросом
+var ErrReviewerNotAssigned = errors.New("reviewer is no longer assigned to the pull request")
+
+// ErrTeamNotFound возвращается хранилищем, когда команды нет (в том числе если ее удалили параллельным запросом)
+var ErrTeamNotFound = errors.New("team not found")
+
+// ErrTeamHasPRs возвращается хранилищем, когда участники команды являются авторами PR, а их удаление не запрошено
+var ErrTeamHasPRs = errors.New("team members authored pull requests")
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// типы событий назначения ревьюверов
+const (
+	EventPRCreated          = "PR_CREATED"
+	EventPRMerged           = "PR_MERGED"
+	EventPRClosed           = "PR_CLOSED"
+	EventPRReopened         = "PR_REOPENED"
+	EventReviewerReassigned = "REVIEWER_REASSIGNED"
+	// автоматическое назначение не набрало нужное количество ревьюверов
+	EventPRUnderstaffed = "PR_UNDERSTAFFED"
+)
+
+// событие назначения ревьюверов для аналитики
+type AssignmentEvent struct {
+	Type          string    `json:"type"`
+	PullRequestID string    `json:"pull_request_id"`
+	AuthorID      string    `json:"author_id"`
+	Reviewers     []string  `json:"reviewers"`
+	OldReviewerID string    `json:"old_reviewer_id,omitempty"`
+	NewReviewerID string    `json:"new_reviewer_id,omitempty"`
+	OccurredAt    time.Time `json:"occurred_at"`
+	// команда, нужное и фактическое количество ревьюверов; заполняются только для PR_UNDERSTAFFED
+	TeamName         string `json:"team_name,omitempty"`
+	DesiredReviewers *int   `json:"desired_reviewers,omitempty"`
+	ActualReviewers  *int   `json:"actual_reviewers,omitempty"`
+}
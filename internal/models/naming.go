@@ -0,0 +1,165 @@
+package models
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// соглашения об именовании полей в JSON ответах
+const (
+	// текущие имена полей: createdAt, mergedAt, closedAt
+	FieldNamingCamel = "camelCase"
+	// имена полей в snake_case: created_at, merged_at, closed_at
+	FieldNamingSnake = "snake_case"
+)
+
+// форматы времени createdAt, mergedAt и closedAt в JSON ответах
+const (
+	// RFC3339 без долей секунды
+	TimestampRFC3339 = "rfc3339"
+	// RFC3339 с наносекундами, формат time.Time по умолчанию
+	TimestampRFC3339Nano = "rfc3339nano"
+	// число миллисекунд Unix
+	TimestampUnixMillis = "unix_millis"
+)
+
+// проверяет что формат времени поддерживается
+// принимает: название формата
+// возвращает: true если формат известен
+func IsKnownTimestampFormat(format string) bool {
+	return format == TimestampRFC3339 || format == TimestampRFC3339Nano || format == TimestampUnixMillis
+}
+
+// устанавливает соглашение об именовании полей для сериализации PR
+// принимает: FieldNamingCamel или FieldNamingSnake
+// возвращает: ничего
+func (pr *PullRequest) SetFieldNaming(naming string) {
+	pr.naming = naming
+}
+
+// устанавливает формат времени createdAt, mergedAt и closedAt для сериализации PR
+// принимает: TimestampRFC3339, TimestampRFC3339Nano или TimestampUnixMillis (пустая строка - формат по умолчанию)
+// возвращает: ничего
+func (pr *PullRequest) SetTimestampFormat(format string) {
+	pr.timestampFormat = format
+}
+
+// время PR, сериализуемое в выбранном формате
+type formattedTime struct {
+	time   time.Time
+	format string
+}
+
+// сериализует время в выбранном формате
+// принимает: ничего
+// возвращает: JSON строку RFC3339/RFC3339Nano в UTC или число миллисекунд Unix
+func (t formattedTime) MarshalJSON() ([]byte, error) {
+	switch t.format {
+	case TimestampUnixMillis:
+		return []byte(strconv.FormatInt(t.time.UnixMilli(), 10)), nil
+	case TimestampRFC3339:
+		return json.Marshal(t.time.UTC().Format(time.RFC3339))
+	default:
+		return json.Marshal(t.time.UTC().Format(time.RFC3339Nano))
+	}
+}
+
+// сериализует PR в JSON с учетом выбранного соглашения об именовании полей и формата времени
+// принимает: ничего
+// возвращает: JSON представление PR или ошибку сериализации
+func (pr PullRequest) MarshalJSON() ([]byte, error) {
+	// псевдоним без методов, чтобы избежать рекурсии
+	type plain PullRequest
+
+	defaultFormat := pr.timestampFormat == "" || pr.timestampFormat == TimestampRFC3339Nano
+	if pr.naming != FieldNamingSnake && defaultFormat {
+		return json.Marshal(plain(pr))
+	}
+
+	createdAt := formattedTime{time: pr.CreatedAt, format: pr.timestampFormat}
+	var mergedAt *formattedTime
+	if pr.MergedAt != nil {
+		mergedAt = &formattedTime{time: *pr.MergedAt, format: pr.timestampFormat}
+	}
+	var closedAt *formattedTime
+	if pr.ClosedAt != nil {
+		closedAt = &formattedTime{time: *pr.ClosedAt, format: pr.timestampFormat}
+	}
+	reviewers := formatReviewers(pr.Reviewers, pr.timestampFormat)
+
+	if pr.naming != FieldNamingSnake {
+		// поля внешней структуры перекрывают одноименные поля встроенной
+		return json.Marshal(struct {
+			plain
+			CreatedAt formattedTime       `json:"createdAt"`
+			MergedAt  *formattedTime      `json:"mergedAt,omitempty"`
+			ClosedAt  *formattedTime      `json:"closedAt,omitempty"`
+			Reviewers []formattedReviewer `json:"reviewers,omitempty"`
+		}{
+			plain:     plain(pr),
+			CreatedAt: createdAt,
+			MergedAt:  mergedAt,
+			ClosedAt:  closedAt,
+			Reviewers: reviewers,
+		})
+	}
+
+	return json.Marshal(struct {
+		PullRequestID       string              `json:"pull_request_id"`
+		PullRequestName     string              `json:"pull_request_name"`
+		AuthorID            string              `json:"author_id"`
+		Status              string              `json:"status"`
+		AssignedReviewers   []string            `json:"assigned_reviewers"`
+		RequiredReviewers   *int                `json:"required_reviewers,omitempty"`
+		LinesChanged        *int                `json:"lines_changed,omitempty"`
+		ComponentTags       []string            `json:"component_tags,omitempty"`
+		CreatedAt           formattedTime       `json:"created_at"`
+		MergedAt            *formattedTime      `json:"merged_at,omitempty"`
+		ClosedAt            *formattedTime      `json:"closed_at,omitempty"`
+		BestEffortReviewers []string            `json:"best_effort_reviewers,omitempty"`
+		AssignmentPaused    bool                `json:"assignment_paused,omitempty"`
+		Warning             *ErrorDetail        `json:"warning,omitempty"`
+		Reviewers           []formattedReviewer `json:"reviewers,omitempty"`
+	}{
+		PullRequestID:       pr.PullRequestID,
+		PullRequestName:     pr.PullRequestName,
+		AuthorID:            pr.AuthorID,
+		Status:              pr.Status,
+		AssignedReviewers:   pr.AssignedReviewers,
+		RequiredReviewers:   pr.RequiredReviewers,
+		LinesChanged:        pr.LinesChanged,
+		ComponentTags:       pr.ComponentTags,
+		CreatedAt:           createdAt,
+		MergedAt:            mergedAt,
+		ClosedAt:            closedAt,
+		BestEffortReviewers: pr.BestEffortReviewers,
+		AssignmentPaused:    pr.AssignmentPaused,
+		Warning:             pr.Warning,
+		Reviewers:           reviewers,
+	})
+}
+
+// ревьювер PR со временем назначения в выбранном формате
+type formattedReviewer struct {
+	UserID     string         `json:"user_id"`
+	AssignedAt *formattedTime `json:"assigned_at,omitempty"`
+}
+
+// переводит время назначения ревьюверов в выбранный формат
+// принимает: ревьюверов PR и формат времени
+// возвращает: слайс ревьюверов для сериализации (nil для пустого списка)
+func formatReviewers(reviewers []PRReviewer, format string) []formattedReviewer {
+	if len(reviewers) == 0 {
+		return nil
+	}
+
+	formatted := make([]formattedReviewer, len(reviewers))
+	for i, reviewer := range reviewers {
+		formatted[i].UserID = reviewer.UserID
+		if reviewer.AssignedAt != nil {
+			formatted[i].AssignedAt = &formattedTime{time: *reviewer.AssignedAt, format: format}
+		}
+	}
+	return formatted
+}
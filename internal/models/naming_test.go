@@ -0,0 +1,117 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func marshalToMap(t *testing.T, value interface{}) map[string]interface{} {
+	t.Helper()
+	data, err := json.Marshal(value)
+	require.NoError(t, err)
+
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &result))
+	return result
+}
+
+func newMergedPR() *PullRequest {
+	mergedAt := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+	return &PullRequest{
+		PullRequestID:     "pr-1",
+		PullRequestName:   "Feature",
+		AuthorID:          "u1",
+		Status:            "MERGED",
+		AssignedReviewers: []string{"u2"},
+		CreatedAt:         time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		MergedAt:          &mergedAt,
+	}
+}
+
+func TestPullRequestDefaultNamingIsCamelCase(t *testing.T) {
+	result := marshalToMap(t, newMergedPR())
+
+	assert.Contains(t, result, "createdAt")
+	assert.Contains(t, result, "mergedAt")
+	assert.NotContains(t, result, "created_at")
+	assert.NotContains(t, result, "merged_at")
+	assert.Equal(t, "pr-1", result["pull_request_id"])
+}
+
+func TestPullRequestTimestampFormats(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 10, 0, 0, 123456789, time.UTC)
+	cases := []struct {
+		format   string
+		expected interface{}
+	}{
+		{"", "2024-05-01T10:00:00.123456789Z"},
+		{TimestampRFC3339Nano, "2024-05-01T10:00:00.123456789Z"},
+		{TimestampRFC3339, "2024-05-01T10:00:00Z"},
+		{TimestampUnixMillis, float64(createdAt.UnixMilli())},
+	}
+
+	for _, tc := range cases {
+		pr := newMergedPR()
+		pr.CreatedAt = createdAt
+		pr.SetTimestampFormat(tc.format)
+
+		result := marshalToMap(t, pr)
+		assert.Equal(t, tc.expected, result["createdAt"], tc.format)
+		assert.Equal(t, "pr-1", result["pull_request_id"], tc.format)
+		assert.NotContains(t, result, "created_at", tc.format)
+
+		pr.SetFieldNaming(FieldNamingSnake)
+		result = marshalToMap(t, pr)
+		assert.Equal(t, tc.expected, result["created_at"], tc.format)
+	}
+
+	pr := newMergedPR()
+	pr.SetTimestampFormat(TimestampUnixMillis)
+	assert.Equal(t, float64(1714644000000), marshalToMap(t, pr)["mergedAt"])
+
+	// время с ненулевым смещением выводится в UTC
+	pr = newMergedPR()
+	pr.CreatedAt = time.Date(2024, 5, 1, 13, 0, 0, 0, time.FixedZone("UTC+3", 3*60*60))
+	pr.SetTimestampFormat(TimestampRFC3339)
+	assert.Equal(t, "2024-05-01T10:00:00Z", marshalToMap(t, pr)["createdAt"])
+}
+
+func TestPullRequestSnakeCaseNaming(t *testing.T) {
+	pr := newMergedPR()
+	pr.SetFieldNaming(FieldNamingSnake)
+
+	// сериализация по значению и внутри вложенной структуры тоже учитывает режим
+	for _, result := range []map[string]interface{}{
+		marshalToMap(t, *pr),
+		marshalToMap(t, map[string]interface{}{"pr": pr})["pr"].(map[string]interface{}),
+	} {
+		assert.Equal(t, "2024-05-01T10:00:00Z", result["created_at"])
+		assert.Equal(t, "2024-05-02T10:00:00Z", result["merged_at"])
+		assert.NotContains(t, result, "createdAt")
+		assert.NotContains(t, result, "mergedAt")
+		assert.Equal(t, []interface{}{"u2"}, result["assigned_reviewers"])
+	}
+}
+
+func TestPullRequestReviewersFollowTimestampFormat(t *testing.T) {
+	assignedAt := time.Date(2024, 5, 1, 11, 0, 0, 0, time.UTC)
+	pr := newMergedPR()
+	pr.Reviewers = []PRReviewer{{UserID: "u2", AssignedAt: &assignedAt}, {UserID: "u3"}}
+
+	reviewers := marshalToMap(t, pr)["reviewers"].([]interface{})
+	require.Len(t, reviewers, 2)
+	assert.Equal(t, map[string]interface{}{"user_id": "u2", "assigned_at": "2024-05-01T11:00:00Z"}, reviewers[0])
+	assert.Equal(t, map[string]interface{}{"user_id": "u3"}, reviewers[1])
+
+	pr.SetFieldNaming(FieldNamingSnake)
+	pr.SetTimestampFormat(TimestampUnixMillis)
+	reviewers = marshalToMap(t, pr)["reviewers"].([]interface{})
+	assert.Equal(t, float64(assignedAt.UnixMilli()), reviewers[0].(map[string]interface{})["assigned_at"])
+
+	// без expand поле не выводится
+	assert.NotContains(t, marshalToMap(t, newMergedPR()), "reviewers")
+}
@@ -0,0 +1,13 @@
+package models
+
+// метаданные постраничной выдачи списка
+type Pagination struct {
+	// максимальное количество элементов на странице
+	Limit int `json:"limit"`
+	// количество пропущенных элементов от начала списка
+	Offset int `json:"offset"`
+	// общее количество элементов без учета страницы
+	Total int `json:"total"`
+	// true, если после текущей страницы есть еще элементы
+	HasMore bool `json:"has_more"`
+}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // ответ статистики
 type StatsResponse struct {
 	TotalAssignments  int64                 `json:"total_assignments"`
@@ -21,3 +23,250 @@ type PRAssignmentStats struct {
 	PRName          string `json:"pr_name"`
 	AssignmentCount int64  `json:"assignment_count"`
 }
+
+// активный пользователь, который ни разу не назначался ревьювером
+type UnassignedUser struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	TeamName string `json:"team_name"`
+}
+
+// ответ со списком ни разу не назначенных ревьюверов
+type UnassignedUsersResponse struct {
+	Users      []UnassignedUser `json:"users"`
+	Pagination Pagination       `json:"pagination"`
+}
+
+// открытый PR, у которого нет ни одного активного ревьювера
+type StuckPR struct {
+	PullRequestID     string   `json:"pull_request_id"`
+	PullRequestName   string   `json:"pull_request_name"`
+	AuthorID          string   `json:"author_id"`
+	AssignedReviewers []string `json:"assigned_reviewers"`
+}
+
+// ответ со списком открытых PR без активных ревьюверов
+type StuckPRsResponse struct {
+	PullRequests []StuckPR  `json:"pull_requests"`
+	Pagination   Pagination `json:"pagination"`
+}
+
+// количество назначений ревьювера по статусам PR
+type ReviewerStatusStats struct {
+	UserID   string           `json:"user_id"`
+	ByStatus map[string]int64 `json:"by_status"`
+	Total    int64            `json:"total"`
+}
+
+// ответ со статистикой назначений по статусам PR
+type StatusStatsResponse struct {
+	Reviewers  []ReviewerStatusStats `json:"reviewers"`
+	Pagination Pagination            `json:"pagination"`
+}
+
+// фильтр статистики авторов PR
+type AuthorStatsFilter struct {
+	// статус PR (пустая строка - все статусы)
+	Status string
+	// начало периода создания PR включительно (nil - без ограничения)
+	From *time.Time
+	// конец периода создания PR не включительно (nil - без ограничения)
+	To *time.Time
+	// максимальное количество авторов в ответе
+	Limit int
+}
+
+// количество созданных автором PR
+type AuthorPRCount struct {
+	AuthorID string `json:"author_id"`
+	Username string `json:"username"`
+	PRCount  int64  `json:"pr_count"`
+}
+
+// ответ со статистикой авторов PR
+type AuthorStatsResponse struct {
+	Authors []AuthorPRCount `json:"authors"`
+}
+
+// фильтр статистики возраста PR
+type AgingFilter struct {
+	// статус PR (по умолчанию OPEN)
+	Status string
+	// название команды автора (пустая строка - все команды)
+	TeamName string
+	// границы корзин по возрастанию: корзина i содержит PR с возрастом от Boundaries[i-1] до Boundaries[i]
+	Boundaries []time.Duration
+	// добавить разбивку по командам авторов
+	ByTeam bool
+}
+
+// количество PR команды в корзине возраста, строка результата запроса
+type AgeBucketCount struct {
+	TeamName string
+	// номер корзины: 0 - моложе первой границы, len(Boundaries) - старше последней
+	Bucket int
+	Count  int64
+}
+
+// корзина возраста PR с количеством PR в ней
+type AgeBucket struct {
+	Label string `json:"label"`
+	Count int64  `json:"count"`
+}
+
+// ответ со статистикой возраста PR по корзинам
+type AgingResponse struct {
+	Status  string                 `json:"status"`
+	Total   int64                  `json:"total"`
+	Buckets []AgeBucket            `json:"buckets"`
+	Teams   map[string][]AgeBucket `json:"teams,omitempty"`
+}
+
+// фильтр статистики перестановок ревьюверов
+type ChurnFilter struct {
+	// начало периода создания PR включительно (nil - без ограничения)
+	From *time.Time
+	// конец периода создания PR не включительно (nil - без ограничения)
+	To *time.Time
+	// название команды автора PR (пустая строка - все команды)
+	TeamName string
+	// максимальное количество PR в списке самых нестабильных
+	Limit int
+}
+
+// количество замен в созданных за период PR, строка результата запроса
+type ChurnTotals struct {
+	// количество событий REASSIGN в этих PR
+	Reassignments int64
+	// PR хотя бы с одним событием REASSIGN
+	ReassignedPRs int64
+	// PR, созданные за период
+	CreatedPRs int64
+}
+
+// количество замен ревьюверов в одном PR
+type PRChurn struct {
+	PullRequestID   string `json:"pull_request_id"`
+	PullRequestName string `json:"pull_request_name"`
+	AuthorID        string `json:"author_id"`
+	Reassignments   int64  `json:"reassignments"`
+}
+
+// ответ со статистикой перестановок ревьюверов; среднее - количество замен в созданных за период PR на один такой PR
+type ChurnResponse struct {
+	TotalReassignments    int64     `json:"total_reassignments"`
+	ReassignedPRs         int64     `json:"reassigned_prs"`
+	CreatedPRs            int64     `json:"created_prs"`
+	AvgReassignmentsPerPR float64   `json:"avg_reassignments_per_pr"`
+	TopPRs                []PRChurn `json:"top_prs"`
+}
+
+// фильтр статистики соблюдения SLA назначения ревьюверов
+type AssignmentSLAFilter struct {
+	// начало периода создания PR включительно (nil - без ограничения)
+	From *time.Time
+	// конец периода создания PR не включительно (nil - без ограничения)
+	To *time.Time
+	// название команды автора PR (пустая строка - все команды)
+	TeamName string
+	// количество ревьюверов, которое PR должен получить
+	Threshold int
+	// время от создания PR, за которое должно быть назначено Threshold ревьюверов
+	Within time.Duration
+}
+
+// количество PR по соблюдению SLA назначения, строка результата запроса
+type AssignmentSLACounts struct {
+	// PR, получившие нужное количество ревьюверов вовремя
+	Met int64
+	// PR, не получившие нужное количество ревьюверов вовремя
+	Missed int64
+	// PR, у которых срок еще не истек и ревьюверов пока не хватает
+	Pending int64
+}
+
+// ответ со статистикой соблюдения SLA назначения: compliance - доля вовремя укомплектованных PR среди met_prs и missed_prs
+type AssignmentSLAResponse struct {
+	Threshold  int     `json:"threshold"`
+	Within     string  `json:"within"`
+	TotalPRs   int64   `json:"total_prs"`
+	MetPRs     int64   `json:"met_prs"`
+	MissedPRs  int64   `json:"missed_prs"`
+	PendingPRs int64   `json:"pending_prs"`
+	Compliance float64 `json:"compliance"`
+}
+
+// фильтр статистики пар ревьюверов
+type PairFilter struct {
+	// начало периода создания PR включительно (nil - без ограничения)
+	From *time.Time
+	// конец периода создания PR не включительно (nil - без ограничения)
+	To *time.Time
+	// название команды автора PR (пустая строка - все команды)
+	TeamName string
+}
+
+// пара ревьюверов и количество PR, на которые они были назначены вместе; ReviewerA меньше ReviewerB
+type ReviewerPair struct {
+	ReviewerA string `json:"reviewer_a"`
+	ReviewerB string `json:"reviewer_b"`
+	CoReviews int64  `json:"co_reviews"`
+}
+
+// ответ со статистикой пар ревьюверов по убыванию количества совместных ревью
+type ReviewerPairsResponse struct {
+	Pairs      []ReviewerPair `json:"pairs"`
+	Pagination Pagination     `json:"pagination"`
+}
+
+// запрос на симуляцию распределения назначений
+type SimulationRequest struct {
+	TeamName string `json:"team_name"`
+	PRCount  int    `json:"pr_count"`
+	Strategy string `json:"strategy"`
+	Seed     *int64 `json:"seed,omitempty"`
+}
+
+// результат симуляции распределения назначений по ревьюверам команды
+type SimulationResponse struct {
+	TeamName       string         `json:"team_name"`
+	Strategy       string         `json:"strategy"`
+	PRCount        int            `json:"pr_count"`
+	ReviewersPerPR int            `json:"reviewers_per_pr"`
+	Histogram      map[string]int `json:"histogram"`
+}
+
+// нагрузка участника команды: все его назначения ревьювером и назначения на открытые PR
+type TeamMemberLoad struct {
+	UserID           string
+	IsActive         bool
+	TotalAssignments int
+	OpenAssignments  int
+}
+
+// показатели одной команды в сравнении команд; fairness_score - индекс Джейна по всем назначениям активных участников
+// (1 - назначения распределены поровну, 1/n - все у одного участника)
+type TeamComparisonStats struct {
+	TeamName         string  `json:"team_name"`
+	ActiveMembers    int     `json:"active_members"`
+	TotalAssignments int     `json:"total_assignments"`
+	OpenPRLoad       int     `json:"open_pr_load"`
+	AvgLoadPerMember float64 `json:"avg_load_per_member"`
+	FairnessScore    float64 `json:"fairness_score"`
+}
+
+// разница показателей команд: значение команды b минус значение команды a
+type TeamComparisonDelta struct {
+	ActiveMembers    int     `json:"active_members"`
+	TotalAssignments int     `json:"total_assignments"`
+	OpenPRLoad       int     `json:"open_pr_load"`
+	AvgLoadPerMember float64 `json:"avg_load_per_member"`
+	FairnessScore    float64 `json:"fairness_score"`
+}
+
+// сравнение двух команд с разницей показателей
+type TeamComparisonResponse struct {
+	A     TeamComparisonStats `json:"a"`
+	B     TeamComparisonStats `json:"b"`
+	Delta TeamComparisonDelta `json:"delta"`
+}
@@ -9,10 +9,27 @@ type ErrorResponse struct {
 
 // содержит детали ошибки с кодом и сообщением
 type ErrorDetail struct {
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Details []FieldError `json:"details,omitempty"`
+}
+
+// код ошибки отдельного поля запроса
+const ValidationFailedCode = "VALIDATION_FAILED"
+
+// ошибка валидации отдельного поля запроса
+type FieldError struct {
+	Field   string `json:"field"`
 	Code    string `json:"code"`
 	Message string `json:"message"`
 }
 
+// результат проверки данных команды без создания
+type TeamValidationResult struct {
+	Valid  bool         `json:"valid"`
+	Errors []FieldError `json:"errors"`
+}
+
 // описывает структуру команды с названием и списком участников
 type Team struct {
 	TeamName string       `json:"team_name"`
@@ -41,8 +58,102 @@ type PullRequest struct {
 	AuthorID          string     `json:"author_id"`
 	Status            string     `json:"status"`
 	AssignedReviewers []string   `json:"assigned_reviewers"`
+	RequiredReviewers *int       `json:"required_reviewers,omitempty"`
+	LinesChanged      *int       `json:"lines_changed,omitempty"`
+	ComponentTags     []string   `json:"component_tags,omitempty"`
 	CreatedAt         time.Time  `json:"createdAt,omitempty"`
 	MergedAt          *time.Time `json:"mergedAt,omitempty"`
+	ClosedAt          *time.Time `json:"closedAt,omitempty"`
+	// ревьюверы из assigned_reviewers, назначенные в режиме best-effort из недавно деактивированных
+	// участников; заполняется только в ответе на создание PR
+	BestEffortReviewers []string `json:"best_effort_reviewers,omitempty"`
+	// PR создан без ревьюверов, потому что назначение приостановлено; заполняется только в ответе на создание PR
+	AssignmentPaused bool `json:"assignment_paused,omitempty"`
+	// предупреждение NO_CANDIDATE, если назначено меньше минимума ревьюверов; заполняется только в ответе на создание
+	// и повторное открытие PR
+	Warning *ErrorDetail `json:"warning,omitempty"`
+	// ревьюверы с временем назначения по возрастанию; заполняется только при expand=reviewers
+	Reviewers []PRReviewer `json:"reviewers,omitempty"`
+
+	// соглашение об именовании полей при сериализации, см. SetFieldNaming
+	naming string
+	// формат времени при сериализации, см. SetTimestampFormat
+	timestampFormat string
+}
+
+// ревьювер Pull Request со временем назначения
+type PRReviewer struct {
+	UserID string `json:"user_id"`
+	// nil для назначений, сделанных до появления времени назначения
+	AssignedAt *time.Time `json:"assigned_at,omitempty"`
+}
+
+// запрос на создание Pull Request
+type CreatePRRequest struct {
+	PullRequestID     string   `json:"pull_request_id"`
+	PullRequestName   string   `json:"pull_request_name"`
+	AuthorID          string   `json:"author_id"`
+	RequiredReviewers *int     `json:"required_reviewers,omitempty"`
+	LinesChanged      *int     `json:"lines_changed,omitempty"`
+	ComponentTags     []string `json:"component_tags,omitempty"`
+}
+
+// запрос на замену списка компонентов, в которых пользователь является экспертом
+type SetExpertiseRequest struct {
+	UserID     string   `json:"user_id"`
+	Components []string `json:"components"`
+}
+
+// компоненты, в которых пользователь является экспертом
+type UserExpertise struct {
+	UserID     string   `json:"user_id"`
+	Components []string `json:"components"`
+}
+
+// запрос на включение пользователя в группу ревьюверов (пустая группа исключает из групп)
+type SetReviewerGroupRequest struct {
+	UserID  string `json:"user_id"`
+	GroupID string `json:"group_id"`
+}
+
+// группа ревьюверов пользователя, из которой на PR назначается не больше одного участника
+type UserReviewerGroup struct {
+	UserID  string `json:"user_id"`
+	GroupID string `json:"group_id"`
+}
+
+// запрос на установку недельной емкости ревью пользователя (null снимает ограничение)
+type SetWeeklyCapacityRequest struct {
+	UserID               string `json:"user_id"`
+	WeeklyReviewCapacity *int   `json:"weekly_review_capacity"`
+}
+
+// недельная емкость ревью пользователя: сколько PR ему можно назначить за скользящие 7 дней
+type UserWeeklyCapacity struct {
+	UserID string `json:"user_id"`
+	// nil - без ограничения
+	WeeklyReviewCapacity *int `json:"weekly_review_capacity"`
+}
+
+// запрос на отказ пользователя от автоматического назначения ревьювером до указанного времени
+type OptOutRequest struct {
+	UserID string     `json:"user_id"`
+	Until  *time.Time `json:"until"`
+	Reason string     `json:"reason"`
+}
+
+// окно отказа пользователя от автоматического назначения ревьювером
+type UserOptOut struct {
+	UserID string `json:"user_id"`
+	// true, если окно отказа еще не истекло
+	OptedOut bool       `json:"opted_out"`
+	Until    *time.Time `json:"until,omitempty"`
+	Reason   string     `json:"reason,omitempty"`
+}
+
+// запрос на получение нескольких Pull Request
+type BatchGetPRRequest struct {
+	PullRequestIDs []string `json:"pull_request_ids"`
 }
 
 // содержит сокращенную информацию о Pull Request
@@ -53,6 +164,294 @@ type PullRequestShort struct {
 	Status          string `json:"status"`
 }
 
+// фильтр и страница списка Pull Request; пустые Status и AuthorID не ограничивают выборку
+type PRFilter struct {
+	Status   string
+	AuthorID string
+	Limit    int
+	Offset   int
+}
+
+// открытый Pull Request, ожидающий ревью, с временем создания
+type BlockingPR struct {
+	PullRequestID   string    `json:"pull_request_id"`
+	PullRequestName string    `json:"pull_request_name"`
+	AuthorID        string    `json:"author_id"`
+	Status          string    `json:"status"`
+	CreatedAt       time.Time `json:"createdAt"`
+}
+
+// типы событий изменения активности пользователя
+const (
+	UserStatusActivate   = "ACTIVATE"
+	UserStatusDeactivate = "DEACTIVATE"
+)
+
+// событие изменения активности пользователя
+type UserStatusEvent struct {
+	EventType string    `json:"event_type"`
+	Actor     string    `json:"actor,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// типы событий журнала членства в командах
+const (
+	TeamMembershipJoin  = "JOIN"
+	TeamMembershipLeave = "LEAVE"
+)
+
+// событие вступления пользователя в команду или выхода из нее
+type TeamMembershipEvent struct {
+	TeamName  string    `json:"team_name"`
+	EventType string    `json:"event_type"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// период членства пользователя в команде; left_at отсутствует, пока пользователь состоит в команде
+type TeamMembership struct {
+	TeamName string     `json:"team_name"`
+	JoinedAt time.Time  `json:"joined_at"`
+	LeftAt   *time.Time `json:"left_at,omitempty"`
+}
+
+// роли пользователя в ревью PR; ревьюверы пока не делятся на основных и резервных,
+// поэтому назначенный ревьювер всегда основной
+const (
+	ReviewerRolePrimary = "primary"
+	ReviewerRoleNone    = "none"
+)
+
+// причины, по которым участник команды не рассматривался при назначении ревьюверов
+const (
+	TraceExcludedAuthor   = "author"
+	TraceExcludedInactive = "inactive"
+	TraceExcludedOptedOut = "opted_out"
+)
+
+// участник команды автора, не попавший в кандидаты, с причиной
+type TraceExclusion struct {
+	UserID string `json:"user_id"`
+	Reason string `json:"reason"`
+}
+
+// ход автоматического назначения ревьюверов при создании PR
+type AssignmentTrace struct {
+	TeamName         string `json:"team_name"`
+	DesiredReviewers int    `json:"desired_reviewers"`
+	// назначение пропущено из-за MANUAL_ASSIGNMENT
+	ManualAssignment bool             `json:"manual_assignment"`
+	Excluded         []TraceExclusion `json:"excluded"`
+	// кандидаты, из которых выбирались ревьюверы
+	Candidates []string `json:"candidates"`
+	// пусто, если кандидатов не было и стратегия не применялась
+	Strategy string   `json:"strategy,omitempty"`
+	Selected []string `json:"selected"`
+	// выбранные из недавно деактивированных участников при нехватке кандидатов
+	BestEffort []string `json:"best_effort,omitempty"`
+}
+
+// результат проверки, является ли пользователь ревьювером PR
+type ReviewerCheck struct {
+	PullRequestID string `json:"pull_request_id"`
+	UserID        string `json:"user_id"`
+	Assigned      bool   `json:"assigned"`
+	Role          string `json:"role"`
+}
+
+// кандидат в ревьюверы PR с итоговой оценкой
+type ReviewerSuggestion struct {
+	UserID  string            `json:"user_id"`
+	Score   float64           `json:"score"`
+	Factors SuggestionFactors `json:"factors"`
+}
+
+// факторы оценки кандидата в ревьюверы; каждая составляющая оценки от 0 до 1
+type SuggestionFactors struct {
+	OpenReviews    int        `json:"open_reviews"`
+	LastAssignedAt *time.Time `json:"last_assigned_at,omitempty"`
+	ExpertiseMatch bool       `json:"expertise_match"`
+	LoadScore      float64    `json:"load_score"`
+	RecencyScore   float64    `json:"recency_score"`
+	ExpertiseScore float64    `json:"expertise_score"`
+}
+
+// ревьювер PR автора с количеством PR автора, на которые он назначен
+type AuthorReviewer struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	PRCount  int    `json:"pr_count"`
+}
+
+// нагрузка ревьювера в сравнении с активными участниками его команды
+type UserLoadComparison struct {
+	UserID      string `json:"user_id"`
+	TeamName    string `json:"team_name"`
+	OpenReviews int    `json:"open_reviews"`
+	// количество участников, по которым считается распределение (активные участники и сам пользователь)
+	TeamSize    int     `json:"team_size"`
+	TeamAverage float64 `json:"team_average"`
+	TeamMedian  float64 `json:"team_median"`
+	// доля участников с меньшей нагрузкой плюс половина доли участников с такой же нагрузкой, от 0 до 100
+	PercentileRank float64 `json:"percentile_rank"`
+	// сколько еще PR можно назначить пользователю за скользящие 7 дней (nil - без ограничения)
+	WeeklyCapacityRemaining *int `json:"weekly_capacity_remaining"`
+}
+
+// запрос на синхронизацию состава команды с желаемым списком участников
+type TeamSyncRequest struct {
+	TeamName string       `json:"team_name"`
+	Members  []TeamMember `json:"members"`
+}
+
+// запрос на частичное обновление состава команды
+type TeamUpdateRequest struct {
+	TeamName string       `json:"team_name"`
+	Members  []TeamMember `json:"members"`
+	// деактивировать активных участников, отсутствующих в members
+	DeactivateMissing bool `json:"deactivate_missing"`
+}
+
+// изменения состава команды, применяемые в одной транзакции
+type TeamSyncPlan struct {
+	// новые участники команды
+	Added []TeamMember
+	// существующие участники с новым именем или флагом активности
+	Updated []TeamMember
+	// замены ревьюверов в открытых PR выбывших участников
+	Replacements []ReviewerReplacement
+}
+
+// замена ревьювера в Pull Request, пустой NewReviewerID означает снятие ревьювера без замены
+type ReviewerReplacement struct {
+	PRID          string `json:"pr_id"`
+	OldReviewerID string `json:"old_reviewer_id"`
+	NewReviewerID string `json:"new_reviewer_id"`
+}
+
+// действующие для команды настройки назначения ревьюверов
+type TeamAssignmentConfig struct {
+	TeamName             string `json:"team_name"`
+	AssignmentStrategy   string `json:"assignment_strategy"`
+	TeamStrategy         bool   `json:"team_strategy"`
+	DefaultReviewerCount int    `json:"default_reviewer_count"`
+	SizeBasedReviewers   bool   `json:"size_based_reviewers"`
+	MinActivePerTeam     int    `json:"min_active_per_team"`
+	ActiveMembers        int    `json:"active_members"`
+	// максимальное количество ревьюверов, которое команда может предоставить на PR своего участника
+	AvailableReviewers int `json:"available_reviewers"`
+	// PR участников команды создаются без автоматического назначения ревьюверов
+	ManualAssignment bool `json:"manual_assignment"`
+}
+
+// запрос на закрепление стратегии выбора ревьюверов за командой
+type SetTeamStrategyRequest struct {
+	TeamName string `json:"team_name"`
+	// пустая строка сбрасывает стратегию команды на глобальную
+	Strategy string `json:"strategy"`
+}
+
+// запрос на создание пользователя в существующей команде
+type CreateUserRequest struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	TeamName string `json:"team_name"`
+	// по умолчанию пользователь создается активным
+	IsActive *bool `json:"is_active"`
+}
+
+// запрос на перевод пользователя в другую команду
+type TransferUserRequest struct {
+	UserID   string `json:"user_id"`
+	TeamName string `json:"team_name"`
+}
+
+// результат перевода пользователя в другую команду
+type TransferUserResponse struct {
+	User         *User  `json:"user"`
+	PreviousTeam string `json:"previous_team"`
+	// открытые PR, в которых пользователь заменен другим ревьювером, потому что больше не состоит в команде автора
+	ReassignedPRs []string `json:"reassigned_prs"`
+}
+
+// запрос на удаление команды
+type DeleteTeamRequest struct {
+	TeamName string `json:"team_name"`
+	// удалить вместе с командой закрытые и замерженные PR авторства участников (без флага такие PR запрещают удаление)
+	DeletePRs bool `json:"delete_prs"`
+}
+
+// результат удаления команды
+type DeleteTeamResponse struct {
+	TeamName       string `json:"team_name"`
+	DeletedMembers int64  `json:"deleted_members"`
+	// закрытые и замерженные PR авторства участников
+	DeletedPRs int64 `json:"deleted_prs"`
+	// назначения ревьюверов в удаленных PR и ревью участников в PR других команд
+	DeletedReviews int64 `json:"deleted_reviews"`
+}
+
+// запрос на установку адреса уведомлений команды
+type SetTeamWebhookRequest struct {
+	TeamName string `json:"team_name"`
+	// пустая строка удаляет адрес команды, уведомления снова уходят на глобальный адрес
+	WebhookURL string `json:"webhook_url"`
+}
+
+// явное соответствие для замены ревьювера PR при массовом переназначении
+type BulkReassignMapping struct {
+	PullRequestID string `json:"pull_request_id"`
+	OldUserID     string `json:"old_user_id"`
+	NewUserID     string `json:"new_user_id"`
+}
+
+// запрос на массовое переназначение ревьюверов по явным соответствиям
+type BulkReassignRequest struct {
+	Mappings []BulkReassignMapping `json:"mappings"`
+}
+
+// результат замены по одному соответствию, Error заполняется только при неудаче
+type BulkReassignResult struct {
+	BulkReassignMapping
+	Status string       `json:"status"`
+	Error  *ErrorDetail `json:"error,omitempty"`
+}
+
+// ответ массового переназначения с результатами в порядке соответствий запроса
+type BulkReassignResponse struct {
+	Results    []BulkReassignResult `json:"results"`
+	Reassigned int                  `json:"reassigned"`
+	Failed     int                  `json:"failed"`
+	// операция остановлена отменой запроса, невыполненные соответствия имеют статус CANCELLED
+	Cancelled bool `json:"cancelled,omitempty"`
+}
+
+// запрос на выравнивание нагрузки ревьюверов команды
+type TeamRebalanceRequest struct {
+	TeamName string `json:"team_name"`
+}
+
+// ответ выравнивания нагрузки с примененными заменами и нагрузкой до и после
+type TeamRebalanceResponse struct {
+	TeamName   string                `json:"team_name"`
+	Changes    []ReviewerReplacement `json:"changes"`
+	LoadBefore map[string]int        `json:"load_before"`
+	LoadAfter  map[string]int        `json:"load_after"`
+	// запрос отменен до применения замен, ничего не изменено
+	Cancelled bool `json:"cancelled,omitempty"`
+}
+
+// ответ синхронизации состава команды с примененными изменениями
+type TeamSyncResponse struct {
+	TeamName      string         `json:"team_name"`
+	Added         []string       `json:"added"`
+	Removed       []string       `json:"removed"`
+	Activated     []string       `json:"activated"`
+	Deactivated   []string       `json:"deactivated"`
+	Renamed       []string       `json:"renamed"`
+	ReassignedPRs []ReassignedPR `json:"reassigned_prs"`
+	Team          *Team          `json:"team"`
+}
+
 // запрос на массовую деактивацию
 type BulkDeactivateRequest struct {
 	TeamName string   `json:"team_name"`
@@ -65,6 +464,45 @@ type BulkDeactivateResponse struct {
 	ReassignedPRs    []ReassignedPR `json:"reassigned_prs"`
 	TotalProcessed   int            `json:"total_processed"`
 	ReassignedCount  int            `json:"reassigned_count"`
+	// операция остановлена отменой запроса, остальные пользователи не обработаны
+	Cancelled bool `json:"cancelled,omitempty"`
+}
+
+// желаемая активность пользователя в массовом изменении активности
+type UserActiveChange struct {
+	UserID   string `json:"user_id"`
+	IsActive bool   `json:"is_active"`
+}
+
+// запрос на массовое изменение активности пользователей с явными состояниями
+type BulkSetActiveRequest struct {
+	Users []BulkSetActiveItem `json:"users"`
+}
+
+// элемент запроса массового изменения активности; is_active обязателен
+type BulkSetActiveItem struct {
+	UserID   string `json:"user_id"`
+	IsActive *bool  `json:"is_active"`
+}
+
+// результат изменения активности одного пользователя, Error заполняется только при неудаче
+type BulkSetActiveResult struct {
+	UserID   string `json:"user_id"`
+	IsActive bool   `json:"is_active"`
+	Status   string `json:"status"`
+	// переназначенные ревью деактивированного пользователя
+	ReassignedPRs []ReassignedPR `json:"reassigned_prs,omitempty"`
+	Error         *ErrorDetail   `json:"error,omitempty"`
+}
+
+// ответ массового изменения активности с результатами в порядке запроса
+type BulkSetActiveResponse struct {
+	Results         []BulkSetActiveResult `json:"results"`
+	Activated       int                   `json:"activated"`
+	Deactivated     int                   `json:"deactivated"`
+	ReassignedCount int                   `json:"reassigned_count"`
+	// операция отменена до применения изменений, ни один пользователь не изменен
+	Cancelled bool `json:"cancelled,omitempty"`
 }
 
 // информация о переназначенных PR
@@ -73,3 +511,18 @@ type ReassignedPR struct {
 	OldReviewers []string `json:"old_reviewers"`
 	NewReviewers []string `json:"new_reviewers"`
 }
+
+// убирает повторяющиеся идентификаторы, сохраняя порядок первых вхождений
+// принимает: слайс идентификаторов, например кандидатов из нескольких источников
+// возвращает: слайс без повторов
+func DedupeIDs(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	deduped := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			deduped = append(deduped, id)
+		}
+	}
+	return deduped
+}
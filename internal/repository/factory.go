@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"pull-request-reviewer-assignment-service/internal/repository/postgres"
+)
+
+// поддерживаемые хранилища данных
+const (
+	BackendPostgres = "postgres"
+)
+
+// настройки выбора хранилища данных
+type Config struct {
+	Backend string
+}
+
+// набор всех репозиториев приложения для выбранного хранилища
+type Repositories struct {
+	Team       TeamRepository
+	User       UserRepository
+	PR         PRRepository
+	Review     ReviewRepository
+	Stats      StatsRepository
+	Events     AssignmentEventRepository
+	Migrations MigrationRepository
+}
+
+// создает набор репозиториев для хранилища, указанного в конфигурации
+// принимает: настройки хранилища, подключение к основной базе данных и к реплике для чтения (nil - чтение с основной)
+// возвращает: указатель на Repositories или ошибку если хранилище не поддерживается
+func NewRepositories(cfg Config, db, readDB *sql.DB) (*Repositories, error) {
+	if readDB == nil {
+		readDB = db
+	}
+
+	switch cfg.Backend {
+	case BackendPostgres:
+		// статистика и списочные запросы читаются с реплики, чтение внутри операций записи - с основной базы
+		return &Repositories{
+			Team:       postgres.NewTeamRepository(db),
+			User:       postgres.NewUserRepository(db),
+			PR:         postgres.NewPRRepositoryWithReplica(db, readDB),
+			Review:     postgres.NewReviewRepository(db),
+			Stats:      postgres.NewStatsRepository(readDB),
+			Events:     postgres.NewAssignmentEventRepository(db),
+			Migrations: postgres.NewMigrationRepository(db),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported storage backend: %q", cfg.Backend)
+	}
+}
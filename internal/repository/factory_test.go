@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"database/sql"
+	"pull-request-reviewer-assignment-service/internal/models"
+	"pull-request-reviewer-assignment-service/internal/repository/postgres"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRepositoriesPostgres(t *testing.T) {
+	// sql.Open не устанавливает соединение, поэтому база данных не нужна
+	db, err := sql.Open("postgres", "host=localhost dbname=unused sslmode=disable")
+	require.NoError(t, err)
+	defer db.Close()
+
+	repos, err := NewRepositories(Config{Backend: BackendPostgres}, db, nil)
+	require.NoError(t, err)
+
+	assert.IsType(t, &postgres.TeamRepository{}, repos.Team)
+	assert.IsType(t, &postgres.UserRepository{}, repos.User)
+	assert.IsType(t, &postgres.PRRepository{}, repos.PR)
+	assert.IsType(t, &postgres.ReviewRepository{}, repos.Review)
+	assert.IsType(t, &postgres.StatsRepository{}, repos.Stats)
+	assert.IsType(t, &postgres.AssignmentEventRepository{}, repos.Events)
+}
+
+func TestNewRepositoriesUnknownBackend(t *testing.T) {
+	for _, backend := range []string{"", "mongodb"} {
+		repos, err := NewRepositories(Config{Backend: backend}, nil, nil)
+		assert.Error(t, err, backend)
+		assert.Nil(t, repos)
+	}
+}
+
+func TestNewRepositoriesRoutesReadsToReplica(t *testing.T) {
+	primary, err := sql.Open("recording", "primary")
+	require.NoError(t, err)
+	defer primary.Close()
+	replica, err := sql.Open("recording", "replica")
+	require.NoError(t, err)
+	defer replica.Close()
+
+	repos, err := NewRepositories(Config{Backend: BackendPostgres}, primary, replica)
+	require.NoError(t, err)
+
+	// статистика и списки читаются с реплики
+	_, err = repos.Stats.GetUserAssignmentStats(nil)
+	require.NoError(t, err)
+	_, err = repos.Stats.GetPRAssignmentStats()
+	require.NoError(t, err)
+	_, _, err = repos.PR.GetOpenPRsByReviewerCreatedBefore("u1", time.Now(), models.Pagination{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, testDriver.count("replica"))
+	assert.Equal(t, 0, testDriver.count("primary"))
+
+	// запись идет в основную базу
+	err = repos.PR.CreatePR(&models.PullRequest{PullRequestID: "pr-1", PullRequestName: "PR", AuthorID: "u1", Status: "OPEN"})
+	require.NoError(t, err)
+	err = repos.Review.AssignReviewers("pr-1", []string{"u2"})
+	require.NoError(t, err)
+	assert.Equal(t, 3, testDriver.count("replica"))
+	assert.Greater(t, testDriver.count("primary"), 0)
+}
+
+func TestNewRepositoriesWithoutReplicaReadsFromPrimary(t *testing.T) {
+	primary, err := sql.Open("recording", "primary-only")
+	require.NoError(t, err)
+	defer primary.Close()
+
+	repos, err := NewRepositories(Config{Backend: BackendPostgres}, primary, nil)
+	require.NoError(t, err)
+
+	_, err = repos.Stats.GetPRAssignmentStats()
+	require.NoError(t, err)
+	assert.Equal(t, 1, testDriver.count("primary-only"))
+}
+
+func TestGetPRsByIDsIssuesSingleQuery(t *testing.T) {
+	db, err := sql.Open("recording", "batch")
+	require.NoError(t, err)
+	defer db.Close()
+
+	repos, err := NewRepositories(Config{Backend: BackendPostgres}, db, nil)
+	require.NoError(t, err)
+
+	_, err = repos.PR.GetPRsByIDs([]string{"pr-1", "pr-2", "pr-3", "pr-4"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, testDriver.count("batch"))
+}
+
+func TestAssignReviewersInsertsEachReviewerOnce(t *testing.T) {
+	db, err := sql.Open("recording", "dedupe")
+	require.NoError(t, err)
+	defer db.Close()
+
+	repos, err := NewRepositories(Config{Backend: BackendPostgres}, db, nil)
+	require.NoError(t, err)
+
+	err = repos.Review.AssignReviewers("pr-1", []string{"u2", "u3", "u2", "u4", "u3"})
+	require.NoError(t, err)
+	assert.Equal(t, 3, testDriver.count("dedupe"))
+}
+
+func TestAssignActiveReviewersInsertsEachReviewerOnce(t *testing.T) {
+	db, err := sql.Open("recording", "dedupe-active")
+	require.NoError(t, err)
+	defer db.Close()
+
+	repos, err := NewRepositories(Config{Backend: BackendPostgres}, db, nil)
+	require.NoError(t, err)
+
+	inactive, err := repos.Review.AssignActiveReviewers("pr-1", []string{"u2", "u3", "u2"})
+	require.NoError(t, err)
+	assert.Empty(t, inactive)
+	assert.Equal(t, 2, testDriver.count("dedupe-active"))
+}
@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"pull-request-reviewer-assignment-service/internal/models"
+)
+
+// количество событий, читаемых из курсора выгрузки за один FETCH
+const exportFetchSize = 500
+
+// предоставляет методы для работы с журналом событий назначения в базе данных
+type AssignmentEventRepository struct {
+	db *sql.DB
+}
+
+// создает и возвращает новый экземпляр AssignmentEventRepository
+// принимает: подключение к базе данных для инициализации репозитория
+// возвращает: указатель на созданный AssignmentEventRepository
+func NewAssignmentEventRepository(db *sql.DB) *AssignmentEventRepository {
+	return &AssignmentEventRepository{db: db}
+}
+
+// создает события ASSIGN для текущих назначений, у которых еще нет события в журнале
+// принимает: ничего
+// возвращает: количество созданных событий или ошибку выполнения запроса
+func (r *AssignmentEventRepository) BackfillAssignEvents() (int64, error) {
+	result, err := r.db.Exec(`
+		INSERT INTO assignment_events (pull_request_id, event_type, reviewer_id, created_at)
+		SELECT rev.pull_request_id, 'ASSIGN', rev.reviewer_id, COALESCE(rev.assigned_at, pr.created_at, NOW())
+		FROM pr_reviewers rev
+		JOIN pull_requests pr ON pr.pull_request_id = rev.pull_request_id
+		WHERE NOT EXISTS (
+			SELECT 1 FROM assignment_events e
+			WHERE e.pull_request_id = rev.pull_request_id
+			AND e.reviewer_id = rev.reviewer_id
+			AND e.event_type IN ('ASSIGN', 'REASSIGN')
+		)
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to backfill assignment events: %w", err)
+	}
+
+	created, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return created, nil
+}
+
+// передает события журнала назначений за период обработчику по одному в порядке записи. События читаются
+// через серверный курсор порциями по exportFetchSize, поэтому журнал не загружается в память целиком
+// принимает: контекст запроса, период выгрузки и обработчик события
+// возвращает: ошибку выполнения запроса или ошибку обработчика
+func (r *AssignmentEventRepository) EachAssignmentEvent(ctx context.Context, filter models.EventExportFilter,
+	fn func(models.ExportedAssignmentEvent) error) error {
+	// курсор существует только внутри транзакции
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		DECLARE export_events NO SCROLL CURSOR FOR
+		SELECT event_id, pull_request_id, event_type, reviewer_id, COALESCE(previous_reviewer_id, ''), created_at
+		FROM assignment_events
+		WHERE ($1::timestamptz IS NULL OR created_at >= $1)
+		  AND ($2::timestamptz IS NULL OR created_at < $2)
+		ORDER BY created_at, event_id
+	`, filter.From, filter.To)
+	if err != nil {
+		return fmt.Errorf("failed to declare export cursor: %w", err)
+	}
+
+	for {
+		rows, err := tx.QueryContext(ctx, fmt.Sprintf("FETCH FORWARD %d FROM export_events", exportFetchSize))
+		if err != nil {
+			return fmt.Errorf("failed to fetch assignment events: %w", err)
+		}
+
+		fetched := 0
+		for rows.Next() {
+			var event models.ExportedAssignmentEvent
+			if err := rows.Scan(&event.EventID, &event.PullRequestID, &event.EventType, &event.ReviewerID,
+				&event.PreviousReviewerID, &event.CreatedAt); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan assignment event: %w", err)
+			}
+			fetched++
+			if err := fn(event); err != nil {
+				rows.Close()
+				return err
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("error iterating assignment events: %w", err)
+		}
+
+		if fetched < exportFetchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"pull-request-reviewer-assignment-service/internal/models"
+)
+
+// предоставляет чтение учета примененных миграций из базы данных
+type MigrationRepository struct {
+	db *sql.DB
+}
+
+// создает и возвращает новый экземпляр MigrationRepository
+// принимает: подключение к базе данных для инициализации репозитория
+// возвращает: указатель на созданный MigrationRepository
+func NewMigrationRepository(db *sql.DB) *MigrationRepository {
+	return &MigrationRepository{db: db}
+}
+
+// возвращает примененные миграции из таблицы учета migration_history в порядке версий
+// принимает: ничего
+// возвращает: слайс примененных миграций (пустой, если таблицы учета нет) или ошибку выполнения запроса
+func (r *MigrationRepository) GetAppliedMigrations() ([]models.AppliedMigration, error) {
+	var table sql.NullString
+	if err := r.db.QueryRow("SELECT to_regclass('migration_history')::text").Scan(&table); err != nil {
+		return nil, fmt.Errorf("failed to check migration_history table: %w", err)
+	}
+	migrations := []models.AppliedMigration{}
+	if !table.Valid {
+		// миграции при старте не применялись, например в режиме READ_ONLY
+		return migrations, nil
+	}
+
+	rows, err := r.db.Query(`SELECT version, filename, applied_at FROM migration_history ORDER BY version`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var migration models.AppliedMigration
+		if err := rows.Scan(&migration.Version, &migration.Filename, &migration.AppliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan applied migration: %w", err)
+		}
+		migrations = append(migrations, migration)
+	}
+	return migrations, rows.Err()
+}
@@ -0,0 +1,49 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"pull-request-reviewer-assignment-service/internal/models"
+)
+
+// выполняет запрос списка с LIMIT и OFFSET страницы и считает общее количество строк без учета страницы
+// принимает: подключение, страницу (limit меньше 1 - без ограничения), запрос без LIMIT/OFFSET, его аргументы и
+// функцию чтения строки
+// возвращает: элементы страницы (пустой слайс за концом списка), общее количество строк или ошибку запроса
+func queryPage[T any](db *sql.DB, page models.Pagination, query string, args []interface{},
+	scan func(*sql.Rows) (T, error)) ([]T, int, error) {
+	var limit interface{}
+	if page.Limit > 0 {
+		limit = page.Limit
+	}
+	pageArgs := append(append([]interface{}{}, args...), limit, page.Offset)
+
+	rows, err := db.Query(fmt.Sprintf("%s LIMIT $%d OFFSET $%d", query, len(args)+1, len(args)+2), pageArgs...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	items := []T{}
+	for rows.Next() {
+		item, err := scan(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	// без ограничения страница уже содержит все строки после offset
+	if page.Limit < 1 {
+		return items, page.Offset + len(items), nil
+	}
+
+	var total int
+	if err := db.QueryRow("SELECT COUNT(*) FROM ("+query+") AS listed", args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
@@ -4,18 +4,29 @@ import (
 	"database/sql"
 	"fmt"
 	"pull-request-reviewer-assignment-service/internal/models"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 // предоставляет методы для работы с данными Pull Request в базе данных
 type PRRepository struct {
-	db *sql.DB
+	db     *sql.DB
+	readDB *sql.DB
 }
 
 // создает и возвращает новый экземпляр PRRepository
 // принимает: подключение к базе данных для инициализации репозитория
 // возвращает: указатель на созданный PRRepository
 func NewPRRepository(db *sql.DB) *PRRepository {
-	return &PRRepository{db: db}
+	return NewPRRepositoryWithReplica(db, db)
+}
+
+// создает PRRepository, выполняющий списочные запросы чтения на реплике
+// принимает: подключение к основной базе данных и подключение к реплике для чтения
+// возвращает: указатель на созданный PRRepository
+func NewPRRepositoryWithReplica(db, readDB *sql.DB) *PRRepository {
+	return &PRRepository{db: db, readDB: readDB}
 }
 
 // сохраняет новый Pull Request в базе данных
@@ -23,9 +34,10 @@ func NewPRRepository(db *sql.DB) *PRRepository {
 // возвращает: ошибку в случае неудачного выполнения запроса к базе данных
 func (r *PRRepository) CreatePR(pr *models.PullRequest) error {
 	_, err := r.db.Exec(`
-		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status, created_at) 
-		VALUES ($1, $2, $3, $4, $5)
-	`, pr.PullRequestID, pr.PullRequestName, pr.AuthorID, pr.Status, pr.CreatedAt)
+		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status, required_reviewers, lines_changed, component_tags, created_at) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, pr.PullRequestID, pr.PullRequestName, pr.AuthorID, pr.Status, pr.RequiredReviewers, pr.LinesChanged,
+		pq.Array(componentTags(pr.ComponentTags)), pr.CreatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create pull request: %w", err)
 	}
@@ -37,15 +49,17 @@ func (r *PRRepository) CreatePR(pr *models.PullRequest) error {
 // возвращает: указатель на объект PullRequest с данными или ошибку если PR не найден
 func (r *PRRepository) GetPR(prID string) (*models.PullRequest, error) {
 	var pr models.PullRequest
-	var mergedAt sql.NullTime
+	var mergedAt, closedAt sql.NullTime
+	var requiredReviewers, linesChanged sql.NullInt64
+	var tags pq.StringArray
 
 	err := r.db.QueryRow(`
-		SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at
+		SELECT pull_request_id, pull_request_name, author_id, status, required_reviewers, lines_changed, component_tags, created_at, merged_at, closed_at
 		FROM pull_requests 
 		WHERE pull_request_id = $1
 	`, prID).Scan(
 		&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status,
-		&pr.CreatedAt, &mergedAt,
+		&requiredReviewers, &linesChanged, &tags, &pr.CreatedAt, &mergedAt, &closedAt,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -57,13 +71,21 @@ func (r *PRRepository) GetPR(prID string) (*models.PullRequest, error) {
 	if mergedAt.Valid {
 		pr.MergedAt = &mergedAt.Time
 	}
+	if closedAt.Valid {
+		pr.ClosedAt = &closedAt.Time
+	}
+	pr.RequiredReviewers = nullableInt(requiredReviewers)
+	pr.LinesChanged = nullableInt(linesChanged)
+	pr.ComponentTags = nullableTags(tags)
 
 	// получаем назначенных ревьюверов
 	reviewers, err := r.getPRReviewers(prID)
 	if err != nil {
 		return nil, err
 	}
-	pr.AssignedReviewers = reviewers
+	for _, reviewer := range reviewers {
+		pr.AssignedReviewers = append(pr.AssignedReviewers, reviewer.UserID)
+	}
 
 	return &pr, nil
 }
@@ -78,12 +100,16 @@ func (r *PRRepository) UpdatePR(pr *models.PullRequest) error {
 	} else {
 		mergedAt = nil
 	}
+	var closedAt interface{}
+	if pr.ClosedAt != nil {
+		closedAt = *pr.ClosedAt
+	}
 
 	result, err := r.db.Exec(`
 		UPDATE pull_requests 
-		SET pull_request_name = $1, author_id = $2, status = $3, merged_at = $4 
-		WHERE pull_request_id = $5
-	`, pr.PullRequestName, pr.AuthorID, pr.Status, mergedAt, pr.PullRequestID)
+		SET pull_request_name = $1, author_id = $2, status = $3, merged_at = $4, closed_at = $5 
+		WHERE pull_request_id = $6
+	`, pr.PullRequestName, pr.AuthorID, pr.Status, mergedAt, closedAt, pr.PullRequestID)
 	if err != nil {
 		return fmt.Errorf("failed to update pull request: %w", err)
 	}
@@ -100,6 +126,131 @@ func (r *PRRepository) UpdatePR(pr *models.PullRequest) error {
 	return nil
 }
 
+// переводит открытый Pull Request в статус MERGED под блокировкой строки PR
+// принимает: идентификатор PR и время мержа
+// возвращает: true если статус изменен этим вызовом, false если PR уже был замержен, или ошибку
+func (r *PRRepository) MergePR(prID string, mergedAt time.Time) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// блокировка сериализует мерж с параллельной заменой ревьювера в том же PR
+	var status string
+	err = tx.QueryRow(`
+		SELECT status FROM pull_requests
+		WHERE pull_request_id = $1
+		FOR UPDATE
+	`, prID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return false, fmt.Errorf("pull request not found")
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to lock pull request: %w", err)
+	}
+	if status != "OPEN" {
+		return false, nil
+	}
+
+	_, err = tx.Exec(`
+		UPDATE pull_requests
+		SET status = 'MERGED', merged_at = $1
+		WHERE pull_request_id = $2
+	`, mergedAt, prID)
+	if err != nil {
+		return false, fmt.Errorf("failed to merge pull request: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit merge: %w", err)
+	}
+	return true, nil
+}
+
+// переводит открытый Pull Request в статус CLOSED без мержа под блокировкой строки PR
+// принимает: идентификатор PR и время закрытия
+// возвращает: true если статус изменен этим вызовом, false если PR уже не открыт, или ошибку
+func (r *PRRepository) ClosePR(prID string, closedAt time.Time) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// блокировка сериализует закрытие с параллельным мержем и заменой ревьювера в том же PR
+	var status string
+	err = tx.QueryRow(`
+		SELECT status FROM pull_requests
+		WHERE pull_request_id = $1
+		FOR UPDATE
+	`, prID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return false, fmt.Errorf("pull request not found")
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to lock pull request: %w", err)
+	}
+	if status != "OPEN" {
+		return false, nil
+	}
+
+	_, err = tx.Exec(`
+		UPDATE pull_requests
+		SET status = 'CLOSED', closed_at = $1
+		WHERE pull_request_id = $2
+	`, closedAt, prID)
+	if err != nil {
+		return false, fmt.Errorf("failed to close pull request: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit close: %w", err)
+	}
+	return true, nil
+}
+
+// возвращает закрытый Pull Request в статус OPEN и сбрасывает время закрытия под блокировкой строки PR
+// принимает: идентификатор PR
+// возвращает: true если статус изменен этим вызовом, false если PR не закрыт, или ошибку
+func (r *PRRepository) ReopenPR(prID string) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var status string
+	err = tx.QueryRow(`
+		SELECT status FROM pull_requests
+		WHERE pull_request_id = $1
+		FOR UPDATE
+	`, prID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return false, fmt.Errorf("pull request not found")
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to lock pull request: %w", err)
+	}
+	if status != "CLOSED" {
+		return false, nil
+	}
+
+	_, err = tx.Exec(`
+		UPDATE pull_requests
+		SET status = 'OPEN', closed_at = NULL
+		WHERE pull_request_id = $1
+	`, prID)
+	if err != nil {
+		return false, fmt.Errorf("failed to reopen pull request: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit reopen: %w", err)
+	}
+	return true, nil
+}
+
 // проверяет наличие Pull Request с указанным идентификатором в базе данных
 // принимает: строку с идентификатором Pull Request для проверки существования
 // возвращает: булево значение и ошибку, где true означает что PR существует
@@ -114,28 +265,32 @@ func (r *PRRepository) PRExists(prID string) (bool, error) {
 	return exists, nil
 }
 
-// возвращает список идентификаторов ревьюверов назначенных на Pull Request
+// возвращает ревьюверов назначенных на Pull Request с временем назначения
 // принимает: строку с идентификатором Pull Request для поиска назначенных ревьюверов
-// возвращает: слайс строк с идентификаторами ревьюверов или ошибку выполнения запроса
-func (r *PRRepository) getPRReviewers(prID string) ([]string, error) {
+// возвращает: слайс ревьюверов по возрастанию времени назначения или ошибку выполнения запроса
+func (r *PRRepository) getPRReviewers(prID string) ([]models.PRReviewer, error) {
 	rows, err := r.db.Query(`
-		SELECT reviewer_id 
+		SELECT reviewer_id, assigned_at
 		FROM pr_reviewers 
 		WHERE pull_request_id = $1 
-		ORDER BY assigned_at
+		ORDER BY assigned_at, reviewer_id
 	`, prID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query PR reviewers: %w", err)
 	}
 	defer rows.Close()
 
-	var reviewers []string
+	var reviewers []models.PRReviewer
 	for rows.Next() {
-		var reviewerID string
-		if err := rows.Scan(&reviewerID); err != nil {
+		var reviewer models.PRReviewer
+		var assignedAt sql.NullTime
+		if err := rows.Scan(&reviewer.UserID, &assignedAt); err != nil {
 			return nil, fmt.Errorf("failed to scan reviewer: %w", err)
 		}
-		reviewers = append(reviewers, reviewerID)
+		if assignedAt.Valid {
+			reviewer.AssignedAt = &assignedAt.Time
+		}
+		reviewers = append(reviewers, reviewer)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -145,41 +300,344 @@ func (r *PRRepository) getPRReviewers(prID string) ([]string, error) {
 	return reviewers, nil
 }
 
-// возвращает список идентификаторов ревьюверов назначенных на указанный Pull Request
+// возвращает ревьюверов назначенных на указанный Pull Request с временем назначения
 // принимает: строку с идентификатором Pull Request для получения списка ревьюверов
-// возвращает: слайс строк с идентификаторами ревьюверов или ошибку выполнения запроса
-func (r *PRRepository) GetPRReviewers(prID string) ([]string, error) {
+// возвращает: слайс ревьюверов по возрастанию времени назначения или ошибку выполнения запроса
+func (r *PRRepository) GetPRReviewers(prID string) ([]models.PRReviewer, error) {
 	return r.getPRReviewers(prID)
 }
 
-// возвращает список Pull Request назначенных пользователю на ревью
-// принимает: строку с идентификатором пользователя для поиска назначенных PR
-// возвращает: слайс сокращенных объектов PullRequestShort или ошибку выполнения запроса
-func (r *PRRepository) GetPRsByReviewer(userID string) ([]*models.PullRequestShort, error) {
-	rows, err := r.db.Query(`
+// возвращает Pull Request с ревьюверами по списку идентификаторов одним запросом
+// принимает: слайс идентификаторов Pull Request
+// возвращает: карту найденных PR по идентификатору (отсутствующие PR не попадают в карту) или ошибку выполнения запроса
+func (r *PRRepository) GetPRsByIDs(prIDs []string) (map[string]*models.PullRequest, error) {
+	rows, err := r.readDB.Query(`
+		SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status, pr.required_reviewers,
+			pr.lines_changed, pr.component_tags, pr.created_at, pr.merged_at, pr.closed_at,
+			COALESCE(array_agg(rev.reviewer_id ORDER BY rev.assigned_at) FILTER (WHERE rev.reviewer_id IS NOT NULL), '{}')
+		FROM pull_requests pr
+		LEFT JOIN pr_reviewers rev ON rev.pull_request_id = pr.pull_request_id
+		WHERE pr.pull_request_id = ANY($1)
+		GROUP BY pr.pull_request_id
+	`, pq.Array(prIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pull requests: %w", err)
+	}
+	defer rows.Close()
+
+	prs := make(map[string]*models.PullRequest, len(prIDs))
+	for rows.Next() {
+		var pr models.PullRequest
+		var mergedAt, closedAt sql.NullTime
+		var requiredReviewers, linesChanged sql.NullInt64
+		var tags, reviewers pq.StringArray
+
+		if err := rows.Scan(
+			&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status,
+			&requiredReviewers, &linesChanged, &tags, &pr.CreatedAt, &mergedAt, &closedAt, &reviewers,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan pull request: %w", err)
+		}
+
+		if mergedAt.Valid {
+			pr.MergedAt = &mergedAt.Time
+		}
+		if closedAt.Valid {
+			pr.ClosedAt = &closedAt.Time
+		}
+		pr.RequiredReviewers = nullableInt(requiredReviewers)
+		pr.LinesChanged = nullableInt(linesChanged)
+		pr.ComponentTags = nullableTags(tags)
+		pr.AssignedReviewers = []string(reviewers)
+		prs[pr.PullRequestID] = &pr
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pull requests: %w", err)
+	}
+
+	return prs, nil
+}
+
+// возвращает страницу Pull Request, авторы которых состоят в команде, с назначенными ревьюверами, от новых к старым
+// принимает: название команды, статус PR (пустая строка - все статусы) и страницу
+// возвращает: PR страницы, общее количество PR команды с этим статусом или ошибку выполнения запроса
+func (r *PRRepository) GetPRsByAuthorTeam(teamName, status string, page models.Pagination) ([]*models.PullRequest, int, error) {
+	prs, total, err := queryPage(r.readDB, page, `
+		SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status, pr.required_reviewers,
+			pr.lines_changed, pr.component_tags, pr.created_at, pr.merged_at, pr.closed_at,
+			COALESCE(array_agg(rev.reviewer_id ORDER BY rev.assigned_at) FILTER (WHERE rev.reviewer_id IS NOT NULL), '{}')
+		FROM pull_requests pr
+		JOIN users u ON u.user_id = pr.author_id
+		LEFT JOIN pr_reviewers rev ON rev.pull_request_id = pr.pull_request_id
+		WHERE u.team_name = $1 AND ($2 = '' OR pr.status = $2)
+		GROUP BY pr.pull_request_id
+		ORDER BY pr.created_at DESC, pr.pull_request_id
+	`, []interface{}{teamName, status}, func(rows *sql.Rows) (*models.PullRequest, error) {
+		var pr models.PullRequest
+		var mergedAt, closedAt sql.NullTime
+		var requiredReviewers, linesChanged sql.NullInt64
+		var tags, reviewers pq.StringArray
+
+		if err := rows.Scan(
+			&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status,
+			&requiredReviewers, &linesChanged, &tags, &pr.CreatedAt, &mergedAt, &closedAt, &reviewers,
+		); err != nil {
+			return nil, err
+		}
+
+		if mergedAt.Valid {
+			pr.MergedAt = &mergedAt.Time
+		}
+		if closedAt.Valid {
+			pr.ClosedAt = &closedAt.Time
+		}
+		pr.RequiredReviewers = nullableInt(requiredReviewers)
+		pr.LinesChanged = nullableInt(linesChanged)
+		pr.ComponentTags = nullableTags(tags)
+		pr.AssignedReviewers = []string(reviewers)
+		return &pr, nil
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query PRs by author team: %w", err)
+	}
+	return prs, total, nil
+}
+
+// возвращает страницу Pull Request назначенных пользователю на ревью от новых к старым
+// принимает: идентификатор пользователя и страницу (limit меньше 1 - все PR)
+// возвращает: сокращенные объекты PullRequestShort страницы, общее количество PR пользователя или ошибку выполнения запроса
+func (r *PRRepository) GetPRsByReviewer(userID string, page models.Pagination) ([]*models.PullRequestShort, int, error) {
+	prs, total, err := queryPage(r.db, page, `
 		SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status
 		FROM pull_requests pr
 		JOIN pr_reviewers rev ON pr.pull_request_id = rev.pull_request_id
 		WHERE rev.reviewer_id = $1
-		ORDER BY pr.created_at DESC
-	`, userID)
+		ORDER BY pr.created_at DESC, pr.pull_request_id
+	`, []interface{}{userID}, scanPRShort)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query PRs by reviewer: %w", err)
+		return nil, 0, fmt.Errorf("failed to query PRs by reviewer: %w", err)
+	}
+	return prs, total, nil
+}
+
+// возвращает страницу Pull Request от новых к старым с фильтрами по статусу и автору
+// принимает: фильтр со статусом и автором (пустые строки - без фильтра), limit и offset страницы
+// возвращает: PR страницы, общее количество PR под фильтром или ошибку выполнения запроса
+func (r *PRRepository) ListPRs(filter models.PRFilter) ([]*models.PullRequestShort, int, error) {
+	var total int
+	err := r.readDB.QueryRow(`
+		SELECT COUNT(*) FROM pull_requests
+		WHERE ($1 = '' OR status = $1) AND ($2 = '' OR author_id = $2)
+	`, filter.Status, filter.AuthorID).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count PRs: %w", err)
+	}
+
+	rows, err := r.readDB.Query(`
+		SELECT pull_request_id, pull_request_name, author_id, status
+		FROM pull_requests
+		WHERE ($1 = '' OR status = $1) AND ($2 = '' OR author_id = $2)
+		ORDER BY created_at DESC, pull_request_id
+		LIMIT $3 OFFSET $4
+	`, filter.Status, filter.AuthorID, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query PRs: %w", err)
 	}
 	defer rows.Close()
 
-	var prs []*models.PullRequestShort
+	prs := []*models.PullRequestShort{}
 	for rows.Next() {
 		var pr models.PullRequestShort
 		if err := rows.Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status); err != nil {
-			return nil, fmt.Errorf("failed to scan PR: %w", err)
+			return nil, 0, fmt.Errorf("failed to scan PR: %w", err)
 		}
 		prs = append(prs, &pr)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating PRs: %w", err)
+		return nil, 0, fmt.Errorf("error iterating PRs: %w", err)
 	}
 
-	return prs, nil
+	return prs, total, nil
+}
+
+// возвращает страницу открытых Pull Request, где пользователь назначен ревьювером и которые созданы раньше указанного времени
+// принимает: идентификатор ревьювера, момент времени, раньше которого должен быть создан PR, и страницу
+// возвращает: PR страницы от самого старого, общее количество таких PR или ошибку выполнения запроса
+func (r *PRRepository) GetOpenPRsByReviewerCreatedBefore(userID string, before time.Time, page models.Pagination) ([]*models.BlockingPR, int, error) {
+	prs, total, err := queryPage(r.readDB, page, `
+		SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status, pr.created_at
+		FROM pull_requests pr
+		JOIN pr_reviewers rev ON pr.pull_request_id = rev.pull_request_id
+		WHERE rev.reviewer_id = $1 AND pr.status = 'OPEN' AND pr.created_at < $2
+		ORDER BY pr.created_at ASC, pr.pull_request_id
+	`, []interface{}{userID, before}, func(rows *sql.Rows) (*models.BlockingPR, error) {
+		var pr models.BlockingPR
+		err := rows.Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status, &pr.CreatedAt)
+		return &pr, err
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query blocking PRs: %w", err)
+	}
+	return prs, total, nil
+}
+
+// возвращает страницу ревьюверов Pull Request автора с количеством его PR у каждого ревьювера
+// принимает: идентификатор автора, статус PR для фильтрации (пустая строка - все статусы) и страницу (limit меньше 1 - все)
+// возвращает: ревьюверов страницы от самого загруженного PR автора к наименее загруженному, общее количество ревьюверов
+// или ошибку выполнения запроса
+func (r *PRRepository) GetReviewersByAuthor(authorID, status string, page models.Pagination) ([]models.AuthorReviewer, int, error) {
+	reviewers, total, err := queryPage(r.readDB, page, `
+		SELECT rev.reviewer_id, COALESCE(u.username, ''), COUNT(DISTINCT pr.pull_request_id)
+		FROM pull_requests pr
+		JOIN pr_reviewers rev ON pr.pull_request_id = rev.pull_request_id
+		LEFT JOIN users u ON u.user_id = rev.reviewer_id
+		WHERE pr.author_id = $1 AND ($2 = '' OR pr.status = $2)
+		GROUP BY rev.reviewer_id, u.username
+		ORDER BY COUNT(DISTINCT pr.pull_request_id) DESC, rev.reviewer_id
+	`, []interface{}{authorID, status}, func(rows *sql.Rows) (models.AuthorReviewer, error) {
+		var reviewer models.AuthorReviewer
+		err := rows.Scan(&reviewer.UserID, &reviewer.Username, &reviewer.PRCount)
+		return reviewer, err
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query author reviewers: %w", err)
+	}
+	return reviewers, total, nil
+}
+
+// возвращает ревьюверов последних PR автора; читает основную базу, чтобы только что созданные PR автора уже учитывались
+// принимает: идентификатор автора и количество последних PR
+// возвращает: слайс отсортированных ревьюверов каждого PR от самого нового PR к более старым или ошибку выполнения запроса
+func (r *PRRepository) GetRecentReviewersByAuthor(authorID string, n int) ([][]string, error) {
+	rows, err := r.db.Query(`
+		SELECT COALESCE(array_agg(rev.reviewer_id ORDER BY rev.reviewer_id) FILTER (WHERE rev.reviewer_id IS NOT NULL), '{}')
+		FROM (
+			SELECT pull_request_id, created_at
+			FROM pull_requests
+			WHERE author_id = $1
+			ORDER BY created_at DESC, pull_request_id DESC
+			LIMIT $2
+		) recent
+		LEFT JOIN pr_reviewers rev ON rev.pull_request_id = recent.pull_request_id
+		GROUP BY recent.pull_request_id, recent.created_at
+		ORDER BY recent.created_at DESC, recent.pull_request_id DESC
+	`, authorID, n)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recent author reviewers: %w", err)
+	}
+	defer rows.Close()
+
+	var reviewers [][]string
+	for rows.Next() {
+		var prReviewers []string
+		if err := rows.Scan(pq.Array(&prReviewers)); err != nil {
+			return nil, fmt.Errorf("failed to scan recent reviewers: %w", err)
+		}
+		reviewers = append(reviewers, prReviewers)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating recent reviewers: %w", err)
+	}
+
+	return reviewers, nil
+}
+
+// возвращает пользователей, которые являются авторами открытых или недавних PR с общими компонентами
+// принимает: идентификаторы пользователей, компоненты PR и момент времени, с которого замерженный PR считается недавним
+// возвращает: отсортированный слайс идентификаторов авторов из переданных пользователей или ошибку выполнения запроса
+func (r *PRRepository) GetRelatedPRAuthors(authorIDs, componentTags []string, since time.Time) ([]string, error) {
+	// запрос участвует в выборе ревьюверов, поэтому читает основную базу: на реплике может не быть только что созданных PR
+	rows, err := r.db.Query(`
+		SELECT DISTINCT author_id
+		FROM pull_requests
+		WHERE author_id = ANY($1) AND component_tags && $2
+		  AND (status = 'OPEN' OR COALESCE(merged_at, created_at) >= $3)
+		ORDER BY author_id
+	`, pq.Array(authorIDs), pq.Array(componentTags), since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query related PR authors: %w", err)
+	}
+	defer rows.Close()
+
+	var authors []string
+	for rows.Next() {
+		var authorID string
+		if err := rows.Scan(&authorID); err != nil {
+			return nil, fmt.Errorf("failed to scan author: %w", err)
+		}
+		authors = append(authors, authorID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating authors: %w", err)
+	}
+
+	return authors, nil
+}
+
+// удаляет не больше limit завершенных Pull Request: замерженных или закрытых без мержа раньше указанного времени
+// принимает: момент времени отсечения и максимальное количество удаляемых PR за вызов
+// возвращает: количество удаленных PR (ревьюверы и события удаляются каскадно) или ошибку выполнения запроса
+func (r *PRRepository) DeleteFinishedPRsBefore(cutoff time.Time, limit int) (int64, error) {
+	result, err := r.db.Exec(`
+		DELETE FROM pull_requests
+		WHERE pull_request_id IN (
+			SELECT pull_request_id FROM pull_requests
+			WHERE (status = 'MERGED' AND merged_at < $1)
+			   OR (status = 'CLOSED' AND closed_at < $1)
+			LIMIT $2
+		)
+	`, cutoff, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete finished pull requests: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return deleted, nil
+}
+
+// читает сокращенный Pull Request из строки результата
+// принимает: строки результата с pull_request_id, pull_request_name, author_id и status
+// возвращает: объект PullRequestShort или ошибку чтения
+func scanPRShort(rows *sql.Rows) (*models.PullRequestShort, error) {
+	var pr models.PullRequestShort
+	err := rows.Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status)
+	return &pr, err
+}
+
+// преобразует nullable целое значение из базы данных в указатель
+// принимает: значение sql.NullInt64
+// возвращает: указатель на значение или nil для NULL
+func nullableInt(value sql.NullInt64) *int {
+	if !value.Valid {
+		return nil
+	}
+	result := int(value.Int64)
+	return &result
+}
+
+// приводит компоненты PR к значению для колонки component_tags
+// принимает: компоненты PR (nil - не указаны)
+// возвращает: непустой слайс для NOT NULL колонки
+func componentTags(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
+
+// преобразует компоненты из базы в поле PR
+// принимает: массив компонентов из колонки component_tags
+// возвращает: nil для пустого массива, чтобы поле не попадало в ответ
+func nullableTags(tags pq.StringArray) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+	return []string(tags)
 }
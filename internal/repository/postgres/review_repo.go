@@ -3,6 +3,10 @@ package postgres
 import (
 	"database/sql"
 	"fmt"
+	"pull-request-reviewer-assignment-service/internal/models"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 // предоставляет методы для работы с данными о ревью в базе данных
@@ -18,7 +22,7 @@ func NewReviewRepository(db *sql.DB) *ReviewRepository {
 }
 
 // назначает нескольких ревьюверов на указанный Pull Request
-// принимает: идентификатор PR и слайс идентификаторов ревьюверов для назначения
+// принимает: идентификатор PR и слайс идентификаторов ревьюверов для назначения (повторы назначаются один раз)
 // возвращает: ошибку в случае неудачного выполнения транзакции назначения
 func (r *ReviewRepository) AssignReviewers(prID string, reviewerIDs []string) error {
 	tx, err := r.db.Begin()
@@ -27,10 +31,131 @@ func (r *ReviewRepository) AssignReviewers(prID string, reviewerIDs []string) er
 	}
 	defer tx.Rollback()
 
-	for _, reviewerID := range reviewerIDs {
+	// событие ASSIGN пишется тем же запросом со временем назначения
+	for _, reviewerID := range models.DedupeIDs(reviewerIDs) {
 		_, err = tx.Exec(`
-			INSERT INTO pr_reviewers (pull_request_id, reviewer_id) 
-			VALUES ($1, $2)
+			WITH assigned AS (
+				INSERT INTO pr_reviewers (pull_request_id, reviewer_id)
+				VALUES ($1, $2)
+				RETURNING pull_request_id, reviewer_id, assigned_at
+			)
+			INSERT INTO assignment_events (pull_request_id, event_type, reviewer_id, created_at)
+			SELECT pull_request_id, 'ASSIGN', reviewer_id, assigned_at FROM assigned
+		`, prID, reviewerID)
+		if err != nil {
+			return fmt.Errorf("failed to assign reviewer %s: %w", reviewerID, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// назначает ревьюверов на Pull Request, только если все они активны в момент записи: активность проверяется
+// тем же запросом, что и вставка, а строка пользователя блокируется до конца транзакции, поэтому параллельная
+// деактивация не может проскочить между проверкой и назначением
+// принимает: идентификатор PR и слайс идентификаторов ревьюверов (повторы назначаются один раз)
+// возвращает: неактивных на момент записи ревьюверов (тогда ничего не назначается) или ошибку выполнения транзакции
+func (r *ReviewRepository) AssignActiveReviewers(prID string, reviewerIDs []string) ([]string, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var inactive []string
+	for _, reviewerID := range models.DedupeIDs(reviewerIDs) {
+		result, err := tx.Exec(`
+			WITH assigned AS (
+				INSERT INTO pr_reviewers (pull_request_id, reviewer_id)
+				SELECT $1, user_id FROM users
+				WHERE user_id = $2 AND is_active
+				FOR SHARE
+				RETURNING pull_request_id, reviewer_id, assigned_at
+			)
+			INSERT INTO assignment_events (pull_request_id, event_type, reviewer_id, created_at)
+			SELECT pull_request_id, 'ASSIGN', reviewer_id, assigned_at FROM assigned
+		`, prID, reviewerID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to assign reviewer %s: %w", reviewerID, err)
+		}
+		inserted, err := result.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if inserted == 0 {
+			inactive = append(inactive, reviewerID)
+		}
+	}
+
+	if len(inactive) > 0 {
+		return inactive, nil
+	}
+	return nil, tx.Commit()
+}
+
+// добавляет ревьюверов в Pull Request, только если PR еще открыт и лимит ревьюверов не будет превышен;
+// строка PR блокируется, а статус и текущие ревьюверы перечитываются под блокировкой, поэтому параллельные
+// добавления и мерж не могут обойти проверки
+// принимает: идентификатор PR, слайс идентификаторов ревьюверов (повторы и уже назначенные пропускаются)
+// и максимальное количество ревьюверов PR (0 - без ограничения)
+// возвращает: models.ErrPRNotOpen если PR уже не открыт, models.ErrReviewerLimitReached при превышении лимита
+// или ошибку выполнения транзакции
+func (r *ReviewRepository) AddReviewersIfOpen(prID string, reviewerIDs []string, maxReviewers int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var status string
+	err = tx.QueryRow(`
+		SELECT status FROM pull_requests
+		WHERE pull_request_id = $1
+		FOR UPDATE
+	`, prID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("pull request not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock pull request: %w", err)
+	}
+	if status != "OPEN" {
+		return models.ErrPRNotOpen
+	}
+
+	var adding []string
+	for _, reviewerID := range models.DedupeIDs(reviewerIDs) {
+		var assigned bool
+		err = tx.QueryRow(`
+			SELECT EXISTS (SELECT 1 FROM pr_reviewers WHERE pull_request_id = $1 AND reviewer_id = $2)
+		`, prID, reviewerID).Scan(&assigned)
+		if err != nil {
+			return fmt.Errorf("failed to check reviewer %s: %w", reviewerID, err)
+		}
+		if !assigned {
+			adding = append(adding, reviewerID)
+		}
+	}
+
+	if maxReviewers > 0 {
+		var count int
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM pr_reviewers WHERE pull_request_id = $1`, prID).Scan(&count); err != nil {
+			return fmt.Errorf("failed to count reviewers: %w", err)
+		}
+		if count+len(adding) > maxReviewers {
+			return models.ErrReviewerLimitReached
+		}
+	}
+
+	for _, reviewerID := range adding {
+		_, err = tx.Exec(`
+			WITH assigned AS (
+				INSERT INTO pr_reviewers (pull_request_id, reviewer_id)
+				VALUES ($1, $2)
+				RETURNING pull_request_id, reviewer_id, assigned_at
+			)
+			INSERT INTO assignment_events (pull_request_id, event_type, reviewer_id, created_at)
+			SELECT pull_request_id, 'ASSIGN', reviewer_id, assigned_at FROM assigned
 		`, prID, reviewerID)
 		if err != nil {
 			return fmt.Errorf("failed to assign reviewer %s: %w", reviewerID, err)
@@ -71,16 +196,36 @@ func (r *ReviewRepository) GetAssignedReviewers(prID string) ([]string, error) {
 	return reviewers, nil
 }
 
-// заменяет одного ревьювера на другого в указанном Pull Request
+// заменяет одного ревьювера на другого в указанном Pull Request, только если PR еще открыт;
+// статус перечитывается под блокировкой строки, поэтому проверка статуса в сервисе не может устареть к моменту замены
 // принимает: идентификатор PR, идентификатор старого ревьювера и идентификатор нового ревьювера
-// возвращает: ошибку если старый ревьювер не был назначен или произошла ошибка замены
-func (r *ReviewRepository) ReplaceReviewer(prID, oldReviewerID, newReviewerID string) error {
+// возвращает: models.ErrPRNotOpen если PR уже не открыт, ошибку если старый ревьювер не был назначен или произошла ошибка замены;
+// замена записывается в журнал событий назначения в той же транзакции
+func (r *ReviewRepository) ReplaceReviewerIfOpen(prID, oldReviewerID, newReviewerID string) error {
 	tx, err := r.db.Begin()
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback()
 
+	// блокируем строку PR, чтобы замена не пересеклась с параллельным мержем,
+	// и перепроверяем статус уже под блокировкой
+	var status string
+	err = tx.QueryRow(`
+		SELECT status FROM pull_requests
+		WHERE pull_request_id = $1
+		FOR UPDATE
+	`, prID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("pull request not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock pull request: %w", err)
+	}
+	if status != "OPEN" {
+		return models.ErrPRNotOpen
+	}
+
 	// удаляем старого ревьювера
 	result, err := tx.Exec(`
 		DELETE FROM pr_reviewers 
@@ -108,9 +253,180 @@ func (r *ReviewRepository) ReplaceReviewer(prID, oldReviewerID, newReviewerID st
 		return fmt.Errorf("failed to assign new reviewer: %w", err)
 	}
 
+	_, err = tx.Exec(`
+		INSERT INTO assignment_events (pull_request_id, event_type, reviewer_id, previous_reviewer_id)
+		VALUES ($1, 'REASSIGN', $2, $3)
+	`, prID, newReviewerID, oldReviewerID)
+	if err != nil {
+		return fmt.Errorf("failed to record reassign event: %w", err)
+	}
+
 	return tx.Commit()
 }
 
+// приводит состав ревьюверов указанного Pull Request к заданному списку, только если PR еще открыт;
+// оставшиеся ревьюверы сохраняют время назначения, каждый добавленный ревьювер записывается в журнал событий
+// как замена одного из снятых (REASSIGN), а при их нехватке - как новое назначение (ASSIGN)
+// принимает: идентификатор PR и итоговый слайс идентификаторов ревьюверов
+// возвращает: models.ErrPRNotOpen если PR уже не открыт или ошибку выполнения транзакции
+func (r *ReviewRepository) SetReviewersIfOpen(prID string, reviewerIDs []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var status string
+	err = tx.QueryRow(`
+		SELECT status FROM pull_requests
+		WHERE pull_request_id = $1
+		FOR UPDATE
+	`, prID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("pull request not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock pull request: %w", err)
+	}
+	if status != "OPEN" {
+		return models.ErrPRNotOpen
+	}
+
+	rows, err := tx.Query(`
+		SELECT reviewer_id FROM pr_reviewers
+		WHERE pull_request_id = $1
+		ORDER BY reviewer_id
+	`, prID)
+	if err != nil {
+		return fmt.Errorf("failed to get current reviewers: %w", err)
+	}
+	current := make(map[string]bool)
+	var currentIDs []string
+	for rows.Next() {
+		var reviewerID string
+		if err := rows.Scan(&reviewerID); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan reviewer: %w", err)
+		}
+		current[reviewerID] = true
+		currentIDs = append(currentIDs, reviewerID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating reviewers: %w", err)
+	}
+
+	desired := make(map[string]bool, len(reviewerIDs))
+	for _, reviewerID := range reviewerIDs {
+		desired[reviewerID] = true
+	}
+
+	var removed []string
+	for _, reviewerID := range currentIDs {
+		if !desired[reviewerID] {
+			removed = append(removed, reviewerID)
+		}
+	}
+	if len(removed) > 0 {
+		_, err = tx.Exec(`
+			DELETE FROM pr_reviewers
+			WHERE pull_request_id = $1 AND reviewer_id = ANY($2)
+		`, prID, pq.Array(removed))
+		if err != nil {
+			return fmt.Errorf("failed to remove reviewers: %w", err)
+		}
+	}
+
+	for _, reviewerID := range reviewerIDs {
+		if current[reviewerID] {
+			continue
+		}
+		_, err = tx.Exec(`
+			INSERT INTO pr_reviewers (pull_request_id, reviewer_id)
+			VALUES ($1, $2)
+		`, prID, reviewerID)
+		if err != nil {
+			return fmt.Errorf("failed to assign reviewer %s: %w", reviewerID, err)
+		}
+
+		if len(removed) > 0 {
+			_, err = tx.Exec(`
+				INSERT INTO assignment_events (pull_request_id, event_type, reviewer_id, previous_reviewer_id)
+				VALUES ($1, 'REASSIGN', $2, $3)
+			`, prID, reviewerID, removed[0])
+			removed = removed[1:]
+		} else {
+			_, err = tx.Exec(`
+				INSERT INTO assignment_events (pull_request_id, event_type, reviewer_id)
+				VALUES ($1, 'ASSIGN', $2)
+			`, prID, reviewerID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to record assignment event: %w", err)
+		}
+	}
+
+	// ревьюверы, снятые без замены
+	for _, reviewerID := range removed {
+		_, err = tx.Exec(`
+			INSERT INTO assignment_events (pull_request_id, event_type, reviewer_id)
+			VALUES ($1, 'UNASSIGN', $2)
+		`, prID, reviewerID)
+		if err != nil {
+			return fmt.Errorf("failed to record unassignment event: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// возвращает события журнала назначений Pull Request в порядке их записи
+// принимает: идентификатор PR и момент времени, до которого включительно нужны события
+// возвращает: слайс событий по возрастанию времени или ошибку выполнения запроса
+func (r *ReviewRepository) GetAssignmentEvents(prID string, until time.Time) ([]models.ReviewerAuditEvent, error) {
+	rows, err := r.db.Query(`
+		SELECT event_type, reviewer_id, COALESCE(previous_reviewer_id, ''), created_at
+		FROM assignment_events
+		WHERE pull_request_id = $1 AND created_at <= $2
+		ORDER BY created_at, event_id
+	`, prID, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query assignment events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []models.ReviewerAuditEvent
+	for rows.Next() {
+		var event models.ReviewerAuditEvent
+		if err := rows.Scan(&event.EventType, &event.ReviewerID, &event.PreviousReviewerID, &event.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan assignment event: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating assignment events: %w", err)
+	}
+
+	return events, nil
+}
+
+// возвращает время последней замены ревьювера в указанном Pull Request по журналу событий
+// принимает: идентификатор PR
+// возвращает: время последнего события REASSIGN, false если замен не было, или ошибку выполнения запроса
+func (r *ReviewRepository) GetLastReassignmentAt(prID string) (time.Time, bool, error) {
+	var lastAt sql.NullTime
+	err := r.db.QueryRow(`
+		SELECT MAX(created_at)
+		FROM assignment_events
+		WHERE pull_request_id = $1 AND event_type = 'REASSIGN'
+	`, prID).Scan(&lastAt)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to query last reassignment: %w", err)
+	}
+	return lastAt.Time, lastAt.Valid, nil
+}
+
 // проверяет назначен ли указанный пользователь ревьювером на Pull Request
 // принимает: идентификатор PR и идентификатор пользователя для проверки назначения
 // возвращает: булево значение и ошибку, где true означает что пользователь назначен ревьювером
@@ -127,3 +443,115 @@ func (r *ReviewRepository) IsReviewerAssigned(prID, userID string) (bool, error)
 	}
 	return assigned, nil
 }
+
+// возвращает количество открытых PR, на которые назначен каждый из пользователей
+// принимает: слайс идентификаторов пользователей для подсчета нагрузки
+// возвращает: мапу идентификатор -> количество открытых ревью (пользователи без ревью имеют 0) или ошибку
+func (r *ReviewRepository) GetOpenAssignmentCounts(userIDs []string) (map[string]int, error) {
+	counts := make(map[string]int, len(userIDs))
+	for _, userID := range userIDs {
+		counts[userID] = 0
+	}
+	if len(userIDs) == 0 {
+		return counts, nil
+	}
+
+	rows, err := r.db.Query(`
+		SELECT rev.reviewer_id, COUNT(*)
+		FROM pr_reviewers rev
+		JOIN pull_requests pr ON pr.pull_request_id = rev.pull_request_id
+		WHERE rev.reviewer_id = ANY($1) AND pr.status = 'OPEN'
+		GROUP BY rev.reviewer_id
+	`, pq.Array(userIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query open assignment counts: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID string
+		var count int
+		if err := rows.Scan(&userID, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan assignment count: %w", err)
+		}
+		counts[userID] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating assignment counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+// возвращает время последнего назначения каждого из пользователей ревьювером
+// принимает: слайс идентификаторов пользователей
+// возвращает: мапу идентификатор -> время последнего назначения (пользователи без назначений отсутствуют) или ошибку
+func (r *ReviewRepository) GetLastAssignedAt(userIDs []string) (map[string]time.Time, error) {
+	lastAssigned := make(map[string]time.Time)
+	if len(userIDs) == 0 {
+		return lastAssigned, nil
+	}
+
+	rows, err := r.db.Query(`
+		SELECT reviewer_id, MAX(assigned_at)
+		FROM pr_reviewers
+		WHERE reviewer_id = ANY($1) AND assigned_at IS NOT NULL
+		GROUP BY reviewer_id
+	`, pq.Array(userIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query last assignments: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID string
+		var assignedAt time.Time
+		if err := rows.Scan(&userID, &assignedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan last assignment: %w", err)
+		}
+		lastAssigned[userID] = assignedAt
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating last assignments: %w", err)
+	}
+
+	return lastAssigned, nil
+}
+
+// возвращает количество назначений каждого из пользователей ревьювером начиная с указанного момента
+// принимает: слайс идентификаторов пользователей и начало периода
+// возвращает: мапу идентификатор -> количество назначений (пользователи без назначений отсутствуют) или ошибку
+func (r *ReviewRepository) GetAssignmentCountsSince(userIDs []string, since time.Time) (map[string]int, error) {
+	counts := make(map[string]int)
+	if len(userIDs) == 0 {
+		return counts, nil
+	}
+
+	rows, err := r.db.Query(`
+		SELECT reviewer_id, COUNT(*)
+		FROM pr_reviewers
+		WHERE reviewer_id = ANY($1) AND assigned_at >= $2
+		GROUP BY reviewer_id
+	`, pq.Array(userIDs), since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query assignment counts: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID string
+		var count int
+		if err := rows.Scan(&userID, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan assignment count: %w", err)
+		}
+		counts[userID] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating assignment counts: %w", err)
+	}
+
+	return counts, nil
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"pull-request-reviewer-assignment-service/internal/models"
+
+	"github.com/lib/pq"
 )
 
 // предоставляет методы для работы со статистикой в базе данных
@@ -19,19 +21,24 @@ func NewStatsRepository(db *sql.DB) *StatsRepository {
 }
 
 // возвращает статистику назначений на код-ревью по активным пользователям
-// принимает: ничего, использует контекст по умолчанию для выполнения запроса
+// принимает: слайс идентификаторов пользователей, которые исключаются из статистики (боты, сервисные аккаунты)
 // возвращает: слайс структур UserAssignmentStats с количеством назначений или ошибку
-func (r *StatsRepository) GetUserAssignmentStats() ([]models.UserAssignmentStats, error) {
+func (r *StatsRepository) GetUserAssignmentStats(excludeUserIDs []string) ([]models.UserAssignmentStats, error) {
 	query := `
         SELECT u.user_id, u.username, COUNT(pr.reviewer_id) as assignment_count
         FROM users u
         LEFT JOIN pr_reviewers pr ON u.user_id = pr.reviewer_id
-        WHERE u.is_active = true
+        WHERE u.is_active = true AND NOT (u.user_id = ANY($1))
         GROUP BY u.user_id, u.username
         ORDER BY assignment_count DESC
     `
 
-	rows, err := r.db.QueryContext(context.Background(), query)
+	// nil слайс превратится в NULL и отфильтрует всех пользователей
+	if excludeUserIDs == nil {
+		excludeUserIDs = []string{}
+	}
+
+	rows, err := r.db.QueryContext(context.Background(), query, pq.Array(excludeUserIDs))
 	if err != nil {
 		return nil, err
 	}
@@ -78,3 +85,410 @@ func (r *StatsRepository) GetPRAssignmentStats() ([]models.PRAssignmentStats, er
 
 	return stats, nil
 }
+
+// активные пользователи без назначений на ревью: $1 - команда (пустая строка - все команды)
+const unassignedUsersQuery = `
+        SELECT u.user_id, u.username, u.team_name
+        FROM users u
+        LEFT JOIN pr_reviewers pr ON u.user_id = pr.reviewer_id
+        WHERE u.is_active = true AND pr.reviewer_id IS NULL AND ($1 = '' OR u.team_name = $1)
+        ORDER BY u.user_id
+    `
+
+// возвращает страницу активных пользователей, у которых нет ни одного назначения на ревью
+// принимает: название команды для фильтрации (пустая строка - все команды) и страницу
+// возвращает: слайс структур UnassignedUser, отсортированный по идентификатору, общее количество или ошибку
+func (r *StatsRepository) GetUnassignedUsers(teamName string, page models.Pagination) ([]models.UnassignedUser, int, error) {
+	return queryPage(r.db, page, unassignedUsersQuery, []interface{}{teamName}, scanUnassignedUser)
+}
+
+// передает активных пользователей без назначений на ревью по одному по мере чтения строк
+// принимает: контекст запроса, название команды для фильтрации (пустая строка - все команды) и обработчик строки
+// возвращает: ошибку запроса или первую ошибку обработчика, после которой чтение прекращается
+func (r *StatsRepository) EachUnassignedUser(ctx context.Context, teamName string, fn func(models.UnassignedUser) error) error {
+	rows, err := r.db.QueryContext(ctx, unassignedUsersQuery, teamName)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		user, err := scanUnassignedUser(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(user); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// читает пользователя без назначений из строки результата
+// принимает: строки результата unassignedUsersQuery
+// возвращает: структуру UnassignedUser или ошибку чтения
+func scanUnassignedUser(rows *sql.Rows) (models.UnassignedUser, error) {
+	var user models.UnassignedUser
+	err := rows.Scan(&user.UserID, &user.Username, &user.TeamName)
+	return user, err
+}
+
+// открытые PR, у которых все назначенные ревьюверы неактивны или ревьюверов нет совсем
+const stuckPRsQuery = `
+        SELECT p.pull_request_id, p.pull_request_name, p.author_id,
+               COALESCE(array_agg(rev.reviewer_id ORDER BY rev.reviewer_id) FILTER (WHERE rev.reviewer_id IS NOT NULL), '{}')
+        FROM pull_requests p
+        LEFT JOIN pr_reviewers rev ON rev.pull_request_id = p.pull_request_id
+        LEFT JOIN users u ON u.user_id = rev.reviewer_id
+        WHERE p.status = 'OPEN'
+        GROUP BY p.pull_request_id, p.pull_request_name, p.author_id
+        HAVING COUNT(*) FILTER (WHERE u.is_active) = 0
+        ORDER BY p.pull_request_id
+    `
+
+// возвращает страницу открытых PR, у которых все назначенные ревьюверы неактивны или ревьюверов нет совсем
+// принимает: страницу
+// возвращает: слайс структур StuckPR, отсортированный по идентификатору PR, общее количество или ошибку
+func (r *StatsRepository) GetStuckPRs(page models.Pagination) ([]models.StuckPR, int, error) {
+	return queryPage(r.db, page, stuckPRsQuery, nil, scanStuckPR)
+}
+
+// передает открытые PR без активных ревьюверов по одному по мере чтения строк
+// принимает: контекст запроса и обработчик строки
+// возвращает: ошибку запроса или первую ошибку обработчика, после которой чтение прекращается
+func (r *StatsRepository) EachStuckPR(ctx context.Context, fn func(models.StuckPR) error) error {
+	rows, err := r.db.QueryContext(ctx, stuckPRsQuery)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		pr, err := scanStuckPR(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(pr); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// читает PR без активных ревьюверов из строки результата
+// принимает: строки результата stuckPRsQuery
+// возвращает: структуру StuckPR или ошибку чтения
+func scanStuckPR(rows *sql.Rows) (models.StuckPR, error) {
+	var pr models.StuckPR
+	var reviewers pq.StringArray
+	if err := rows.Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &reviewers); err != nil {
+		return pr, err
+	}
+	pr.AssignedReviewers = []string(reviewers)
+	return pr, nil
+}
+
+// возвращает количество PR в корзинах возраста (от created_at до текущего момента) по командам авторов
+// принимает: фильтр по статусу, команде и границам корзин
+// возвращает: слайс структур AgeBucketCount, отсортированный по команде и номеру корзины, или ошибку
+func (r *StatsRepository) GetPRAgeBucketCounts(filter models.AgingFilter) ([]models.AgeBucketCount, error) {
+	boundaries := make([]float64, len(filter.Boundaries))
+	for i, boundary := range filter.Boundaries {
+		boundaries[i] = boundary.Seconds()
+	}
+
+	// width_bucket возвращает 0 для возраста меньше первой границы и len(boundaries) для возраста не меньше последней
+	query := `
+        SELECT u.team_name,
+               width_bucket(EXTRACT(EPOCH FROM NOW() - p.created_at)::float8, $3::float8[]) AS bucket,
+               COUNT(*)
+        FROM pull_requests p
+        JOIN users u ON u.user_id = p.author_id
+        WHERE p.status = $1 AND ($2 = '' OR u.team_name = $2)
+        GROUP BY u.team_name, bucket
+        ORDER BY u.team_name, bucket
+    `
+
+	rows, err := r.db.QueryContext(context.Background(), query, filter.Status, filter.TeamName, pq.Array(boundaries))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []models.AgeBucketCount{}
+	for rows.Next() {
+		var count models.AgeBucketCount
+		if err := rows.Scan(&count.TeamName, &count.Bucket, &count.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, count)
+	}
+
+	return counts, rows.Err()
+}
+
+// возвращает страницу ревьюверов с количеством назначений по статусам PR
+// принимает: идентификатор ревьювера, название команды ревьювера (пустые строки - без фильтра) и страницу ревьюверов
+// возвращает: слайс структур ReviewerStatusStats, отсортированный по идентификатору ревьювера, общее количество ревьюверов или ошибку
+func (r *StatsRepository) GetAssignmentCountsByStatus(userID, teamName string, page models.Pagination) ([]models.ReviewerStatusStats, int, error) {
+	// страница выбирается по ревьюверам, а не по строкам ревьювер-статус
+	reviewersQuery := `
+        SELECT DISTINCT rev.reviewer_id
+        FROM pr_reviewers rev
+        JOIN users u ON u.user_id = rev.reviewer_id
+        WHERE ($1 = '' OR rev.reviewer_id = $1) AND ($2 = '' OR u.team_name = $2)
+        ORDER BY rev.reviewer_id
+    `
+	reviewerIDs, total, err := queryPage(r.db, page, reviewersQuery, []interface{}{userID, teamName},
+		func(rows *sql.Rows) (string, error) {
+			var reviewerID string
+			err := rows.Scan(&reviewerID)
+			return reviewerID, err
+		})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+        SELECT rev.reviewer_id, p.status, COUNT(*)
+        FROM pr_reviewers rev
+        JOIN pull_requests p ON p.pull_request_id = rev.pull_request_id
+        WHERE rev.reviewer_id = ANY($1)
+        GROUP BY rev.reviewer_id, p.status
+        ORDER BY rev.reviewer_id
+    `
+
+	rows, err := r.db.QueryContext(context.Background(), query, pq.Array(reviewerIDs))
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	stats := []models.ReviewerStatusStats{}
+	for rows.Next() {
+		var reviewerID, status string
+		var count int64
+		if err := rows.Scan(&reviewerID, &status, &count); err != nil {
+			return nil, 0, err
+		}
+
+		// строки одного ревьювера идут подряд благодаря сортировке
+		if len(stats) == 0 || stats[len(stats)-1].UserID != reviewerID {
+			stats = append(stats, models.ReviewerStatusStats{UserID: reviewerID, ByStatus: map[string]int64{}})
+		}
+		current := &stats[len(stats)-1]
+		current.ByStatus[status] = count
+		current.Total += count
+	}
+
+	return stats, total, rows.Err()
+}
+
+// возвращает количество созданных PR по авторам
+// принимает: фильтр по статусу, периоду создания и максимальному количеству авторов
+// возвращает: слайс структур AuthorPRCount по убыванию количества PR или ошибку
+func (r *StatsRepository) GetAuthorPRCounts(filter models.AuthorStatsFilter) ([]models.AuthorPRCount, error) {
+	query := `
+        SELECT p.author_id, COALESCE(u.username, ''), COUNT(*)
+        FROM pull_requests p
+        LEFT JOIN users u ON u.user_id = p.author_id
+        WHERE ($1 = '' OR p.status = $1)
+            AND ($2::timestamptz IS NULL OR p.created_at >= $2)
+            AND ($3::timestamptz IS NULL OR p.created_at < $3)
+        GROUP BY p.author_id, u.username
+        ORDER BY COUNT(*) DESC, p.author_id
+        LIMIT $4
+    `
+
+	rows, err := r.db.QueryContext(context.Background(), query, filter.Status, filter.From, filter.To, filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	authors := []models.AuthorPRCount{}
+	for rows.Next() {
+		var author models.AuthorPRCount
+		if err := rows.Scan(&author.AuthorID, &author.Username, &author.PRCount); err != nil {
+			return nil, err
+		}
+		authors = append(authors, author)
+	}
+
+	return authors, rows.Err()
+}
+
+// условие отбора PR по периоду создания ($1, $2) и команде автора ($3); замены и созданные PR
+// считаются по одному и тому же набору PR, чтобы среднее на PR не смешивало разные окна
+const churnPRsFilter = `
+            ($1::timestamptz IS NULL OR p.created_at >= $1)
+            AND ($2::timestamptz IS NULL OR p.created_at < $2)
+            AND ($3 = '' OR u.team_name = $3)
+`
+
+// возвращает количество замен ревьюверов в созданных за период PR и количество этих PR
+// принимает: фильтр по периоду создания и команде автора PR
+// возвращает: указатель на ChurnTotals или ошибку
+func (r *StatsRepository) GetChurnTotals(filter models.ChurnFilter) (*models.ChurnTotals, error) {
+	// у PR без ревьюверов нет событий, поэтому созданные PR считаются по pull_requests
+	query := `
+        SELECT reassigned.reassignments, reassigned.prs, created.prs
+        FROM (
+            SELECT COUNT(*) AS reassignments, COUNT(DISTINCT e.pull_request_id) AS prs
+            FROM assignment_events e
+            JOIN pull_requests p ON p.pull_request_id = e.pull_request_id
+            LEFT JOIN users u ON u.user_id = p.author_id
+            WHERE e.event_type = 'REASSIGN' AND ` + churnPRsFilter + `
+        ) reassigned, (
+            SELECT COUNT(*) AS prs
+            FROM pull_requests p
+            LEFT JOIN users u ON u.user_id = p.author_id
+            WHERE ` + churnPRsFilter + `
+        ) created
+    `
+
+	var totals models.ChurnTotals
+	err := r.db.QueryRowContext(context.Background(), query, filter.From, filter.To, filter.TeamName).
+		Scan(&totals.Reassignments, &totals.ReassignedPRs, &totals.CreatedPRs)
+	if err != nil {
+		return nil, err
+	}
+	return &totals, nil
+}
+
+// возвращает созданные за период PR с наибольшим количеством замен ревьюверов
+// принимает: фильтр по периоду создания PR, команде автора PR и максимальному количеству PR
+// возвращает: слайс структур PRChurn по убыванию количества замен или ошибку
+func (r *StatsRepository) GetTopChurnPRs(filter models.ChurnFilter) ([]models.PRChurn, error) {
+	query := `
+        SELECT p.pull_request_id, p.pull_request_name, p.author_id, COUNT(*)
+        FROM assignment_events e
+        JOIN pull_requests p ON p.pull_request_id = e.pull_request_id
+        LEFT JOIN users u ON u.user_id = p.author_id
+        WHERE e.event_type = 'REASSIGN' AND ` + churnPRsFilter + `
+        GROUP BY p.pull_request_id, p.pull_request_name, p.author_id
+        ORDER BY COUNT(*) DESC, p.pull_request_id
+        LIMIT $4
+    `
+
+	rows, err := r.db.QueryContext(context.Background(), query, filter.From, filter.To, filter.TeamName, filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prs := []models.PRChurn{}
+	for rows.Next() {
+		var pr models.PRChurn
+		if err := rows.Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Reassignments); err != nil {
+			return nil, err
+		}
+		prs = append(prs, pr)
+	}
+
+	return prs, rows.Err()
+}
+
+// возвращает страницу пар ревьюверов, назначенных на одни и те же PR, с количеством таких PR
+// принимает: фильтр по периоду создания PR и команде автора PR и страницу
+// возвращает: слайс структур ReviewerPair по убыванию количества совместных ревью, общее количество пар или ошибку
+func (r *StatsRepository) GetReviewerPairs(filter models.PairFilter, page models.Pagination) ([]models.ReviewerPair, int, error) {
+	query := `
+        SELECT a.reviewer_id, b.reviewer_id, COUNT(DISTINCT a.pull_request_id)
+        FROM pr_reviewers a
+        JOIN pr_reviewers b ON b.pull_request_id = a.pull_request_id AND a.reviewer_id < b.reviewer_id
+        JOIN pull_requests p ON p.pull_request_id = a.pull_request_id
+        LEFT JOIN users u ON u.user_id = p.author_id
+        WHERE ($1::timestamptz IS NULL OR p.created_at >= $1)
+            AND ($2::timestamptz IS NULL OR p.created_at < $2)
+            AND ($3 = '' OR u.team_name = $3)
+        GROUP BY a.reviewer_id, b.reviewer_id
+        ORDER BY COUNT(DISTINCT a.pull_request_id) DESC, a.reviewer_id, b.reviewer_id
+    `
+
+	return queryPage(r.db, page, query, []interface{}{filter.From, filter.To, filter.TeamName},
+		func(rows *sql.Rows) (models.ReviewerPair, error) {
+			var pair models.ReviewerPair
+			err := rows.Scan(&pair.ReviewerA, &pair.ReviewerB, &pair.CoReviews)
+			return pair, err
+		})
+}
+
+// возвращает количество созданных за период PR, которые получили и не получили нужное количество ревьюверов вовремя
+// принимает: фильтр по периоду создания PR, команде автора, количеству ревьюверов и допустимому времени назначения
+// возвращает: указатель на AssignmentSLACounts или ошибку
+func (r *StatsRepository) GetAssignmentSLACounts(filter models.AssignmentSLAFilter) (*models.AssignmentSLACounts, error) {
+	// момент укомплектования - время назначения Threshold-го по счету из текущих ревьюверов PR
+	query := `
+        WITH prs AS (
+            SELECT p.created_at + $5::float8 * INTERVAL '1 millisecond' AS deadline,
+                   (SELECT rev.assigned_at FROM pr_reviewers rev
+                    WHERE rev.pull_request_id = p.pull_request_id
+                    ORDER BY rev.assigned_at
+                    OFFSET $4::int - 1 LIMIT 1) AS staffed_at
+            FROM pull_requests p
+            LEFT JOIN users u ON u.user_id = p.author_id
+            WHERE ($1::timestamptz IS NULL OR p.created_at >= $1)
+                AND ($2::timestamptz IS NULL OR p.created_at < $2)
+                AND ($3 = '' OR u.team_name = $3)
+        )
+        SELECT COUNT(*) FILTER (WHERE staffed_at <= deadline),
+               COUNT(*) FILTER (WHERE (staffed_at IS NULL OR staffed_at > deadline) AND deadline <= NOW()),
+               COUNT(*) FILTER (WHERE (staffed_at IS NULL OR staffed_at > deadline) AND deadline > NOW())
+        FROM prs
+    `
+
+	var counts models.AssignmentSLACounts
+	err := r.db.QueryRowContext(context.Background(), query, filter.From, filter.To, filter.TeamName,
+		filter.Threshold, filter.Within.Milliseconds()).
+		Scan(&counts.Met, &counts.Missed, &counts.Pending)
+	if err != nil {
+		return nil, err
+	}
+	return &counts, nil
+}
+
+// возвращает нагрузку каждого участника команды: количество всех его назначений и назначений на открытые PR
+// принимает: название команды
+// возвращает: слайс нагрузок участников по идентификатору, признак существования команды или ошибку
+func (r *StatsRepository) GetTeamMemberLoads(teamName string) ([]models.TeamMemberLoad, bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM teams WHERE team_name = $1)`, teamName).Scan(&exists)
+	if err != nil {
+		return nil, false, err
+	}
+	if !exists {
+		return nil, false, nil
+	}
+
+	query := `
+        SELECT u.user_id, u.is_active,
+               COUNT(rev.reviewer_id),
+               COUNT(rev.reviewer_id) FILTER (WHERE p.status = 'OPEN')
+        FROM users u
+        LEFT JOIN pr_reviewers rev ON rev.reviewer_id = u.user_id
+        LEFT JOIN pull_requests p ON p.pull_request_id = rev.pull_request_id
+        WHERE u.team_name = $1
+        GROUP BY u.user_id, u.is_active
+        ORDER BY u.user_id
+    `
+
+	rows, err := r.db.Query(query, teamName)
+	if err != nil {
+		return nil, true, err
+	}
+	defer rows.Close()
+
+	var loads []models.TeamMemberLoad
+	for rows.Next() {
+		var load models.TeamMemberLoad
+		if err := rows.Scan(&load.UserID, &load.IsActive, &load.TotalAssignments, &load.OpenAssignments); err != nil {
+			return nil, true, err
+		}
+		loads = append(loads, load)
+	}
+
+	return loads, true, rows.Err()
+}
@@ -41,6 +41,9 @@ func (r *TeamRepository) CreateTeam(team *models.Team) error {
 		if err != nil {
 			return fmt.Errorf("failed to insert user %s: %w", member.UserID, err)
 		}
+		if err := recordTeamJoin(tx, member.UserID, team.TeamName); err != nil {
+			return err
+		}
 	}
 
 	return tx.Commit()
@@ -96,3 +99,325 @@ func (r *TeamRepository) TeamExists(teamName string) (bool, error) {
 	}
 	return exists, nil
 }
+
+// применяет изменения состава команды и замены ревьюверов в одной транзакции; замена выполняется под блокировкой
+// строки PR и пропускается, если PR к этому моменту уже не открыт
+// принимает: название команды и план изменений
+// возвращает: models.ErrReviewerNotAssigned если заменяемый ревьювер уже снят с PR, ошибку если любое из изменений
+// не удалось применить; в обоих случаях ничего не сохраняется
+func (r *TeamRepository) UpdateTeamMembers(teamName string, plan *models.TeamSyncPlan) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := applyMemberChanges(tx, teamName, plan.Added, plan.Updated); err != nil {
+		return err
+	}
+
+	for _, replacement := range plan.Replacements {
+		// блокируем строку PR, чтобы замена не пересеклась с параллельным мержем или заменой,
+		// и перепроверяем статус уже под блокировкой; закрытые и смерженные PR сохраняют ревьюверов
+		var status string
+		err = tx.QueryRow(
+			"SELECT status FROM pull_requests WHERE pull_request_id = $1 FOR UPDATE",
+			replacement.PRID,
+		).Scan(&status)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to lock PR %s: %w", replacement.PRID, err)
+		}
+		if status != "OPEN" {
+			continue
+		}
+
+		result, err := tx.Exec(
+			"DELETE FROM pr_reviewers WHERE pull_request_id = $1 AND reviewer_id = $2",
+			replacement.PRID, replacement.OldReviewerID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to remove reviewer %s from PR %s: %w", replacement.OldReviewerID, replacement.PRID, err)
+		}
+		removed, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if removed != 1 {
+			return fmt.Errorf("reviewer %s of PR %s: %w", replacement.OldReviewerID, replacement.PRID, models.ErrReviewerNotAssigned)
+		}
+
+		if replacement.NewReviewerID == "" {
+			_, err = tx.Exec(
+				"INSERT INTO assignment_events (pull_request_id, event_type, reviewer_id) VALUES ($1, 'UNASSIGN', $2)",
+				replacement.PRID, replacement.OldReviewerID,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to record unassignment event for PR %s: %w", replacement.PRID, err)
+			}
+			continue
+		}
+		_, err = tx.Exec(
+			"INSERT INTO pr_reviewers (pull_request_id, reviewer_id) VALUES ($1, $2)",
+			replacement.PRID, replacement.NewReviewerID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to add reviewer %s to PR %s: %w", replacement.NewReviewerID, replacement.PRID, err)
+		}
+
+		_, err = tx.Exec(
+			"INSERT INTO assignment_events (pull_request_id, event_type, reviewer_id, previous_reviewer_id) VALUES ($1, 'REASSIGN', $2, $3)",
+			replacement.PRID, replacement.NewReviewerID, replacement.OldReviewerID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to record reassign event for PR %s: %w", replacement.PRID, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// вставляет новых участников команды с событием JOIN и обновляет имя и активность существующих с событием смены статуса
+// принимает: транзакцию, название команды, новых и обновляемых участников
+// возвращает: ошибку вставки или обновления пользователя
+func applyMemberChanges(tx *sql.Tx, teamName string, added, updated []models.TeamMember) error {
+	for _, member := range added {
+		_, err := tx.Exec(
+			"INSERT INTO users (user_id, username, team_name, is_active) VALUES ($1, $2, $3, $4)",
+			member.UserID, member.Username, teamName, member.IsActive,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert user %s: %w", member.UserID, err)
+		}
+		if err := recordTeamJoin(tx, member.UserID, teamName); err != nil {
+			return err
+		}
+	}
+
+	for _, member := range updated {
+		// фиксируем смену активности в журнале до обновления, пока известен прежний статус
+		_, err := tx.Exec(`
+			INSERT INTO user_status_events (user_id, event_type)
+			SELECT user_id, CASE WHEN $2 THEN 'ACTIVATE' ELSE 'DEACTIVATE' END
+			FROM users
+			WHERE user_id = $1 AND team_name = $3 AND is_active <> $2
+		`, member.UserID, member.IsActive, teamName)
+		if err != nil {
+			return fmt.Errorf("failed to record status event for %s: %w", member.UserID, err)
+		}
+
+		_, err = tx.Exec(
+			"UPDATE users SET username = $1, is_active = $2, updated_at = NOW(), "+
+				"deactivated_at = CASE WHEN $2 THEN NULL WHEN is_active THEN NOW() ELSE deactivated_at END "+
+				"WHERE user_id = $3 AND team_name = $4",
+			member.Username, member.IsActive, member.UserID, teamName,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to update user %s: %w", member.UserID, err)
+		}
+	}
+	return nil
+}
+
+// удаляет команду вместе с участниками в одной транзакции, если никто из участников не является автором или ревьювером
+// открытого PR. Закрытые и замерженные PR авторства участников (вместе с ревью в них, в том числе ревьюверов других команд)
+// удаляются только при deletePRs, иначе их наличие запрещает удаление. Ревью участников в закрытых PR других команд
+// удаляются каскадно вместе с пользователями. Строки команды и участников блокируются до проверки, поэтому параллельное
+// создание PR или назначение участника ревьювером дожидается удаления и завершается ошибкой
+// принимает: название команды и флаг удаления PR авторства участников
+// возвращает: количество удаленных участников, PR и ревью, false если у участников есть открытые PR (ничего не удаляется),
+// models.ErrTeamNotFound если команды нет, models.ErrTeamHasPRs если участники - авторы PR, а deletePRs не задан, или ошибку
+func (r *TeamRepository) DeleteTeam(teamName string, deletePRs bool) (*models.DeleteTeamResponse, bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var locked string
+	err = tx.QueryRow("SELECT team_name FROM teams WHERE team_name = $1 FOR UPDATE", teamName).Scan(&locked)
+	if err == sql.ErrNoRows {
+		return nil, false, models.ErrTeamNotFound
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to lock team: %w", err)
+	}
+	if _, err := tx.Exec("SELECT user_id FROM users WHERE team_name = $1 FOR UPDATE", teamName); err != nil {
+		return nil, false, fmt.Errorf("failed to lock team members: %w", err)
+	}
+
+	var hasOpenPRs bool
+	err = tx.QueryRow(`
+		SELECT EXISTS(
+			SELECT 1
+			FROM pull_requests pr
+			WHERE pr.status = 'OPEN'
+			  AND (pr.author_id IN (SELECT user_id FROM users WHERE team_name = $1)
+			       OR EXISTS(
+			           SELECT 1 FROM pr_reviewers rev
+			           JOIN users u ON u.user_id = rev.reviewer_id
+			           WHERE rev.pull_request_id = pr.pull_request_id AND u.team_name = $1))
+		)
+	`, teamName).Scan(&hasOpenPRs)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to check open PRs of team: %w", err)
+	}
+	if hasOpenPRs {
+		return nil, false, nil
+	}
+
+	if !deletePRs {
+		var hasPRs bool
+		err = tx.QueryRow(
+			"SELECT EXISTS(SELECT 1 FROM pull_requests WHERE author_id IN (SELECT user_id FROM users WHERE team_name = $1))",
+			teamName,
+		).Scan(&hasPRs)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to check PRs of team members: %w", err)
+		}
+		if hasPRs {
+			return nil, false, models.ErrTeamHasPRs
+		}
+	}
+
+	// считаем ревью до удаления: они исчезают каскадно вместе с PR и пользователями
+	response := &models.DeleteTeamResponse{TeamName: teamName}
+	err = tx.QueryRow(`
+		SELECT COUNT(*)
+		FROM pr_reviewers rev
+		WHERE rev.reviewer_id IN (SELECT user_id FROM users WHERE team_name = $1)
+		   OR rev.pull_request_id IN (
+		       SELECT pull_request_id FROM pull_requests
+		       WHERE author_id IN (SELECT user_id FROM users WHERE team_name = $1))
+	`, teamName).Scan(&response.DeletedReviews)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to count reviews of team members: %w", err)
+	}
+
+	// PR не может существовать без автора; ревью и журнал назначений удаляются каскадно
+	result, err := tx.Exec(
+		"DELETE FROM pull_requests WHERE author_id IN (SELECT user_id FROM users WHERE team_name = $1)",
+		teamName,
+	)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to delete PRs of team members: %w", err)
+	}
+	response.DeletedPRs, err = result.RowsAffected()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to count deleted PRs of team members: %w", err)
+	}
+
+	result, err = tx.Exec("DELETE FROM users WHERE team_name = $1", teamName)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to delete team members: %w", err)
+	}
+	response.DeletedMembers, err = result.RowsAffected()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to count deleted team members: %w", err)
+	}
+
+	if _, err := tx.Exec("DELETE FROM teams WHERE team_name = $1", teamName); err != nil {
+		return nil, false, fmt.Errorf("failed to delete team: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, false, fmt.Errorf("failed to commit team deletion: %w", err)
+	}
+	return response, true, nil
+}
+
+// возвращает стратегию выбора ревьюверов, закрепленную за командой
+// принимает: название команды
+// возвращает: название стратегии (пустая строка если не задана или команды нет) или ошибку
+func (r *TeamRepository) GetAssignmentStrategy(teamName string) (string, error) {
+	var strategy sql.NullString
+	err := r.db.QueryRow(`
+		SELECT assignment_strategy FROM teams WHERE team_name = $1
+	`, teamName).Scan(&strategy)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get team assignment strategy: %w", err)
+	}
+	return strategy.String, nil
+}
+
+// закрепляет за командой стратегию выбора ревьюверов
+// принимает: название команды и стратегию (пустая строка - сбросить на глобальную)
+// возвращает: ошибку если команда не найдена или обновление не удалось
+func (r *TeamRepository) SetAssignmentStrategy(teamName, strategy string) error {
+	result, err := r.db.Exec(`
+		UPDATE teams SET assignment_strategy = NULLIF($2, '') WHERE team_name = $1
+	`, teamName, strategy)
+	if err != nil {
+		return fmt.Errorf("failed to set team assignment strategy: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("team not found")
+	}
+	return nil
+}
+
+// устанавливает адрес уведомлений команды
+// принимает: название команды и адрес (пустая строка - удалить адрес команды)
+// возвращает: ошибку выполнения запроса
+func (r *TeamRepository) SetWebhook(teamName, webhookURL string) error {
+	if webhookURL == "" {
+		if _, err := r.db.Exec(`DELETE FROM team_webhooks WHERE team_name = $1`, teamName); err != nil {
+			return fmt.Errorf("failed to delete team webhook: %w", err)
+		}
+		return nil
+	}
+
+	_, err := r.db.Exec(`
+		INSERT INTO team_webhooks (team_name, webhook_url)
+		VALUES ($1, $2)
+		ON CONFLICT (team_name) DO UPDATE SET webhook_url = EXCLUDED.webhook_url, updated_at = NOW()
+	`, teamName, webhookURL)
+	if err != nil {
+		return fmt.Errorf("failed to set team webhook: %w", err)
+	}
+	return nil
+}
+
+// возвращает адрес уведомлений команды, в которой состоит пользователь
+// принимает: идентификатор пользователя
+// возвращает: адрес уведомлений (пустая строка если у команды нет адреса) или ошибку выполнения запроса
+func (r *TeamRepository) GetWebhookByUser(userID string) (string, error) {
+	var webhookURL string
+	err := r.db.QueryRow(`
+		SELECT w.webhook_url
+		FROM users u
+		JOIN team_webhooks w ON w.team_name = u.team_name
+		WHERE u.user_id = $1
+	`, userID).Scan(&webhookURL)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get team webhook: %w", err)
+	}
+	return webhookURL, nil
+}
+
+// записывает в журнал членства вступление пользователя в команду
+// принимает: транзакцию, идентификатор пользователя и название команды
+// возвращает: ошибку выполнения запроса
+func recordTeamJoin(tx *sql.Tx, userID, teamName string) error {
+	_, err := tx.Exec(
+		"INSERT INTO team_membership_events (user_id, team_name, event_type) VALUES ($1, $2, 'JOIN')",
+		userID, teamName,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record team join of %s: %w", userID, err)
+	}
+	return nil
+}
@@ -4,6 +4,9 @@ import (
 	"database/sql"
 	"fmt"
 	"pull-request-reviewer-assignment-service/internal/models"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 // предоставляет методы для работы с данными пользователей в базе данных
@@ -18,18 +21,28 @@ func NewUserRepository(db *sql.DB) *UserRepository {
 	return &UserRepository{db: db}
 }
 
-// сохраняет нового пользователя в базе данных
+// сохраняет нового пользователя в базе данных и фиксирует его вступление в команду в журнале членства
 // принимает: указатель на объект User с данными для создания
-// возвращает: ошибку в случае неудачного выполнения запроса к базе данных
+// возвращает: ошибку в случае неудачного выполнения запроса к базе данных, в этом случае ничего не сохраняется
 func (r *UserRepository) CreateUser(user *models.User) error {
-	_, err := r.db.Exec(
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
 		"INSERT INTO users (user_id, username, team_name, is_active) VALUES ($1, $2, $3, $4)",
 		user.UserID, user.Username, user.TeamName, user.IsActive,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create user: %w", err)
 	}
-	return nil
+	if err := recordTeamJoin(tx, user.UserID, user.TeamName); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 // возвращает данные пользователя по его идентификатору из базы данных
@@ -51,12 +64,35 @@ func (r *UserRepository) GetUser(userID string) (*models.User, error) {
 	return &user, nil
 }
 
-// обновляет данные существующего пользователя в базе данных
+// обновляет данные существующего пользователя в базе данных; смена команды записывается в журнал членства
+// событиями LEAVE прежней команды и JOIN новой в той же транзакции
 // принимает: указатель на объект User с обновленными данными
-// возвращает: ошибку в случае если пользователь не найден или произошла ошибка обновления
+// возвращает: ошибку в случае если пользователь не найден или произошла ошибка обновления;
+// при деактивации запоминается ее время, при активации оно сбрасывается
 func (r *UserRepository) UpdateUser(user *models.User) error {
-	result, err := r.db.Exec(
-		"UPDATE users SET username = $1, team_name = $2, is_active = $3 WHERE user_id = $4",
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// фиксируем выход до обновления, пока известна прежняя команда
+	left, err := tx.Exec(`
+		INSERT INTO team_membership_events (user_id, team_name, event_type)
+		SELECT user_id, team_name, 'LEAVE'
+		FROM users
+		WHERE user_id = $1 AND team_name <> $2
+	`, user.UserID, user.TeamName)
+	if err != nil {
+		return fmt.Errorf("failed to record team leave of %s: %w", user.UserID, err)
+	}
+	teamChanged, err := left.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	result, err := tx.Exec(
+		"UPDATE users SET username = $1, team_name = $2, is_active = $3, deactivated_at = "+deactivatedAtExpr+" WHERE user_id = $4",
 		user.Username, user.TeamName, user.IsActive, user.UserID,
 	)
 	if err != nil {
@@ -72,9 +108,175 @@ func (r *UserRepository) UpdateUser(user *models.User) error {
 		return fmt.Errorf("user not found")
 	}
 
+	if teamChanged > 0 {
+		if err := recordTeamJoin(tx, user.UserID, user.TeamName); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// изменяет активность пользователя и записывает изменение в журнал в одной транзакции
+// принимает: идентификатор пользователя, новое значение активности и инициатора изменения (пустая строка - неизвестен)
+// возвращает: ошибку если пользователь не найден или произошла ошибка обновления; если статус не меняется, событие не пишется
+func (r *UserRepository) SetUserActive(userID string, isActive bool, actor string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := setUserActive(tx, userID, isActive, actor); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// изменяет активность нескольких пользователей в одной транзакции: применяются все изменения или ни одного
+// принимает: изменения активности пользователей и инициатора изменения (пустая строка - неизвестен)
+// возвращает: ошибку если пользователь не найден или запрос не выполнен
+func (r *UserRepository) SetUsersActive(changes []models.UserActiveChange, actor string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, change := range changes {
+		if err := setUserActive(tx, change.UserID, change.IsActive, actor); err != nil {
+			return fmt.Errorf("user %s: %w", change.UserID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// изменяет активность пользователя внутри транзакции и записывает изменение в журнал, если состояние изменилось
+// принимает: транзакцию, идентификатор пользователя, новое состояние и инициатора изменения
+// возвращает: ошибку если пользователь не найден или запрос не выполнен
+func setUserActive(tx *sql.Tx, userID string, isActive bool, actor string) error {
+	var current bool
+	err := tx.QueryRow("SELECT is_active FROM users WHERE user_id = $1 FOR UPDATE", userID).Scan(&current)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("user not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock user: %w", err)
+	}
+	if current == isActive {
+		return nil
+	}
+
+	_, err = tx.Exec(
+		"UPDATE users SET is_active = $2, deactivated_at = CASE WHEN $2 THEN NULL ELSE NOW() END WHERE user_id = $1",
+		userID, isActive,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	eventType := models.UserStatusDeactivate
+	if isActive {
+		eventType = models.UserStatusActivate
+	}
+	_, err = tx.Exec(
+		"INSERT INTO user_status_events (user_id, event_type, actor) VALUES ($1, $2, NULLIF($3, ''))",
+		userID, eventType, actor,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record status event: %w", err)
+	}
 	return nil
 }
 
+// возвращает страницу истории изменений активности пользователя
+// принимает: идентификатор пользователя, границы периода [from, to) (nil - без ограничения) и страницу
+// возвращает: события страницы в хронологическом порядке, общее количество событий за период или ошибку выполнения запроса
+func (r *UserRepository) GetStatusEvents(userID string, from, to *time.Time, page models.Pagination) ([]models.UserStatusEvent, int, error) {
+	events, total, err := queryPage(r.db, page, `
+		SELECT event_type, COALESCE(actor, ''), created_at
+		FROM user_status_events
+		WHERE user_id = $1
+			AND ($2::timestamptz IS NULL OR created_at >= $2)
+			AND ($3::timestamptz IS NULL OR created_at < $3)
+		ORDER BY created_at, event_id
+	`, []interface{}{userID, from, to}, func(rows *sql.Rows) (models.UserStatusEvent, error) {
+		var event models.UserStatusEvent
+		err := rows.Scan(&event.EventType, &event.Actor, &event.CreatedAt)
+		return event, err
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query status events: %w", err)
+	}
+	return events, total, nil
+}
+
+// возвращает журнал вступлений пользователя в команды и выходов из них
+// принимает: идентификатор пользователя
+// возвращает: слайс событий JOIN/LEAVE в хронологическом порядке или ошибку выполнения запроса
+func (r *UserRepository) GetTeamMembershipEvents(userID string) ([]models.TeamMembershipEvent, error) {
+	rows, err := r.db.Query(`
+		SELECT team_name, event_type, created_at
+		FROM team_membership_events
+		WHERE user_id = $1
+		ORDER BY created_at, event_id
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query team membership events: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]models.TeamMembershipEvent, 0)
+	for rows.Next() {
+		var event models.TeamMembershipEvent
+		if err := rows.Scan(&event.TeamName, &event.EventType, &event.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan team membership event: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating team membership events: %w", err)
+	}
+
+	return events, nil
+}
+
+// выражение для нового значения deactivated_at при обновлении is_active значением $3:
+// время фиксируется только при переходе из активного состояния в неактивное
+const deactivatedAtExpr = "CASE WHEN $3 THEN NULL WHEN is_active THEN NOW() ELSE deactivated_at END"
+
+// возвращает неактивных пользователей команды, деактивированных не раньше указанного времени
+// принимает: название команды и нижнюю границу времени деактивации
+// возвращает: слайс указателей на объекты User или ошибку выполнения запроса
+func (r *UserRepository) GetUsersDeactivatedSince(teamName string, since time.Time) ([]*models.User, error) {
+	rows, err := r.db.Query(`
+		SELECT user_id, username, team_name, is_active
+		FROM users
+		WHERE team_name = $1 AND is_active = false AND deactivated_at >= $2
+		ORDER BY user_id
+	`, teamName, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recently deactivated users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*models.User
+	for rows.Next() {
+		var user models.User
+		if err := rows.Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, &user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating users: %w", err)
+	}
+
+	return users, nil
+}
+
 // возвращает список активных пользователей указанной команды
 // принимает: строку с названием команды для поиска активных пользователей
 // возвращает: слайс указателей на объекты User или ошибку выполнения запроса
@@ -119,3 +321,228 @@ func (r *UserRepository) UserExists(userID string) (bool, error) {
 	}
 	return exists, nil
 }
+
+// заменяет список компонентов, в которых пользователь является экспертом
+// принимает: идентификатор пользователя и новый список компонентов (пустой - очистить)
+// возвращает: ошибку выполнения транзакции
+func (r *UserRepository) SetComponentExpertise(userID string, components []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM user_component_expertise WHERE user_id = $1", userID); err != nil {
+		return fmt.Errorf("failed to clear expertise: %w", err)
+	}
+
+	for _, component := range components {
+		_, err := tx.Exec(`
+			INSERT INTO user_component_expertise (user_id, component) VALUES ($1, $2)
+			ON CONFLICT DO NOTHING
+		`, userID, component)
+		if err != nil {
+			return fmt.Errorf("failed to add expertise %s: %w", component, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// возвращает пользователей из списка, являющихся экспертами хотя бы в одном из компонентов
+// принимает: идентификаторы пользователей и компоненты
+// возвращает: слайс идентификаторов экспертов или ошибку выполнения запроса
+func (r *UserRepository) GetComponentExperts(userIDs, components []string) ([]string, error) {
+	rows, err := r.db.Query(`
+		SELECT DISTINCT user_id
+		FROM user_component_expertise
+		WHERE user_id = ANY($1) AND component = ANY($2)
+		ORDER BY user_id
+	`, pq.Array(userIDs), pq.Array(components))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get component experts: %w", err)
+	}
+	defer rows.Close()
+
+	var experts []string
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("failed to scan expert: %w", err)
+		}
+		experts = append(experts, userID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating experts: %w", err)
+	}
+
+	return experts, nil
+}
+
+// сохраняет окно отказа пользователя от автоматического назначения, заменяя предыдущее
+// принимает: идентификатор пользователя, время окончания отказа и причину
+// возвращает: ошибку выполнения запроса
+func (r *UserRepository) SetOptOut(userID string, until time.Time, reason string) error {
+	_, err := r.db.Exec(`
+		UPDATE users SET opt_out_until = $2, opt_out_reason = NULLIF($3, '')
+		WHERE user_id = $1
+	`, userID, until, reason)
+	if err != nil {
+		return fmt.Errorf("failed to set opt-out: %w", err)
+	}
+	return nil
+}
+
+// возвращает последнее сохраненное окно отказа пользователя от автоматического назначения
+// принимает: идентификатор пользователя
+// возвращает: объект UserOptOut (без Until, если отказа не было) или ошибку если пользователь не найден
+func (r *UserRepository) GetOptOut(userID string) (*models.UserOptOut, error) {
+	var until sql.NullTime
+	var reason sql.NullString
+	err := r.db.QueryRow(`
+		SELECT opt_out_until, opt_out_reason FROM users WHERE user_id = $1
+	`, userID).Scan(&until, &reason)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get opt-out: %w", err)
+	}
+
+	optOut := &models.UserOptOut{UserID: userID, Reason: reason.String}
+	if until.Valid {
+		optOut.Until = &until.Time
+		optOut.OptedOut = until.Time.After(time.Now())
+	}
+	return optOut, nil
+}
+
+// возвращает пользователей из списка, отказавшихся от автоматического назначения на указанный момент
+// принимает: идентификаторы пользователей и момент времени
+// возвращает: слайс идентификаторов пользователей с неистекшим окном отказа или ошибку выполнения запроса
+func (r *UserRepository) GetOptedOutUsers(userIDs []string, at time.Time) ([]string, error) {
+	rows, err := r.db.Query(`
+		SELECT user_id
+		FROM users
+		WHERE user_id = ANY($1) AND opt_out_until > $2
+		ORDER BY user_id
+	`, pq.Array(userIDs), at)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get opted-out users: %w", err)
+	}
+	defer rows.Close()
+
+	var optedOut []string
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("failed to scan opted-out user: %w", err)
+		}
+		optedOut = append(optedOut, userID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating opted-out users: %w", err)
+	}
+
+	return optedOut, nil
+}
+
+// сохраняет группу ревьюверов пользователя, заменяя предыдущую; пустая группа убирает пользователя из групп
+// принимает: идентификатор пользователя и идентификатор группы
+// возвращает: ошибку выполнения запроса
+func (r *UserRepository) SetReviewerGroup(userID, groupID string) error {
+	if groupID == "" {
+		if _, err := r.db.Exec("DELETE FROM reviewer_groups WHERE user_id = $1", userID); err != nil {
+			return fmt.Errorf("failed to clear reviewer group: %w", err)
+		}
+		return nil
+	}
+
+	_, err := r.db.Exec(`
+		INSERT INTO reviewer_groups (user_id, group_id) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET group_id = EXCLUDED.group_id
+	`, userID, groupID)
+	if err != nil {
+		return fmt.Errorf("failed to set reviewer group: %w", err)
+	}
+	return nil
+}
+
+// возвращает группы ревьюверов пользователей из списка
+// принимает: идентификаторы пользователей
+// возвращает: отображение идентификатора пользователя в группу (пользователи без группы отсутствуют) или ошибку выполнения запроса
+func (r *UserRepository) GetReviewerGroups(userIDs []string) (map[string]string, error) {
+	groups := make(map[string]string)
+	if len(userIDs) == 0 {
+		return groups, nil
+	}
+
+	rows, err := r.db.Query(`
+		SELECT user_id, group_id
+		FROM reviewer_groups
+		WHERE user_id = ANY($1)
+	`, pq.Array(userIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reviewer groups: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID, groupID string
+		if err := rows.Scan(&userID, &groupID); err != nil {
+			return nil, fmt.Errorf("failed to scan reviewer group: %w", err)
+		}
+		groups[userID] = groupID
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reviewer groups: %w", err)
+	}
+
+	return groups, nil
+}
+
+// сохраняет недельную емкость ревью пользователя; nil снимает ограничение
+// принимает: идентификатор пользователя и количество PR за скользящие 7 дней
+// возвращает: ошибку выполнения запроса
+func (r *UserRepository) SetWeeklyCapacity(userID string, capacity *int) error {
+	_, err := r.db.Exec("UPDATE users SET weekly_review_capacity = $2 WHERE user_id = $1", userID, capacity)
+	if err != nil {
+		return fmt.Errorf("failed to set weekly capacity: %w", err)
+	}
+	return nil
+}
+
+// возвращает недельную емкость ревью пользователей из списка
+// принимает: идентификаторы пользователей
+// возвращает: отображение идентификатора пользователя в емкость (пользователи без ограничения отсутствуют) или ошибку выполнения запроса
+func (r *UserRepository) GetWeeklyCapacities(userIDs []string) (map[string]int, error) {
+	capacities := make(map[string]int)
+	if len(userIDs) == 0 {
+		return capacities, nil
+	}
+
+	rows, err := r.db.Query(`
+		SELECT user_id, weekly_review_capacity
+		FROM users
+		WHERE user_id = ANY($1) AND weekly_review_capacity IS NOT NULL
+	`, pq.Array(userIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get weekly capacities: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID string
+		var capacity int
+		if err := rows.Scan(&userID, &capacity); err != nil {
+			return nil, fmt.Errorf("failed to scan weekly capacity: %w", err)
+		}
+		capacities[userID] = capacity
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating weekly capacities: %w", err)
+	}
+
+	return capacities, nil
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync"
+)
+
+// драйвер SQL, запоминающий запросы по имени подключения и возвращающий пустые результаты
+type recordingDriver struct {
+	mu      sync.Mutex
+	queries map[string][]string
+}
+
+var testDriver = &recordingDriver{queries: make(map[string][]string)}
+
+func init() {
+	sql.Register("recording", testDriver)
+}
+
+// возвращает количество запросов, выполненных через подключение с указанным именем
+func (d *recordingDriver) count(name string) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.queries[name])
+}
+
+func (d *recordingDriver) record(name, query string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.queries[name] = append(d.queries[name], query)
+}
+
+func (d *recordingDriver) Open(name string) (driver.Conn, error) {
+	return &recordingConn{name: name, driver: d}, nil
+}
+
+type recordingConn struct {
+	name   string
+	driver *recordingDriver
+}
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return &recordingStmt{conn: c, query: query}, nil
+}
+
+func (c *recordingConn) Close() error { return nil }
+
+func (c *recordingConn) Begin() (driver.Tx, error) { return recordingTx{}, nil }
+
+type recordingTx struct{}
+
+func (recordingTx) Commit() error   { return nil }
+func (recordingTx) Rollback() error { return nil }
+
+type recordingStmt struct {
+	conn  *recordingConn
+	query string
+}
+
+func (s *recordingStmt) Close() error  { return nil }
+func (s *recordingStmt) NumInput() int { return -1 }
+
+func (s *recordingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.conn.driver.record(s.conn.name, s.query)
+	return driver.RowsAffected(1), nil
+}
+
+func (s *recordingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.conn.driver.record(s.conn.name, s.query)
+	return emptyRows{}, nil
+}
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string              { return nil }
+func (emptyRows) Close() error                   { return nil }
+func (emptyRows) Next(dest []driver.Value) error { return io.EOF }
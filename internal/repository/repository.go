@@ -1,12 +1,22 @@
 package repository
 
-import "pull-request-reviewer-assignment-service/internal/models"
+import (
+	"context"
+	"pull-request-reviewer-assignment-service/internal/models"
+	"time"
+)
 
 // интерфейс для работы с командами
 type TeamRepository interface {
 	CreateTeam(team *models.Team) error
 	GetTeam(teamName string) (*models.Team, error)
 	TeamExists(teamName string) (bool, error)
+	UpdateTeamMembers(teamName string, plan *models.TeamSyncPlan) error
+	DeleteTeam(teamName string, deletePRs bool) (*models.DeleteTeamResponse, bool, error)
+	GetAssignmentStrategy(teamName string) (string, error)
+	SetAssignmentStrategy(teamName, strategy string) error
+	SetWebhook(teamName, webhookURL string) error
+	GetWebhookByUser(userID string) (string, error)
 }
 
 // интерфейс для работы с пользователями
@@ -15,28 +25,85 @@ type UserRepository interface {
 	GetUser(userID string) (*models.User, error)
 	UpdateUser(user *models.User) error
 	GetActiveUsersByTeam(teamName string) ([]*models.User, error)
+	GetUsersDeactivatedSince(teamName string, since time.Time) ([]*models.User, error)
 	UserExists(userID string) (bool, error)
+	SetUserActive(userID string, isActive bool, actor string) error
+	SetUsersActive(changes []models.UserActiveChange, actor string) error
+	GetStatusEvents(userID string, from, to *time.Time, page models.Pagination) ([]models.UserStatusEvent, int, error)
+	GetTeamMembershipEvents(userID string) ([]models.TeamMembershipEvent, error)
+	SetComponentExpertise(userID string, components []string) error
+	GetComponentExperts(userIDs, components []string) ([]string, error)
+	SetOptOut(userID string, until time.Time, reason string) error
+	GetOptOut(userID string) (*models.UserOptOut, error)
+	GetOptedOutUsers(userIDs []string, at time.Time) ([]string, error)
+	SetReviewerGroup(userID, groupID string) error
+	GetReviewerGroups(userIDs []string) (map[string]string, error)
+	SetWeeklyCapacity(userID string, capacity *int) error
+	GetWeeklyCapacities(userIDs []string) (map[string]int, error)
 }
 
 // интерфейс для работы с pull requests
 type PRRepository interface {
 	CreatePR(pr *models.PullRequest) error
 	GetPR(prID string) (*models.PullRequest, error)
+	GetPRsByIDs(prIDs []string) (map[string]*models.PullRequest, error)
+	GetPRReviewers(prID string) ([]models.PRReviewer, error)
 	UpdatePR(pr *models.PullRequest) error
+	MergePR(prID string, mergedAt time.Time) (bool, error)
+	ClosePR(prID string, closedAt time.Time) (bool, error)
+	ReopenPR(prID string) (bool, error)
 	PRExists(prID string) (bool, error)
-	GetPRsByReviewer(userID string) ([]*models.PullRequestShort, error)
+	GetPRsByReviewer(userID string, page models.Pagination) ([]*models.PullRequestShort, int, error)
+	ListPRs(filter models.PRFilter) ([]*models.PullRequestShort, int, error)
+	GetOpenPRsByReviewerCreatedBefore(userID string, before time.Time, page models.Pagination) ([]*models.BlockingPR, int, error)
+	GetReviewersByAuthor(authorID, status string, page models.Pagination) ([]models.AuthorReviewer, int, error)
+	GetRecentReviewersByAuthor(authorID string, n int) ([][]string, error)
+	GetRelatedPRAuthors(authorIDs, componentTags []string, since time.Time) ([]string, error)
+	GetPRsByAuthorTeam(teamName, status string, page models.Pagination) ([]*models.PullRequest, int, error)
+	DeleteFinishedPRsBefore(cutoff time.Time, limit int) (int64, error)
 }
 
 // интерфейс для работы с ревьюверами
 type ReviewRepository interface {
 	AssignReviewers(prID string, reviewerIDs []string) error
+	AssignActiveReviewers(prID string, reviewerIDs []string) ([]string, error)
+	AddReviewersIfOpen(prID string, reviewerIDs []string, maxReviewers int) error
 	GetAssignedReviewers(prID string) ([]string, error)
-	ReplaceReviewer(prID, oldReviewerID, newReviewerID string) error
+	ReplaceReviewerIfOpen(prID, oldReviewerID, newReviewerID string) error
+	SetReviewersIfOpen(prID string, reviewerIDs []string) error
+	GetLastReassignmentAt(prID string) (time.Time, bool, error)
+	GetAssignmentEvents(prID string, until time.Time) ([]models.ReviewerAuditEvent, error)
 	IsReviewerAssigned(prID, userID string) (bool, error)
+	GetOpenAssignmentCounts(userIDs []string) (map[string]int, error)
+	GetLastAssignedAt(userIDs []string) (map[string]time.Time, error)
+	GetAssignmentCountsSince(userIDs []string, since time.Time) (map[string]int, error)
 }
 
 // интерфейс для работы со статистикой
 type StatsRepository interface {
-	GetUserAssignmentStats() ([]models.UserAssignmentStats, error)
+	GetUserAssignmentStats(excludeUserIDs []string) ([]models.UserAssignmentStats, error)
 	GetPRAssignmentStats() ([]models.PRAssignmentStats, error)
+	GetUnassignedUsers(teamName string, page models.Pagination) ([]models.UnassignedUser, int, error)
+	GetStuckPRs(page models.Pagination) ([]models.StuckPR, int, error)
+	EachUnassignedUser(ctx context.Context, teamName string, fn func(models.UnassignedUser) error) error
+	EachStuckPR(ctx context.Context, fn func(models.StuckPR) error) error
+	GetPRAgeBucketCounts(filter models.AgingFilter) ([]models.AgeBucketCount, error)
+	GetAssignmentCountsByStatus(userID, teamName string, page models.Pagination) ([]models.ReviewerStatusStats, int, error)
+	GetAuthorPRCounts(filter models.AuthorStatsFilter) ([]models.AuthorPRCount, error)
+	GetChurnTotals(filter models.ChurnFilter) (*models.ChurnTotals, error)
+	GetTopChurnPRs(filter models.ChurnFilter) ([]models.PRChurn, error)
+	GetReviewerPairs(filter models.PairFilter, page models.Pagination) ([]models.ReviewerPair, int, error)
+	GetAssignmentSLACounts(filter models.AssignmentSLAFilter) (*models.AssignmentSLACounts, error)
+	GetTeamMemberLoads(teamName string) ([]models.TeamMemberLoad, bool, error)
+}
+
+// интерфейс для работы с журналом событий назначения
+type AssignmentEventRepository interface {
+	BackfillAssignEvents() (int64, error)
+	EachAssignmentEvent(ctx context.Context, filter models.EventExportFilter, fn func(models.ExportedAssignmentEvent) error) error
+}
+
+// интерфейс для чтения учета примененных миграций
+type MigrationRepository interface {
+	GetAppliedMigrations() ([]models.AppliedMigration, error)
 }
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"fmt"
+	"pull-request-reviewer-assignment-service/internal/models"
+)
+
+// возвращает настройки назначения ревьюверов, которые действуют для команды
+// принимает: название команды
+// возвращает: объект TeamAssignmentConfig с глобальными настройками, стратегией команды и данными команды или ошибку NOT_FOUND
+func (s *PRService) GetTeamAssignmentConfig(teamName string) (*models.TeamAssignmentConfig, error) {
+	if err := s.teamService.ensureTeamExists(teamName); err != nil {
+		return nil, err
+	}
+
+	activeUsers, err := s.userRepo.GetActiveUsersByTeam(teamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active users: %w", err)
+	}
+
+	teamStrategy, err := s.teamService.assignmentStrategy(teamName)
+	if err != nil {
+		return nil, err
+	}
+	strategy, err := s.resolveStrategy(teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	// автор PR не может быть ревьювером
+	availableReviewers := len(activeUsers) - 1
+	if availableReviewers < 0 {
+		availableReviewers = 0
+	}
+
+	return &models.TeamAssignmentConfig{
+		TeamName:             teamName,
+		AssignmentStrategy:   strategy,
+		TeamStrategy:         teamStrategy != "" && teamStrategy == strategy,
+		DefaultReviewerCount: min(s.policyReviewerCount(), availableReviewers),
+		SizeBasedReviewers:   s.cfg.SizeBasedReviewers,
+		MinActivePerTeam:     s.cfg.MinActivePerTeam,
+		ActiveMembers:        len(activeUsers),
+		AvailableReviewers:   availableReviewers,
+		ManualAssignment:     s.isManualAssignment(teamName),
+	}, nil
+}
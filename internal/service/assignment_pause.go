@@ -0,0 +1,76 @@
+package service
+
+import (
+	"log"
+	"pull-request-reviewer-assignment-service/internal/models"
+	"sync/atomic"
+)
+
+// флаг паузы назначения ревьюверов, общий для сервисов PR и пользователей: на паузе не выполняется
+// ни одно автоматическое или ручное назначение, включая замены при деактивации и синхронизации команд
+type AssignmentPause struct {
+	paused atomic.Bool
+}
+
+// возвращает, приостановлено ли назначение ревьюверов; nil означает, что пауза не подключена
+// принимает: ничего
+// возвращает: true если назначение на паузе
+func (p *AssignmentPause) Paused() bool {
+	return p != nil && p.paused.Load()
+}
+
+// проверяет, что назначение ревьюверов не приостановлено
+// принимает: ничего
+// возвращает: ошибку ASSIGNMENT_PAUSED если назначение на паузе
+func (p *AssignmentPause) check() error {
+	if p.Paused() {
+		return NewServiceError("ASSIGNMENT_PAUSED", "reviewer assignment is paused")
+	}
+	return nil
+}
+
+// приостанавливает или возобновляет назначение ревьюверов в этом экземпляре сервиса: на паузе PR создаются
+// без ревьюверов, а добавление и замены ревьюверов отклоняются с ASSIGNMENT_PAUSED
+// принимает: true для паузы, false для возобновления
+// возвращает: объект AssignmentPauseState с новым состоянием
+func (s *PRService) SetAssignmentPaused(paused bool) *models.AssignmentPauseState {
+	if s.pause.paused.Swap(paused) != paused {
+		log.Printf("Reviewer assignment paused: %t", paused)
+	}
+	return &models.AssignmentPauseState{Paused: paused}
+}
+
+// возвращает, приостановлено ли назначение ревьюверов
+// принимает: ничего
+// возвращает: true если назначение на паузе
+func (s *PRService) AssignmentPaused() bool {
+	return s.pause.Paused()
+}
+
+// возвращает флаг паузы назначения, чтобы подключить его к сервису пользователей
+// принимает: ничего
+// возвращает: указатель на AssignmentPause этого сервиса
+func (s *PRService) AssignmentPause() *AssignmentPause {
+	return s.pause
+}
+
+// проверяет, что назначение ревьюверов не приостановлено
+// принимает: ничего
+// возвращает: ошибку ASSIGNMENT_PAUSED если назначение на паузе
+func (s *PRService) checkAssignmentNotPaused() error {
+	return s.pause.check()
+}
+
+// подключает флаг паузы назначения, общий с сервисом PR: на паузе операции, переназначающие ревью, отклоняются
+// принимает: указатель на AssignmentPause
+// возвращает: ничего
+func (s *UserService) SetAssignmentPause(pause *AssignmentPause) {
+	s.pause = pause
+}
+
+// проверяет, что назначение ревьюверов не приостановлено
+// принимает: ничего
+// возвращает: ошибку ASSIGNMENT_PAUSED если назначение на паузе
+func (s *UserService) checkAssignmentNotPaused() error {
+	return s.pause.check()
+}
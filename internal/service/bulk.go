@@ -0,0 +1,49 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"sync"
+)
+
+// выполняет задания пулом из не более чем concurrency воркеров и дожидается завершения всех заданий;
+// паника в задании не останавливает пул, а передается в onPanic для этого задания
+// принимает: количество заданий, максимальное число одновременно выполняемых заданий (меньше 1 - по одному),
+// функцию задания по номеру и обработчик паники задания
+// возвращает: ничего, результаты задания сохраняют сами
+func runBulk(jobs, concurrency int, work func(job int), onPanic func(job int, err error)) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < min(concurrency, jobs); worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range indexes {
+				runBulkJob(job, work, onPanic)
+			}
+		}()
+	}
+
+	for job := 0; job < jobs; job++ {
+		indexes <- job
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// выполняет одно задание пула, перехватывая панику
+// принимает: номер задания, функцию задания и обработчик паники
+// возвращает: ничего
+func runBulkJob(job int, work func(job int), onPanic func(job int, err error)) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			log.Printf("Bulk job %d panicked: %v", job, recovered)
+			onPanic(job, fmt.Errorf("panic: %v", recovered))
+		}
+	}()
+	work(job)
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"pull-request-reviewer-assignment-service/internal/models"
+)
+
+// статусы результата отдельной замены при массовом переназначении
+const (
+	BulkReassignApplied   = "REASSIGNED"
+	BulkReassignFailed    = "FAILED"
+	BulkReassignCancelled = "CANCELLED"
+)
+
+// заменяет ревьюверов по явно заданным соответствиям; каждая замена выполняется отдельно,
+// поэтому ошибка одной не отменяет остальные. Разные PR обрабатываются параллельно (не больше BulkConcurrency),
+// соответствия одного PR - последовательно в порядке запроса. После отмены контекста новые замены не начинаются,
+// начатые завершаются, а оставшиеся соответствия получают статус CANCELLED
+// принимает: контекст запроса и слайс соответствий PR, старого и нового ревьювера
+// возвращает: результаты по каждому соответствию в исходном порядке и количество успешных и неудачных замен
+func (s *PRService) BulkReassign(ctx context.Context, mappings []models.BulkReassignMapping) *models.BulkReassignResponse {
+	log.Printf("Bulk reassigning %d mappings", len(mappings))
+
+	// группируем соответствия по PR, сохраняя порядок первого появления
+	var groups [][]int
+	groupByPR := make(map[string]int)
+	for i, mapping := range mappings {
+		group, ok := groupByPR[mapping.PullRequestID]
+		if !ok {
+			group = len(groups)
+			groupByPR[mapping.PullRequestID] = group
+			groups = append(groups, nil)
+		}
+		groups[group] = append(groups[group], i)
+	}
+
+	// каждый воркер пишет только в элементы своих соответствий
+	results := make([]models.BulkReassignResult, len(mappings))
+	for i, mapping := range mappings {
+		results[i] = models.BulkReassignResult{BulkReassignMapping: mapping, Status: BulkReassignApplied}
+	}
+	fail := func(i int, err error) {
+		mapping := mappings[i]
+		log.Printf("Bulk reassign of %s -> %s in PR %s failed: %v", mapping.OldUserID, mapping.NewUserID, mapping.PullRequestID, err)
+		results[i].Status = BulkReassignFailed
+		results[i].Error = bulkReassignError(err)
+	}
+
+	done := make([]bool, len(mappings))
+	runBulk(len(groups), s.cfg.BulkConcurrency, func(group int) {
+		for _, i := range groups[group] {
+			if ctx.Err() != nil {
+				results[i].Status = BulkReassignCancelled
+				done[i] = true
+				continue
+			}
+			if err := s.reassignTo(mappings[i]); err != nil {
+				fail(i, err)
+			}
+			done[i] = true
+		}
+	}, func(group int, err error) {
+		// упавшее соответствие и следующие за ним в группе не выполнены
+		for _, i := range groups[group] {
+			if !done[i] {
+				fail(i, err)
+			}
+		}
+	})
+
+	response := &models.BulkReassignResponse{Results: results}
+	for _, result := range results {
+		switch result.Status {
+		case BulkReassignFailed:
+			response.Failed++
+		case BulkReassignCancelled:
+			response.Cancelled = true
+		default:
+			response.Reassigned++
+		}
+	}
+
+	if response.Cancelled {
+		log.Printf("Bulk reassign cancelled: %d reassigned, %d failed before cancellation", response.Reassigned, response.Failed)
+		return response
+	}
+	log.Printf("Bulk reassign completed: %d reassigned, %d failed", response.Reassigned, response.Failed)
+	return response
+}
+
+// заменяет старого ревьювера PR на явно указанного нового в отдельной транзакции
+// принимает: соответствие PR, старого и нового ревьювера
+// возвращает: ServiceError с причиной, если PR не открыт, старый не назначен или новый не может ревьюить PR
+func (s *PRService) reassignTo(mapping models.BulkReassignMapping) error {
+	prID, oldUserID, newUserID := mapping.PullRequestID, mapping.OldUserID, mapping.NewUserID
+	if prID == "" || oldUserID == "" || newUserID == "" {
+		return NewServiceError("INVALID_REQUEST", "pull_request_id, old_user_id and new_user_id are required")
+	}
+
+	pr, err := s.prRepo.GetPR(prID)
+	if err != nil {
+		return NewServiceError("NOT_FOUND", "PR not found")
+	}
+	if pr.Status != "OPEN" {
+		return notOpenError(pr.Status, "reassign on")
+	}
+	if !contains(pr.AssignedReviewers, oldUserID) {
+		return NewServiceError("NOT_ASSIGNED", "old user is not assigned to this PR")
+	}
+
+	newUser, err := s.userRepo.GetUser(newUserID)
+	if err != nil {
+		return NewServiceError("NOT_FOUND", "new user not found")
+	}
+	if !newUser.IsActive {
+		return NewServiceError("INVALID_REQUEST", "new user is not active")
+	}
+	if newUserID == pr.AuthorID {
+		return NewServiceError("INVALID_REQUEST", "author cannot review own PR")
+	}
+	if contains(pr.AssignedReviewers, newUserID) {
+		return NewServiceError("ALREADY_ASSIGNED", "new user is already a reviewer of this PR")
+	}
+
+	if err := s.reviewRepo.ReplaceReviewerIfOpen(prID, oldUserID, newUserID); err != nil {
+		if errors.Is(err, models.ErrPRNotOpen) {
+			return s.concurrentNotOpenError(prID, "reassign on")
+		}
+		return fmt.Errorf("failed to replace reviewer: %w", err)
+	}
+
+	pr.AssignedReviewers = s.replaceInSlice(pr.AssignedReviewers, oldUserID, newUserID)
+	s.publishEvent(models.EventReviewerReassigned, pr, oldUserID, newUserID)
+	return nil
+}
+
+// преобразует ошибку замены в описание для результата массового переназначения
+// принимает: ошибку замены
+// возвращает: код и сообщение ошибки (внутренние ошибки не раскрываются)
+func bulkReassignError(err error) *models.ErrorDetail {
+	var serviceErr *ServiceError
+	if errors.As(err, &serviceErr) {
+		return &models.ErrorDetail{Code: serviceErr.Code, Message: serviceErr.Message}
+	}
+	return &models.ErrorDetail{Code: "INTERNAL_ERROR", Message: "Internal server error"}
+}
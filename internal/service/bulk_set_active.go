@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"pull-request-reviewer-assignment-service/internal/models"
+	"sort"
+)
+
+// статусы результата изменения активности отдельного пользователя при массовом изменении
+const (
+	BulkSetActiveUpdated   = "UPDATED"
+	BulkSetActiveUnchanged = "UNCHANGED"
+	BulkSetActiveFailed    = "FAILED"
+	BulkSetActiveCancelled = "CANCELLED"
+)
+
+// изменяет активность нескольких пользователей с явными состояниями: все изменения применяются в одной транзакции,
+// после чего открытые ревью деактивированных пользователей переназначаются на активных участников их команд.
+// Неизвестные пользователи получают статус FAILED и не мешают остальным; нарушение MinActivePerTeam отклоняет весь запрос
+// принимает: контекст запроса, желаемые состояния пользователей и инициатора изменения (пустая строка - неизвестен)
+// возвращает: результаты по каждому пользователю в исходном порядке или ошибку TEAM_TOO_SMALL/сохранения
+func (s *UserService) BulkSetActive(ctx context.Context, changes []models.UserActiveChange,
+	actor string) (*models.BulkSetActiveResponse, error) {
+	log.Printf("Bulk setting activity for %d users", len(changes))
+
+	response := &models.BulkSetActiveResponse{Results: make([]models.BulkSetActiveResult, len(changes))}
+	teams := make(map[string]string)
+	deactivations := make(map[string]int)
+	var applied []models.UserActiveChange
+	for i, change := range changes {
+		result := &response.Results[i]
+		result.UserID, result.IsActive = change.UserID, change.IsActive
+
+		user, err := s.userRepo.GetUser(change.UserID)
+		if err != nil {
+			result.Status = BulkSetActiveFailed
+			result.Error = &models.ErrorDetail{Code: "NOT_FOUND", Message: "user not found"}
+			continue
+		}
+		if user.IsActive == change.IsActive {
+			result.Status = BulkSetActiveUnchanged
+			continue
+		}
+
+		result.Status = BulkSetActiveUpdated
+		teams[change.UserID] = user.TeamName
+		applied = append(applied, change)
+		if change.IsActive {
+			deactivations[user.TeamName]--
+		} else {
+			deactivations[user.TeamName]++
+		}
+	}
+
+	// активации в той же команде компенсируют деактивации
+	teamNames := make([]string, 0, len(deactivations))
+	for teamName := range deactivations {
+		teamNames = append(teamNames, teamName)
+	}
+	sort.Strings(teamNames)
+	for _, change := range applied {
+		// ревью деактивируемых переназначаются, поэтому на паузе назначения деактивации отклоняются
+		if !change.IsActive {
+			if err := s.checkAssignmentNotPaused(); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	for _, teamName := range teamNames {
+		if deactivations[teamName] > 0 {
+			if err := s.checkMinActiveMembers(teamName, deactivations[teamName]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if ctx.Err() != nil {
+		log.Printf("Bulk set active cancelled before applying changes: %v", ctx.Err())
+		for i := range response.Results {
+			if response.Results[i].Status == BulkSetActiveUpdated {
+				response.Results[i].Status = BulkSetActiveCancelled
+			}
+		}
+		response.Cancelled = true
+		return response, nil
+	}
+
+	if len(applied) > 0 {
+		if err := s.userRepo.SetUsersActive(applied, actor); err != nil {
+			log.Printf("Failed to apply bulk activity changes: %v", err)
+			return nil, fmt.Errorf("failed to update users: %w", err)
+		}
+	}
+
+	// состав команд запрашивается после применения изменений, поэтому активированные участники уже могут стать заменой
+	members := newActiveMembersCache(s.userRepo)
+	excluded := make(map[string]bool)
+	var affectedPRs []string
+	for _, change := range applied {
+		if !change.IsActive {
+			excluded[change.UserID] = true
+		}
+	}
+
+	for i := range response.Results {
+		result := &response.Results[i]
+		if result.Status != BulkSetActiveUpdated {
+			continue
+		}
+		if result.IsActive {
+			response.Activated++
+			continue
+		}
+		response.Deactivated++
+
+		openPRs, err := s.getOpenPRsWithReviewer(result.UserID)
+		if err != nil {
+			log.Printf("Failed to get open PRs for user %s: %v", result.UserID, err)
+			continue
+		}
+		affectedPRs = append(affectedPRs, prIDsOf(openPRs)...)
+		for _, pr := range openPRs {
+			reassignedPR, err := s.reassignReviewerInPR(pr.PullRequestID, result.UserID, teams[result.UserID], members, excluded)
+			if err != nil {
+				log.Printf("Failed to reassign PR %s: %v", pr.PullRequestID, err)
+				continue
+			}
+			if reassignedPR != nil {
+				result.ReassignedPRs = append(result.ReassignedPRs, *reassignedPR)
+				response.ReassignedCount++
+			}
+		}
+	}
+
+	s.checkStaffing(affectedPRs)
+
+	log.Printf("Bulk set active completed: %d activated, %d deactivated, %d PRs reassigned",
+		response.Activated, response.Deactivated, response.ReassignedCount)
+	return response, nil
+}
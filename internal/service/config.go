@@ -0,0 +1,94 @@
+package service
+
+import (
+	"fmt"
+	"time"
+)
+
+// настройки бизнес-логики сервисов
+type Config struct {
+	// минимальное количество активных участников, которое должно остаться в команде (0 - без ограничения)
+	MinActivePerTeam int
+	// стратегия выбора ревьюверов: random или least_loaded
+	AssignmentStrategy string
+	// количество автоматически назначаемых ревьюверов
+	ReviewerPolicy ReviewerPolicy
+	// максимальная длина названия PR в символах (0 - без ограничения)
+	MaxPRNameLength int
+	// поведение при превышении длины названия PR: reject или truncate
+	PRNameOverflow string
+	// максимальное количество PR в одном запросе batchGet
+	MaxBatchSize int
+	// выбирать количество ревьюверов по размеру PR (lines_changed)
+	SizeBasedReviewers bool
+	// предпочитать ревьюверов с экспертизой в компонентах PR (component_tags)
+	OwnershipAffinity bool
+	// предпочитать ревьюверов, которые сами являются авторами открытых или недавних PR с общими компонентами
+	RelatedAuthorAffinity bool
+	// окно, в течение которого замерженный PR с общими компонентами дает автору приоритет при RelatedAuthorAffinity
+	RelatedAuthorWindow time.Duration
+	// минимальный интервал между заменами ревьюверов одного PR (0 - без ограничения)
+	ReassignMinInterval time.Duration
+	// окно, в течение которого деактивированные участники могут быть назначены при нехватке активных (0 - отключено)
+	InactiveFallbackWindow time.Duration
+	// максимальное количество ревьюверов на одном PR (0 - без ограничения)
+	MaxReviewersPerPR int
+	// не назначать ревьювера, если его открытых ревью станет больше минимума среди кандидатов команды более чем на FairnessMaxDelta
+	EnforceFairness bool
+	// допустимое превышение минимальной нагрузки при EnforceFairness (не меньше 1)
+	FairnessMaxDelta int
+	// максимальное количество одновременно обрабатываемых элементов массовых операций (меньше 1 - по одному)
+	BulkConcurrency int
+	// разрешать создание PR от имени неактивного автора (автоматизация, импорт истории)
+	AllowInactiveAuthor bool
+	// предпочитать ревьюверов, которые не ревьюят другие открытые PR того же автора
+	SpreadAuthorReviews bool
+	// создавать PR без автоматического назначения ревьюверов, ревьюверы добавляются вручную
+	ManualAssignment bool
+	// команды, PR авторов которых создаются без автоматического назначения независимо от ManualAssignment
+	ManualAssignmentTeams []string
+	// назначать не больше одного ревьювера из каждой группы ревьюверов, ослабляя ограничение при нехватке кандидатов
+	OneReviewerPerGroup bool
+	// обязательные команды ревьюверов: PR автора из команды-ключа должен иметь ревьювера из команды-значения
+	RequiredReviewerTeams map[string]string
+	// количество повторных выборов, если выбранный ревьювер деактивирован до записи назначения (0 - без повторов)
+	AssignmentRetries int
+	// количество последних PR автора, ревьюверы которых выбираются в последнюю очередь (0 - отключено)
+	RecentAuthorPRs int
+}
+
+// политика количества автоматически назначаемых ревьюверов
+type ReviewerPolicy struct {
+	// количество ревьюверов для PR без required_reviewers и без SizeBasedReviewers (меньше 1 - по умолчанию 2)
+	MaxReviewers int
+	// минимум ревьюверов, при нехватке которого ответ на создание PR содержит предупреждение NO_CANDIDATE (0 - без предупреждения)
+	MinReviewers int
+}
+
+// проверяет согласованность политики с лимитом ревьюверов на PR: MinReviewers <= MaxReviewers <= maxReviewersPerPR
+// принимает: лимит MAX_REVIEWERS_PER_PR (0 - без ограничения)
+// возвращает: ошибку с описанием нарушенного условия или nil
+func (p ReviewerPolicy) Validate(maxReviewersPerPR int) error {
+	maxReviewers := p.MaxReviewers
+	if maxReviewers < 1 {
+		maxReviewers = defaultReviewerCount
+	}
+	if p.MinReviewers < 0 {
+		return fmt.Errorf("REVIEWER_POLICY_MIN must not be negative, got %d", p.MinReviewers)
+	}
+	if p.MinReviewers > maxReviewers {
+		return fmt.Errorf("REVIEWER_POLICY_MIN (%d) must not exceed REVIEWER_POLICY_MAX (%d)", p.MinReviewers, maxReviewers)
+	}
+	if maxReviewersPerPR > 0 && maxReviewers > maxReviewersPerPR {
+		return fmt.Errorf("REVIEWER_POLICY_MAX (%d) must not exceed MAX_REVIEWERS_PER_PR (%d)", maxReviewers, maxReviewersPerPR)
+	}
+	return nil
+}
+
+// режимы обработки слишком длинного названия PR
+const (
+	// отклонять запрос с ошибкой INVALID_REQUEST
+	PRNameOverflowReject = "reject"
+	// обрезать название до максимальной длины
+	PRNameOverflowTruncate = "truncate"
+)
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"log"
+	"pull-request-reviewer-assignment-service/internal/models"
+	"time"
+)
+
+// публикует события назначения ревьюверов, реализация не должна блокировать вызывающего
+type EventPublisher interface {
+	Publish(event models.AssignmentEvent)
+}
+
+// публикатор по умолчанию, отбрасывающий все события
+type noopPublisher struct{}
+
+// отбрасывает событие
+// принимает: событие назначения
+// возвращает: ничего
+func (noopPublisher) Publish(models.AssignmentEvent) {}
+
+// публикует событие о Pull Request с текущим временем
+// принимает: тип события, PR и опционально старого и нового ревьювера для переназначения
+// возвращает: ничего, ошибки публикации не влияют на операцию
+func (s *PRService) publishEvent(eventType string, pr *models.PullRequest, oldReviewerID, newReviewerID string) {
+	reviewers := make([]string, len(pr.AssignedReviewers))
+	copy(reviewers, pr.AssignedReviewers)
+
+	s.publisher.Publish(models.AssignmentEvent{
+		Type:          eventType,
+		PullRequestID: pr.PullRequestID,
+		AuthorID:      pr.AuthorID,
+		Reviewers:     reviewers,
+		OldReviewerID: oldReviewerID,
+		NewReviewerID: newReviewerID,
+		OccurredAt:    time.Now().UTC(),
+	})
+}
+
+// публикует событие о PR, которому автоматическое назначение не смогло набрать нужное количество ревьюверов
+// принимает: PR, название команды автора и нужное количество ревьюверов
+// возвращает: ничего, событие не публикуется если ревьюверов достаточно
+func (s *PRService) publishUnderstaffed(pr *models.PullRequest, teamName string, desired int) {
+	actual := len(pr.AssignedReviewers)
+	if actual >= desired {
+		return
+	}
+
+	log.Printf("PR %s of team %s has %d of %d required reviewers", pr.PullRequestID, teamName, actual, desired)
+	reviewers := make([]string, actual)
+	copy(reviewers, pr.AssignedReviewers)
+
+	s.publisher.Publish(models.AssignmentEvent{
+		Type:             models.EventPRUnderstaffed,
+		PullRequestID:    pr.PullRequestID,
+		AuthorID:         pr.AuthorID,
+		Reviewers:        reviewers,
+		OccurredAt:       time.Now().UTC(),
+		TeamName:         teamName,
+		DesiredReviewers: &desired,
+		ActualReviewers:  &actual,
+	})
+}
+
+// проверяет укомплектованность открытого PR после изменения его ревьюверов вне создания PR
+// принимает: идентификатор PR
+// возвращает: ничего, ошибки получения PR логируются
+func (s *PRService) checkStaffing(prID string) {
+	pr, err := s.prRepo.GetPR(prID)
+	if err != nil {
+		log.Printf("Failed to get PR %s for staffing check: %v", prID, err)
+		return
+	}
+	s.publishIfUnderstaffed(pr)
+}
+
+// публикует PR_UNDERSTAFFED, если у открытого PR активных ревьюверов меньше, чем назначается при его создании;
+// как и при создании, PR команд с ручным назначением и PR во время паузы назначения не проверяются
+// принимает: PR
+// возвращает: ничего, ошибки получения пользователей логируются
+func (s *PRService) publishIfUnderstaffed(pr *models.PullRequest) {
+	if pr.Status != "OPEN" || s.pause.Paused() {
+		return
+	}
+
+	author, err := s.userRepo.GetUser(pr.AuthorID)
+	if err != nil {
+		log.Printf("Failed to get author %s of PR %s for staffing check: %v", pr.AuthorID, pr.PullRequestID, err)
+		return
+	}
+	if s.isManualAssignment(author.TeamName) {
+		return
+	}
+
+	// деактивированный ревьювер без замены остается назначенным, но ревью не проводит
+	active := make([]string, 0, len(pr.AssignedReviewers))
+	for _, reviewerID := range pr.AssignedReviewers {
+		reviewer, err := s.userRepo.GetUser(reviewerID)
+		if err != nil {
+			log.Printf("Failed to get reviewer %s of PR %s for staffing check: %v", reviewerID, pr.PullRequestID, err)
+			return
+		}
+		if reviewer.IsActive {
+			active = append(active, reviewerID)
+		}
+	}
+
+	staffed := *pr
+	staffed.AssignedReviewers = active
+	s.publishUnderstaffed(&staffed, author.TeamName, s.prReviewerCount(pr))
+}
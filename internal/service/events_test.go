@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"pull-request-reviewer-assignment-service/internal/models"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// публикатор, сохраняющий события для проверок
+type recordingPublisher struct {
+	mu     sync.Mutex
+	events []models.AssignmentEvent
+}
+
+func (p *recordingPublisher) Publish(event models.AssignmentEvent) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, event)
+}
+
+func (p *recordingPublisher) published() []models.AssignmentEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]models.AssignmentEvent(nil), p.events...)
+}
+
+func TestCreatePRPublishesEvent(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2")
+	publisher := &recordingPublisher{}
+	service := NewPRService(repo, repo, repo, NewTeamService(repo, repo), Config{}, publisher)
+
+	pr, err := service.CreatePR(&models.CreatePRRequest{PullRequestID: "pr-1", PullRequestName: "Feature", AuthorID: "author"})
+	require.NoError(t, err)
+
+	events := publisher.published()
+	require.Len(t, events, 1)
+	assert.Equal(t, models.EventPRCreated, events[0].Type)
+	assert.Equal(t, "pr-1", events[0].PullRequestID)
+	assert.Equal(t, "author", events[0].AuthorID)
+	assert.ElementsMatch(t, pr.AssignedReviewers, events[0].Reviewers)
+	assert.False(t, events[0].OccurredAt.IsZero())
+}
+
+func TestUnderstaffedCreatePRPublishesEvent(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1")
+	publisher := &recordingPublisher{}
+	service := NewPRService(repo, repo, repo, NewTeamService(repo, repo), Config{}, publisher)
+
+	_, err := service.CreatePR(&models.CreatePRRequest{PullRequestID: "pr-1", PullRequestName: "Feature", AuthorID: "author"})
+	require.NoError(t, err)
+
+	events := publisher.published()
+	require.Len(t, events, 2)
+	assert.Equal(t, models.EventPRCreated, events[0].Type)
+	assert.Equal(t, models.EventPRUnderstaffed, events[1].Type)
+	assert.Equal(t, "pr-1", events[1].PullRequestID)
+	assert.Equal(t, "backend", events[1].TeamName)
+	require.NotNil(t, events[1].DesiredReviewers)
+	require.NotNil(t, events[1].ActualReviewers)
+	assert.Equal(t, defaultReviewerCount, *events[1].DesiredReviewers)
+	assert.Equal(t, 1, *events[1].ActualReviewers)
+}
+
+func TestFullyStaffedCreatePRDoesNotPublishUnderstaffed(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3")
+	publisher := &recordingPublisher{}
+	service := NewPRService(repo, repo, repo, NewTeamService(repo, repo), Config{}, publisher)
+
+	_, err := service.CreatePR(&models.CreatePRRequest{PullRequestID: "pr-1", PullRequestName: "Feature", AuthorID: "author"})
+	require.NoError(t, err)
+
+	for _, event := range publisher.published() {
+		assert.NotEqual(t, models.EventPRUnderstaffed, event.Type)
+	}
+}
+
+func TestReopenPRPublishesUnderstaffedWhenReviewerLeft(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2")
+	repo.addPR("pr-1", "author", "u1", "u2")
+	_, err := repo.ClosePR("pr-1", time.Now())
+	require.NoError(t, err)
+	// пока PR закрыт, u2 деактивирован без переназначения, а активный u1 остается ревьювером
+	require.NoError(t, repo.SetUserActive("u2", false, ""))
+	publisher := &recordingPublisher{}
+	service := NewPRService(repo, repo, repo, NewTeamService(repo, repo), Config{}, publisher)
+
+	_, err = service.ReopenPR("pr-1")
+	require.NoError(t, err)
+
+	events := publisher.published()
+	require.Len(t, events, 2)
+	assert.Equal(t, models.EventPRReopened, events[0].Type)
+	assert.Equal(t, models.EventPRUnderstaffed, events[1].Type)
+	assert.Equal(t, []string{"u1"}, events[1].Reviewers)
+	require.NotNil(t, events[1].ActualReviewers)
+	assert.Equal(t, 1, *events[1].ActualReviewers)
+}
+
+func TestDeactivationWithoutReplacementPublishesUnderstaffed(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2")
+	repo.addPR("pr-1", "author", "u1", "u2")
+	publisher := &recordingPublisher{}
+	prService := NewPRService(repo, repo, repo, NewTeamService(repo, repo), Config{}, publisher)
+	userService := newTestUserService(repo, Config{})
+	userService.SetPRService(prService)
+
+	// в команде нет замены для u2
+	_, err := userService.BulkDeactivateUsers(context.Background(), "backend", []string{"u2"}, "")
+	require.NoError(t, err)
+
+	events := publisher.published()
+	require.Len(t, events, 1)
+	assert.Equal(t, models.EventPRUnderstaffed, events[0].Type)
+	assert.Equal(t, "pr-1", events[0].PullRequestID)
+	require.NotNil(t, events[0].ActualReviewers)
+	assert.Equal(t, 1, *events[0].ActualReviewers)
+
+	// снятие выбывшего ревьювера при синхронизации команды тоже оставляет PR без нужного числа ревьюверов
+	repo.addPR("pr-2", "author", "u1")
+	_, err = userService.SyncTeam("backend", []models.TeamMember{{UserID: "author", Username: "author", IsActive: true}})
+	require.NoError(t, err)
+
+	events = publisher.published()
+	require.Len(t, events, 3)
+	assert.Equal(t, models.EventPRUnderstaffed, events[1].Type)
+	assert.Equal(t, models.EventPRUnderstaffed, events[2].Type)
+	assert.ElementsMatch(t, []string{"pr-1", "pr-2"}, []string{events[1].PullRequestID, events[2].PullRequestID})
+	assert.Equal(t, 0, *events[2].ActualReviewers)
+}
+
+func TestDeactivationWithReplacementDoesNotPublishUnderstaffed(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3")
+	repo.addPR("pr-1", "author", "u1", "u2")
+	publisher := &recordingPublisher{}
+	prService := NewPRService(repo, repo, repo, NewTeamService(repo, repo), Config{}, publisher)
+	userService := newTestUserService(repo, Config{})
+	userService.SetPRService(prService)
+
+	_, err := userService.BulkDeactivateUsers(context.Background(), "backend", []string{"u2"}, "")
+	require.NoError(t, err)
+
+	assert.Empty(t, publisher.published())
+}
+
+func TestMergePRPublishesEventOnce(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1")
+	repo.addPR("pr-1", "author", "u1")
+	publisher := &recordingPublisher{}
+	service := NewPRService(repo, repo, repo, NewTeamService(repo, repo), Config{}, publisher)
+
+	_, err := service.MergePR("pr-1")
+	require.NoError(t, err)
+	// повторный мерж идемпотентен и не публикует событие
+	_, err = service.MergePR("pr-1")
+	require.NoError(t, err)
+
+	events := publisher.published()
+	require.Len(t, events, 1)
+	assert.Equal(t, models.EventPRMerged, events[0].Type)
+	assert.Equal(t, []string{"u1"}, events[0].Reviewers)
+}
+
+func TestReassignReviewerPublishesEvent(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2")
+	repo.addPR("pr-1", "author", "u1")
+	publisher := &recordingPublisher{}
+	service := NewPRService(repo, repo, repo, NewTeamService(repo, repo), Config{}, publisher)
+
+	_, newReviewerID, err := service.ReassignReviewer("pr-1", "u1")
+	require.NoError(t, err)
+	assert.Equal(t, "u2", newReviewerID)
+
+	events := publisher.published()
+	require.Len(t, events, 1)
+	assert.Equal(t, models.EventReviewerReassigned, events[0].Type)
+	assert.Equal(t, "u1", events[0].OldReviewerID)
+	assert.Equal(t, "u2", events[0].NewReviewerID)
+	assert.Equal(t, []string{"u2"}, events[0].Reviewers)
+}
+
+func TestFailedOperationDoesNotPublish(t *testing.T) {
+	repo := newFakeRepo()
+	publisher := &recordingPublisher{}
+	service := NewPRService(repo, repo, repo, NewTeamService(repo, repo), Config{}, publisher)
+
+	_, err := service.CreatePR(&models.CreatePRRequest{PullRequestID: "pr-1", PullRequestName: "Feature", AuthorID: "missing"})
+	require.Error(t, err)
+	assert.Empty(t, publisher.published())
+}
@@ -0,0 +1,1076 @@
+package service
+
+import (
+	"fmt"
+	"pull-request-reviewer-assignment-service/internal/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+// хранилище в памяти, реализующее интерфейсы репозиториев для unit-тестов
+type fakeRepo struct {
+	mu        sync.Mutex
+	teams     map[string]bool
+	users     map[string]*models.User
+	prs       map[string]*models.PullRequest
+	reviewers map[string][]string
+	expertise map[string][]string
+	// время последней замены ревьювера по PR, аналог журнала событий REASSIGN
+	reassignedAt map[string]time.Time
+	// время деактивации пользователей, аналог колонки deactivated_at
+	deactivatedAt map[string]time.Time
+	statusEvents  map[string][]models.UserStatusEvent
+	// журнал членства в командах
+	membershipEvents map[string][]models.TeamMembershipEvent
+	lastAssigned     map[string]time.Time
+	// стратегии, закрепленные за командами
+	teamStrategies map[string]string
+	// окна отказа от автоматического назначения
+	optOuts map[string]models.UserOptOut
+	// адреса уведомлений команд
+	teamWebhooks map[string]string
+	// группы ревьюверов пользователей
+	reviewerGroups map[string]string
+	// недельная емкость ревью пользователей и время их назначений ревьюверами, аналог pr_reviewers.assigned_at
+	weeklyCapacities map[string]int
+	assignmentTimes  map[string][]time.Time
+	// журнал назначений по PR, заполняется тестами напрямую
+	auditEvents map[string][]models.ReviewerAuditEvent
+	// вызывается перед записью назначения активных ревьюверов, чтобы тесты могли вмешаться между выбором и записью
+	beforeAssign func(prID string, reviewerIDs []string)
+	// вызывается перед применением плана синхронизации команды, чтобы тесты могли вмешаться между планом и записью
+	beforeSync func()
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{
+		teams:            make(map[string]bool),
+		users:            make(map[string]*models.User),
+		prs:              make(map[string]*models.PullRequest),
+		reviewers:        make(map[string][]string),
+		expertise:        make(map[string][]string),
+		reassignedAt:     make(map[string]time.Time),
+		deactivatedAt:    make(map[string]time.Time),
+		statusEvents:     make(map[string][]models.UserStatusEvent),
+		membershipEvents: make(map[string][]models.TeamMembershipEvent),
+		lastAssigned:     make(map[string]time.Time),
+		teamStrategies:   make(map[string]string),
+		optOuts:          make(map[string]models.UserOptOut),
+		teamWebhooks:     make(map[string]string),
+		reviewerGroups:   make(map[string]string),
+		weeklyCapacities: make(map[string]int),
+		assignmentTimes:  make(map[string][]time.Time),
+		auditEvents:      make(map[string][]models.ReviewerAuditEvent),
+	}
+}
+
+// добавляет команду с активными участниками
+func (f *fakeRepo) addTeam(teamName string, userIDs ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.teams[teamName] = true
+	for _, userID := range userIDs {
+		f.users[userID] = &models.User{UserID: userID, Username: userID, TeamName: teamName, IsActive: true}
+	}
+}
+
+// деактивирует пользователя с указанным временем деактивации
+func (f *fakeRepo) deactivateAt(userID string, deactivatedAt time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.users[userID].IsActive = false
+	f.deactivatedAt[userID] = deactivatedAt
+}
+
+// добавляет открытый PR с назначенными ревьюверами
+func (f *fakeRepo) addPR(prID, authorID string, reviewerIDs ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.prs[prID] = &models.PullRequest{PullRequestID: prID, PullRequestName: prID, AuthorID: authorID, Status: "OPEN"}
+	f.reviewers[prID] = append([]string(nil), reviewerIDs...)
+}
+
+// помечает PR как замерженный в указанное время
+func (f *fakeRepo) mergePRAt(prID string, mergedAt time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.prs[prID].Status = "MERGED"
+	f.prs[prID].MergedAt = &mergedAt
+}
+
+// заменяет время назначений пользователя ревьювером на count назначений в момент at
+func (f *fakeRepo) assignmentsAt(userID string, at time.Time, count int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.assignmentTimes[userID] = nil
+	for i := 0; i < count; i++ {
+		f.assignmentTimes[userID] = append(f.assignmentTimes[userID], at)
+	}
+}
+
+// TeamRepository
+
+func (f *fakeRepo) CreateTeam(team *models.Team) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.teams[team.TeamName] = true
+	for _, member := range team.Members {
+		f.users[member.UserID] = &models.User{
+			UserID: member.UserID, Username: member.Username, TeamName: team.TeamName, IsActive: member.IsActive,
+		}
+		f.recordJoin(member.UserID, team.TeamName)
+	}
+	return nil
+}
+
+// добавляет в журнал членства вступление пользователя в команду, вызывается под f.mu
+func (f *fakeRepo) recordJoin(userID, teamName string) {
+	f.membershipEvents[userID] = append(f.membershipEvents[userID], models.TeamMembershipEvent{
+		TeamName: teamName, EventType: models.TeamMembershipJoin, CreatedAt: time.Now(),
+	})
+}
+
+func (f *fakeRepo) GetTeam(teamName string) (*models.Team, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	team := &models.Team{TeamName: teamName}
+	for _, user := range f.sortedUsers() {
+		if user.TeamName == teamName {
+			team.Members = append(team.Members, models.TeamMember{
+				UserID: user.UserID, Username: user.Username, IsActive: user.IsActive,
+			})
+		}
+	}
+	return team, nil
+}
+
+func (f *fakeRepo) TeamExists(teamName string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.teams[teamName], nil
+}
+
+func (f *fakeRepo) GetAssignmentStrategy(teamName string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.teamStrategies[teamName], nil
+}
+
+func (f *fakeRepo) SetAssignmentStrategy(teamName, strategy string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !f.teams[teamName] {
+		return fmt.Errorf("team not found")
+	}
+	f.teamStrategies[teamName] = strategy
+	return nil
+}
+
+func (f *fakeRepo) SetWebhook(teamName, webhookURL string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if webhookURL == "" {
+		delete(f.teamWebhooks, teamName)
+		return nil
+	}
+	f.teamWebhooks[teamName] = webhookURL
+	return nil
+}
+
+func (f *fakeRepo) GetWebhookByUser(userID string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if user, ok := f.users[userID]; ok {
+		return f.teamWebhooks[user.TeamName], nil
+	}
+	return "", nil
+}
+
+func (f *fakeRepo) UpdateTeamMembers(teamName string, plan *models.TeamSyncPlan) error {
+	if f.beforeSync != nil {
+		f.beforeSync()
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, replacement := range plan.Replacements {
+		if pr, ok := f.prs[replacement.PRID]; ok && pr.Status == "OPEN" &&
+			!contains(f.reviewers[replacement.PRID], replacement.OldReviewerID) {
+			return models.ErrReviewerNotAssigned
+		}
+	}
+
+	f.applyMemberChanges(teamName, plan.Added, plan.Updated)
+	for _, replacement := range plan.Replacements {
+		if pr, ok := f.prs[replacement.PRID]; !ok || pr.Status != "OPEN" {
+			continue
+		}
+		var reviewers []string
+		for _, reviewerID := range f.reviewers[replacement.PRID] {
+			if reviewerID != replacement.OldReviewerID {
+				reviewers = append(reviewers, reviewerID)
+			}
+		}
+		if replacement.NewReviewerID != "" {
+			reviewers = append(reviewers, replacement.NewReviewerID)
+		}
+		f.reviewers[replacement.PRID] = reviewers
+	}
+	return nil
+}
+
+func (f *fakeRepo) DeleteTeam(teamName string, deletePRs bool) (*models.DeleteTeamResponse, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	members := make(map[string]bool)
+	for userID, user := range f.users {
+		if user.TeamName == teamName {
+			members[userID] = true
+		}
+	}
+	for prID, pr := range f.prs {
+		if pr.Status != "OPEN" {
+			continue
+		}
+		if members[pr.AuthorID] {
+			return nil, false, nil
+		}
+		for _, reviewerID := range f.reviewers[prID] {
+			if members[reviewerID] {
+				return nil, false, nil
+			}
+		}
+	}
+
+	if !f.teams[teamName] {
+		return nil, false, models.ErrTeamNotFound
+	}
+	if !deletePRs {
+		for _, pr := range f.prs {
+			if members[pr.AuthorID] {
+				return nil, false, models.ErrTeamHasPRs
+			}
+		}
+	}
+
+	response := &models.DeleteTeamResponse{TeamName: teamName, DeletedMembers: int64(len(members))}
+	for prID, pr := range f.prs {
+		if members[pr.AuthorID] {
+			response.DeletedPRs++
+			response.DeletedReviews += int64(len(f.reviewers[prID]))
+			delete(f.prs, prID)
+			delete(f.reviewers, prID)
+		}
+	}
+	for prID, reviewers := range f.reviewers {
+		var kept []string
+		for _, reviewerID := range reviewers {
+			if members[reviewerID] {
+				response.DeletedReviews++
+			} else {
+				kept = append(kept, reviewerID)
+			}
+		}
+		f.reviewers[prID] = kept
+	}
+	for userID := range members {
+		delete(f.users, userID)
+	}
+	delete(f.teams, teamName)
+	return response, true, nil
+}
+
+// добавляет и обновляет участников команды, вызывается под блокировкой
+func (f *fakeRepo) applyMemberChanges(teamName string, added, updated []models.TeamMember) {
+	for _, member := range added {
+		f.users[member.UserID] = &models.User{
+			UserID: member.UserID, Username: member.Username, TeamName: teamName, IsActive: member.IsActive,
+		}
+		f.recordJoin(member.UserID, teamName)
+	}
+	for _, member := range updated {
+		user := f.users[member.UserID]
+		if user.IsActive && !member.IsActive {
+			f.deactivatedAt[member.UserID] = time.Now()
+		} else if member.IsActive {
+			delete(f.deactivatedAt, member.UserID)
+		}
+		user.Username, user.IsActive = member.Username, member.IsActive
+	}
+}
+
+// UserRepository
+
+func (f *fakeRepo) CreateUser(user *models.User) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.users[user.UserID]; ok {
+		return fmt.Errorf("user %s already exists", user.UserID)
+	}
+	copied := *user
+	f.users[user.UserID] = &copied
+	f.recordJoin(user.UserID, user.TeamName)
+	return nil
+}
+
+func (f *fakeRepo) GetUser(userID string) (*models.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	user, ok := f.users[userID]
+	if !ok {
+		return nil, fmt.Errorf("user not found")
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (f *fakeRepo) UpdateUser(user *models.User) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	existing, ok := f.users[user.UserID]
+	if !ok {
+		return fmt.Errorf("user not found")
+	}
+	if existing.IsActive && !user.IsActive {
+		f.deactivatedAt[user.UserID] = time.Now()
+	} else if user.IsActive {
+		delete(f.deactivatedAt, user.UserID)
+	}
+	if existing.TeamName != user.TeamName {
+		f.membershipEvents[user.UserID] = append(f.membershipEvents[user.UserID], models.TeamMembershipEvent{
+			TeamName: existing.TeamName, EventType: models.TeamMembershipLeave, CreatedAt: time.Now(),
+		})
+		f.recordJoin(user.UserID, user.TeamName)
+	}
+	copied := *user
+	f.users[user.UserID] = &copied
+	return nil
+}
+
+func (f *fakeRepo) GetActiveUsersByTeam(teamName string) ([]*models.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var users []*models.User
+	for _, user := range f.sortedUsers() {
+		if user.TeamName == teamName && user.IsActive {
+			copied := *user
+			users = append(users, &copied)
+		}
+	}
+	return users, nil
+}
+
+func (f *fakeRepo) SetUserActive(userID string, isActive bool, actor string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	user, ok := f.users[userID]
+	if !ok {
+		return fmt.Errorf("user not found")
+	}
+	if user.IsActive == isActive {
+		return nil
+	}
+
+	user.IsActive = isActive
+	eventType := models.UserStatusDeactivate
+	if isActive {
+		eventType = models.UserStatusActivate
+		delete(f.deactivatedAt, userID)
+	} else {
+		f.deactivatedAt[userID] = time.Now()
+	}
+	f.statusEvents[userID] = append(f.statusEvents[userID], models.UserStatusEvent{
+		EventType: eventType, Actor: actor, CreatedAt: time.Now(),
+	})
+	return nil
+}
+
+func (f *fakeRepo) SetUsersActive(changes []models.UserActiveChange, actor string) error {
+	for _, change := range changes {
+		f.mu.Lock()
+		_, ok := f.users[change.UserID]
+		f.mu.Unlock()
+		if !ok {
+			return fmt.Errorf("user %s: user not found", change.UserID)
+		}
+	}
+	for _, change := range changes {
+		if err := f.SetUserActive(change.UserID, change.IsActive, actor); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fakeRepo) GetStatusEvents(userID string, from, to *time.Time, page models.Pagination) ([]models.UserStatusEvent, int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	events := make([]models.UserStatusEvent, 0)
+	for _, event := range f.statusEvents[userID] {
+		if (from == nil || !event.CreatedAt.Before(*from)) && (to == nil || event.CreatedAt.Before(*to)) {
+			events = append(events, event)
+		}
+	}
+	events, total := pageOf(events, page)
+	return events, total, nil
+}
+
+func (f *fakeRepo) GetTeamMembershipEvents(userID string) ([]models.TeamMembershipEvent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]models.TeamMembershipEvent{}, f.membershipEvents[userID]...), nil
+}
+
+func (f *fakeRepo) GetUsersDeactivatedSince(teamName string, since time.Time) ([]*models.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var users []*models.User
+	for _, user := range f.sortedUsers() {
+		deactivatedAt, ok := f.deactivatedAt[user.UserID]
+		if user.TeamName == teamName && !user.IsActive && ok && !deactivatedAt.Before(since) {
+			copied := *user
+			users = append(users, &copied)
+		}
+	}
+	return users, nil
+}
+
+func (f *fakeRepo) UserExists(userID string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	_, ok := f.users[userID]
+	return ok, nil
+}
+
+func (f *fakeRepo) SetComponentExpertise(userID string, components []string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.expertise[userID] = append([]string(nil), components...)
+	return nil
+}
+
+func (f *fakeRepo) GetComponentExperts(userIDs, components []string) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var experts []string
+	for _, userID := range userIDs {
+		for _, component := range f.expertise[userID] {
+			if contains(components, component) {
+				experts = append(experts, userID)
+				break
+			}
+		}
+	}
+	return experts, nil
+}
+
+func (f *fakeRepo) SetOptOut(userID string, until time.Time, reason string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.optOuts[userID] = models.UserOptOut{UserID: userID, Until: &until, Reason: reason}
+	return nil
+}
+
+func (f *fakeRepo) GetOptOut(userID string) (*models.UserOptOut, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	optOut, ok := f.optOuts[userID]
+	if !ok {
+		return &models.UserOptOut{UserID: userID}, nil
+	}
+	optOut.OptedOut = optOut.Until.After(time.Now())
+	return &optOut, nil
+}
+
+func (f *fakeRepo) GetOptedOutUsers(userIDs []string, at time.Time) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var optedOut []string
+	for _, userID := range userIDs {
+		if optOut, ok := f.optOuts[userID]; ok && optOut.Until.After(at) {
+			optedOut = append(optedOut, userID)
+		}
+	}
+	return optedOut, nil
+}
+
+func (f *fakeRepo) SetReviewerGroup(userID, groupID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if groupID == "" {
+		delete(f.reviewerGroups, userID)
+		return nil
+	}
+	f.reviewerGroups[userID] = groupID
+	return nil
+}
+
+func (f *fakeRepo) GetReviewerGroups(userIDs []string) (map[string]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	groups := make(map[string]string)
+	for _, userID := range userIDs {
+		if groupID, ok := f.reviewerGroups[userID]; ok {
+			groups[userID] = groupID
+		}
+	}
+	return groups, nil
+}
+
+func (f *fakeRepo) SetWeeklyCapacity(userID string, capacity *int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if capacity == nil {
+		delete(f.weeklyCapacities, userID)
+		return nil
+	}
+	f.weeklyCapacities[userID] = *capacity
+	return nil
+}
+
+func (f *fakeRepo) GetWeeklyCapacities(userIDs []string) (map[string]int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	capacities := make(map[string]int)
+	for _, userID := range userIDs {
+		if capacity, ok := f.weeklyCapacities[userID]; ok {
+			capacities[userID] = capacity
+		}
+	}
+	return capacities, nil
+}
+
+// PRRepository
+
+func (f *fakeRepo) CreatePR(pr *models.PullRequest) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	copied := *pr
+	copied.AssignedReviewers = nil
+	f.prs[pr.PullRequestID] = &copied
+	return nil
+}
+
+func (f *fakeRepo) GetPR(prID string) (*models.PullRequest, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	pr, ok := f.prs[prID]
+	if !ok {
+		return nil, fmt.Errorf("pull request not found")
+	}
+	copied := *pr
+	copied.AssignedReviewers = append([]string(nil), f.reviewers[prID]...)
+	return &copied, nil
+}
+
+func (f *fakeRepo) GetPRReviewers(prID string) ([]models.PRReviewer, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var reviewers []models.PRReviewer
+	for _, reviewerID := range f.reviewers[prID] {
+		reviewers = append(reviewers, models.PRReviewer{UserID: reviewerID})
+	}
+	return reviewers, nil
+}
+
+func (f *fakeRepo) GetPRsByIDs(prIDs []string) (map[string]*models.PullRequest, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	prs := make(map[string]*models.PullRequest)
+	for _, prID := range prIDs {
+		if pr, ok := f.prs[prID]; ok {
+			copied := *pr
+			copied.AssignedReviewers = append([]string{}, f.reviewers[prID]...)
+			prs[prID] = &copied
+		}
+	}
+	return prs, nil
+}
+
+func (f *fakeRepo) GetPRsByAuthorTeam(teamName, status string, page models.Pagination) ([]*models.PullRequest, int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var prs []*models.PullRequest
+	for _, prID := range f.sortedPRIDs() {
+		pr := f.prs[prID]
+		author, ok := f.users[pr.AuthorID]
+		if !ok || author.TeamName != teamName || (status != "" && pr.Status != status) {
+			continue
+		}
+		copied := *pr
+		copied.AssignedReviewers = append([]string{}, f.reviewers[prID]...)
+		prs = append(prs, &copied)
+	}
+	prs, total := pageOf(prs, page)
+	return prs, total, nil
+}
+
+func (f *fakeRepo) UpdatePR(pr *models.PullRequest) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.prs[pr.PullRequestID]; !ok {
+		return fmt.Errorf("pull request not found")
+	}
+	copied := *pr
+	f.prs[pr.PullRequestID] = &copied
+	return nil
+}
+
+func (f *fakeRepo) MergePR(prID string, mergedAt time.Time) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	pr, ok := f.prs[prID]
+	if !ok {
+		return false, fmt.Errorf("pull request not found")
+	}
+	if pr.Status != "OPEN" {
+		return false, nil
+	}
+	pr.Status = "MERGED"
+	pr.MergedAt = &mergedAt
+	return true, nil
+}
+
+func (f *fakeRepo) ClosePR(prID string, closedAt time.Time) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	pr, ok := f.prs[prID]
+	if !ok {
+		return false, fmt.Errorf("pull request not found")
+	}
+	if pr.Status != "OPEN" {
+		return false, nil
+	}
+	pr.Status = "CLOSED"
+	pr.ClosedAt = &closedAt
+	return true, nil
+}
+
+func (f *fakeRepo) ReopenPR(prID string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	pr, ok := f.prs[prID]
+	if !ok {
+		return false, fmt.Errorf("pull request not found")
+	}
+	if pr.Status != "CLOSED" {
+		return false, nil
+	}
+	pr.Status = "OPEN"
+	pr.ClosedAt = nil
+	return true, nil
+}
+
+func (f *fakeRepo) PRExists(prID string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	_, ok := f.prs[prID]
+	return ok, nil
+}
+
+func (f *fakeRepo) ListPRs(filter models.PRFilter) ([]*models.PullRequestShort, int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var matched []*models.PullRequest
+	for _, pr := range f.prs {
+		if (filter.Status == "" || pr.Status == filter.Status) && (filter.AuthorID == "" || pr.AuthorID == filter.AuthorID) {
+			matched = append(matched, pr)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].CreatedAt.Equal(matched[j].CreatedAt) {
+			return matched[i].CreatedAt.After(matched[j].CreatedAt)
+		}
+		return matched[i].PullRequestID < matched[j].PullRequestID
+	})
+
+	prs := []*models.PullRequestShort{}
+	for i := filter.Offset; i < len(matched) && i < filter.Offset+filter.Limit; i++ {
+		pr := matched[i]
+		prs = append(prs, &models.PullRequestShort{
+			PullRequestID: pr.PullRequestID, PullRequestName: pr.PullRequestName, AuthorID: pr.AuthorID, Status: pr.Status,
+		})
+	}
+	return prs, len(matched), nil
+}
+
+func (f *fakeRepo) GetPRsByReviewer(userID string, page models.Pagination) ([]*models.PullRequestShort, int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var prs []*models.PullRequestShort
+	for _, prID := range f.sortedPRIDs() {
+		if contains(f.reviewers[prID], userID) {
+			pr := f.prs[prID]
+			prs = append(prs, &models.PullRequestShort{
+				PullRequestID: pr.PullRequestID, PullRequestName: pr.PullRequestName,
+				AuthorID: pr.AuthorID, Status: pr.Status,
+			})
+		}
+	}
+	prs, total := pageOf(prs, page)
+	return prs, total, nil
+}
+
+func (f *fakeRepo) GetOpenPRsByReviewerCreatedBefore(userID string, before time.Time, page models.Pagination) ([]*models.BlockingPR, int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	prs := make([]*models.BlockingPR, 0)
+	for _, prID := range f.sortedPRIDs() {
+		pr := f.prs[prID]
+		if pr.Status == "OPEN" && pr.CreatedAt.Before(before) && contains(f.reviewers[prID], userID) {
+			prs = append(prs, &models.BlockingPR{
+				PullRequestID: pr.PullRequestID, PullRequestName: pr.PullRequestName,
+				AuthorID: pr.AuthorID, Status: pr.Status, CreatedAt: pr.CreatedAt,
+			})
+		}
+	}
+	sort.SliceStable(prs, func(i, j int) bool { return prs[i].CreatedAt.Before(prs[j].CreatedAt) })
+	prs, total := pageOf(prs, page)
+	return prs, total, nil
+}
+
+func (f *fakeRepo) GetRelatedPRAuthors(authorIDs, componentTags []string, since time.Time) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var authors []string
+	for _, prID := range f.sortedPRIDs() {
+		pr := f.prs[prID]
+		if !contains(authorIDs, pr.AuthorID) || contains(authors, pr.AuthorID) {
+			continue
+		}
+		recent := pr.Status == "OPEN" || (pr.MergedAt != nil && !pr.MergedAt.Before(since))
+		shared := false
+		for _, tag := range pr.ComponentTags {
+			shared = shared || contains(componentTags, tag)
+		}
+		if recent && shared {
+			authors = append(authors, pr.AuthorID)
+		}
+	}
+	sort.Strings(authors)
+	return authors, nil
+}
+
+func (f *fakeRepo) GetReviewersByAuthor(authorID, status string, page models.Pagination) ([]models.AuthorReviewer, int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	counts := make(map[string]int)
+	for _, prID := range f.sortedPRIDs() {
+		pr := f.prs[prID]
+		if pr.AuthorID != authorID || (status != "" && pr.Status != status) {
+			continue
+		}
+		for _, reviewerID := range f.reviewers[prID] {
+			counts[reviewerID]++
+		}
+	}
+
+	reviewers := make([]models.AuthorReviewer, 0, len(counts))
+	for reviewerID, count := range counts {
+		reviewers = append(reviewers, models.AuthorReviewer{UserID: reviewerID, Username: reviewerID, PRCount: count})
+	}
+	sort.Slice(reviewers, func(i, j int) bool {
+		if reviewers[i].PRCount != reviewers[j].PRCount {
+			return reviewers[i].PRCount > reviewers[j].PRCount
+		}
+		return reviewers[i].UserID < reviewers[j].UserID
+	})
+	reviewers, total := pageOf(reviewers, page)
+	return reviewers, total, nil
+}
+
+// вырезает страницу из полного списка, как LIMIT и OFFSET в запросе (limit меньше 1 - без ограничения)
+func pageOf[T any](items []T, page models.Pagination) ([]T, int) {
+	start := min(page.Offset, len(items))
+	end := len(items)
+	if page.Limit > 0 {
+		end = min(start+page.Limit, len(items))
+	}
+	return append([]T{}, items[start:end]...), len(items)
+}
+
+func (f *fakeRepo) GetRecentReviewersByAuthor(authorID string, n int) ([][]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var prs []*models.PullRequest
+	for _, pr := range f.prs {
+		if pr.AuthorID == authorID {
+			prs = append(prs, pr)
+		}
+	}
+	sort.Slice(prs, func(i, j int) bool {
+		if !prs[i].CreatedAt.Equal(prs[j].CreatedAt) {
+			return prs[i].CreatedAt.After(prs[j].CreatedAt)
+		}
+		return prs[i].PullRequestID > prs[j].PullRequestID
+	})
+	if len(prs) > n {
+		prs = prs[:n]
+	}
+
+	reviewers := make([][]string, 0, len(prs))
+	for _, pr := range prs {
+		prReviewers := append([]string{}, f.reviewers[pr.PullRequestID]...)
+		sort.Strings(prReviewers)
+		reviewers = append(reviewers, prReviewers)
+	}
+	return reviewers, nil
+}
+
+func (f *fakeRepo) DeleteFinishedPRsBefore(cutoff time.Time, limit int) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var deleted int64
+	for _, prID := range f.sortedPRIDs() {
+		pr := f.prs[prID]
+		finishedAt := pr.MergedAt
+		if pr.Status == "CLOSED" {
+			finishedAt = pr.ClosedAt
+		}
+		if int(deleted) < limit && pr.Status != "OPEN" && finishedAt != nil && finishedAt.Before(cutoff) {
+			delete(f.prs, prID)
+			delete(f.reviewers, prID)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// ReviewRepository
+
+func (f *fakeRepo) AssignReviewers(prID string, reviewerIDs []string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.reviewers[prID] = append(f.reviewers[prID], reviewerIDs...)
+	for _, reviewerID := range reviewerIDs {
+		f.assignmentTimes[reviewerID] = append(f.assignmentTimes[reviewerID], time.Now())
+	}
+	return nil
+}
+
+func (f *fakeRepo) AssignActiveReviewers(prID string, reviewerIDs []string) ([]string, error) {
+	if f.beforeAssign != nil {
+		f.beforeAssign(prID, reviewerIDs)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var inactive []string
+	for _, reviewerID := range reviewerIDs {
+		if user, ok := f.users[reviewerID]; !ok || !user.IsActive {
+			inactive = append(inactive, reviewerID)
+		}
+	}
+	if len(inactive) > 0 {
+		return inactive, nil
+	}
+
+	f.reviewers[prID] = append(f.reviewers[prID], reviewerIDs...)
+	for _, reviewerID := range reviewerIDs {
+		f.assignmentTimes[reviewerID] = append(f.assignmentTimes[reviewerID], time.Now())
+	}
+	return nil, nil
+}
+
+func (f *fakeRepo) AddReviewersIfOpen(prID string, reviewerIDs []string, maxReviewers int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if pr, ok := f.prs[prID]; ok && pr.Status != "OPEN" {
+		return models.ErrPRNotOpen
+	}
+
+	var adding []string
+	for _, reviewerID := range reviewerIDs {
+		if !contains(f.reviewers[prID], reviewerID) && !contains(adding, reviewerID) {
+			adding = append(adding, reviewerID)
+		}
+	}
+	if maxReviewers > 0 && len(f.reviewers[prID])+len(adding) > maxReviewers {
+		return models.ErrReviewerLimitReached
+	}
+
+	f.reviewers[prID] = append(f.reviewers[prID], adding...)
+	for _, reviewerID := range adding {
+		f.assignmentTimes[reviewerID] = append(f.assignmentTimes[reviewerID], time.Now())
+	}
+	return nil
+}
+
+func (f *fakeRepo) GetAssignedReviewers(prID string) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.reviewers[prID]...), nil
+}
+
+func (f *fakeRepo) ReplaceReviewerIfOpen(prID, oldReviewerID, newReviewerID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if pr, ok := f.prs[prID]; ok && pr.Status != "OPEN" {
+		return models.ErrPRNotOpen
+	}
+
+	for i, reviewerID := range f.reviewers[prID] {
+		if reviewerID == oldReviewerID {
+			f.reviewers[prID][i] = newReviewerID
+			f.reassignedAt[prID] = time.Now()
+			return nil
+		}
+	}
+	return fmt.Errorf("reviewer not assigned to this PR")
+}
+
+func (f *fakeRepo) SetReviewersIfOpen(prID string, reviewerIDs []string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if pr, ok := f.prs[prID]; ok && pr.Status != "OPEN" {
+		return models.ErrPRNotOpen
+	}
+
+	f.reviewers[prID] = append([]string(nil), reviewerIDs...)
+	f.reassignedAt[prID] = time.Now()
+	return nil
+}
+
+func (f *fakeRepo) GetLastReassignmentAt(prID string) (time.Time, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	lastAt, ok := f.reassignedAt[prID]
+	return lastAt, ok, nil
+}
+
+func (f *fakeRepo) GetAssignmentEvents(prID string, until time.Time) ([]models.ReviewerAuditEvent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var events []models.ReviewerAuditEvent
+	for _, event := range f.auditEvents[prID] {
+		if !event.CreatedAt.After(until) {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (f *fakeRepo) IsReviewerAssigned(prID, userID string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return contains(f.reviewers[prID], userID), nil
+}
+
+func (f *fakeRepo) GetOpenAssignmentCounts(userIDs []string) (map[string]int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	counts := make(map[string]int, len(userIDs))
+	for _, userID := range userIDs {
+		counts[userID] = 0
+		for prID, reviewerIDs := range f.reviewers {
+			if f.prs[prID] != nil && f.prs[prID].Status == "OPEN" && contains(reviewerIDs, userID) {
+				counts[userID]++
+			}
+		}
+	}
+	return counts, nil
+}
+
+func (f *fakeRepo) GetLastAssignedAt(userIDs []string) (map[string]time.Time, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	lastAssigned := make(map[string]time.Time)
+	for _, userID := range userIDs {
+		if assignedAt, ok := f.lastAssigned[userID]; ok {
+			lastAssigned[userID] = assignedAt
+		}
+	}
+	return lastAssigned, nil
+}
+
+func (f *fakeRepo) GetAssignmentCountsSince(userIDs []string, since time.Time) (map[string]int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	counts := make(map[string]int)
+	for _, userID := range userIDs {
+		for _, assignedAt := range f.assignmentTimes[userID] {
+			if !assignedAt.Before(since) {
+				counts[userID]++
+			}
+		}
+	}
+	return counts, nil
+}
+
+// вспомогательные методы, вызываются под мьютексом
+
+func (f *fakeRepo) sortedUsers() []*models.User {
+	users := make([]*models.User, 0, len(f.users))
+	for _, user := range f.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].UserID < users[j].UserID })
+	return users
+}
+
+func (f *fakeRepo) sortedPRIDs() []string {
+	ids := make([]string, 0, len(f.prs))
+	for id := range f.prs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"pull-request-reviewer-assignment-service/internal/models"
+	"sort"
+	"time"
+)
+
+// сравнивает количество открытых ревью пользователя с распределением нагрузки по активным участникам его команды
+// принимает: идентификатор пользователя
+// возвращает: объект UserLoadComparison или ошибку NOT_FOUND если пользователь не найден
+func (s *UserService) GetLoadComparison(userID string) (*models.UserLoadComparison, error) {
+	user, err := s.userRepo.GetUser(userID)
+	if err != nil {
+		log.Printf("User not found: %s, error: %v", userID, err)
+		return nil, NewServiceError("NOT_FOUND", "user not found")
+	}
+
+	activeUsers, err := s.userRepo.GetActiveUsersByTeam(user.TeamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active users: %w", err)
+	}
+
+	// неактивный пользователь сравнивается с активными коллегами, но сам входит в распределение
+	members := []string{userID}
+	for _, member := range activeUsers {
+		if member.UserID != userID {
+			members = append(members, member.UserID)
+		}
+	}
+
+	loads, err := s.reviewRepo.GetOpenAssignmentCounts(members)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reviewer loads: %w", err)
+	}
+
+	counts := make([]int, 0, len(members))
+	total := 0
+	below, equal := 0, 0
+	userLoad := loads[userID]
+	for _, member := range members {
+		load := loads[member]
+		counts = append(counts, load)
+		total += load
+		switch {
+		case load < userLoad:
+			below++
+		case load == userLoad:
+			equal++
+		}
+	}
+	sort.Ints(counts)
+
+	size := len(counts)
+	median := float64(counts[size/2])
+	if size%2 == 0 {
+		median = float64(counts[size/2-1]+counts[size/2]) / 2
+	}
+
+	remaining, err := remainingWeeklyCapacity(s.userRepo, s.reviewRepo, []string{userID}, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	var weeklyRemaining *int
+	if capacity, ok := remaining[userID]; ok {
+		weeklyRemaining = &capacity
+	}
+
+	return &models.UserLoadComparison{
+		UserID:         userID,
+		TeamName:       user.TeamName,
+		OpenReviews:    userLoad,
+		TeamSize:       size,
+		TeamAverage:    float64(total) / float64(size),
+		TeamMedian:     median,
+		PercentileRank: (float64(below) + float64(equal)/2) / float64(size) * 100,
+
+		WeeklyCapacityRemaining: weeklyRemaining,
+	}, nil
+}
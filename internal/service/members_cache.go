@@ -0,0 +1,63 @@
+package service
+
+import (
+	"pull-request-reviewer-assignment-service/internal/models"
+	"pull-request-reviewer-assignment-service/internal/repository"
+	"sync"
+)
+
+// снимок активных участников команд на время одной массовой операции, чтобы не запрашивать
+// состав команды заново для каждого PR; создается на операцию и не переживает ее
+type activeMembersCache struct {
+	userRepo repository.UserRepository
+	mu       sync.Mutex
+	teams    map[string][]*models.User
+}
+
+// создает пустой снимок активных участников
+// принимает: репозиторий пользователей
+// возвращает: указатель на созданный activeMembersCache
+func newActiveMembersCache(userRepo repository.UserRepository) *activeMembersCache {
+	return &activeMembersCache{
+		userRepo: userRepo,
+		teams:    make(map[string][]*models.User),
+	}
+}
+
+// возвращает активных участников команды, запрашивая их только при первом обращении
+// принимает: название команды
+// возвращает: слайс активных участников или ошибку выполнения запроса
+func (c *activeMembersCache) get(teamName string) ([]*models.User, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if members, ok := c.teams[teamName]; ok {
+		return members, nil
+	}
+	members, err := c.userRepo.GetActiveUsersByTeam(teamName)
+	if err != nil {
+		return nil, err
+	}
+	c.teams[teamName] = members
+	return members, nil
+}
+
+// убирает деактивированного участника из снимка команды, чтобы снимок не устаревал без повторного запроса
+// принимает: название команды и идентификатор участника
+// возвращает: ничего
+func (c *activeMembersCache) removeMember(teamName, userID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	members, ok := c.teams[teamName]
+	if !ok {
+		return
+	}
+	remaining := make([]*models.User, 0, len(members))
+	for _, member := range members {
+		if member.UserID != userID {
+			remaining = append(remaining, member)
+		}
+	}
+	c.teams[teamName] = remaining
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"pull-request-reviewer-assignment-service/internal/models"
+	"sort"
+)
+
+// заново выбирает ревьюверов открытого PR по действующей стратегии и ограничениям назначения,
+// как если бы PR создавался сейчас; текущие ревьюверы остаются кандидатами наравне с остальными
+// принимает: идентификатор PR
+// возвращает: объект PolicyReassignResponse с ревьюверами до и после или ошибку валидации/назначения
+func (s *PRService) ReassignByPolicy(prID string) (*models.PolicyReassignResponse, error) {
+	log.Printf("Reassigning reviewers of PR %s by current policy", prID)
+
+	if err := s.checkAssignmentNotPaused(); err != nil {
+		return nil, err
+	}
+
+	pr, err := s.prRepo.GetPR(prID)
+	if err != nil {
+		log.Printf("PR not found: %s, error: %v", prID, err)
+		return nil, NewServiceError("NOT_FOUND", "PR not found")
+	}
+	if pr.Status != "OPEN" {
+		log.Printf("Cannot reassign by policy on non-open PR: %s (%s)", prID, pr.Status)
+		return nil, notOpenError(pr.Status, "reassign on")
+	}
+
+	author, err := s.userRepo.GetUser(pr.AuthorID)
+	if err != nil {
+		log.Printf("Author not found: %s, error: %v", pr.AuthorID, err)
+		return nil, NewServiceError("NOT_FOUND", "author not found")
+	}
+
+	// количество ревьюверов определяется так же, как при создании PR
+	reviewerCount := s.reviewerCountForSize(pr.LinesChanged)
+	if pr.RequiredReviewers != nil {
+		reviewerCount = *pr.RequiredReviewers
+	}
+	if s.checkReviewerCap(0, reviewerCount) != nil {
+		reviewerCount = s.cfg.MaxReviewersPerPR
+	}
+
+	strategy, err := s.resolveStrategy(author.TeamName)
+	if err != nil {
+		return nil, err
+	}
+
+	before := append([]string{}, pr.AssignedReviewers...)
+	after, err := s.assignReviewers(pr.AuthorID, author.TeamName, reviewerCount, pr.ComponentTags, before, nil)
+	if err != nil {
+		log.Printf("Failed to assign reviewers: %v", err)
+		return nil, fmt.Errorf("failed to assign reviewers: %w", err)
+	}
+	bestEffort, err := s.fallbackReviewers(pr.AuthorID, author.TeamName, after, reviewerCount-len(after))
+	if err != nil {
+		log.Printf("Failed to assign fallback reviewers: %v", err)
+		return nil, fmt.Errorf("failed to assign reviewers: %w", err)
+	}
+	after, err = s.ensureRequiredReviewer(pr.AuthorID, author.TeamName, append(after, bestEffort...), reviewerCount)
+	if err != nil {
+		log.Printf("Failed to assign required team reviewer: %v", err)
+		return nil, fmt.Errorf("failed to assign reviewers: %w", err)
+	}
+
+	response := &models.PolicyReassignResponse{
+		PullRequestID: prID,
+		Strategy:      strategy,
+		Before:        before,
+		After:         after,
+		Changed:       !sameReviewers(before, after),
+	}
+	if !response.Changed {
+		log.Printf("Reviewers of PR %s already match policy: %v", prID, after)
+		return response, nil
+	}
+
+	if err := s.reviewRepo.SetReviewersIfOpen(prID, after); err != nil {
+		if errors.Is(err, models.ErrPRNotOpen) {
+			// PR замержен или закрыт параллельно после проверки статуса выше
+			log.Printf("PR merged or closed concurrently, reassign by policy rejected: %s", prID)
+			return nil, s.concurrentNotOpenError(prID, "reassign on")
+		}
+		log.Printf("Failed to set reviewers of PR %s: %v", prID, err)
+		return nil, fmt.Errorf("failed to set reviewers: %w", err)
+	}
+
+	// публикуем замены парами снятый/добавленный ревьювер, как при ручном переназначении
+	pr.AssignedReviewers = after
+	var removed []string
+	for _, reviewerID := range before {
+		if !contains(after, reviewerID) {
+			removed = append(removed, reviewerID)
+		}
+	}
+	for _, reviewerID := range after {
+		if contains(before, reviewerID) {
+			continue
+		}
+		oldReviewerID := ""
+		if len(removed) > 0 {
+			oldReviewerID, removed = removed[0], removed[1:]
+		}
+		s.publishEvent(models.EventReviewerReassigned, pr, oldReviewerID, reviewerID)
+	}
+
+	log.Printf("Reviewers of PR %s reassigned by policy (%s): %v -> %v", prID, strategy, before, after)
+	return response, nil
+}
+
+// проверяет, совпадают ли составы ревьюверов без учета порядка
+// принимает: два слайса идентификаторов ревьюверов
+// возвращает: true если составы совпадают
+func sameReviewers(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	sortedA := append([]string{}, a...)
+	sortedB := append([]string{}, b...)
+	sort.Strings(sortedA)
+	sort.Strings(sortedB)
+	for i := range sortedA {
+		if sortedA[i] != sortedB[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -1,12 +1,34 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"log"
-	"math/rand"
 	"pull-request-reviewer-assignment-service/internal/models"
 	"pull-request-reviewer-assignment-service/internal/repository"
+	"strings"
 	"time"
+	"unicode/utf8"
+)
+
+// количество ревьюверов, назначаемых на PR по умолчанию
+const defaultReviewerCount = 2
+
+// количество PR, удаляемых за один запрос при очистке, чтобы не держать долгие блокировки
+const purgeBatchSize = 500
+
+// размер страницы списка PR по умолчанию и его предел, защищающий базу данных от больших выборок
+const (
+	PRListDefaultLimit = 50
+	PRListMaxLimit     = 200
+)
+
+// пороги размера PR в строках для политики SizeBasedReviewers
+const (
+	// PR меньше этого размера получает одного ревьювера
+	smallPRLines = 100
+	// PR меньше этого размера получает двух ревьюверов, больше - трех
+	mediumPRLines = 500
 )
 
 // предоставляет логику для работы с Pull Request
@@ -15,29 +37,104 @@ type PRService struct {
 	reviewRepo  repository.ReviewRepository
 	userRepo    repository.UserRepository
 	teamService *TeamService
+	cfg         Config
+	rng         RandomSource
+	publisher   EventPublisher
+	// назначение ревьюверов приостановлено через /admin/pauseAssignment
+	pause *AssignmentPause
 }
 
 // создает и возвращает новый экземпляр PRService с внедренными зависимостями
-// принимает: репозитории PR, ревью, пользователей и сервис команд для инициализации
-// возвращает: указатель на созданный PRService с инициализированным генератором случайных чисел
-func NewPRService(prRepo repository.PRRepository, reviewRepo repository.ReviewRepository, userRepo repository.UserRepository, teamService *TeamService) *PRService {
-	// инициализируем генератор случайных чисел
-	rand.Seed(time.Now().UnixNano())
+// принимает: репозитории PR, ревью, пользователей, сервис команд, настройки сервиса и публикатор событий (nil - события не публикуются)
+// возвращает: указатель на созданный PRService с глобальным генератором случайных чисел
+func NewPRService(prRepo repository.PRRepository, reviewRepo repository.ReviewRepository, userRepo repository.UserRepository,
+	teamService *TeamService, cfg Config, publisher EventPublisher) *PRService {
+	if cfg.AssignmentStrategy == "" {
+		cfg.AssignmentStrategy = StrategyRandom
+	}
+	if !isKnownStrategy(cfg.AssignmentStrategy) {
+		log.Printf("Unknown assignment strategy %q, falling back to %s", cfg.AssignmentStrategy, StrategyRandom)
+		cfg.AssignmentStrategy = StrategyRandom
+	}
+	if cfg.PRNameOverflow != PRNameOverflowReject && cfg.PRNameOverflow != PRNameOverflowTruncate {
+		if cfg.PRNameOverflow != "" {
+			log.Printf("Unknown PR name overflow mode %q, falling back to %s", cfg.PRNameOverflow, PRNameOverflowReject)
+		}
+		cfg.PRNameOverflow = PRNameOverflowReject
+	}
+	if cfg.EnforceFairness && cfg.FairnessMaxDelta < 1 {
+		log.Printf("Fairness delta %d is below 1, using 1", cfg.FairnessMaxDelta)
+		cfg.FairnessMaxDelta = 1
+	}
+	if publisher == nil {
+		publisher = noopPublisher{}
+	}
 
 	return &PRService{
 		prRepo:      prRepo,
 		reviewRepo:  reviewRepo,
 		userRepo:    userRepo,
 		teamService: teamService,
+		cfg:         cfg,
+		rng:         globalRandom{},
+		publisher:   publisher,
+		pause:       &AssignmentPause{},
 	}
 }
 
 // создает новый Pull Request и автоматически назначает ревьюверов из команды автора
-// принимает: идентификатор PR, название PR и идентификатор автора для создания
+// принимает: запрос с идентификатором PR, названием, автором и опциональным требуемым числом ревьюверов
+// возвращает: указатель на созданный PullRequest или ошибку валидации/назначения
+func (s *PRService) CreatePR(req *models.CreatePRRequest) (*models.PullRequest, error) {
+	return s.createPR(req, nil)
+}
+
+// создает Pull Request так же, как CreatePR, и возвращает ход назначения ревьюверов
+// принимает: запрос на создание PR
+// возвращает: созданный PR, объект AssignmentTrace с кандидатами, исключенными участниками и выбором, или ошибку
+func (s *PRService) CreatePRWithTrace(req *models.CreatePRRequest) (*models.PullRequest, *models.AssignmentTrace, error) {
+	trace := &models.AssignmentTrace{Excluded: []models.TraceExclusion{}, Candidates: []string{}, Selected: []string{}}
+	pr, err := s.createPR(req, trace)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pr, trace, nil
+}
+
+// создает Pull Request и назначает ревьюверов, при переданном trace записывая в него ход назначения
+// принимает: запрос на создание PR и ход назначения для заполнения (nil - не записывать)
 // возвращает: указатель на созданный PullRequest или ошибку валидации/назначения
-func (s *PRService) CreatePR(prID, prName, authorID string) (*models.PullRequest, error) {
+func (s *PRService) createPR(req *models.CreatePRRequest, trace *models.AssignmentTrace) (*models.PullRequest, error) {
+	prID, prName, authorID := req.PullRequestID, req.PullRequestName, req.AuthorID
 	log.Printf("Creating PR: %s by author: %s", prID, authorID)
 
+	// проверяем размер изменений
+	if req.LinesChanged != nil && *req.LinesChanged < 0 {
+		return nil, NewServiceError("INVALID_REQUEST", "lines_changed must not be negative")
+	}
+
+	// проверяем требуемое количество ревьюверов, явно заданное значение важнее размера PR
+	reviewerCount := s.reviewerCountForSize(req.LinesChanged)
+	if req.RequiredReviewers != nil {
+		if *req.RequiredReviewers < 1 {
+			return nil, NewServiceError("INVALID_REQUEST", "required_reviewers must be at least 1")
+		}
+		reviewerCount = *req.RequiredReviewers
+	}
+	if err := s.checkReviewerCap(0, reviewerCount); err != nil {
+		if req.RequiredReviewers != nil {
+			return nil, err
+		}
+		// количество по размеру PR не должно приводить к ошибке, ограничиваем его лимитом
+		reviewerCount = s.cfg.MaxReviewersPerPR
+	}
+
+	// проверяем длину названия
+	prName, err := s.normalizePRName(prName)
+	if err != nil {
+		return nil, err
+	}
+
 	// проверяем существование PR
 	exists, err := s.prRepo.PRExists(prID)
 	if err != nil {
@@ -56,29 +153,56 @@ func (s *PRService) CreatePR(prID, prName, authorID string) (*models.PullRequest
 		return nil, NewServiceError("NOT_FOUND", "author not found")
 	}
 
-	// проверяем что автор активен
+	// проверяем что автор активен; ревьюверы для неактивного автора все равно выбираются из его команды
 	if !author.IsActive {
-		log.Printf("Author is not active: %s", authorID)
-		return nil, NewServiceError("INVALID_REQUEST", "author is not active")
+		if !s.cfg.AllowInactiveAuthor {
+			log.Printf("Author is not active: %s", authorID)
+			return nil, NewServiceError("INVALID_REQUEST", "author is not active")
+		}
+		log.Printf("Creating PR %s for inactive author %s (ALLOW_INACTIVE_AUTHOR)", prID, authorID)
 	}
 
-	// назначаем ревьюверов
-	reviewerIDs, err := s.assignReviewers(authorID, author.TeamName)
-	if err != nil {
-		log.Printf("Failed to assign reviewers: %v", err)
-		return nil, fmt.Errorf("failed to assign reviewers: %w", err)
+	// в ручном режиме PR создается без ревьюверов, их добавляют через /pullRequest/addReviewer
+	manual := s.isManualAssignment(author.TeamName)
+	if trace != nil {
+		if err := s.startTrace(trace, author, reviewerCount, manual); err != nil {
+			return nil, err
+		}
 	}
+	reviewerIDs := []string{}
+	var bestEffort []string
+	paused := s.pause.Paused()
+	if manual {
+		log.Printf("Manual assignment for team %s, creating PR %s without reviewers", author.TeamName, prID)
+	} else if paused {
+		log.Printf("Reviewer assignment is paused, creating PR %s without reviewers", prID)
+	} else {
+		reviewerIDs, bestEffort, err = s.selectReviewers(authorID, author.TeamName, reviewerCount, req.ComponentTags, trace)
+		if err != nil {
+			return nil, err
+		}
+		if trace != nil {
+			trace.BestEffort = bestEffort
+		}
 
-	log.Printf("Assigned reviewers for PR %s: %v", prID, reviewerIDs)
+		log.Printf("Assigned reviewers for PR %s: %v", prID, reviewerIDs)
+	}
+	warning := s.minReviewersWarning(prID, reviewerIDs, manual || paused)
 
 	// создаем PR
 	pr := &models.PullRequest{
-		PullRequestID:     prID,
-		PullRequestName:   prName,
-		AuthorID:          authorID,
-		Status:            "OPEN",
-		AssignedReviewers: reviewerIDs,
-		CreatedAt:         time.Now(),
+		PullRequestID:       prID,
+		PullRequestName:     prName,
+		AuthorID:            authorID,
+		Status:              "OPEN",
+		AssignedReviewers:   reviewerIDs,
+		RequiredReviewers:   req.RequiredReviewers,
+		LinesChanged:        req.LinesChanged,
+		ComponentTags:       req.ComponentTags,
+		CreatedAt:           time.Now().UTC(),
+		BestEffortReviewers: bestEffort,
+		AssignmentPaused:    paused && !manual,
+		Warning:             warning,
 	}
 
 	if err := s.prRepo.CreatePR(pr); err != nil {
@@ -88,15 +212,402 @@ func (s *PRService) CreatePR(prID, prName, authorID string) (*models.PullRequest
 
 	// назначаем ревьюверов в отдельной таблице
 	if len(reviewerIDs) > 0 {
-		if err := s.reviewRepo.AssignReviewers(prID, reviewerIDs); err != nil {
+		stored, storedBestEffort, err := s.storeReviewers(prID, author, reviewerCount, req.ComponentTags, reviewerIDs, bestEffort)
+		if err != nil {
 			log.Printf("Failed to assign reviewers to PR: %s, error: %v", prID, err)
+			stored, storedBestEffort = []string{}, nil
+		}
+		// при повторном выборе ревьюверов их может стать меньше
+		if len(stored) != len(reviewerIDs) {
+			pr.Warning = s.minReviewersWarning(prID, stored, false)
+		}
+		pr.AssignedReviewers, pr.BestEffortReviewers = stored, storedBestEffort
+	}
+
+	log.Printf("PR created successfully: %s with %d reviewers", prID, len(pr.AssignedReviewers))
+	s.publishEvent(models.EventPRCreated, pr, "", "")
+	if !manual && !paused {
+		s.publishUnderstaffed(pr, author.TeamName, reviewerCount)
+	}
+	return pr, nil
+}
+
+// выбирает ревьюверов для PR: автоматическое назначение из команды автора, добор недавно деактивированных
+// при нехватке активных и ревьювер из обязательной команды автора
+// принимает: автора, его команду, нужное количество ревьюверов, компоненты PR и ход назначения (nil - не записывать)
+// возвращает: выбранных ревьюверов, из них назначенных в режиме best-effort, или ошибку назначения
+func (s *PRService) selectReviewers(authorID, teamName string, reviewerCount int, componentTags []string,
+	trace *models.AssignmentTrace) ([]string, []string, error) {
+	reviewerIDs, err := s.assignReviewers(authorID, teamName, reviewerCount, componentTags, nil, trace)
+	if err != nil {
+		log.Printf("Failed to assign reviewers: %v", err)
+		return nil, nil, fmt.Errorf("failed to assign reviewers: %w", err)
+	}
+
+	// при нехватке активных ревьюверов добираем недавно деактивированных
+	bestEffort, err := s.fallbackReviewers(authorID, teamName, reviewerIDs, reviewerCount-len(reviewerIDs))
+	if err != nil {
+		log.Printf("Failed to assign fallback reviewers: %v", err)
+		return nil, nil, fmt.Errorf("failed to assign reviewers: %w", err)
+	}
+	reviewerIDs = models.DedupeIDs(append(reviewerIDs, bestEffort...))
+
+	// гарантируем ревьювера из обязательной команды автора
+	reviewerIDs, err = s.ensureRequiredReviewer(authorID, teamName, reviewerIDs, reviewerCount)
+	if err != nil {
+		log.Printf("Failed to assign required team reviewer: %v", err)
+		return nil, nil, fmt.Errorf("failed to assign reviewers: %w", err)
+	}
+	return reviewerIDs, bestEffort, nil
+}
+
+// записывает выбранных ревьюверов PR. Активность выбранных ревьюверов перепроверяется в транзакции назначения:
+// если кто-то из них деактивирован между выбором и записью, ревьюверы выбираются заново, не больше AssignmentRetries раз
+// принимает: идентификатор PR, автора, нужное количество ревьюверов, компоненты PR, выбранных ревьюверов и best-effort из них
+// возвращает: назначенных ревьюверов и best-effort из них или ошибку назначения, в том числе после исчерпания повторов
+func (s *PRService) storeReviewers(prID string, author *models.User, reviewerCount int, componentTags,
+	reviewerIDs, bestEffort []string) ([]string, []string, error) {
+	for attempt := 0; ; attempt++ {
+		if err := s.checkReviewerCap(0, len(reviewerIDs)); err != nil {
+			return nil, nil, err
 		}
+
+		// ревьюверы best-effort уже неактивны, проверка активности к ним не применяется
+		inactive, err := s.reviewRepo.AssignActiveReviewers(prID, subtractIDs(reviewerIDs, bestEffort))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to assign reviewers: %w", err)
+		}
+		if len(inactive) == 0 {
+			break
+		}
+		if attempt >= s.cfg.AssignmentRetries {
+			return nil, nil, fmt.Errorf("reviewers %v became inactive during assignment, %d retries exhausted", inactive, attempt)
+		}
+
+		log.Printf("Warning: reviewers %v of PR %s became inactive before assignment, selecting again (retry %d of %d)",
+			inactive, prID, attempt+1, s.cfg.AssignmentRetries)
+		reviewerIDs, bestEffort, err = s.selectReviewers(author.UserID, author.TeamName, reviewerCount, componentTags, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if len(bestEffort) > 0 {
+		if err := s.reviewRepo.AssignReviewers(prID, bestEffort); err != nil {
+			return nil, nil, fmt.Errorf("failed to assign reviewers: %w", err)
+		}
+	}
+	return reviewerIDs, bestEffort, nil
+}
+
+// проверяет, что на PR назначено не меньше MinReviewers политики ревьюверов
+// принимает: идентификатор PR, назначенных ревьюверов и признак того, что автоматическое назначение не выполнялось
+// возвращает: предупреждение NO_CANDIDATE при нехватке кандидатов или nil
+func (s *PRService) minReviewersWarning(prID string, reviewerIDs []string, skipped bool) *models.ErrorDetail {
+	minReviewers := s.cfg.ReviewerPolicy.MinReviewers
+	if skipped || len(reviewerIDs) >= minReviewers {
+		return nil
+	}
+
+	log.Printf("Warning: PR %s has %d reviewers, fewer than the minimum of %d", prID, len(reviewerIDs), minReviewers)
+	return &models.ErrorDetail{
+		Code:    "NO_CANDIDATE",
+		Message: fmt.Sprintf("only %d of at least %d reviewers available", len(reviewerIDs), minReviewers),
+	}
+}
+
+// начинает запись хода назначения: команда, нужное количество ревьюверов, автор и неактивные участники
+// принимает: ход назначения, автора PR, нужное количество ревьюверов и признак ручного режима
+// возвращает: ошибку получения состава команды
+func (s *PRService) startTrace(trace *models.AssignmentTrace, author *models.User, reviewerCount int, manual bool) error {
+	trace.TeamName = author.TeamName
+	trace.DesiredReviewers = reviewerCount
+	trace.ManualAssignment = manual
+	trace.Excluded = append(trace.Excluded, models.TraceExclusion{UserID: author.UserID, Reason: models.TraceExcludedAuthor})
+
+	team, err := s.teamService.loadTeam(author.TeamName)
+	if err != nil {
+		return err
+	}
+	for _, member := range team.Members {
+		if !member.IsActive && member.UserID != author.UserID {
+			trace.Excluded = append(trace.Excluded, models.TraceExclusion{UserID: member.UserID, Reason: models.TraceExcludedInactive})
+		}
+	}
+	return nil
+}
+
+// проверяет, отключено ли автоматическое назначение ревьюверов для команды
+// принимает: название команды
+// возвращает: true если включен глобальный ручной режим или команда указана в ManualAssignmentTeams
+func (s *PRService) isManualAssignment(teamName string) bool {
+	return s.cfg.ManualAssignment || contains(s.cfg.ManualAssignmentTeams, teamName)
+}
+
+// вручную добавляет ревьювера в открытый Pull Request
+// принимает: идентификатор PR и идентификатор добавляемого пользователя
+// возвращает: обновленный PR или ошибку валидации, превышения лимита ревьюверов или назначения
+func (s *PRService) AddReviewer(prID, userID string) (*models.PullRequest, error) {
+	log.Printf("Adding reviewer %s to PR: %s", userID, prID)
+
+	if err := s.checkAssignmentNotPaused(); err != nil {
+		return nil, err
+	}
+
+	pr, err := s.prRepo.GetPR(prID)
+	if err != nil {
+		log.Printf("PR not found: %s, error: %v", prID, err)
+		return nil, NewServiceError("NOT_FOUND", "PR not found")
+	}
+	if pr.Status != "OPEN" {
+		log.Printf("Cannot add reviewer to non-open PR: %s (%s)", prID, pr.Status)
+		return nil, notOpenError(pr.Status, "add reviewer to")
+	}
+
+	user, err := s.userRepo.GetUser(userID)
+	if err != nil {
+		log.Printf("User not found: %s, error: %v", userID, err)
+		return nil, NewServiceError("NOT_FOUND", "user not found")
+	}
+	if !user.IsActive {
+		return nil, NewServiceError("INVALID_REQUEST", "user is not active")
+	}
+	if userID == pr.AuthorID {
+		return nil, NewServiceError("INVALID_REQUEST", "author cannot review own PR")
+	}
+	if contains(pr.AssignedReviewers, userID) {
+		return nil, NewServiceError("ALREADY_ASSIGNED", "user is already a reviewer of this PR")
+	}
+
+	if err := s.addReviewers(prID, []string{userID}); err != nil {
+		return nil, err
+	}
+	pr.AssignedReviewers = append(pr.AssignedReviewers, userID)
+
+	log.Printf("Reviewer %s added to PR: %s", userID, prID)
+	return pr, nil
+}
+
+// назначает ревьюверов на PR, не позволяя превысить MAX_REVIEWERS_PER_PR;
+// все пути, добавляющие ревьюверов в PR, должны проходить через этот метод. Статус PR и количество
+// ревьюверов проверяются хранилищем под блокировкой строки PR в той же транзакции, что и вставка
+// принимает: идентификатор PR и новых ревьюверов (уже назначенные пропускаются)
+// возвращает: ошибку PR_MERGED/PR_CLOSED если PR уже не открыт, INVALID_REQUEST при превышении лимита или ошибку назначения
+func (s *PRService) addReviewers(prID string, reviewerIDs []string) error {
+	err := s.reviewRepo.AddReviewersIfOpen(prID, models.DedupeIDs(reviewerIDs), s.cfg.MaxReviewersPerPR)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, models.ErrPRNotOpen):
+		log.Printf("Cannot add reviewers to PR %s: it is no longer open", prID)
+		return s.concurrentNotOpenError(prID, "add reviewer to")
+	case errors.Is(err, models.ErrReviewerLimitReached):
+		log.Printf("Reviewer cap reached for PR %s: %d to add", prID, len(reviewerIDs))
+		return NewServiceError("INVALID_REQUEST",
+			fmt.Sprintf("PR cannot have more than %d reviewers", s.cfg.MaxReviewersPerPR))
+	default:
+		return fmt.Errorf("failed to assign reviewers: %w", err)
+	}
+}
+
+// возвращает ошибку действия над неоткрытым PR с указанием его фактического статуса
+// принимает: статус PR и действие для сообщения (например "reassign on")
+// возвращает: ServiceError PR_CLOSED для закрытого PR, иначе PR_MERGED
+func notOpenError(status, action string) error {
+	if status == "CLOSED" {
+		return NewServiceError("PR_CLOSED", fmt.Sprintf("cannot %s closed PR", action))
+	}
+	return NewServiceError("PR_MERGED", fmt.Sprintf("cannot %s merged PR", action))
+}
+
+// возвращает ошибку для PR, который замержили или закрыли параллельно после проверки статуса
+// принимает: идентификатор PR и действие для сообщения
+// возвращает: ServiceError PR_CLOSED или PR_MERGED по текущему статусу PR
+func (s *PRService) concurrentNotOpenError(prID, action string) error {
+	pr, err := s.prRepo.GetPR(prID)
+	if err != nil {
+		log.Printf("Failed to read status of PR %s: %v", prID, err)
+		return notOpenError("", action)
+	}
+	return notOpenError(pr.Status, action)
+}
+
+// проверяет, что количество ревьюверов PR после добавления не превысит лимит
+// принимает: текущее количество ревьюверов и количество добавляемых
+// возвращает: ошибку INVALID_REQUEST при превышении лимита или nil
+func (s *PRService) checkReviewerCap(assigned, adding int) error {
+	if s.cfg.MaxReviewersPerPR <= 0 || assigned+adding <= s.cfg.MaxReviewersPerPR {
+		return nil
+	}
+	return NewServiceError("INVALID_REQUEST",
+		fmt.Sprintf("PR cannot have more than %d reviewers", s.cfg.MaxReviewersPerPR))
+}
+
+// возвращает Pull Request с текущими ревьюверами по идентификатору
+// принимает: идентификатор Pull Request
+// возвращает: указатель на PullRequest или ошибку NOT_FOUND если PR не существует
+func (s *PRService) GetPR(prID string) (*models.PullRequest, error) {
+	pr, err := s.prRepo.GetPR(prID)
+	if err != nil {
+		log.Printf("PR not found: %s, error: %v", prID, err)
+		return nil, NewServiceError("NOT_FOUND", "PR not found")
+	}
+	return pr, nil
+}
+
+// возвращает Pull Request с ревьюверами и временем их назначения
+// принимает: идентификатор Pull Request
+// возвращает: указатель на PullRequest с заполненным Reviewers или ошибку NOT_FOUND если PR не существует
+func (s *PRService) GetPRWithReviewers(prID string) (*models.PullRequest, error) {
+	pr, err := s.GetPR(prID)
+	if err != nil {
+		return nil, err
 	}
 
-	log.Printf("PR created successfully: %s with %d reviewers", prID, len(reviewerIDs))
+	reviewers, err := s.prRepo.GetPRReviewers(prID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PR reviewers: %w", err)
+	}
+	pr.Reviewers = reviewers
 	return pr, nil
 }
 
+// проверяет, назначен ли пользователь ревьювером PR
+// принимает: идентификатор PR и идентификатор пользователя
+// возвращает: объект ReviewerCheck с признаком назначения и ролью или ошибку NOT_FOUND если PR не существует
+func (s *PRService) CheckReviewer(prID, userID string) (*models.ReviewerCheck, error) {
+	exists, err := s.prRepo.PRExists(prID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check PR existence: %w", err)
+	}
+	if !exists {
+		return nil, NewServiceError("NOT_FOUND", "PR not found")
+	}
+
+	assigned, err := s.reviewRepo.IsReviewerAssigned(prID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check reviewer assignment: %w", err)
+	}
+
+	check := &models.ReviewerCheck{PullRequestID: prID, UserID: userID, Assigned: assigned, Role: models.ReviewerRoleNone}
+	if assigned {
+		check.Role = models.ReviewerRolePrimary
+	}
+	return check, nil
+}
+
+// возвращает Pull Request с текущими ревьюверами для списка идентификаторов одним запросом к хранилищу
+// принимает: слайс идентификаторов Pull Request
+// возвращает: карту идентификатора на PR (nil для отсутствующих) или ошибку валидации/получения данных
+func (s *PRService) BatchGetPRs(prIDs []string) (map[string]*models.PullRequest, error) {
+	if len(prIDs) == 0 {
+		return nil, NewServiceError("INVALID_REQUEST", "pull_request_ids must not be empty")
+	}
+	if s.cfg.MaxBatchSize > 0 && len(prIDs) > s.cfg.MaxBatchSize {
+		return nil, NewServiceError("INVALID_REQUEST",
+			fmt.Sprintf("at most %d pull_request_ids are allowed per request", s.cfg.MaxBatchSize))
+	}
+
+	found, err := s.prRepo.GetPRsByIDs(prIDs)
+	if err != nil {
+		log.Printf("Failed to batch get PRs: %v", err)
+		return nil, fmt.Errorf("failed to get PRs: %w", err)
+	}
+
+	result := make(map[string]*models.PullRequest, len(prIDs))
+	for _, prID := range prIDs {
+		result[prID] = found[prID]
+	}
+
+	log.Printf("Batch get: %d requested, %d found", len(prIDs), len(found))
+	return result, nil
+}
+
+// возвращает страницу Pull Request от новых к старым с фильтрами по статусу и автору
+// принимает: фильтр со статусом (OPEN, MERGED, CLOSED или пустая строка), автором, limit (меньше 1 - PRListDefaultLimit,
+// больше PRListMaxLimit ограничивается им) и offset
+// возвращает: PR страницы, страницу с общим количеством PR под фильтром или ошибку INVALID_REQUEST
+func (s *PRService) ListPRs(filter models.PRFilter) ([]*models.PullRequestShort, models.Pagination, error) {
+	if filter.Status != "" && !contains(prStatuses, filter.Status) {
+		return nil, models.Pagination{}, NewServiceError("INVALID_REQUEST", "status must be one of "+strings.Join(prStatuses, ", "))
+	}
+	if filter.Offset < 0 {
+		return nil, models.Pagination{}, NewServiceError("INVALID_REQUEST", "offset must be a non-negative integer")
+	}
+	if filter.Limit < 1 {
+		filter.Limit = PRListDefaultLimit
+	}
+	filter.Limit = min(filter.Limit, PRListMaxLimit)
+
+	prs, total, err := s.prRepo.ListPRs(filter)
+	if err != nil {
+		return nil, models.Pagination{}, fmt.Errorf("failed to list PRs: %w", err)
+	}
+
+	page := listPage(models.Pagination{Limit: filter.Limit, Offset: filter.Offset}, total, len(prs))
+	log.Printf("Listed %d of %d PRs (status %q, author %q)", len(prs), total, filter.Status, filter.AuthorID)
+	return prs, page, nil
+}
+
+// заполняет метаданные страницы, выбранной в базе данных
+// принимает: запрошенную страницу, общее количество элементов и количество элементов на странице
+// возвращает: страницу с total и has_more
+func listPage(page models.Pagination, total, count int) models.Pagination {
+	page.Total = total
+	page.HasMore = page.Offset+count < total
+	return page
+}
+
+// возвращает страницу Pull Request, авторы которых состоят в команде, от новых к старым
+// принимает: название команды, статус PR (OPEN, MERGED, CLOSED или пустая строка для всех) и страницу
+// возвращает: PR страницы с ревьюверами, страницу с общим количеством или ошибку NOT_FOUND/INVALID_REQUEST
+func (s *PRService) GetTeamAuthoredPRs(teamName, status string, page models.Pagination) ([]*models.PullRequest, models.Pagination, error) {
+	if status != "" && !contains(prStatuses, status) {
+		return nil, page, NewServiceError("INVALID_REQUEST", "status must be one of "+strings.Join(prStatuses, ", "))
+	}
+	if err := s.teamService.ensureTeamExists(teamName); err != nil {
+		return nil, page, err
+	}
+
+	prs, total, err := s.prRepo.GetPRsByAuthorTeam(teamName, status, page)
+	if err != nil {
+		log.Printf("Failed to get PRs authored by team %s: %v", teamName, err)
+		return nil, page, fmt.Errorf("failed to get team authored PRs: %w", err)
+	}
+	if prs == nil {
+		prs = []*models.PullRequest{}
+	}
+	return prs, listPage(page, total, len(prs)), nil
+}
+
+// удаляет замерженные и закрытые без мержа Pull Request старше периода хранения вместе с их ревьюверами
+// принимает: период хранения после мержа или закрытия
+// возвращает: объект PurgeResponse с количеством удаленных PR или ошибку; открытые PR никогда не удаляются
+func (s *PRService) PurgeMergedPRs(olderThan time.Duration) (*models.PurgeResponse, error) {
+	if olderThan <= 0 {
+		return nil, NewServiceError("INVALID_REQUEST", "older_than must be positive")
+	}
+
+	cutoff := time.Now().Add(-olderThan)
+	log.Printf("Purging PRs merged or closed before %v", cutoff)
+
+	var purged int64
+	for {
+		deleted, err := s.prRepo.DeleteFinishedPRsBefore(cutoff, purgeBatchSize)
+		if err != nil {
+			log.Printf("Failed to purge finished PRs after %d deleted: %v", purged, err)
+			return nil, fmt.Errorf("failed to purge finished PRs: %w", err)
+		}
+		purged += deleted
+		if deleted < purgeBatchSize {
+			break
+		}
+	}
+
+	log.Printf("Purged %d merged or closed PRs", purged)
+	return &models.PurgeResponse{PurgedCount: purged}, nil
+}
+
 // помечает Pull Request как MERGED (идемпотентная операция)
 // принимает: идентификатор Pull Request для выполнения операции мержа
 // возвращает: обновленный объект PullRequest или ошибку если PR не найден или не может быть мержен
@@ -123,23 +634,252 @@ func (s *PRService) MergePR(prID string) (*models.PullRequest, error) {
 		return nil, NewServiceError("INVALID_REQUEST", "cannot merge PR that is not open")
 	}
 
-	// обновляем статус и время мержа
-	now := time.Now()
-	pr.Status = "MERGED"
-	pr.MergedAt = &now
+	// проверяем наличие ревьювера из обязательной команды автора
+	if err := s.checkRequiredReviewer(pr); err != nil {
+		return nil, err
+	}
 
-	// сохраняем изменения
-	if err := s.prRepo.UpdatePR(pr); err != nil {
+	// меняем статус под блокировкой строки PR, чтобы мерж не пересекся с заменой ревьювера
+	now := time.Now().UTC()
+	merged, err := s.prRepo.MergePR(prID, now)
+	if err != nil {
 		log.Printf("Failed to merge PR: %s, error: %v", prID, err)
 		return nil, fmt.Errorf("failed to merge PR: %w", err)
 	}
 
+	if !merged {
+		// PR замержен параллельным запросом - возвращаем текущее состояние
+		log.Printf("PR merged concurrently: %s, returning current state", prID)
+		pr, err = s.prRepo.GetPR(prID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get PR: %w", err)
+		}
+		return pr, nil
+	}
+
+	pr.Status = "MERGED"
+	pr.MergedAt = &now
+
 	log.Printf("PR merged successfully: %s at %v", prID, now)
+	s.publishEvent(models.EventPRMerged, pr, "", "")
+	return pr, nil
+}
+
+// помечает Pull Request как CLOSED без мержа (идемпотентная операция)
+// принимает: идентификатор Pull Request для закрытия
+// возвращает: обновленный объект PullRequest или ошибку NOT_FOUND, INVALID_REQUEST для замерженного PR
+func (s *PRService) ClosePR(prID string) (*models.PullRequest, error) {
+	log.Printf("Closing PR: %s", prID)
+
+	pr, err := s.prRepo.GetPR(prID)
+	if err != nil {
+		log.Printf("PR not found: %s, error: %v", prID, err)
+		return nil, NewServiceError("NOT_FOUND", "PR not found")
+	}
+
+	if pr.Status == "CLOSED" {
+		log.Printf("PR already closed: %s, returning current state", prID)
+		return pr, nil
+	}
+	if pr.Status != "OPEN" {
+		log.Printf("PR is not open: %s, status: %s", prID, pr.Status)
+		return nil, NewServiceError("INVALID_REQUEST", "cannot close PR that is merged")
+	}
+
+	now := time.Now().UTC()
+	closed, err := s.prRepo.ClosePR(prID, now)
+	if err != nil {
+		log.Printf("Failed to close PR: %s, error: %v", prID, err)
+		return nil, fmt.Errorf("failed to close PR: %w", err)
+	}
+
+	if !closed {
+		// статус изменен параллельным запросом: закрытие идемпотентно, а мерж делает закрытие невозможным
+		pr, err = s.prRepo.GetPR(prID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get PR: %w", err)
+		}
+		if pr.Status != "CLOSED" {
+			log.Printf("PR changed concurrently: %s, status: %s", prID, pr.Status)
+			return nil, NewServiceError("INVALID_REQUEST", "cannot close PR that is merged")
+		}
+		log.Printf("PR closed concurrently: %s, returning current state", prID)
+		return pr, nil
+	}
+
+	pr.Status = "CLOSED"
+	pr.ClosedAt = &now
+
+	log.Printf("PR closed successfully: %s at %v", prID, now)
+	s.publishEvent(models.EventPRClosed, pr, "", "")
+	return pr, nil
+}
+
+// возвращает закрытый Pull Request в статус OPEN (идемпотентная операция); если среди назначенных ревьюверов
+// не осталось активных, ревьюверы назначаются заново так же, как при создании PR
+// принимает: идентификатор Pull Request
+// возвращает: обновленный объект PullRequest или ошибку NOT_FOUND, PR_MERGED для замерженного PR или ошибку назначения
+func (s *PRService) ReopenPR(prID string) (*models.PullRequest, error) {
+	log.Printf("Reopening PR: %s", prID)
+
+	pr, err := s.prRepo.GetPR(prID)
+	if err != nil {
+		log.Printf("PR not found: %s, error: %v", prID, err)
+		return nil, NewServiceError("NOT_FOUND", "PR not found")
+	}
+
+	if pr.Status == "OPEN" {
+		log.Printf("PR already open: %s, returning current state", prID)
+		return pr, nil
+	}
+	if pr.Status == "MERGED" {
+		log.Printf("Cannot reopen merged PR: %s", prID)
+		return nil, NewServiceError("PR_MERGED", "cannot reopen merged PR")
+	}
+
+	reopened, err := s.prRepo.ReopenPR(prID)
+	if err != nil {
+		log.Printf("Failed to reopen PR: %s, error: %v", prID, err)
+		return nil, fmt.Errorf("failed to reopen PR: %w", err)
+	}
+	if !reopened {
+		// статус изменен параллельным запросом
+		pr, err = s.prRepo.GetPR(prID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get PR: %w", err)
+		}
+		if pr.Status == "MERGED" {
+			return nil, NewServiceError("PR_MERGED", "cannot reopen merged PR")
+		}
+		log.Printf("PR reopened concurrently: %s, returning current state", prID)
+		return pr, nil
+	}
+
+	pr.Status = "OPEN"
+	pr.ClosedAt = nil
+
+	if err := s.reassignStaleReviewers(pr); err != nil {
+		return nil, err
+	}
+
+	log.Printf("PR reopened successfully: %s with %d reviewers", prID, len(pr.AssignedReviewers))
+	s.publishEvent(models.EventPRReopened, pr, "", "")
+	s.publishIfUnderstaffed(pr)
 	return pr, nil
 }
 
-// assignReviewers назначает до 2 активных ревьюверов из команды автора
-func (s *PRService) assignReviewers(authorID, teamName string) ([]string, error) {
+// назначает ревьюверов заново, если среди назначенных на PR нет ни одного активного: пока PR был закрыт,
+// его ревьюверов не переназначали при деактивации. Неактивные ревьюверы заменяются выбранными
+// принимает: открытый PR с назначенными ревьюверами (обновляется на месте)
+// возвращает: ошибку получения данных или назначения
+func (s *PRService) reassignStaleReviewers(pr *models.PullRequest) error {
+	for _, reviewerID := range pr.AssignedReviewers {
+		reviewer, err := s.userRepo.GetUser(reviewerID)
+		if err != nil {
+			return fmt.Errorf("failed to get reviewer %s: %w", reviewerID, err)
+		}
+		if reviewer.IsActive {
+			return nil
+		}
+	}
+
+	author, err := s.userRepo.GetUser(pr.AuthorID)
+	if err != nil {
+		return fmt.Errorf("failed to get author: %w", err)
+	}
+	if s.isManualAssignment(author.TeamName) || s.pause.Paused() {
+		log.Printf("Automatic assignment is off, reopened PR %s keeps its reviewers", pr.PullRequestID)
+		return nil
+	}
+
+	reviewerCount := s.prReviewerCount(pr)
+	reviewerIDs, _, err := s.selectReviewers(pr.AuthorID, author.TeamName, reviewerCount, pr.ComponentTags, nil)
+	if err != nil {
+		return err
+	}
+	if len(reviewerIDs) == 0 {
+		log.Printf("Warning: no active reviewers available for reopened PR %s", pr.PullRequestID)
+		return nil
+	}
+
+	if err := s.reviewRepo.SetReviewersIfOpen(pr.PullRequestID, reviewerIDs); err != nil {
+		log.Printf("Failed to reassign reviewers of reopened PR %s: %v", pr.PullRequestID, err)
+		return fmt.Errorf("failed to assign reviewers: %w", err)
+	}
+	log.Printf("Reassigned reviewers of reopened PR %s: %v -> %v", pr.PullRequestID, pr.AssignedReviewers, reviewerIDs)
+	pr.AssignedReviewers = reviewerIDs
+	pr.Warning = s.minReviewersWarning(pr.PullRequestID, reviewerIDs, false)
+	return nil
+}
+
+// возвращает нужное количество ревьюверов существующего PR так же, как при его создании
+// принимает: PR
+// возвращает: required_reviewers или количество по размеру PR, ограниченное MaxReviewersPerPR
+func (s *PRService) prReviewerCount(pr *models.PullRequest) int {
+	reviewerCount := s.reviewerCountForSize(pr.LinesChanged)
+	if pr.RequiredReviewers != nil {
+		reviewerCount = *pr.RequiredReviewers
+	}
+	if s.checkReviewerCap(0, reviewerCount) != nil {
+		reviewerCount = s.cfg.MaxReviewersPerPR
+	}
+	return reviewerCount
+}
+
+// возвращает количество ревьюверов для PR с учетом его размера
+// принимает: количество измененных строк (nil если не указано)
+// возвращает: количество ревьюверов по порогам размера при включенной политике, иначе значение по умолчанию
+func (s *PRService) reviewerCountForSize(linesChanged *int) int {
+	if !s.cfg.SizeBasedReviewers || linesChanged == nil {
+		return s.policyReviewerCount()
+	}
+
+	switch {
+	case *linesChanged < smallPRLines:
+		return 1
+	case *linesChanged < mediumPRLines:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// возвращает количество ревьюверов по умолчанию из ReviewerPolicy
+// принимает: ничего
+// возвращает: MaxReviewers политики или defaultReviewerCount, если политика не задана
+func (s *PRService) policyReviewerCount() int {
+	if s.cfg.ReviewerPolicy.MaxReviewers < 1 {
+		return defaultReviewerCount
+	}
+	return s.cfg.ReviewerPolicy.MaxReviewers
+}
+
+// проверяет длину названия PR и при необходимости обрезает его согласно настройкам
+// принимает: название PR
+// возвращает: допустимое название или ошибку INVALID_REQUEST если название слишком длинное
+func (s *PRService) normalizePRName(name string) (string, error) {
+	limit := s.cfg.MaxPRNameLength
+	if limit <= 0 || utf8.RuneCountInString(name) <= limit {
+		return name, nil
+	}
+
+	if s.cfg.PRNameOverflow == PRNameOverflowTruncate {
+		truncated := string([]rune(name)[:limit])
+		log.Printf("PR name exceeds %d characters, truncating: %q", limit, truncated)
+		return truncated, nil
+	}
+
+	return "", NewServiceError("INVALID_REQUEST",
+		fmt.Sprintf("pull_request_name must be at most %d characters", limit))
+}
+
+// назначает до reviewerCount активных ревьюверов из команды автора
+// принимает: идентификатор автора, название команды, желаемое количество ревьюверов, компоненты PR
+// текущих ревьюверов PR при повторном выборе (их назначение на этот PR не учитывается в нагрузке)
+// и ход назначения для записи кандидатов и выбора (nil - не записывать)
+// возвращает: слайс выбранных ревьюверов (не больше числа доступных кандидатов) или ошибку
+func (s *PRService) assignReviewers(authorID, teamName string, reviewerCount int, componentTags, released []string,
+	trace *models.AssignmentTrace) ([]string, error) {
 	log.Printf("Assigning reviewers for author: %s from team: %s", authorID, teamName)
 
 	// получаем активных пользователей команды
@@ -158,6 +898,20 @@ func (s *PRService) assignReviewers(authorID, teamName string) ([]string, error)
 		}
 	}
 
+	activeCandidates := models.DedupeIDs(candidateUserIDs)
+	candidateUserIDs, err = s.excludeOptedOut(activeCandidates)
+	if err != nil {
+		return nil, err
+	}
+	if trace != nil {
+		for _, userID := range activeCandidates {
+			if !contains(candidateUserIDs, userID) {
+				trace.Excluded = append(trace.Excluded, models.TraceExclusion{UserID: userID, Reason: models.TraceExcludedOptedOut})
+			}
+		}
+		trace.Candidates = append(trace.Candidates, candidateUserIDs...)
+	}
+
 	log.Printf("Available reviewers (excluding author): %v", candidateUserIDs)
 
 	if len(candidateUserIDs) == 0 {
@@ -165,24 +919,294 @@ func (s *PRService) assignReviewers(authorID, teamName string) ([]string, error)
 		return []string{}, nil
 	}
 
-	// выбираем до 2 случайных ревьюверов
-	reviewerCount := min(2, len(candidateUserIDs))
-	selectedReviewers := make([]string, 0, reviewerCount)
+	// выбираем до reviewerCount ревьюверов согласно стратегии команды
+	strategy, err := s.resolveStrategy(teamName)
+	if err != nil {
+		return nil, err
+	}
+	loads, err := s.candidateLoads(candidateUserIDs, strategy)
+	if err != nil {
+		return nil, err
+	}
+	for _, reviewerID := range released {
+		if loads[reviewerID] > 0 {
+			loads[reviewerID]--
+		}
+	}
+	tiers, err := s.candidateTiers(candidateUserIDs, componentTags)
+	if err != nil {
+		return nil, err
+	}
+	tiers, err = s.spreadAuthorTiers(tiers, authorID, released)
+	if err != nil {
+		return nil, err
+	}
+	tiers, err = s.recentAuthorTiers(tiers, authorID)
+	if err != nil {
+		return nil, err
+	}
+	tiers = s.fairTiers(tiers, candidateUserIDs, loads, reviewerCount)
+	tiers, err = s.weeklyCapacityTiers(tiers, candidateUserIDs, reviewerCount)
+	if err != nil {
+		return nil, err
+	}
+	selectedReviewers, err := s.pickReviewersByGroup(tiers, reviewerCount, strategy, loads, candidateUserIDs)
+	if err != nil {
+		return nil, err
+	}
+	if trace != nil {
+		trace.Strategy = strategy
+		trace.Selected = append(trace.Selected, selectedReviewers...)
+	}
 
-	// перемешиваем кандидатов
-	shuffledCandidates := make([]string, len(candidateUserIDs))
-	copy(shuffledCandidates, candidateUserIDs)
-	rand.Shuffle(len(shuffledCandidates), func(i, j int) {
-		shuffledCandidates[i], shuffledCandidates[j] = shuffledCandidates[j], shuffledCandidates[i]
-	})
+	log.Printf("Selected %d reviewers (%s): %v", len(selectedReviewers), strategy, selectedReviewers)
+	return selectedReviewers, nil
+}
 
-	// выбираем первых reviewerCount кандидатов
-	for i := 0; i < reviewerCount; i++ {
-		selectedReviewers = append(selectedReviewers, shuffledCandidates[i])
+// выбирает ревьюверов из уровней кандидатов; при OneReviewerPerGroup - не больше одного из каждой группы ревьюверов,
+// а при нехватке кандидатов ограничение ослабляется с предупреждением в логе
+// принимает: уровни кандидатов, количество ревьюверов, стратегию, нагрузку и всех кандидатов
+// возвращает: слайс выбранных ревьюверов или ошибку получения групп
+func (s *PRService) pickReviewersByGroup(tiers [][]string, reviewerCount int, strategy string, loads map[string]int,
+	candidateUserIDs []string) ([]string, error) {
+	if !s.cfg.OneReviewerPerGroup {
+		return pickTiered(tiers, reviewerCount, strategy, loads, s.rng), nil
 	}
 
-	log.Printf("Selected %d reviewers: %v", len(selectedReviewers), selectedReviewers)
-	return selectedReviewers, nil
+	groups, err := s.userRepo.GetReviewerGroups(candidateUserIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reviewer groups: %w", err)
+	}
+	selected, relaxed := pickOnePerGroup(tiers, reviewerCount, strategy, loads, groups, s.rng)
+	if relaxed {
+		log.Printf("Warning: not enough reviewers from distinct groups, one-per-group constraint relaxed: %v", selected)
+	}
+	return selected, nil
+}
+
+// выбирает ревьюверов best-effort из участников команды, деактивированных в пределах окна INACTIVE_FALLBACK_WINDOW
+// принимает: идентификатор автора, название команды, уже выбранных ревьюверов и количество недостающих
+// возвращает: слайс дополнительных ревьюверов (пустой если окно не задано или нехватки нет) или ошибку
+func (s *PRService) fallbackReviewers(authorID, teamName string, selected []string, missing int) ([]string, error) {
+	if s.cfg.InactiveFallbackWindow <= 0 || missing <= 0 {
+		return nil, nil
+	}
+
+	recentlyInactive, err := s.userRepo.GetUsersDeactivatedSince(teamName, time.Now().Add(-s.cfg.InactiveFallbackWindow))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recently deactivated users: %w", err)
+	}
+
+	var candidateUserIDs []string
+	for _, user := range recentlyInactive {
+		if user.UserID != authorID && !contains(selected, user.UserID) {
+			candidateUserIDs = append(candidateUserIDs, user.UserID)
+		}
+	}
+	candidateUserIDs, err = s.excludeOptedOut(candidateUserIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidateUserIDs) == 0 {
+		return nil, nil
+	}
+
+	strategy, err := s.resolveStrategy(teamName)
+	if err != nil {
+		return nil, err
+	}
+	loads, err := s.candidateLoads(candidateUserIDs, strategy)
+	if err != nil {
+		return nil, err
+	}
+	bestEffort := pickReviewers(candidateUserIDs, missing, strategy, loads, s.rng)
+
+	log.Printf("Active reviewers short by %d in team %s, best-effort reviewers: %v", missing, teamName, bestEffort)
+	return bestEffort, nil
+}
+
+// разбивает кандидатов на уровни предпочтения согласно включенным политикам:
+// эксперты компонентов, затем авторы открытых или недавних PR с теми же компонентами, затем все кандидаты
+// принимает: слайс идентификаторов кандидатов и компоненты PR
+// возвращает: уровни кандидатов по убыванию предпочтения (последний уровень - все кандидаты) или ошибку
+func (s *PRService) candidateTiers(candidateUserIDs, componentTags []string) ([][]string, error) {
+	if len(componentTags) == 0 {
+		return [][]string{candidateUserIDs}, nil
+	}
+
+	var tiers [][]string
+	if s.cfg.OwnershipAffinity {
+		experts, err := s.userRepo.GetComponentExperts(candidateUserIDs, componentTags)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get component experts: %w", err)
+		}
+		log.Printf("Component experts for %v: %v", componentTags, experts)
+		tiers = append(tiers, experts)
+	}
+	if s.cfg.RelatedAuthorAffinity {
+		authors, err := s.prRepo.GetRelatedPRAuthors(candidateUserIDs, componentTags, time.Now().Add(-s.cfg.RelatedAuthorWindow))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get related PR authors: %w", err)
+		}
+		log.Printf("Authors of related PRs for %v: %v", componentTags, authors)
+		tiers = append(tiers, authors)
+	}
+
+	return append(tiers, candidateUserIDs), nil
+}
+
+// при SpreadAuthorReviews ставит перед каждым уровнем кандидатов его часть без ревьюверов других открытых PR автора,
+// чтобы PR одного автора распределялись по команде; исходный уровень остается следом на случай нехватки кандидатов
+// принимает: уровни кандидатов по убыванию предпочтения, идентификатор автора и текущих ревьюверов PR при повторном выборе
+// возвращает: уровни кандидатов с учетом политики или ошибку получения ревьюверов автора
+func (s *PRService) spreadAuthorTiers(tiers [][]string, authorID string, released []string) ([][]string, error) {
+	if !s.cfg.SpreadAuthorReviews {
+		return tiers, nil
+	}
+
+	reviewers, _, err := s.prRepo.GetReviewersByAuthor(authorID, "OPEN", models.Pagination{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reviewers of author's open PRs: %w", err)
+	}
+	if len(reviewers) == 0 {
+		return tiers, nil
+	}
+
+	busy := make(map[string]bool, len(reviewers))
+	for _, reviewer := range reviewers {
+		// назначение на сам переназначаемый PR не делает ревьювера занятым
+		prCount := reviewer.PRCount
+		if contains(released, reviewer.UserID) {
+			prCount--
+		}
+		busy[reviewer.UserID] = prCount > 0
+	}
+
+	spread := make([][]string, 0, 2*len(tiers))
+	for _, tier := range tiers {
+		var free []string
+		for _, candidate := range tier {
+			if !busy[candidate] {
+				free = append(free, candidate)
+			}
+		}
+		spread = append(spread, free, tier)
+	}
+
+	log.Printf("Spreading reviews of author %s away from: %v", authorID, reviewers)
+	return spread, nil
+}
+
+// при RecentAuthorPRs > 0 делит уровни кандидатов по давности их последнего ревью PR автора: сначала кандидаты,
+// не ревьюившие последние RecentAuthorPRs PR автора, затем ревьюверы более старых PR и в конце ревьюверы самого нового,
+// поэтому PR автора, созданные подряд, по очереди достаются всем участникам команды
+// принимает: уровни кандидатов и идентификатор автора
+// возвращает: уровни кандидатов с учетом давности ревью или ошибку получения ревьюверов
+func (s *PRService) recentAuthorTiers(tiers [][]string, authorID string) ([][]string, error) {
+	if s.cfg.RecentAuthorPRs <= 0 {
+		return tiers, nil
+	}
+
+	recent, err := s.prRepo.GetRecentReviewersByAuthor(authorID, s.cfg.RecentAuthorPRs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reviewers of author's recent PRs: %w", err)
+	}
+
+	// индекс самого нового из последних PR автора, который ревьюил кандидат (0 - самый новый)
+	lastReviewed := make(map[string]int)
+	for i := len(recent) - 1; i >= 0; i-- {
+		for _, reviewerID := range recent[i] {
+			lastReviewed[reviewerID] = i
+		}
+	}
+	if len(lastReviewed) == 0 {
+		return tiers, nil
+	}
+
+	spread := make([][]string, 0, (len(recent)+1)*len(tiers))
+	for _, tier := range tiers {
+		buckets := make([][]string, len(recent)+1)
+		for _, candidate := range tier {
+			bucket := 0
+			if index, ok := lastReviewed[candidate]; ok {
+				bucket = len(recent) - index
+			}
+			buckets[bucket] = append(buckets[bucket], candidate)
+		}
+		spread = append(spread, buckets...)
+	}
+
+	log.Printf("Deprioritizing recent reviewers of author %s: %v", authorID, recent)
+	return spread, nil
+}
+
+// при EnforceFairness ставит перед уровнями кандидатов их части, назначение которых не нарушит баланс нагрузки:
+// открытых ревью у ревьювера не должно стать больше минимума среди кандидатов более чем на FairnessMaxDelta;
+// исходные уровни остаются в конце, чтобы при нехватке подходящих кандидатов ограничение ослаблялось
+// принимает: уровни кандидатов, всех кандидатов, их нагрузку и нужное количество ревьюверов
+// возвращает: уровни кандидатов с учетом ограничения
+func (s *PRService) fairTiers(tiers [][]string, candidateUserIDs []string, loads map[string]int, reviewerCount int) [][]string {
+	if !s.cfg.EnforceFairness || len(candidateUserIDs) == 0 {
+		return tiers
+	}
+
+	minLoad := loads[candidateUserIDs[0]]
+	for _, candidate := range candidateUserIDs {
+		minLoad = min(minLoad, loads[candidate])
+	}
+
+	fair := make([][]string, 0, 2*len(tiers))
+	allowed := 0
+	for _, tier := range tiers {
+		var fairTier []string
+		for _, candidate := range tier {
+			if loads[candidate]+1-minLoad <= s.cfg.FairnessMaxDelta {
+				fairTier = append(fairTier, candidate)
+			}
+		}
+		fair = append(fair, fairTier)
+		allowed = len(fairTier)
+	}
+
+	// последний уровень содержит всех кандидатов
+	if allowed < reviewerCount {
+		log.Printf("Fairness constraint allows only %d of %d needed reviewers (min load %d, delta %d), relaxing",
+			allowed, min(reviewerCount, len(candidateUserIDs)), minLoad, s.cfg.FairnessMaxDelta)
+	}
+	return append(fair, tiers...)
+}
+
+// возвращает стратегию выбора ревьюверов для команды: закрепленную за командой или глобальную
+// принимает: название команды
+// возвращает: название стратегии или ошибку получения настроек команды
+func (s *PRService) resolveStrategy(teamName string) (string, error) {
+	strategy, err := s.teamService.assignmentStrategy(teamName)
+	if err != nil {
+		return "", err
+	}
+	if strategy == "" {
+		return s.cfg.AssignmentStrategy, nil
+	}
+	if !isKnownStrategy(strategy) {
+		log.Printf("Unknown assignment strategy %q for team %s, falling back to %s", strategy, teamName, s.cfg.AssignmentStrategy)
+		return s.cfg.AssignmentStrategy, nil
+	}
+	return strategy, nil
+}
+
+// возвращает текущее количество открытых ревью кандидатов, если этого требует стратегия или EnforceFairness
+// принимает: слайс идентификаторов кандидатов и стратегию выбора
+// возвращает: нагрузку по кандидатам (nil если нагрузка не учитывается) или ошибку
+func (s *PRService) candidateLoads(candidateUserIDs []string, strategy string) (map[string]int, error) {
+	if strategy != StrategyLeastLoaded && !s.cfg.EnforceFairness {
+		return nil, nil
+	}
+
+	loads, err := s.reviewRepo.GetOpenAssignmentCounts(candidateUserIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reviewer loads: %w", err)
+	}
+	return loads, nil
 }
 
 // возвращает минимальное значение из двух целых чисел
@@ -201,6 +1225,10 @@ func min(a, b int) int {
 func (s *PRService) ReassignReviewer(prID, oldReviewerID string) (*models.PullRequest, string, error) {
 	log.Printf("Reassigning reviewer: %s in PR: %s", oldReviewerID, prID)
 
+	if err := s.checkAssignmentNotPaused(); err != nil {
+		return nil, "", err
+	}
+
 	// получаем PR
 	pr, err := s.prRepo.GetPR(prID)
 	if err != nil {
@@ -208,10 +1236,10 @@ func (s *PRService) ReassignReviewer(prID, oldReviewerID string) (*models.PullRe
 		return nil, "", NewServiceError("NOT_FOUND", "PR not found")
 	}
 
-	// проверяем что PR не мержен
-	if pr.Status == "MERGED" {
-		log.Printf("Cannot reassign on merged PR: %s", prID)
-		return nil, "", NewServiceError("PR_MERGED", "cannot reassign on merged PR")
+	// проверяем что PR открыт
+	if pr.Status != "OPEN" {
+		log.Printf("Cannot reassign on non-open PR: %s (%s)", prID, pr.Status)
+		return nil, "", notOpenError(pr.Status, "reassign on")
 	}
 
 	// проверяем что старый ревьювер назначен на PR
@@ -238,6 +1266,10 @@ func (s *PRService) ReassignReviewer(prID, oldReviewerID string) (*models.PullRe
 		return nil, "", NewServiceError("INVALID_REQUEST", "old reviewer is not active")
 	}
 
+	if err := s.checkReassignInterval(prID); err != nil {
+		return nil, "", err
+	}
+
 	// выбираем нового ревьювера из команды старого ревьювера
 	newReviewerID, err := s.selectReplacementReviewer(oldReviewer.TeamName, prID, pr.AuthorID, oldReviewerID)
 	if err != nil {
@@ -246,7 +1278,12 @@ func (s *PRService) ReassignReviewer(prID, oldReviewerID string) (*models.PullRe
 	}
 
 	// заменяем ревьювера
-	if err := s.reviewRepo.ReplaceReviewer(prID, oldReviewerID, newReviewerID); err != nil {
+	if err := s.reviewRepo.ReplaceReviewerIfOpen(prID, oldReviewerID, newReviewerID); err != nil {
+		if errors.Is(err, models.ErrPRNotOpen) {
+			// PR замержен или закрыт параллельно после проверки статуса выше
+			log.Printf("PR merged or closed concurrently, reassign rejected: %s", prID)
+			return nil, "", s.concurrentNotOpenError(prID, "reassign on")
+		}
 		log.Printf("Failed to replace reviewer: %s -> %s in PR: %s, error: %v", oldReviewerID, newReviewerID, prID, err)
 		return nil, "", fmt.Errorf("failed to replace reviewer: %w", err)
 	}
@@ -255,9 +1292,36 @@ func (s *PRService) ReassignReviewer(prID, oldReviewerID string) (*models.PullRe
 	pr.AssignedReviewers = s.replaceInSlice(pr.AssignedReviewers, oldReviewerID, newReviewerID)
 
 	log.Printf("Reviewer reassigned successfully: %s -> %s in PR: %s", oldReviewerID, newReviewerID, prID)
+	s.publishEvent(models.EventReviewerReassigned, pr, oldReviewerID, newReviewerID)
 	return pr, newReviewerID, nil
 }
 
+// проверяет что с последней замены ревьювера в PR прошло не меньше минимального интервала
+// принимает: идентификатор PR
+// возвращает: ошибку TOO_SOON если замена была недавно, ошибку чтения журнала или nil
+func (s *PRService) checkReassignInterval(prID string) error {
+	if s.cfg.ReassignMinInterval <= 0 {
+		return nil
+	}
+
+	lastAt, found, err := s.reviewRepo.GetLastReassignmentAt(prID)
+	if err != nil {
+		log.Printf("Failed to get last reassignment of PR: %s, error: %v", prID, err)
+		return fmt.Errorf("failed to get last reassignment: %w", err)
+	}
+	if !found {
+		return nil
+	}
+
+	if elapsed := time.Since(lastAt); elapsed < s.cfg.ReassignMinInterval {
+		wait := (s.cfg.ReassignMinInterval - elapsed).Round(time.Second)
+		log.Printf("Reassign rejected, PR %s was reassigned %s ago", prID, elapsed.Round(time.Second))
+		return NewServiceError("TOO_SOON",
+			fmt.Sprintf("reviewers of this PR were reassigned recently, retry in %s", wait))
+	}
+	return nil
+}
+
 // выбирает случайного активного пользователя из команды для замены ревьювера
 // принимает: название команды, идентификаторы PR, автора и старого ревьювера для фильтрации кандидатов
 // возвращает: идентификатор выбранного пользователя или ошибку если нет подходящих кандидатов
@@ -283,6 +1347,18 @@ func (s *PRService) selectReplacementReviewer(teamName, prID, authorID, oldRevie
 		}
 	}
 
+	candidateUserIDs, err = s.excludeOptedOut(candidateUserIDs)
+	if err != nil {
+		return "", err
+	}
+
+	if s.cfg.OneReviewerPerGroup {
+		candidateUserIDs, err = s.excludeTakenGroups(prID, oldReviewerID, candidateUserIDs)
+		if err != nil {
+			return "", err
+		}
+	}
+
 	log.Printf("Available replacement candidates: %v", candidateUserIDs)
 
 	if len(candidateUserIDs) == 0 {
@@ -290,12 +1366,90 @@ func (s *PRService) selectReplacementReviewer(teamName, prID, authorID, oldRevie
 		return "", NewServiceError("NO_CANDIDATE", "no active replacement candidate in team")
 	}
 
-	// выбираем случайного кандидата
-	selectedReviewer := candidateUserIDs[rand.Intn(len(candidateUserIDs))]
+	// выбираем кандидата согласно стратегии команды
+	strategy, err := s.resolveStrategy(teamName)
+	if err != nil {
+		return "", err
+	}
+	loads, err := s.candidateLoads(candidateUserIDs, strategy)
+	if err != nil {
+		return "", err
+	}
+	selectedReviewer := pickReviewers(candidateUserIDs, 1, strategy, loads, s.rng)[0]
 	log.Printf("Selected replacement reviewer: %s", selectedReviewer)
 	return selectedReviewer, nil
 }
 
+// исключает из кандидатов на замену участников групп, уже представленных остальными ревьюверами PR;
+// если подходящих кандидатов не остается, ограничение ослабляется с предупреждением в логе
+// принимает: идентификатор PR, заменяемого ревьювера и кандидатов
+// возвращает: кандидатов из незанятых групп (или всех кандидатов при ослаблении) или ошибку получения данных
+func (s *PRService) excludeTakenGroups(prID, oldReviewerID string, candidateUserIDs []string) ([]string, error) {
+	if len(candidateUserIDs) == 0 {
+		return candidateUserIDs, nil
+	}
+
+	assigned, err := s.reviewRepo.GetAssignedReviewers(prID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assigned reviewers: %w", err)
+	}
+	var remaining []string
+	for _, reviewerID := range assigned {
+		if reviewerID != oldReviewerID {
+			remaining = append(remaining, reviewerID)
+		}
+	}
+
+	groups, err := s.userRepo.GetReviewerGroups(append(remaining, candidateUserIDs...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reviewer groups: %w", err)
+	}
+	takenGroups := make(map[string]bool)
+	for _, reviewerID := range remaining {
+		if groupID, ok := groups[reviewerID]; ok {
+			takenGroups[groupID] = true
+		}
+	}
+
+	var filtered []string
+	for _, userID := range candidateUserIDs {
+		if groupID, ok := groups[userID]; !ok || !takenGroups[groupID] {
+			filtered = append(filtered, userID)
+		}
+	}
+	if len(filtered) == 0 {
+		log.Printf("Warning: no replacement candidate from a free reviewer group for PR %s, one-per-group constraint relaxed", prID)
+		return candidateUserIDs, nil
+	}
+	return filtered, nil
+}
+
+// исключает из кандидатов пользователей, отказавшихся от автоматического назначения (POST /users/optOut)
+// принимает: идентификаторы кандидатов
+// возвращает: кандидатов без пользователей с неистекшим окном отказа или ошибку получения данных
+func (s *PRService) excludeOptedOut(candidateUserIDs []string) ([]string, error) {
+	if len(candidateUserIDs) == 0 {
+		return candidateUserIDs, nil
+	}
+
+	optedOut, err := s.userRepo.GetOptedOutUsers(candidateUserIDs, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get opted-out users: %w", err)
+	}
+	if len(optedOut) == 0 {
+		return candidateUserIDs, nil
+	}
+
+	log.Printf("Skipping opted-out candidates: %v", optedOut)
+	var available []string
+	for _, userID := range candidateUserIDs {
+		if !contains(optedOut, userID) {
+			available = append(available, userID)
+		}
+	}
+	return available, nil
+}
+
 // проверяет назначен ли указанный пользователь ревьювером на Pull Request
 // принимает: идентификатор PR и идентификатор пользователя для проверки назначения
 // возвращает: булево значение true если пользователь назначен ревьювером на PR
@@ -0,0 +1,1761 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"pull-request-reviewer-assignment-service/internal/models"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createPRWithName(t *testing.T, cfg Config, name string) (*models.PullRequest, error) {
+	t.Helper()
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1")
+	service := newTestPRService(repo, cfg)
+	return service.CreatePR(&models.CreatePRRequest{PullRequestID: "pr-1", PullRequestName: name, AuthorID: "author"})
+}
+
+func TestCreatePRNameAtLimit(t *testing.T) {
+	name := strings.Repeat("я", 10)
+
+	pr, err := createPRWithName(t, Config{MaxPRNameLength: 10}, name)
+	require.NoError(t, err)
+	assert.Equal(t, name, pr.PullRequestName)
+}
+
+func TestCreatePRNameOverLimitRejected(t *testing.T) {
+	_, err := createPRWithName(t, Config{MaxPRNameLength: 10, PRNameOverflow: PRNameOverflowReject}, strings.Repeat("a", 11))
+
+	var serviceErr *ServiceError
+	require.ErrorAs(t, err, &serviceErr)
+	assert.Equal(t, "INVALID_REQUEST", serviceErr.Code)
+	assert.Contains(t, serviceErr.Message, "10")
+}
+
+func TestCreatePRNameOverLimitTruncated(t *testing.T) {
+	pr, err := createPRWithName(t, Config{MaxPRNameLength: 10, PRNameOverflow: PRNameOverflowTruncate}, strings.Repeat("я", 15))
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat("я", 10), pr.PullRequestName)
+}
+
+func TestCreateAndMergePRTimestampsAreUTC(t *testing.T) {
+	// сервер в часовом поясе с ненулевым смещением
+	local := time.Local
+	time.Local = time.FixedZone("UTC+3", 3*60*60)
+	t.Cleanup(func() { time.Local = local })
+
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1")
+	service := newTestPRService(repo, Config{})
+
+	pr, err := service.CreatePR(&models.CreatePRRequest{PullRequestID: "pr-1", PullRequestName: "Feature", AuthorID: "author"})
+	require.NoError(t, err)
+	assert.Equal(t, time.UTC, pr.CreatedAt.Location())
+
+	pr, err = service.MergePR("pr-1")
+	require.NoError(t, err)
+	require.NotNil(t, pr.MergedAt)
+	assert.Equal(t, time.UTC, pr.MergedAt.Location())
+
+	data, err := json.Marshal(pr)
+	require.NoError(t, err)
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &result))
+	assert.True(t, strings.HasSuffix(result["createdAt"].(string), "Z"), result["createdAt"])
+	assert.True(t, strings.HasSuffix(result["mergedAt"].(string), "Z"), result["mergedAt"])
+}
+
+func TestClosePRIsIdempotentAndRejectsMerged(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1")
+	repo.addPR("pr-open", "author", "u1")
+	repo.addPR("pr-merged", "author", "u1")
+	service := newTestPRService(repo, Config{})
+
+	pr, err := service.ClosePR("pr-open")
+	require.NoError(t, err)
+	assert.Equal(t, "CLOSED", pr.Status)
+	require.NotNil(t, pr.ClosedAt)
+	assert.Nil(t, pr.MergedAt)
+	closedAt := *pr.ClosedAt
+
+	// повторное закрытие возвращает текущее состояние
+	pr, err = service.ClosePR("pr-open")
+	require.NoError(t, err)
+	assert.Equal(t, "CLOSED", pr.Status)
+	assert.Equal(t, closedAt, *pr.ClosedAt)
+
+	_, err = service.MergePR("pr-merged")
+	require.NoError(t, err)
+	var serviceErr *ServiceError
+	_, err = service.ClosePR("pr-merged")
+	require.ErrorAs(t, err, &serviceErr)
+	assert.Equal(t, "INVALID_REQUEST", serviceErr.Code)
+
+	_, err = service.ClosePR("pr-missing")
+	require.ErrorAs(t, err, &serviceErr)
+	assert.Equal(t, "NOT_FOUND", serviceErr.Code)
+}
+
+func TestReopenPRReassignsWhenAllReviewersDeactivated(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3", "u4")
+	service := newTestPRService(repo, Config{})
+	userService := newTestUserService(repo, Config{})
+
+	pr, err := service.CreatePR(&models.CreatePRRequest{PullRequestID: "pr-1", PullRequestName: "Feature", AuthorID: "author"})
+	require.NoError(t, err)
+	original := pr.AssignedReviewers
+	require.Len(t, original, 2)
+
+	_, err = service.ClosePR("pr-1")
+	require.NoError(t, err)
+	// закрытый PR не переназначается при деактивации ревьюверов
+	for _, reviewerID := range original {
+		_, err = userService.SetUserActive(reviewerID, false, "")
+		require.NoError(t, err)
+	}
+	assert.ElementsMatch(t, original, repo.reviewers["pr-1"])
+
+	pr, err = service.ReopenPR("pr-1")
+	require.NoError(t, err)
+	assert.Equal(t, "OPEN", pr.Status)
+	assert.Nil(t, pr.ClosedAt)
+	require.Len(t, pr.AssignedReviewers, 2)
+	for _, reviewerID := range pr.AssignedReviewers {
+		assert.NotContains(t, original, reviewerID)
+		assert.NotEqual(t, "author", reviewerID)
+	}
+	assert.ElementsMatch(t, pr.AssignedReviewers, repo.reviewers["pr-1"])
+}
+
+func TestReopenPRKeepsActiveReviewersAndRejectsMerged(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3")
+	repo.addPR("pr-1", "author", "u1", "u2")
+	repo.addPR("pr-merged", "author", "u1")
+	service := newTestPRService(repo, Config{})
+
+	// повторное открытие открытого PR идемпотентно
+	pr, err := service.ReopenPR("pr-1")
+	require.NoError(t, err)
+	assert.Equal(t, "OPEN", pr.Status)
+
+	_, err = service.ClosePR("pr-1")
+	require.NoError(t, err)
+	repo.users["u1"].IsActive = false
+	pr, err = service.ReopenPR("pr-1")
+	require.NoError(t, err)
+	assert.Equal(t, "OPEN", pr.Status)
+	// u2 активен, поэтому ревьюверы не меняются
+	assert.Equal(t, []string{"u1", "u2"}, pr.AssignedReviewers)
+
+	_, err = service.MergePR("pr-merged")
+	require.NoError(t, err)
+	var serviceErr *ServiceError
+	_, err = service.ReopenPR("pr-merged")
+	require.ErrorAs(t, err, &serviceErr)
+	assert.Equal(t, "PR_MERGED", serviceErr.Code)
+}
+
+func TestCreatePRReselectsReviewerDeactivatedMidAssignment(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3", "u4")
+	service := newTestPRService(repo, Config{AssignmentRetries: 2})
+	userService := newTestUserService(repo, Config{})
+
+	// первый выбранный ревьювер деактивируется параллельным запросом между выбором и записью назначения
+	var deactivated string
+	repo.beforeAssign = func(prID string, reviewerIDs []string) {
+		if deactivated != "" {
+			return
+		}
+		deactivated = reviewerIDs[0]
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := userService.SetUserActive(deactivated, false, "")
+			assert.NoError(t, err)
+		}()
+		wg.Wait()
+	}
+
+	pr, err := service.CreatePR(&models.CreatePRRequest{PullRequestID: "pr-1", PullRequestName: "Feature", AuthorID: "author"})
+	require.NoError(t, err)
+	require.NotEmpty(t, deactivated)
+	require.Len(t, pr.AssignedReviewers, 2)
+	assert.NotContains(t, pr.AssignedReviewers, deactivated)
+	assert.ElementsMatch(t, pr.AssignedReviewers, repo.reviewers["pr-1"])
+	for _, reviewerID := range pr.AssignedReviewers {
+		assert.True(t, repo.users[reviewerID].IsActive, reviewerID)
+	}
+}
+
+func TestCreatePRGivesUpAfterAssignmentRetries(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3", "u4", "u5")
+	service := newTestPRService(repo, Config{AssignmentRetries: 1})
+
+	// каждый выбор теряет ревьювера до записи
+	attempts := 0
+	repo.beforeAssign = func(prID string, reviewerIDs []string) {
+		attempts++
+		repo.mu.Lock()
+		repo.users[reviewerIDs[0]].IsActive = false
+		repo.mu.Unlock()
+	}
+
+	pr, err := service.CreatePR(&models.CreatePRRequest{PullRequestID: "pr-1", PullRequestName: "Feature", AuthorID: "author"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+	assert.Empty(t, pr.AssignedReviewers)
+	assert.Empty(t, repo.reviewers["pr-1"])
+}
+
+func TestCreatePRRotatesReviewersAcrossAuthorsRecentPRs(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3", "u4")
+	service := newTestPRService(repo, Config{RecentAuthorPRs: 3})
+
+	var assigned [][]string
+	for i := 1; i <= 6; i++ {
+		pr, err := service.CreatePR(&models.CreatePRRequest{
+			PullRequestID:   fmt.Sprintf("pr-%d", i),
+			PullRequestName: "Feature",
+			AuthorID:        "author",
+		})
+		require.NoError(t, err)
+		require.Len(t, pr.AssignedReviewers, 2)
+		assigned = append(assigned, pr.AssignedReviewers)
+	}
+
+	// два PR подряд покрывают всех четырех ревьюверов, а третий возвращается к ревьюверам самого старого
+	for i := 1; i < len(assigned); i++ {
+		assert.ElementsMatch(t, []string{"u1", "u2", "u3", "u4"}, append(append([]string{}, assigned[i-1]...), assigned[i]...), i)
+	}
+	for i := 2; i < len(assigned); i++ {
+		assert.ElementsMatch(t, assigned[i-2], assigned[i], i)
+	}
+}
+
+func TestCreatePRFollowUpAvoidsPreviousReviewersWhenPoolAllows(t *testing.T) {
+	createFollowUp := func(t *testing.T, repo *fakeRepo) (previous, followUp []string) {
+		service := newTestPRService(repo, Config{RecentAuthorPRs: 1})
+		first, err := service.CreatePR(&models.CreatePRRequest{PullRequestID: "pr-1", PullRequestName: "Feature", AuthorID: "author"})
+		require.NoError(t, err)
+		second, err := service.CreatePR(&models.CreatePRRequest{PullRequestID: "pr-2", PullRequestName: "Follow-up", AuthorID: "author"})
+		require.NoError(t, err)
+		return first.AssignedReviewers, second.AssignedReviewers
+	}
+
+	t.Run("large team gets fresh reviewers", func(t *testing.T) {
+		repo := newFakeRepo()
+		repo.addTeam("backend", "author", "u1", "u2", "u3", "u4", "u5")
+		previous, followUp := createFollowUp(t, repo)
+		require.Len(t, followUp, 2)
+		for _, reviewerID := range followUp {
+			assert.NotContains(t, previous, reviewerID)
+		}
+	})
+
+	t.Run("partial pool keeps one previous reviewer", func(t *testing.T) {
+		repo := newFakeRepo()
+		repo.addTeam("backend", "author", "u1", "u2", "u3")
+		previous, followUp := createFollowUp(t, repo)
+		require.Len(t, followUp, 2)
+		assert.Len(t, subtractIDs(followUp, previous), 1)
+	})
+
+	t.Run("small team reuses previous reviewers", func(t *testing.T) {
+		repo := newFakeRepo()
+		repo.addTeam("backend", "author", "u1", "u2")
+		previous, followUp := createFollowUp(t, repo)
+		assert.ElementsMatch(t, previous, followUp)
+	})
+}
+
+func TestListPRsFiltersAndLimitsPage(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "a", "b", "u1")
+	repo.addPR("pr-a1", "a", "u1")
+	repo.addPR("pr-a2", "a", "u1")
+	repo.addPR("pr-a3", "a", "u1")
+	repo.addPR("pr-b1", "b", "u1")
+	repo.mergePRAt("pr-a3", time.Now())
+	service := newTestPRService(repo, Config{})
+
+	prs, page, err := service.ListPRs(models.PRFilter{Status: "OPEN", AuthorID: "a", Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, prs, 1)
+	assert.Equal(t, "pr-a1", prs[0].PullRequestID)
+	assert.Equal(t, models.Pagination{Limit: 1, Total: 2, HasMore: true}, page)
+
+	prs, page, err = service.ListPRs(models.PRFilter{Status: "OPEN", AuthorID: "a", Limit: 1, Offset: 1})
+	require.NoError(t, err)
+	require.Len(t, prs, 1)
+	assert.Equal(t, "pr-a2", prs[0].PullRequestID)
+	assert.False(t, page.HasMore)
+
+	// без limit - размер страницы по умолчанию, слишком большой limit ограничивается
+	_, page, err = service.ListPRs(models.PRFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, PRListDefaultLimit, page.Limit)
+	assert.Equal(t, 4, page.Total)
+	_, page, err = service.ListPRs(models.PRFilter{Limit: 10000})
+	require.NoError(t, err)
+	assert.Equal(t, PRListMaxLimit, page.Limit)
+
+	var serviceErr *ServiceError
+	_, _, err = service.ListPRs(models.PRFilter{Status: "DRAFT"})
+	require.ErrorAs(t, err, &serviceErr)
+	assert.Equal(t, "INVALID_REQUEST", serviceErr.Code)
+}
+
+func TestBatchGetPRsMixOfExistingAndMissing(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2")
+	repo.addPR("pr-1", "author", "u1", "u2")
+	repo.addPR("pr-2", "author")
+	service := newTestPRService(repo, Config{MaxBatchSize: 10})
+
+	prs, err := service.BatchGetPRs([]string{"pr-1", "missing", "pr-2"})
+	require.NoError(t, err)
+
+	require.Len(t, prs, 3)
+	assert.Equal(t, []string{"u1", "u2"}, prs["pr-1"].AssignedReviewers)
+	assert.Empty(t, prs["pr-2"].AssignedReviewers)
+	assert.Contains(t, prs, "missing")
+	assert.Nil(t, prs["missing"])
+}
+
+func TestBatchGetPRsRespectsMaxBatchSize(t *testing.T) {
+	service := newTestPRService(newFakeRepo(), Config{MaxBatchSize: 2})
+
+	_, err := service.BatchGetPRs([]string{"pr-1", "pr-2", "pr-3"})
+	var serviceErr *ServiceError
+	require.ErrorAs(t, err, &serviceErr)
+	assert.Equal(t, "INVALID_REQUEST", serviceErr.Code)
+
+	_, err = service.BatchGetPRs(nil)
+	require.ErrorAs(t, err, &serviceErr)
+	assert.Equal(t, "INVALID_REQUEST", serviceErr.Code)
+}
+
+func TestCreatePRScalesReviewersBySize(t *testing.T) {
+	cases := []struct {
+		lines    int
+		expected int
+	}{
+		{0, 1},
+		{99, 1},
+		{100, 2},
+		{499, 2},
+		{500, 3},
+		{5000, 3},
+	}
+
+	for _, tc := range cases {
+		repo := newFakeRepo()
+		repo.addTeam("backend", "author", "u1", "u2", "u3", "u4")
+		service := newTestPRService(repo, Config{SizeBasedReviewers: true})
+
+		lines := tc.lines
+		pr, err := service.CreatePR(&models.CreatePRRequest{
+			PullRequestID: "pr-1", PullRequestName: "Feature", AuthorID: "author", LinesChanged: &lines,
+		})
+		require.NoError(t, err)
+		assert.Len(t, pr.AssignedReviewers, tc.expected, "lines_changed=%d", tc.lines)
+		assert.Equal(t, tc.lines, *pr.LinesChanged)
+	}
+}
+
+func TestCreatePRSizeBasedClampsAndDefaults(t *testing.T) {
+	lines := 1000
+
+	// больше кандидатов нет - назначаются все доступные
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2")
+	pr, err := newTestPRService(repo, Config{SizeBasedReviewers: true}).CreatePR(&models.CreatePRRequest{
+		PullRequestID: "pr-1", PullRequestName: "Feature", AuthorID: "author", LinesChanged: &lines,
+	})
+	require.NoError(t, err)
+	assert.Len(t, pr.AssignedReviewers, 2)
+
+	// без политики размер не влияет на количество ревьюверов
+	repo = newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3", "u4")
+	pr, err = newTestPRService(repo, Config{}).CreatePR(&models.CreatePRRequest{
+		PullRequestID: "pr-1", PullRequestName: "Feature", AuthorID: "author", LinesChanged: &lines,
+	})
+	require.NoError(t, err)
+	assert.Len(t, pr.AssignedReviewers, defaultReviewerCount)
+}
+
+func TestReviewerPolicyLimitsReviewersAndWarnsBelowMinimum(t *testing.T) {
+	cases := []struct {
+		members   int
+		reviewers int
+		warning   bool
+	}{
+		// автор без коллег - ревьюверов нет совсем
+		{members: 1, reviewers: 0, warning: true},
+		{members: 3, reviewers: 2, warning: false},
+		{members: 10, reviewers: 4, warning: false},
+	}
+
+	for _, tc := range cases {
+		repo := newFakeRepo()
+		userIDs := make([]string, tc.members)
+		for i := range userIDs {
+			userIDs[i] = fmt.Sprintf("u%d", i+1)
+		}
+		repo.addTeam("backend", userIDs...)
+		service := newTestPRService(repo, Config{ReviewerPolicy: ReviewerPolicy{MaxReviewers: 4, MinReviewers: 2}})
+
+		pr, err := service.CreatePR(&models.CreatePRRequest{PullRequestID: "pr-1", PullRequestName: "Feature", AuthorID: "u1"})
+		require.NoError(t, err)
+		assert.Len(t, pr.AssignedReviewers, tc.reviewers, "members: %d", tc.members)
+		if tc.warning {
+			require.NotNil(t, pr.Warning, "members: %d", tc.members)
+			assert.Equal(t, "NO_CANDIDATE", pr.Warning.Code)
+		} else {
+			assert.Nil(t, pr.Warning, "members: %d", tc.members)
+		}
+	}
+}
+
+func TestReviewerPolicyValidate(t *testing.T) {
+	cases := []struct {
+		policy            ReviewerPolicy
+		maxReviewersPerPR int
+		valid             bool
+	}{
+		{policy: ReviewerPolicy{}, maxReviewersPerPR: 10, valid: true},
+		{policy: ReviewerPolicy{MaxReviewers: 3, MinReviewers: 3}, maxReviewersPerPR: 3, valid: true},
+		// 0 - без ограничения на PR
+		{policy: ReviewerPolicy{MaxReviewers: 20}, maxReviewersPerPR: 0, valid: true},
+		{policy: ReviewerPolicy{MaxReviewers: 2, MinReviewers: 3}, maxReviewersPerPR: 10, valid: false},
+		// незаданный MaxReviewers сравнивается как значение по умолчанию 2
+		{policy: ReviewerPolicy{MinReviewers: 3}, maxReviewersPerPR: 10, valid: false},
+		{policy: ReviewerPolicy{MaxReviewers: 5}, maxReviewersPerPR: 4, valid: false},
+		{policy: ReviewerPolicy{}, maxReviewersPerPR: 1, valid: false},
+		{policy: ReviewerPolicy{MinReviewers: -1}, maxReviewersPerPR: 10, valid: false},
+	}
+
+	for _, tc := range cases {
+		err := tc.policy.Validate(tc.maxReviewersPerPR)
+		if tc.valid {
+			assert.NoError(t, err, "policy %+v, max per PR %d", tc.policy, tc.maxReviewersPerPR)
+		} else {
+			assert.Error(t, err, "policy %+v, max per PR %d", tc.policy, tc.maxReviewersPerPR)
+		}
+	}
+}
+
+func TestPurgeMergedPRsOnlyRemovesOldMergedAndClosed(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1")
+	repo.addPR("old-merged", "author", "u1")
+	repo.addPR("recent-merged", "author", "u1")
+	repo.addPR("old-open", "author", "u1")
+	repo.addPR("old-closed", "author", "u1")
+	repo.addPR("recent-closed", "author", "u1")
+	repo.mergePRAt("old-merged", time.Now().Add(-60*24*time.Hour))
+	repo.mergePRAt("recent-merged", time.Now().Add(-time.Hour))
+	_, err := repo.ClosePR("old-closed", time.Now().Add(-60*24*time.Hour))
+	require.NoError(t, err)
+	_, err = repo.ClosePR("recent-closed", time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	service := newTestPRService(repo, Config{})
+
+	response, err := service.PurgeMergedPRs(30 * 24 * time.Hour)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, response.PurgedCount)
+
+	_, err = repo.GetPR("old-merged")
+	assert.Error(t, err)
+	_, err = repo.GetPR("old-closed")
+	assert.Error(t, err)
+	_, err = repo.GetPR("recent-merged")
+	assert.NoError(t, err)
+	_, err = repo.GetPR("recent-closed")
+	assert.NoError(t, err)
+	openPR, err := repo.GetPR("old-open")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u1"}, openPR.AssignedReviewers)
+}
+
+func TestPurgeMergedPRsDeletesInBatches(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author")
+	total := purgeBatchSize + 3
+	for i := 0; i < total; i++ {
+		prID := fmt.Sprintf("pr-%d", i)
+		repo.addPR(prID, "author")
+		repo.mergePRAt(prID, time.Now().Add(-48*time.Hour))
+	}
+	service := newTestPRService(repo, Config{})
+
+	response, err := service.PurgeMergedPRs(24 * time.Hour)
+	require.NoError(t, err)
+	assert.EqualValues(t, total, response.PurgedCount)
+}
+
+// хранилище ревьюверов, которое мержит PR сразу после проверки назначения,
+// воспроизводя мерж между проверкой статуса и заменой ревьювера
+type mergeDuringReassignRepo struct {
+	*fakeRepo
+}
+
+func (r mergeDuringReassignRepo) IsReviewerAssigned(prID, userID string) (bool, error) {
+	r.mergePRAt(prID, time.Now())
+	return r.fakeRepo.IsReviewerAssigned(prID, userID)
+}
+
+func TestReassignReviewerLosesRaceToMerge(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2")
+	repo.addPR("pr-1", "author", "u1")
+	service := NewPRService(repo, mergeDuringReassignRepo{repo}, repo, NewTeamService(repo, repo), Config{}, nil)
+
+	_, _, err := service.ReassignReviewer("pr-1", "u1")
+
+	var serviceErr *ServiceError
+	require.ErrorAs(t, err, &serviceErr)
+	assert.Equal(t, "PR_MERGED", serviceErr.Code)
+
+	pr, err := repo.GetPR("pr-1")
+	require.NoError(t, err)
+	assert.Equal(t, "MERGED", pr.Status)
+	assert.Equal(t, []string{"u1"}, pr.AssignedReviewers)
+}
+
+func TestConcurrentMergeAndReassignStayConsistent(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		repo := newFakeRepo()
+		repo.addTeam("backend", "author", "u1", "u2")
+		repo.addPR("pr-1", "author", "u1")
+		service := newTestPRService(repo, Config{})
+
+		var wg sync.WaitGroup
+		var mergeErr, reassignErr error
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, mergeErr = service.MergePR("pr-1")
+		}()
+		go func() {
+			defer wg.Done()
+			_, _, reassignErr = service.ReassignReviewer("pr-1", "u1")
+		}()
+		wg.Wait()
+
+		require.NoError(t, mergeErr)
+		pr, err := repo.GetPR("pr-1")
+		require.NoError(t, err)
+		assert.Equal(t, "MERGED", pr.Status)
+
+		if reassignErr == nil {
+			assert.Equal(t, []string{"u2"}, pr.AssignedReviewers)
+			continue
+		}
+		var serviceErr *ServiceError
+		require.ErrorAs(t, reassignErr, &serviceErr)
+		assert.Equal(t, "PR_MERGED", serviceErr.Code)
+		assert.Equal(t, []string{"u1"}, pr.AssignedReviewers)
+	}
+}
+
+func TestReassignWithinMinIntervalRejected(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3")
+	repo.addPR("pr-1", "author", "u1")
+	service := newTestPRService(repo, Config{ReassignMinInterval: time.Minute})
+
+	_, newReviewerID, err := service.ReassignReviewer("pr-1", "u1")
+	require.NoError(t, err)
+
+	_, _, err = service.ReassignReviewer("pr-1", newReviewerID)
+
+	var serviceErr *ServiceError
+	require.ErrorAs(t, err, &serviceErr)
+	assert.Equal(t, "TOO_SOON", serviceErr.Code)
+
+	pr, err := repo.GetPR("pr-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{newReviewerID}, pr.AssignedReviewers)
+}
+
+func TestReassignAfterMinIntervalAllowed(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3")
+	repo.addPR("pr-1", "author", "u1")
+	service := newTestPRService(repo, Config{ReassignMinInterval: time.Minute})
+
+	_, newReviewerID, err := service.ReassignReviewer("pr-1", "u1")
+	require.NoError(t, err)
+
+	// последняя замена была раньше интервала
+	repo.mu.Lock()
+	repo.reassignedAt["pr-1"] = time.Now().Add(-2 * time.Minute)
+	repo.mu.Unlock()
+
+	_, _, err = service.ReassignReviewer("pr-1", newReviewerID)
+	require.NoError(t, err)
+}
+
+func TestCreatePRFallsBackToRecentlyDeactivated(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3")
+	repo.deactivateAt("u2", time.Now().Add(-time.Hour))
+	repo.deactivateAt("u3", time.Now().Add(-72*time.Hour))
+	service := newTestPRService(repo, Config{InactiveFallbackWindow: 24 * time.Hour})
+
+	pr, err := service.CreatePR(&models.CreatePRRequest{PullRequestID: "pr-1", PullRequestName: "pr-1", AuthorID: "author"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"u1", "u2"}, pr.AssignedReviewers)
+	assert.Equal(t, []string{"u2"}, pr.BestEffortReviewers)
+}
+
+func TestCreatePRFallbackWindowTooShort(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2")
+	repo.deactivateAt("u2", time.Now().Add(-time.Hour))
+	service := newTestPRService(repo, Config{InactiveFallbackWindow: 30 * time.Minute})
+
+	pr, err := service.CreatePR(&models.CreatePRRequest{PullRequestID: "pr-1", PullRequestName: "pr-1", AuthorID: "author"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"u1"}, pr.AssignedReviewers)
+	assert.Empty(t, pr.BestEffortReviewers)
+}
+
+func TestAddReviewerRespectsMaxReviewersPerPR(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3", "u4")
+	repo.addPR("pr-1", "author", "u1")
+	service := newTestPRService(repo, Config{MaxReviewersPerPR: 3})
+
+	_, err := service.AddReviewer("pr-1", "u2")
+	require.NoError(t, err)
+	pr, err := service.AddReviewer("pr-1", "u3")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u1", "u2", "u3"}, pr.AssignedReviewers)
+
+	_, err = service.AddReviewer("pr-1", "u4")
+	var serviceErr *ServiceError
+	require.ErrorAs(t, err, &serviceErr)
+	assert.Equal(t, "INVALID_REQUEST", serviceErr.Code)
+
+	pr, err = repo.GetPR("pr-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u1", "u2", "u3"}, pr.AssignedReviewers)
+}
+
+func TestConcurrentAddReviewerNeverExceedsMaxReviewersPerPR(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3", "u4", "u5")
+	repo.addPR("pr-1", "author", "u1")
+	service := newTestPRService(repo, Config{MaxReviewersPerPR: 2})
+
+	// все запросы видят одного ревьювера, но лимит перепроверяется при записи
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	added := 0
+	for _, userID := range []string{"u2", "u3", "u4", "u5"} {
+		wg.Add(1)
+		go func(userID string) {
+			defer wg.Done()
+			if _, err := service.AddReviewer("pr-1", userID); err == nil {
+				mu.Lock()
+				added++
+				mu.Unlock()
+			}
+		}(userID)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, added)
+	assert.Len(t, repo.reviewers["pr-1"], 2)
+}
+
+func TestAddReviewerRejectedWhenPRMergedBeforeWrite(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2")
+	repo.addPR("pr-1", "author", "u1")
+	service := newTestPRService(repo, Config{})
+
+	// PR смержен после проверки статуса в AddReviewer, но до записи
+	repo.prs["pr-1"].Status = "MERGED"
+	err := service.addReviewers("pr-1", []string{"u2"})
+	var serviceErr *ServiceError
+	require.ErrorAs(t, err, &serviceErr)
+	assert.Equal(t, "PR_MERGED", serviceErr.Code)
+	assert.Equal(t, []string{"u1"}, repo.reviewers["pr-1"])
+}
+
+func TestAddAndReassignOnClosedPRRejectedAsClosed(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3")
+	repo.addPR("pr-1", "author", "u1")
+	service := newTestPRService(repo, Config{})
+	_, err := service.ClosePR("pr-1")
+	require.NoError(t, err)
+
+	var serviceErr *ServiceError
+	_, err = service.AddReviewer("pr-1", "u2")
+	require.ErrorAs(t, err, &serviceErr)
+	assert.Equal(t, "PR_CLOSED", serviceErr.Code)
+	assert.Equal(t, "cannot add reviewer to closed PR", serviceErr.Message)
+
+	_, _, err = service.ReassignReviewer("pr-1", "u1")
+	require.ErrorAs(t, err, &serviceErr)
+	assert.Equal(t, "PR_CLOSED", serviceErr.Code)
+	assert.Equal(t, "cannot reassign on closed PR", serviceErr.Message)
+
+	// запись под блокировкой тоже называет фактический статус, если PR закрыли после проверки
+	err = service.addReviewers("pr-1", []string{"u2"})
+	require.ErrorAs(t, err, &serviceErr)
+	assert.Equal(t, "PR_CLOSED", serviceErr.Code)
+
+	assert.Equal(t, []string{"u1"}, repo.reviewers["pr-1"])
+}
+
+func TestCreatePRRequiredReviewersAboveCapRejected(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3")
+	service := newTestPRService(repo, Config{MaxReviewersPerPR: 2})
+
+	required := 3
+	_, err := service.CreatePR(&models.CreatePRRequest{
+		PullRequestID: "pr-1", PullRequestName: "pr-1", AuthorID: "author", RequiredReviewers: &required,
+	})
+
+	var serviceErr *ServiceError
+	require.ErrorAs(t, err, &serviceErr)
+	assert.Equal(t, "INVALID_REQUEST", serviceErr.Code)
+}
+
+func TestReviewerSuggestionsRankLeastLoadedFirst(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3", "other")
+	repo.addPR("busy-1", "other", "u1", "u2")
+	repo.addPR("busy-2", "other", "u1")
+	repo.addPR("pr-1", "author")
+	service := newTestPRService(repo, Config{})
+
+	suggestions, err := service.GetReviewerSuggestions("pr-1")
+	require.NoError(t, err)
+
+	var ranking []string
+	for _, suggestion := range suggestions {
+		ranking = append(ranking, suggestion.UserID)
+	}
+	// other свободен, но тоже без нагрузки: при равной оценке порядок по идентификатору
+	assert.Equal(t, []string{"other", "u3", "u2", "u1"}, ranking)
+	assert.Equal(t, 2, suggestions[3].Factors.OpenReviews)
+	assert.Greater(t, suggestions[1].Score, suggestions[2].Score)
+	assert.Greater(t, suggestions[2].Score, suggestions[3].Score)
+}
+
+func TestReviewerSuggestionsAccountForRecencyAndExpertise(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2")
+	repo.addPR("pr-1", "author")
+	repo.prs["pr-1"].ComponentTags = []string{"billing"}
+	repo.expertise["u2"] = []string{"billing"}
+	repo.lastAssigned["u1"] = time.Now()
+	service := newTestPRService(repo, Config{})
+
+	suggestions, err := service.GetReviewerSuggestions("pr-1")
+	require.NoError(t, err)
+	require.Len(t, suggestions, 2)
+
+	assert.Equal(t, "u2", suggestions[0].UserID)
+	assert.True(t, suggestions[0].Factors.ExpertiseMatch)
+	assert.Equal(t, 1.0, suggestions[0].Score)
+	assert.Equal(t, "u1", suggestions[1].UserID)
+	assert.NotNil(t, suggestions[1].Factors.LastAssignedAt)
+	assert.InDelta(t, 0.5, suggestions[1].Score, 0.001)
+}
+
+func TestGetTeamAssignmentConfigInheritsGlobals(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "u1", "u2", "u3", "u4")
+	repo.addTeam("solo", "s1", "s2")
+	service := newTestPRService(repo, Config{
+		AssignmentStrategy: StrategyLeastLoaded,
+		SizeBasedReviewers: true,
+		MinActivePerTeam:   2,
+	})
+
+	config, err := service.GetTeamAssignmentConfig("backend")
+	require.NoError(t, err)
+	assert.Equal(t, &models.TeamAssignmentConfig{
+		TeamName:             "backend",
+		AssignmentStrategy:   StrategyLeastLoaded,
+		DefaultReviewerCount: defaultReviewerCount,
+		SizeBasedReviewers:   true,
+		MinActivePerTeam:     2,
+		ActiveMembers:        4,
+		AvailableReviewers:   3,
+	}, config)
+
+	// в маленькой команде ревьюверов по умолчанию меньше, чем глобальное значение
+	config, err = service.GetTeamAssignmentConfig("solo")
+	require.NoError(t, err)
+	assert.Equal(t, 1, config.DefaultReviewerCount)
+	assert.Equal(t, 1, config.AvailableReviewers)
+
+	_, err = service.GetTeamAssignmentConfig("missing")
+	var serviceErr *ServiceError
+	require.ErrorAs(t, err, &serviceErr)
+	assert.Equal(t, "NOT_FOUND", serviceErr.Code)
+}
+
+func TestBulkReassignReportsPerItemResults(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3")
+	repo.addTeam("platform", "p1", "p2")
+	repo.deactivateAt("p2", time.Now())
+	repo.addPR("pr-1", "author", "u1")
+	repo.addPR("pr-2", "author", "u2")
+	service := newTestPRService(repo, Config{})
+
+	response := service.BulkReassign(context.Background(), []models.BulkReassignMapping{
+		{PullRequestID: "pr-1", OldUserID: "u1", NewUserID: "p1"},
+		{PullRequestID: "pr-2", OldUserID: "u3", NewUserID: "p1"},
+		{PullRequestID: "pr-2", OldUserID: "u2", NewUserID: "p2"},
+	})
+
+	require.Len(t, response.Results, 3)
+	assert.Equal(t, 1, response.Reassigned)
+	assert.Equal(t, 2, response.Failed)
+
+	assert.Equal(t, BulkReassignApplied, response.Results[0].Status)
+	assert.Nil(t, response.Results[0].Error)
+	assert.Equal(t, BulkReassignFailed, response.Results[1].Status)
+	assert.Equal(t, "NOT_ASSIGNED", response.Results[1].Error.Code)
+	assert.Equal(t, BulkReassignFailed, response.Results[2].Status)
+	assert.Equal(t, "INVALID_REQUEST", response.Results[2].Error.Code)
+
+	// успешная замена применена, неудачные ничего не изменили
+	assert.Equal(t, []string{"p1"}, repo.reviewers["pr-1"])
+	assert.Equal(t, []string{"u2"}, repo.reviewers["pr-2"])
+}
+
+func TestSpreadAuthorReviewsRotatesReviewers(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3", "u4")
+	service := newTestPRService(repo, Config{SpreadAuthorReviews: true})
+
+	create := func(prID string) []string {
+		pr, err := service.CreatePR(&models.CreatePRRequest{PullRequestID: prID, PullRequestName: "Feature", AuthorID: "author"})
+		require.NoError(t, err)
+		require.Len(t, pr.AssignedReviewers, 2)
+		return pr.AssignedReviewers
+	}
+
+	// второй PR автора достается свободной паре ревьюверов
+	first, second := create("pr-1"), create("pr-2")
+	assert.ElementsMatch(t, []string{"u1", "u2", "u3", "u4"}, append(first, second...))
+
+	// все заняты - ревьюверы все равно назначаются
+	create("pr-3")
+
+	// замерженный PR больше не занимает своих ревьюверов
+	repo.mergePRAt("pr-1", time.Now())
+	repo.mergePRAt("pr-3", time.Now())
+	assert.ElementsMatch(t, first, create("pr-4"))
+}
+
+func TestOneReviewerPerGroupSelectsSingleGroupMember(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3")
+	require.NoError(t, repo.SetReviewerGroup("u1", "payments"))
+	require.NoError(t, repo.SetReviewerGroup("u2", "payments"))
+	service := newTestPRService(repo, Config{OneReviewerPerGroup: true})
+
+	for i := 0; i < 20; i++ {
+		pr, err := service.CreatePR(&models.CreatePRRequest{
+			PullRequestID: fmt.Sprintf("pr-%d", i), PullRequestName: "Feature", AuthorID: "author",
+		})
+		require.NoError(t, err)
+
+		// из группы payments выбирается только один участник, второе место достается u3
+		require.Len(t, pr.AssignedReviewers, 2)
+		assert.Contains(t, pr.AssignedReviewers, "u3")
+		assert.False(t, contains(pr.AssignedReviewers, "u1") && contains(pr.AssignedReviewers, "u2"))
+	}
+}
+
+func TestOneReviewerPerGroupRelaxesToMeetCount(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3")
+	for _, userID := range []string{"u1", "u2", "u3"} {
+		require.NoError(t, repo.SetReviewerGroup(userID, "payments"))
+	}
+	service := newTestPRService(repo, Config{OneReviewerPerGroup: true})
+
+	// кандидатов из разных групп не хватает - ограничение ослабляется до нужного количества
+	required := 3
+	pr, err := service.CreatePR(&models.CreatePRRequest{
+		PullRequestID: "pr-1", PullRequestName: "Feature", AuthorID: "author", RequiredReviewers: &required,
+	})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"u1", "u2", "u3"}, pr.AssignedReviewers)
+}
+
+func TestRequiredReviewerTeamPullsGovernanceReviewer(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2")
+	repo.addTeam("security", "s1")
+	service := newTestPRService(repo, Config{RequiredReviewerTeams: map[string]string{"backend": "security"}})
+
+	pr, err := service.CreatePR(&models.CreatePRRequest{PullRequestID: "pr-1", PullRequestName: "Feature", AuthorID: "author"})
+	require.NoError(t, err)
+
+	// один из двух слотов достается участнику обязательной команды
+	require.Len(t, pr.AssignedReviewers, 2)
+	assert.Contains(t, pr.AssignedReviewers, "s1")
+
+	merged, err := service.MergePR("pr-1")
+	require.NoError(t, err)
+	assert.Equal(t, "MERGED", merged.Status)
+}
+
+func TestRequiredReviewerTeamWithoutActiveMembersBlocksMerge(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2")
+	repo.addTeam("security", "s1")
+	require.NoError(t, repo.SetUserActive("s1", false, ""))
+	service := newTestPRService(repo, Config{RequiredReviewerTeams: map[string]string{"backend": "security"}})
+
+	// PR создается с ревьюверами своей команды, но мерж блокируется
+	pr, err := service.CreatePR(&models.CreatePRRequest{PullRequestID: "pr-1", PullRequestName: "Feature", AuthorID: "author"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"u1", "u2"}, pr.AssignedReviewers)
+
+	_, err = service.MergePR("pr-1")
+	var serviceErr *ServiceError
+	require.ErrorAs(t, err, &serviceErr)
+	assert.Equal(t, "REQUIRED_REVIEWER_MISSING", serviceErr.Code)
+}
+
+func TestRequiredReviewerKeptWhenGovernanceReviewerLeaves(t *testing.T) {
+	cfg := Config{RequiredReviewerTeams: map[string]string{"backend": "security"}}
+	for _, leave := range []string{"deactivate", "transfer"} {
+		t.Run(leave, func(t *testing.T) {
+			repo := newFakeRepo()
+			repo.addTeam("backend", "author", "u1", "u2")
+			repo.addTeam("security", "s1", "s2")
+			repo.addTeam("frontend", "f1")
+			repo.addPR("pr-1", "author", "u1", "s1")
+			prService := newTestPRService(repo, cfg)
+			userService := newTestUserService(repo, cfg)
+
+			// s1 - единственный ревьювер из обязательной команды, замена должна прийти из нее же
+			var err error
+			if leave == "deactivate" {
+				_, err = userService.BulkSetActive(context.Background(), []models.UserActiveChange{{UserID: "s1", IsActive: false}}, "")
+			} else {
+				_, err = userService.TransferUser("s1", "frontend")
+			}
+			require.NoError(t, err)
+			assert.ElementsMatch(t, []string{"u1", "s2"}, repo.reviewers["pr-1"])
+
+			merged, err := prService.MergePR("pr-1")
+			require.NoError(t, err)
+			assert.Equal(t, "MERGED", merged.Status)
+		})
+	}
+}
+
+func TestWeeklyCapacitySkipsUserAtCapUntilWindowRolls(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3")
+	capacity := 2
+	require.NoError(t, repo.SetWeeklyCapacity("u1", &capacity))
+	repo.assignmentsAt("u1", time.Now().Add(-6*24*time.Hour), 2)
+	service := newTestPRService(repo, Config{})
+
+	for i := 0; i < 10; i++ {
+		pr, err := service.CreatePR(&models.CreatePRRequest{
+			PullRequestID: fmt.Sprintf("pr-%d", i), PullRequestName: "Feature", AuthorID: "author",
+		})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"u2", "u3"}, pr.AssignedReviewers)
+	}
+
+	// назначения вышли из окна 7 дней - u1 снова в пределах емкости, а u2 и u3 ее исчерпали
+	repo.assignmentsAt("u1", time.Now().Add(-8*24*time.Hour), 2)
+	exhausted := 0
+	require.NoError(t, repo.SetWeeklyCapacity("u2", &exhausted))
+	require.NoError(t, repo.SetWeeklyCapacity("u3", &exhausted))
+	one := 1
+	pr, err := service.CreatePR(&models.CreatePRRequest{
+		PullRequestID: "pr-rolled", PullRequestName: "Feature", AuthorID: "author", RequiredReviewers: &one,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u1"}, pr.AssignedReviewers)
+
+	comparison, err := newTestUserService(repo, Config{}).GetLoadComparison("u1")
+	require.NoError(t, err)
+	require.NotNil(t, comparison.WeeklyCapacityRemaining)
+	assert.Equal(t, 1, *comparison.WeeklyCapacityRemaining)
+}
+
+func TestWeeklyCapacityFallsBackPastCap(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3")
+	capacity := 1
+	for _, userID := range []string{"u1", "u2"} {
+		require.NoError(t, repo.SetWeeklyCapacity(userID, &capacity))
+		repo.assignmentsAt(userID, time.Now().Add(-time.Hour), 1)
+	}
+	service := newTestPRService(repo, Config{})
+
+	// в пределах емкости только u3 - второй ревьювер назначается сверх емкости
+	pr, err := service.CreatePR(&models.CreatePRRequest{PullRequestID: "pr-1", PullRequestName: "Feature", AuthorID: "author"})
+	require.NoError(t, err)
+	require.Len(t, pr.AssignedReviewers, 2)
+	assert.Contains(t, pr.AssignedReviewers, "u3")
+}
+
+func TestAssignmentPauseSkipsAssignmentUntilResumed(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3")
+	repo.addPR("pr-old", "author", "u1")
+	service := newTestPRService(repo, Config{})
+
+	assert.True(t, service.SetAssignmentPaused(true).Paused)
+
+	// на паузе PR создается без ревьюверов, а замены отклоняются
+	pr, err := service.CreatePR(&models.CreatePRRequest{PullRequestID: "pr-1", PullRequestName: "Feature", AuthorID: "author"})
+	require.NoError(t, err)
+	assert.Empty(t, pr.AssignedReviewers)
+	assert.True(t, pr.AssignmentPaused)
+
+	_, _, err = service.ReassignReviewer("pr-old", "u1")
+	var serviceErr *ServiceError
+	require.ErrorAs(t, err, &serviceErr)
+	assert.Equal(t, "ASSIGNMENT_PAUSED", serviceErr.Code)
+
+	assert.False(t, service.SetAssignmentPaused(false).Paused)
+
+	pr, err = service.CreatePR(&models.CreatePRRequest{PullRequestID: "pr-2", PullRequestName: "Feature", AuthorID: "author"})
+	require.NoError(t, err)
+	assert.Len(t, pr.AssignedReviewers, 2)
+	assert.False(t, pr.AssignmentPaused)
+
+	// PR, созданный на паузе, добирает ревьюверов по текущей политике
+	response, err := service.ReassignByPolicy("pr-1")
+	require.NoError(t, err)
+	assert.Len(t, response.After, 2)
+}
+
+func TestAssignmentPauseBlocksEveryReassigningPath(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3", "u4")
+	repo.addTeam("frontend", "f1", "f2")
+	repo.addPR("pr-1", "author", "u1")
+	prService := newTestPRService(repo, Config{})
+	userService := newTestUserService(repo, Config{})
+	userService.SetAssignmentPause(prService.AssignmentPause())
+	teamService := NewTeamService(repo, repo)
+	teamService.SetUserService(userService)
+	prService.SetAssignmentPaused(true)
+
+	backendWithoutU1 := []models.TeamMember{
+		{UserID: "author", Username: "author", IsActive: true},
+		{UserID: "u2", Username: "u2", IsActive: true},
+		{UserID: "u3", Username: "u3", IsActive: true},
+		{UserID: "u4", Username: "u4", IsActive: true},
+	}
+	calls := map[string]func() error{
+		"addReviewer": func() error {
+			_, err := prService.AddReviewer("pr-1", "u2")
+			return err
+		},
+		"bulkDeactivate": func() error {
+			_, err := userService.BulkDeactivateUsers(context.Background(), "backend", []string{"u1"}, "")
+			return err
+		},
+		"bulkSetActive": func() error {
+			_, err := userService.BulkSetActive(context.Background(), []models.UserActiveChange{{UserID: "u1", IsActive: false}}, "")
+			return err
+		},
+		"rebalance": func() error {
+			_, err := userService.RebalanceTeam(context.Background(), "backend")
+			return err
+		},
+		"sync": func() error {
+			_, err := userService.SyncTeam("backend", backendWithoutU1)
+			return err
+		},
+		"update": func() error {
+			_, err := teamService.UpdateTeamMembers("backend", backendWithoutU1, true)
+			return err
+		},
+		"transfer": func() error {
+			_, err := userService.TransferUser("u1", "frontend")
+			return err
+		},
+	}
+	for name, call := range calls {
+		var serviceErr *ServiceError
+		require.ErrorAs(t, call(), &serviceErr, name)
+		assert.Equal(t, "ASSIGNMENT_PAUSED", serviceErr.Code, name)
+	}
+
+	// ничего не изменилось: u1 активен, в своей команде и остается единственным ревьювером
+	assert.Equal(t, []string{"u1"}, repo.reviewers["pr-1"])
+	assert.True(t, repo.users["u1"].IsActive)
+	assert.Equal(t, "backend", repo.users["u1"].TeamName)
+
+	// изменения без переназначения ревью на паузе разрешены
+	_, err := userService.SyncTeam("backend", append(backendWithoutU1, models.TeamMember{UserID: "u1", Username: "u1", IsActive: true}))
+	require.NoError(t, err)
+}
+
+func TestCheckReviewer(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2")
+	repo.addPR("pr-1", "author", "u1")
+	service := newTestPRService(repo, Config{})
+
+	check, err := service.CheckReviewer("pr-1", "u1")
+	require.NoError(t, err)
+	assert.True(t, check.Assigned)
+	assert.Equal(t, models.ReviewerRolePrimary, check.Role)
+
+	check, err = service.CheckReviewer("pr-1", "u2")
+	require.NoError(t, err)
+	assert.False(t, check.Assigned)
+	assert.Equal(t, models.ReviewerRoleNone, check.Role)
+
+	_, err = service.CheckReviewer("pr-missing", "u1")
+	var serviceErr *ServiceError
+	require.ErrorAs(t, err, &serviceErr)
+	assert.Equal(t, "NOT_FOUND", serviceErr.Code)
+}
+
+func TestCreatePRWithInactiveAuthor(t *testing.T) {
+	for _, allow := range []bool{false, true} {
+		repo := newFakeRepo()
+		repo.addTeam("backend", "bot", "u1", "u2")
+		repo.deactivateAt("bot", time.Now())
+		service := newTestPRService(repo, Config{AllowInactiveAuthor: allow})
+
+		pr, err := service.CreatePR(&models.CreatePRRequest{PullRequestID: "pr-1", PullRequestName: "Import", AuthorID: "bot"})
+		if !allow {
+			var serviceErr *ServiceError
+			require.ErrorAs(t, err, &serviceErr)
+			assert.Equal(t, "INVALID_REQUEST", serviceErr.Code)
+			continue
+		}
+
+		// ревьюверы назначаются из команды неактивного автора
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"u1", "u2"}, pr.AssignedReviewers)
+	}
+}
+
+func TestAssignmentSkipsOptedOutUser(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3")
+	repo.addPR("pr-0", "author", "u1")
+	service := newTestPRService(repo, Config{})
+	require.NoError(t, repo.SetOptOut("u2", time.Now().Add(time.Hour), "focus time"))
+
+	for i := 1; i <= 20; i++ {
+		pr, err := service.CreatePR(&models.CreatePRRequest{
+			PullRequestID: fmt.Sprintf("pr-%d", i), PullRequestName: "Feature", AuthorID: "author",
+		})
+		require.NoError(t, err)
+		assert.NotContains(t, pr.AssignedReviewers, "u2")
+	}
+
+	// замена тоже не выбирает отказавшегося пользователя
+	_, replacedBy, err := service.ReassignReviewer("pr-0", "u1")
+	require.NoError(t, err)
+	assert.Equal(t, "u3", replacedBy)
+}
+
+func TestAssignmentHonorsOptOutExpiry(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1")
+	service := newTestPRService(repo, Config{})
+
+	require.NoError(t, repo.SetOptOut("u1", time.Now().Add(time.Hour), ""))
+	pr, err := service.CreatePR(&models.CreatePRRequest{PullRequestID: "pr-1", PullRequestName: "Feature", AuthorID: "author"})
+	require.NoError(t, err)
+	assert.Empty(t, pr.AssignedReviewers)
+
+	// после окончания окна пользователь снова назначается
+	require.NoError(t, repo.SetOptOut("u1", time.Now().Add(-time.Minute), ""))
+	pr, err = service.CreatePR(&models.CreatePRRequest{PullRequestID: "pr-2", PullRequestName: "Feature", AuthorID: "author"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u1"}, pr.AssignedReviewers)
+}
+
+// считает одновременные замены ревьюверов; замена ревьювера panicOld вызывает панику
+type concurrencyCountingRepo struct {
+	*fakeRepo
+	panicOld string
+	mu       sync.Mutex
+	inFlight int
+	maxSeen  int
+}
+
+func (r *concurrencyCountingRepo) ReplaceReviewerIfOpen(prID, oldReviewerID, newReviewerID string) error {
+	r.mu.Lock()
+	r.inFlight++
+	r.maxSeen = max(r.maxSeen, r.inFlight)
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		r.inFlight--
+		r.mu.Unlock()
+	}()
+
+	time.Sleep(5 * time.Millisecond)
+	if oldReviewerID == r.panicOld {
+		panic("storage exploded")
+	}
+	return r.fakeRepo.ReplaceReviewerIfOpen(prID, oldReviewerID, newReviewerID)
+}
+
+func TestBulkReassignRespectsConcurrency(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3", "u4")
+	var mappings []models.BulkReassignMapping
+	for i := 0; i < 12; i++ {
+		prID := fmt.Sprintf("pr-%d", i)
+		repo.addPR(prID, "author", "u1")
+		mappings = append(mappings, models.BulkReassignMapping{PullRequestID: prID, OldUserID: "u1", NewUserID: "u2"})
+	}
+	counting := &concurrencyCountingRepo{fakeRepo: repo}
+	service := NewPRService(repo, counting, repo, NewTeamService(repo, repo), Config{BulkConcurrency: 3}, nil)
+
+	response := service.BulkReassign(context.Background(), mappings)
+
+	require.Len(t, response.Results, len(mappings))
+	assert.Equal(t, len(mappings), response.Reassigned)
+	assert.LessOrEqual(t, counting.maxSeen, 3)
+	assert.Greater(t, counting.maxSeen, 1)
+}
+
+// хранилище ревью, отменяющее контекст операции при первой замене
+type cancelOnReplaceRepo struct {
+	*fakeRepo
+	cancel context.CancelFunc
+}
+
+func (r cancelOnReplaceRepo) ReplaceReviewerIfOpen(prID, oldReviewerID, newReviewerID string) error {
+	r.cancel()
+	return r.fakeRepo.ReplaceReviewerIfOpen(prID, oldReviewerID, newReviewerID)
+}
+
+func TestBulkReassignStopsOnCancel(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2")
+	var mappings []models.BulkReassignMapping
+	for i := 0; i < 4; i++ {
+		prID := fmt.Sprintf("pr-%d", i)
+		repo.addPR(prID, "author", "u1")
+		mappings = append(mappings, models.BulkReassignMapping{PullRequestID: prID, OldUserID: "u1", NewUserID: "u2"})
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	service := NewPRService(repo, cancelOnReplaceRepo{fakeRepo: repo, cancel: cancel}, repo, NewTeamService(repo, repo),
+		Config{BulkConcurrency: 1}, nil)
+
+	response := service.BulkReassign(ctx, mappings)
+
+	// начатая замена завершена, остальные не выполнялись
+	assert.True(t, response.Cancelled)
+	assert.Equal(t, 1, response.Reassigned)
+	assert.Equal(t, BulkReassignApplied, response.Results[0].Status)
+	assert.Equal(t, []string{"u2"}, repo.reviewers["pr-0"])
+	for i := 1; i < len(mappings); i++ {
+		assert.Equal(t, BulkReassignCancelled, response.Results[i].Status)
+		assert.Equal(t, []string{"u1"}, repo.reviewers[mappings[i].PullRequestID])
+	}
+}
+
+func TestBulkReassignSurvivesPanickingItem(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3", "u4")
+	repo.addPR("pr-1", "author", "u1", "u3")
+	repo.addPR("pr-2", "author", "u1")
+	counting := &concurrencyCountingRepo{fakeRepo: repo, panicOld: "u3"}
+	service := NewPRService(repo, counting, repo, NewTeamService(repo, repo), Config{BulkConcurrency: 2}, nil)
+
+	done := make(chan *models.BulkReassignResponse)
+	go func() {
+		done <- service.BulkReassign(context.Background(), []models.BulkReassignMapping{
+			{PullRequestID: "pr-1", OldUserID: "u1", NewUserID: "u2"},
+			{PullRequestID: "pr-1", OldUserID: "u3", NewUserID: "u4"},
+			{PullRequestID: "pr-2", OldUserID: "u1", NewUserID: "u2"},
+		})
+	}()
+
+	var response *models.BulkReassignResponse
+	select {
+	case response = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("bulk reassign did not finish")
+	}
+
+	// у каждого соответствия есть результат, паника затронула только свое соответствие
+	require.Len(t, response.Results, 3)
+	assert.Equal(t, 2, response.Reassigned)
+	assert.Equal(t, 1, response.Failed)
+	assert.Equal(t, BulkReassignApplied, response.Results[0].Status)
+	assert.Equal(t, BulkReassignFailed, response.Results[1].Status)
+	assert.Equal(t, "INTERNAL_ERROR", response.Results[1].Error.Code)
+	assert.Equal(t, BulkReassignApplied, response.Results[2].Status)
+}
+
+func TestEnforceFairnessPicksLeastLoaded(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3")
+	repo.addTeam("platform", "other")
+	repo.addPR("busy-1", "other", "u1", "u2")
+	repo.addPR("busy-2", "other", "u1")
+	service := newTestPRService(repo, Config{AssignmentStrategy: StrategyRandom, EnforceFairness: true, FairnessMaxDelta: 1})
+
+	one := 1
+	pr, err := service.CreatePR(&models.CreatePRRequest{
+		PullRequestID: "pr-1", PullRequestName: "Feature", AuthorID: "author", RequiredReviewers: &one,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u3"}, pr.AssignedReviewers)
+
+	// подходящий кандидат только один, второй выбирается с ослаблением ограничения
+	pr, err = service.CreatePR(&models.CreatePRRequest{PullRequestID: "pr-2", PullRequestName: "Feature", AuthorID: "author"})
+	require.NoError(t, err)
+	assert.Len(t, pr.AssignedReviewers, 2)
+	assert.Contains(t, pr.AssignedReviewers, "u3")
+}
+
+func TestEnforceFairnessAcceptsAnyWhenLoadsEqual(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3")
+	service := newTestPRService(repo, Config{EnforceFairness: true, FairnessMaxDelta: 1})
+
+	pr, err := service.CreatePR(&models.CreatePRRequest{PullRequestID: "pr-1", PullRequestName: "Feature", AuthorID: "author"})
+	require.NoError(t, err)
+	assert.Len(t, pr.AssignedReviewers, 2)
+	assert.Subset(t, []string{"u1", "u2", "u3"}, pr.AssignedReviewers)
+}
+
+func TestCreatePRUsesTeamStrategyOverGlobal(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3", "u4")
+	repo.addTeam("platform", "other")
+	repo.addPR("busy-1", "other", "u1", "u2")
+	repo.addPR("busy-2", "other", "u1", "u2")
+	repo.addPR("busy-3", "other", "u1", "u2")
+	service := newTestPRService(repo, Config{AssignmentStrategy: StrategyRandom})
+	require.NoError(t, NewTeamService(repo, repo).SetAssignmentStrategy("backend", StrategyLeastLoaded))
+
+	pr, err := service.CreatePR(&models.CreatePRRequest{PullRequestID: "pr-1", PullRequestName: "Feature", AuthorID: "author"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"u3", "u4"}, pr.AssignedReviewers)
+
+	config, err := service.GetTeamAssignmentConfig("backend")
+	require.NoError(t, err)
+	assert.Equal(t, StrategyLeastLoaded, config.AssignmentStrategy)
+	assert.True(t, config.TeamStrategy)
+}
+
+func TestSetTeamAssignmentStrategyValidation(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "u1")
+	service := NewTeamService(repo, repo)
+
+	var serviceErr *ServiceError
+	err := service.SetAssignmentStrategy("backend", "round_robin")
+	require.ErrorAs(t, err, &serviceErr)
+	assert.Equal(t, "INVALID_REQUEST", serviceErr.Code)
+
+	err = service.SetAssignmentStrategy("missing", StrategyLeastLoaded)
+	require.ErrorAs(t, err, &serviceErr)
+	assert.Equal(t, "NOT_FOUND", serviceErr.Code)
+
+	// пустая стратегия возвращает команду к глобальной
+	require.NoError(t, service.SetAssignmentStrategy("backend", StrategyLeastLoaded))
+	require.NoError(t, service.SetAssignmentStrategy("backend", ""))
+	assert.Equal(t, "", repo.teamStrategies["backend"])
+}
+
+func TestSetTeamWebhook(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "u1")
+	service := NewTeamService(repo, repo)
+
+	// без списка разрешенных хостов адреса команд отключены
+	var serviceErr *ServiceError
+	err := service.SetWebhook("backend", "https://hooks.example.com/backend")
+	require.ErrorAs(t, err, &serviceErr)
+	assert.Equal(t, "INVALID_REQUEST", serviceErr.Code)
+
+	service.SetWebhookHosts([]string{"hooks.example.com"})
+	for _, webhookURL := range []string{
+		"hooks.example.com/backend",
+		"http://169.254.169.254/latest/meta-data",
+		"http://localhost:8080/admin/purge",
+	} {
+		err = service.SetWebhook("backend", webhookURL)
+		require.ErrorAs(t, err, &serviceErr, webhookURL)
+		assert.Equal(t, "INVALID_REQUEST", serviceErr.Code, webhookURL)
+	}
+
+	err = service.SetWebhook("missing", "https://hooks.example.com/missing")
+	require.ErrorAs(t, err, &serviceErr)
+	assert.Equal(t, "NOT_FOUND", serviceErr.Code)
+
+	require.NoError(t, service.SetWebhook("backend", "https://hooks.example.com/backend"))
+	webhookURL, err := repo.GetWebhookByUser("u1")
+	require.NoError(t, err)
+	assert.Equal(t, "https://hooks.example.com/backend", webhookURL)
+
+	// пустой адрес возвращает команду к глобальному
+	require.NoError(t, service.SetWebhook("backend", ""))
+	webhookURL, err = repo.GetWebhookByUser("u1")
+	require.NoError(t, err)
+	assert.Equal(t, "", webhookURL)
+}
+
+func TestCreatePRPrefersComponentExperts(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3", "u4")
+	require.NoError(t, repo.SetComponentExpertise("u3", []string{"billing"}))
+	service := newTestPRService(repo, Config{OwnershipAffinity: true})
+
+	for i := 0; i < 20; i++ {
+		pr, err := service.CreatePR(&models.CreatePRRequest{
+			PullRequestID:   fmt.Sprintf("pr-%d", i),
+			PullRequestName: "Invoice rounding",
+			AuthorID:        "author",
+			ComponentTags:   []string{"billing", "api"},
+		})
+		require.NoError(t, err)
+
+		// эксперт всегда назначен, второй ревьювер добирается из общего пула
+		assert.Contains(t, pr.AssignedReviewers, "u3")
+		assert.Len(t, pr.AssignedReviewers, defaultReviewerCount)
+		assert.Equal(t, []string{"billing", "api"}, pr.ComponentTags)
+	}
+}
+
+func TestCreatePRFallsBackWhenNoExpertMatches(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2")
+	require.NoError(t, repo.SetComponentExpertise("u1", []string{"search"}))
+	service := newTestPRService(repo, Config{OwnershipAffinity: true})
+
+	pr, err := service.CreatePR(&models.CreatePRRequest{
+		PullRequestID:   "pr-1",
+		PullRequestName: "Invoice rounding",
+		AuthorID:        "author",
+		ComponentTags:   []string{"billing"},
+	})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"u1", "u2"}, pr.AssignedReviewers)
+}
+
+func TestRelatedAuthorAffinityPrefersAuthorsOfRelatedPRs(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3", "u4")
+	service := newTestPRService(repo, Config{RelatedAuthorAffinity: true, RelatedAuthorWindow: 7 * 24 * time.Hour})
+
+	create := func(prID, authorID string, tags ...string) *models.PullRequest {
+		pr, err := service.CreatePR(&models.CreatePRRequest{
+			PullRequestID: prID, PullRequestName: "Feature", AuthorID: authorID, ComponentTags: tags,
+		})
+		require.NoError(t, err)
+		return pr
+	}
+
+	// u1 сейчас правит billing, u2 правил billing слишком давно, u3 - другой компонент
+	create("related-open", "u1", "billing")
+	create("related-old", "u2", "billing")
+	repo.mergePRAt("related-old", time.Now().Add(-30*24*time.Hour))
+	create("unrelated", "u3", "search")
+
+	for i := 0; i < 20; i++ {
+		pr := create(fmt.Sprintf("pr-%d", i), "author", "billing", "api")
+
+		// автор PR с общим компонентом всегда назначен, второй ревьювер добирается из общего пула
+		assert.Contains(t, pr.AssignedReviewers, "u1")
+		assert.Len(t, pr.AssignedReviewers, defaultReviewerCount)
+	}
+}
+
+func TestRelatedAuthorAffinityRanksBelowComponentExperts(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3", "u4")
+	require.NoError(t, repo.SetComponentExpertise("u4", []string{"billing"}))
+	service := newTestPRService(repo, Config{
+		OwnershipAffinity: true, RelatedAuthorAffinity: true, RelatedAuthorWindow: 7 * 24 * time.Hour,
+	})
+
+	_, err := service.CreatePR(&models.CreatePRRequest{
+		PullRequestID: "related", PullRequestName: "Feature", AuthorID: "u1", ComponentTags: []string{"billing"},
+	})
+	require.NoError(t, err)
+
+	pr, err := service.CreatePR(&models.CreatePRRequest{
+		PullRequestID: "pr-1", PullRequestName: "Feature", AuthorID: "author", ComponentTags: []string{"billing"},
+	})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"u4", "u1"}, pr.AssignedReviewers)
+}
+
+func TestReassignByPolicyFollowsLeastLoadedStrategy(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3")
+	repo.addTeam("platform", "other")
+	repo.addPR("busy-1", "other", "u1", "u2")
+	repo.addPR("busy-2", "other", "u1")
+	repo.addPR("pr-1", "author", "u1", "u2")
+	service := newTestPRService(repo, Config{AssignmentStrategy: StrategyLeastLoaded})
+
+	response, err := service.ReassignByPolicy("pr-1")
+	require.NoError(t, err)
+	assert.Equal(t, StrategyLeastLoaded, response.Strategy)
+	assert.Equal(t, []string{"u1", "u2"}, response.Before)
+	assert.Equal(t, []string{"u3", "u2"}, response.After)
+	assert.True(t, response.Changed)
+
+	pr, err := service.GetPR("pr-1")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"u2", "u3"}, pr.AssignedReviewers)
+
+	// состав уже соответствует политике, повторный вызов ничего не меняет
+	response, err = service.ReassignByPolicy("pr-1")
+	require.NoError(t, err)
+	assert.False(t, response.Changed)
+	assert.ElementsMatch(t, response.Before, response.After)
+
+	repo.mergePRAt("pr-1", time.Now())
+	_, err = service.ReassignByPolicy("pr-1")
+	var serviceErr *ServiceError
+	require.ErrorAs(t, err, &serviceErr)
+	assert.Equal(t, "PR_MERGED", serviceErr.Code)
+}
+
+func TestCreatePRInManualAssignmentMode(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2")
+	repo.addTeam("platform", "lead", "p1", "p2")
+	service := newTestPRService(repo, Config{MaxReviewersPerPR: 10, ManualAssignmentTeams: []string{"backend"}})
+
+	pr, err := service.CreatePR(&models.CreatePRRequest{PullRequestID: "pr-1", PullRequestName: "Feature", AuthorID: "author"})
+	require.NoError(t, err)
+	assert.Empty(t, pr.AssignedReviewers)
+
+	pr, err = service.AddReviewer("pr-1", "u2")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u2"}, pr.AssignedReviewers)
+
+	// команды вне списка назначаются автоматически
+	pr, err = service.CreatePR(&models.CreatePRRequest{PullRequestID: "pr-2", PullRequestName: "Feature", AuthorID: "lead"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"p1", "p2"}, pr.AssignedReviewers)
+
+	// глобальный ручной режим действует на все команды
+	service = newTestPRService(repo, Config{ManualAssignment: true})
+	pr, err = service.CreatePR(&models.CreatePRRequest{PullRequestID: "pr-3", PullRequestName: "Feature", AuthorID: "lead"})
+	require.NoError(t, err)
+	assert.Empty(t, pr.AssignedReviewers)
+}
+
+func TestValidateTeam(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "u1")
+	service := NewTeamService(repo, repo)
+
+	members := func(userIDs ...string) []models.TeamMember {
+		var result []models.TeamMember
+		for _, userID := range userIDs {
+			result = append(result, models.TeamMember{UserID: userID, Username: userID, IsActive: true})
+		}
+		return result
+	}
+	fields := func(errs []models.FieldError) []string {
+		result := []string{}
+		for _, fieldErr := range errs {
+			result = append(result, fieldErr.Field+": "+fieldErr.Message)
+		}
+		return result
+	}
+
+	errs, err := service.ValidateTeam(&models.Team{TeamName: "frontend", Members: members("f1", "f2")}, 3)
+	require.NoError(t, err)
+	assert.Empty(t, errs)
+
+	errs, err = service.ValidateTeam(&models.Team{TeamName: "frontend", Members: members("f1", "f2", "f1")}, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"members[2].user_id: duplicate member f1"}, fields(errs))
+
+	errs, err = service.ValidateTeam(&models.Team{TeamName: "frontend", Members: members("f1", "f2", "f3", "f4")}, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"members: too many items in members: limit is 3"}, fields(errs))
+
+	// все ошибки возвращаются сразу, ничего не сохраняется
+	errs, err = service.ValidateTeam(&models.Team{TeamName: "backend", Members: append(members("u1"), models.TeamMember{UserID: "x"})}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"team_name: team_name already exists",
+		"members[0].user_id: user u1 already belongs to team backend",
+		"members[1].username: username is required for member 1",
+	}, fields(errs))
+	assert.Equal(t, "TEAM_EXISTS", errs[0].Code)
+	assert.NotContains(t, repo.users, "x")
+
+	// создание команды использует те же проверки
+	err = service.CreateTeam(&models.Team{TeamName: "frontend", Members: members("f1", "f1")})
+	var serviceErr *ServiceError
+	require.ErrorAs(t, err, &serviceErr)
+	assert.Equal(t, "INVALID_REQUEST", serviceErr.Code)
+	assert.False(t, repo.teams["frontend"])
+}
+
+func TestGetTeamAuthoredPRs(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "b1", "b2")
+	repo.addTeam("platform", "p1", "p2")
+	repo.addPR("pr-b1", "b1", "p1")
+	repo.addPR("pr-b2", "b2", "b1")
+	repo.addPR("pr-p1", "p1", "b2")
+	repo.mergePRAt("pr-b2", time.Now())
+	service := newTestPRService(repo, Config{})
+
+	prs, page, err := service.GetTeamAuthoredPRs("backend", "", models.Pagination{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, prs, 2)
+	assert.ElementsMatch(t, []string{"pr-b1", "pr-b2"}, []string{prs[0].PullRequestID, prs[1].PullRequestID})
+	assert.Equal(t, models.Pagination{Limit: 10, Total: 2}, page)
+
+	// страница выбирается в хранилище, а общее количество считается по всему фильтру
+	prs, page, err = service.GetTeamAuthoredPRs("backend", "", models.Pagination{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, prs, 1)
+	assert.Equal(t, models.Pagination{Limit: 1, Total: 2, HasMore: true}, page)
+
+	prs, _, err = service.GetTeamAuthoredPRs("backend", "OPEN", models.Pagination{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, prs, 1)
+	assert.Equal(t, "pr-b1", prs[0].PullRequestID)
+	assert.Equal(t, []string{"p1"}, prs[0].AssignedReviewers)
+
+	var serviceErr *ServiceError
+	_, _, err = service.GetTeamAuthoredPRs("missing", "", models.Pagination{Limit: 10})
+	require.ErrorAs(t, err, &serviceErr)
+	assert.Equal(t, "NOT_FOUND", serviceErr.Code)
+
+	_, _, err = service.GetTeamAuthoredPRs("backend", "DRAFT", models.Pagination{Limit: 10})
+	require.ErrorAs(t, err, &serviceErr)
+	assert.Equal(t, "INVALID_REQUEST", serviceErr.Code)
+}
+
+func TestAddReviewersDeduplicatesInput(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3")
+	repo.addPR("pr-1", "author", "u1")
+	service := newTestPRService(repo, Config{MaxReviewersPerPR: 3})
+
+	// повторы и уже назначенные ревьюверы не занимают места под лимитом
+	require.NoError(t, service.addReviewers("pr-1", []string{"u2", "u1", "u3", "u2"}))
+	assert.Equal(t, []string{"u1", "u2", "u3"}, repo.reviewers["pr-1"])
+}
+
+func TestCreatePRWithTraceListsExclusions(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3", "u4")
+	repo.deactivateAt("u2", time.Now().Add(-time.Hour))
+	require.NoError(t, repo.SetOptOut("u3", time.Now().Add(time.Hour), "vacation"))
+	service := newTestPRService(repo, Config{AssignmentStrategy: StrategyLeastLoaded})
+
+	pr, trace, err := service.CreatePRWithTrace(&models.CreatePRRequest{
+		PullRequestID: "pr-1", PullRequestName: "Feature", AuthorID: "author",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "backend", trace.TeamName)
+	assert.Equal(t, 2, trace.DesiredReviewers)
+	assert.Equal(t, []models.TraceExclusion{
+		{UserID: "author", Reason: models.TraceExcludedAuthor},
+		{UserID: "u2", Reason: models.TraceExcludedInactive},
+		{UserID: "u3", Reason: models.TraceExcludedOptedOut},
+	}, trace.Excluded)
+	assert.ElementsMatch(t, []string{"u1", "u4"}, trace.Candidates)
+	assert.Equal(t, StrategyLeastLoaded, trace.Strategy)
+	assert.ElementsMatch(t, pr.AssignedReviewers, trace.Selected)
+	assert.ElementsMatch(t, []string{"u1", "u4"}, trace.Selected)
+}
+
+func TestGetReviewersAtReplaysAssignmentEvents(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3", "u4")
+	repo.addPR("pr-1", "author", "u3", "u4")
+	created := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	repo.prs["pr-1"].CreatedAt = created
+	at := func(minutes int) time.Time { return created.Add(time.Duration(minutes) * time.Minute) }
+	repo.auditEvents["pr-1"] = []models.ReviewerAuditEvent{
+		{EventType: models.AssignmentEventAssign, ReviewerID: "u1", CreatedAt: at(0)},
+		{EventType: models.AssignmentEventAssign, ReviewerID: "u2", CreatedAt: at(0)},
+		{EventType: models.AssignmentEventReassign, ReviewerID: "u3", PreviousReviewerID: "u1", CreatedAt: at(10)},
+		{EventType: models.AssignmentEventReassign, ReviewerID: "u4", PreviousReviewerID: "u2", CreatedAt: at(20)},
+		{EventType: models.AssignmentEventAssign, ReviewerID: "u1", CreatedAt: at(30)},
+		{EventType: models.AssignmentEventUnassign, ReviewerID: "u1", CreatedAt: at(40)},
+	}
+	service := newTestPRService(repo, Config{})
+
+	cases := []struct {
+		minutes   int
+		reviewers []string
+	}{
+		{minutes: -1, reviewers: []string{}},
+		{minutes: 0, reviewers: []string{"u1", "u2"}},
+		{minutes: 15, reviewers: []string{"u2", "u3"}},
+		{minutes: 20, reviewers: []string{"u3", "u4"}},
+		{minutes: 35, reviewers: []string{"u1", "u3", "u4"}},
+		{minutes: 60, reviewers: []string{"u3", "u4"}},
+	}
+	for _, tc := range cases {
+		response, err := service.GetReviewersAt("pr-1", at(tc.minutes), nil)
+		require.NoError(t, err)
+		assert.Equal(t, tc.reviewers, response.Reviewers, "minute %d", tc.minutes)
+	}
+
+	// изменения между двумя моментами
+	since := at(5)
+	response, err := service.GetReviewersAt("pr-1", at(35), &since)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u1", "u3", "u4"}, response.Reviewers)
+	assert.Equal(t, []string{"u3", "u4"}, response.Added)
+	assert.Equal(t, []string{"u2"}, response.Removed)
+
+	_, err = service.GetReviewersAt("pr-1", at(5), &since)
+	var serviceErr *ServiceError
+	require.ErrorAs(t, err, &serviceErr)
+	assert.Equal(t, "INVALID_REQUEST", serviceErr.Code)
+
+	_, err = service.GetReviewersAt("missing", at(5), nil)
+	require.ErrorAs(t, err, &serviceErr)
+	assert.Equal(t, "NOT_FOUND", serviceErr.Code)
+}
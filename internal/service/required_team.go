@@ -0,0 +1,138 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"pull-request-reviewer-assignment-service/internal/models"
+	"pull-request-reviewer-assignment-service/internal/repository"
+)
+
+// гарантирует, что среди ревьюверов PR есть участник обязательной команды автора (RequiredReviewerTeams):
+// если никто из выбранных не входит в нее, один слот отдается активному участнику обязательной команды
+// принимает: идентификатор и команду автора, выбранных ревьюверов и нужное количество ревьюверов
+// возвращает: ревьюверов с участником обязательной команды (без изменений, если в ней нет доступных кандидатов) или ошибку
+func (s *PRService) ensureRequiredReviewer(authorID, authorTeam string, reviewerIDs []string, reviewerCount int) ([]string, error) {
+	requiredTeam := s.cfg.RequiredReviewerTeams[authorTeam]
+	if requiredTeam == "" {
+		return reviewerIDs, nil
+	}
+
+	covered, err := s.hasRequiredReviewer(requiredTeam, reviewerIDs)
+	if err != nil || covered {
+		return reviewerIDs, err
+	}
+
+	activeUsers, err := s.userRepo.GetActiveUsersByTeam(requiredTeam)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active users: %w", err)
+	}
+	var candidateUserIDs []string
+	for _, user := range activeUsers {
+		if user.UserID != authorID && !contains(reviewerIDs, user.UserID) {
+			candidateUserIDs = append(candidateUserIDs, user.UserID)
+		}
+	}
+	candidateUserIDs, err = s.excludeOptedOut(models.DedupeIDs(candidateUserIDs))
+	if err != nil {
+		return nil, err
+	}
+	if len(candidateUserIDs) == 0 {
+		log.Printf("Warning: no active reviewers in required team %s for author %s, merge will be blocked", requiredTeam, authorID)
+		return reviewerIDs, nil
+	}
+
+	strategy, err := s.resolveStrategy(requiredTeam)
+	if err != nil {
+		return nil, err
+	}
+	loads, err := s.candidateLoads(candidateUserIDs, strategy)
+	if err != nil {
+		return nil, err
+	}
+	picked := pickTiered([][]string{candidateUserIDs}, 1, strategy, loads, s.rng)[0]
+
+	// обязательный ревьювер занимает последний слот, если все слоты уже заняты
+	result := append([]string{}, reviewerIDs...)
+	if len(result) >= reviewerCount && len(result) > 0 {
+		log.Printf("Replacing reviewer %s with %s from required team %s", result[len(result)-1], picked, requiredTeam)
+		result[len(result)-1] = picked
+	} else {
+		log.Printf("Adding reviewer %s from required team %s", picked, requiredTeam)
+		result = append(result, picked)
+	}
+	return result, nil
+}
+
+// проверяет, что среди ревьюверов PR есть участник обязательной команды автора
+// принимает: PR
+// возвращает: ошибку REQUIRED_REVIEWER_MISSING если требование не выполнено или ошибку получения данных
+func (s *PRService) checkRequiredReviewer(pr *models.PullRequest) error {
+	author, err := s.userRepo.GetUser(pr.AuthorID)
+	if err != nil {
+		log.Printf("Author not found: %s, error: %v", pr.AuthorID, err)
+		return NewServiceError("NOT_FOUND", "author not found")
+	}
+	requiredTeam := s.cfg.RequiredReviewerTeams[author.TeamName]
+	if requiredTeam == "" {
+		return nil
+	}
+
+	covered, err := s.hasRequiredReviewer(requiredTeam, pr.AssignedReviewers)
+	if err != nil {
+		return err
+	}
+	if !covered {
+		log.Printf("PR %s has no reviewer from required team %s", pr.PullRequestID, requiredTeam)
+		return NewServiceError("REQUIRED_REVIEWER_MISSING", fmt.Sprintf("PR requires a reviewer from team %s", requiredTeam))
+	}
+	return nil
+}
+
+// проверяет, входит ли хотя бы один из ревьюверов в обязательную команду
+// принимает: название обязательной команды и идентификаторы ревьюверов
+// возвращает: true если такой ревьювер есть или ошибку получения команды
+func (s *PRService) hasRequiredReviewer(requiredTeam string, reviewerIDs []string) (bool, error) {
+	if len(reviewerIDs) == 0 {
+		return false, nil
+	}
+	team, err := s.teamService.loadTeam(requiredTeam)
+	if err != nil {
+		return false, err
+	}
+	for _, member := range team.Members {
+		if contains(reviewerIDs, member.UserID) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// возвращает обязательную команду, из которой нужно выбрать замену уходящего ревьювера: если он единственный
+// ревьювер PR из обязательной команды автора, замена из другой команды сделала бы мерж невозможным
+// принимает: настройки сервиса, репозиторий команд, команду автора, текущих ревьюверов PR и уходящего ревьювера
+// возвращает: название обязательной команды или пустую строку, если замену можно выбирать как обычно, или ошибку
+func requiredReplacementTeam(cfg Config, teamRepo repository.TeamRepository, authorTeam string,
+	reviewerIDs []string, outgoingID string) (string, error) {
+	requiredTeam := cfg.RequiredReviewerTeams[authorTeam]
+	if requiredTeam == "" {
+		return "", nil
+	}
+
+	team, err := teamRepo.GetTeam(requiredTeam)
+	if err != nil {
+		return "", fmt.Errorf("failed to get team: %w", err)
+	}
+	outgoingIsMember := false
+	for _, member := range team.Members {
+		if member.UserID == outgoingID {
+			outgoingIsMember = true
+		} else if contains(reviewerIDs, member.UserID) {
+			// покрытие сохранится за счет другого ревьювера
+			return "", nil
+		}
+	}
+	if !outgoingIsMember {
+		return "", nil
+	}
+	return requiredTeam, nil
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"pull-request-reviewer-assignment-service/internal/models"
+	"sort"
+	"time"
+)
+
+// веса факторов в итоговой оценке кандидата, в сумме 1
+const (
+	suggestionLoadWeight      = 0.5
+	suggestionRecencyWeight   = 0.3
+	suggestionExpertiseWeight = 0.2
+)
+
+// время без назначений, после которого фактор давности назначения максимален
+const suggestionRecencyHorizon = 7 * 24 * time.Hour
+
+// возвращает кандидатов в ревьюверы PR с оценкой и факторами, из которых она сложилась, ничего не назначая
+// принимает: идентификатор PR
+// возвращает: слайс кандидатов от лучшего к худшему или ошибку NOT_FOUND/PR_MERGED/PR_CLOSED
+func (s *PRService) GetReviewerSuggestions(prID string) ([]models.ReviewerSuggestion, error) {
+	pr, err := s.prRepo.GetPR(prID)
+	if err != nil {
+		log.Printf("PR not found: %s, error: %v", prID, err)
+		return nil, NewServiceError("NOT_FOUND", "PR not found")
+	}
+	if pr.Status != "OPEN" {
+		return nil, notOpenError(pr.Status, "suggest reviewers for")
+	}
+
+	author, err := s.userRepo.GetUser(pr.AuthorID)
+	if err != nil {
+		return nil, NewServiceError("NOT_FOUND", "author not found")
+	}
+
+	activeUsers, err := s.userRepo.GetActiveUsersByTeam(author.TeamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active users: %w", err)
+	}
+
+	// кандидаты - активные участники команды автора, кроме автора и уже назначенных
+	var candidates []string
+	for _, user := range activeUsers {
+		if user.UserID != pr.AuthorID && !contains(pr.AssignedReviewers, user.UserID) {
+			candidates = append(candidates, user.UserID)
+		}
+	}
+	if len(candidates) == 0 {
+		return []models.ReviewerSuggestion{}, nil
+	}
+
+	loads, err := s.reviewRepo.GetOpenAssignmentCounts(candidates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reviewer loads: %w", err)
+	}
+	lastAssigned, err := s.reviewRepo.GetLastAssignedAt(candidates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last assignments: %w", err)
+	}
+	experts := map[string]bool{}
+	if len(pr.ComponentTags) > 0 {
+		expertIDs, err := s.userRepo.GetComponentExperts(candidates, pr.ComponentTags)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get component experts: %w", err)
+		}
+		for _, expertID := range expertIDs {
+			experts[expertID] = true
+		}
+	}
+
+	now := time.Now()
+	suggestions := make([]models.ReviewerSuggestion, 0, len(candidates))
+	for _, userID := range candidates {
+		factors := models.SuggestionFactors{
+			OpenReviews:    loads[userID],
+			ExpertiseMatch: experts[userID],
+			LoadScore:      1 / float64(1+loads[userID]),
+			RecencyScore:   1,
+		}
+		if assignedAt, ok := lastAssigned[userID]; ok {
+			factors.LastAssignedAt = &assignedAt
+			factors.RecencyScore = math.Min(now.Sub(assignedAt).Hours()/suggestionRecencyHorizon.Hours(), 1)
+		}
+		if factors.ExpertiseMatch {
+			factors.ExpertiseScore = 1
+		}
+
+		score := suggestionLoadWeight*factors.LoadScore +
+			suggestionRecencyWeight*factors.RecencyScore +
+			suggestionExpertiseWeight*factors.ExpertiseScore
+
+		factors.LoadScore = roundScore(factors.LoadScore)
+		factors.RecencyScore = roundScore(factors.RecencyScore)
+		suggestions = append(suggestions, models.ReviewerSuggestion{
+			UserID:  userID,
+			Score:   roundScore(score),
+			Factors: factors,
+		})
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		return suggestions[i].UserID < suggestions[j].UserID
+	})
+	return suggestions, nil
+}
+
+// округляет оценку до трех знаков после запятой для читаемого ответа
+// принимает: оценку
+// возвращает: округленную оценку
+func roundScore(score float64) float64 {
+	return math.Round(score*1000) / 1000
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"pull-request-reviewer-assignment-service/internal/models"
+	"sort"
+	"time"
+)
+
+// восстанавливает состав ревьюверов PR на момент времени, воспроизводя журнал назначений assignment_events;
+// при переданном since дополнительно возвращает, кто был добавлен и снят между since и at
+// принимает: идентификатор PR, момент времени и опциональный более ранний момент для сравнения
+// возвращает: объект ReviewersAtResponse (пустой состав для моментов до создания PR) или ошибку NOT_FOUND/INVALID_REQUEST
+func (s *PRService) GetReviewersAt(prID string, at time.Time, since *time.Time) (*models.ReviewersAtResponse, error) {
+	if since != nil && !since.Before(at) {
+		return nil, NewServiceError("INVALID_REQUEST", "since must be before at")
+	}
+
+	pr, err := s.prRepo.GetPR(prID)
+	if err != nil {
+		log.Printf("PR not found: %s, error: %v", prID, err)
+		return nil, NewServiceError("NOT_FOUND", "PR not found")
+	}
+
+	events, err := s.reviewRepo.GetAssignmentEvents(prID, at)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assignment events: %w", err)
+	}
+
+	response := &models.ReviewersAtResponse{
+		PullRequestID: prID,
+		At:            at,
+		Reviewers:     replayReviewers(pr, events, at),
+	}
+	if since != nil {
+		before := replayReviewers(pr, events, *since)
+		response.Since = since
+		response.Added = subtractIDs(response.Reviewers, before)
+		response.Removed = subtractIDs(before, response.Reviewers)
+	}
+
+	log.Printf("Reviewers of PR %s at %s: %v", prID, at.Format(time.RFC3339), response.Reviewers)
+	return response, nil
+}
+
+// воспроизводит события журнала назначений до момента времени включительно
+// принимает: PR, события журнала по возрастанию времени и момент времени
+// возвращает: отсортированный состав ревьюверов (пустой до создания PR)
+func replayReviewers(pr *models.PullRequest, events []models.ReviewerAuditEvent, at time.Time) []string {
+	reviewers := []string{}
+	if at.Before(pr.CreatedAt) {
+		return reviewers
+	}
+
+	assigned := make(map[string]bool)
+	for _, event := range events {
+		if event.CreatedAt.After(at) {
+			break
+		}
+		switch event.EventType {
+		case models.AssignmentEventAssign:
+			assigned[event.ReviewerID] = true
+		case models.AssignmentEventReassign:
+			delete(assigned, event.PreviousReviewerID)
+			assigned[event.ReviewerID] = true
+		case models.AssignmentEventUnassign:
+			delete(assigned, event.ReviewerID)
+		}
+	}
+
+	for reviewerID := range assigned {
+		reviewers = append(reviewers, reviewerID)
+	}
+	sort.Strings(reviewers)
+	return reviewers
+}
+
+// возвращает идентификаторы из первого списка, которых нет во втором
+// принимает: два слайса идентификаторов
+// возвращает: слайс идентификаторов в порядке первого списка (nil если таких нет)
+func subtractIDs(ids, excluded []string) []string {
+	var result []string
+	for _, id := range ids {
+		if !contains(excluded, id) {
+			result = append(result, id)
+		}
+	}
+	return result
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"pull-request-reviewer-assignment-service/internal/models"
+)
+
+// максимальное количество синтетических PR в одной симуляции
+const maxSimulationPRs = 10000
+
+// симулирует назначение ревьюверов на синтетические PR команды без сохранения результатов
+// принимает: название команды, количество PR, стратегию (пустая - стратегия команды или из конфигурации) и опциональный seed
+// возвращает: гистограмму назначений по активным участникам команды или ошибку валидации
+func (s *PRService) SimulateAssignments(teamName string, prCount int, strategy string, seed *int64) (*models.SimulationResponse, error) {
+	log.Printf("Simulating %d PRs for team %s with strategy %q", prCount, teamName, strategy)
+
+	if prCount < 1 || prCount > maxSimulationPRs {
+		return nil, NewServiceError("INVALID_REQUEST", fmt.Sprintf("pr_count must be between 1 and %d", maxSimulationPRs))
+	}
+	if strategy != "" && !isKnownStrategy(strategy) {
+		return nil, NewServiceError("INVALID_REQUEST", fmt.Sprintf("unknown strategy: %s", strategy))
+	}
+
+	if err := s.teamService.ensureTeamExists(teamName); err != nil {
+		return nil, err
+	}
+	if strategy == "" {
+		resolved, err := s.resolveStrategy(teamName)
+		if err != nil {
+			return nil, err
+		}
+		strategy = resolved
+	}
+
+	activeUsers, err := s.userRepo.GetActiveUsersByTeam(teamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active users: %w", err)
+	}
+
+	// работаем с копией пула кандидатов, чтобы не влиять на реальные данные
+	members := make([]string, 0, len(activeUsers))
+	histogram := make(map[string]int, len(activeUsers))
+	for _, user := range activeUsers {
+		members = append(members, user.UserID)
+		histogram[user.UserID] = 0
+	}
+
+	var rng RandomSource = s.rng
+	if seed != nil {
+		rng = rand.New(rand.NewSource(*seed))
+	}
+
+	// авторы синтетических PR чередуются по кругу среди активных участников
+	for i := 0; i < prCount && len(members) > 0; i++ {
+		authorID := members[i%len(members)]
+
+		candidates := make([]string, 0, len(members)-1)
+		for _, userID := range members {
+			if userID != authorID {
+				candidates = append(candidates, userID)
+			}
+		}
+
+		for _, reviewerID := range pickReviewers(candidates, s.policyReviewerCount(), strategy, histogram, rng) {
+			histogram[reviewerID]++
+		}
+	}
+
+	log.Printf("Simulation finished for team %s: %v", teamName, histogram)
+	return &models.SimulationResponse{
+		TeamName:       teamName,
+		Strategy:       strategy,
+		PRCount:        prCount,
+		ReviewersPerPR: s.policyReviewerCount(),
+		Histogram:      histogram,
+	}, nil
+}
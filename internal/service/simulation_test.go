@@ -0,0 +1,96 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPRService(repo *fakeRepo, cfg Config) *PRService {
+	return NewPRService(repo, repo, repo, NewTeamService(repo, repo), cfg, nil)
+}
+
+// разница между максимальной и минимальной нагрузкой в гистограмме
+func histogramSpread(histogram map[string]int) int {
+	first := true
+	var minCount, maxCount int
+	for _, count := range histogram {
+		if first {
+			minCount, maxCount = count, count
+			first = false
+			continue
+		}
+		minCount = min(minCount, count)
+		if count > maxCount {
+			maxCount = count
+		}
+	}
+	return maxCount - minCount
+}
+
+func TestSimulateAssignmentsHistogramShape(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "u1", "u2", "u3", "u4", "u5", "u6", "u7")
+	service := newTestPRService(repo, Config{})
+	seed := int64(42)
+
+	random, err := service.SimulateAssignments("backend", 700, StrategyRandom, &seed)
+	require.NoError(t, err)
+	leastLoaded, err := service.SimulateAssignments("backend", 700, StrategyLeastLoaded, &seed)
+	require.NoError(t, err)
+
+	// каждая симуляция распределяет ровно pr_count * reviewers_per_pr назначений
+	for _, result := range []map[string]int{random.Histogram, leastLoaded.Histogram} {
+		total := 0
+		for _, count := range result {
+			total += count
+		}
+		assert.Equal(t, 700*defaultReviewerCount, total)
+		assert.Len(t, result, 7)
+	}
+
+	// least_loaded выравнивает нагрузку, случайный выбор дает заметный разброс
+	assert.LessOrEqual(t, histogramSpread(leastLoaded.Histogram), 1)
+	assert.Greater(t, histogramSpread(random.Histogram), histogramSpread(leastLoaded.Histogram))
+
+	// симуляция ничего не сохраняет
+	assert.Empty(t, repo.prs)
+}
+
+func TestSimulateAssignmentsIsReproducibleWithSeed(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "u1", "u2", "u3", "u4")
+	service := newTestPRService(repo, Config{})
+	seed := int64(7)
+
+	first, err := service.SimulateAssignments("backend", 50, StrategyRandom, &seed)
+	require.NoError(t, err)
+	second, err := service.SimulateAssignments("backend", 50, StrategyRandom, &seed)
+	require.NoError(t, err)
+
+	assert.Equal(t, first.Histogram, second.Histogram)
+}
+
+func TestSimulateAssignmentsValidation(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "u1", "u2")
+	service := newTestPRService(repo, Config{})
+
+	_, err := service.SimulateAssignments("backend", 10, "round_robin", nil)
+	require.Error(t, err)
+	assert.Equal(t, "INVALID_REQUEST", err.(*ServiceError).Code)
+
+	_, err = service.SimulateAssignments("backend", 0, StrategyRandom, nil)
+	require.Error(t, err)
+	assert.Equal(t, "INVALID_REQUEST", err.(*ServiceError).Code)
+
+	// пустая стратегия означает стратегию из конфигурации
+	result, err := service.SimulateAssignments("backend", 10, "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, StrategyRandom, result.Strategy)
+
+	_, err = service.SimulateAssignments("unknown", 10, StrategyRandom, nil)
+	require.Error(t, err)
+	assert.Equal(t, "NOT_FOUND", err.(*ServiceError).Code)
+}
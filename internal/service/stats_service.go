@@ -1,29 +1,67 @@
 package service
 
 import (
+	"context"
+	"fmt"
+	"log"
+	"math"
 	"pull-request-reviewer-assignment-service/internal/models"
 	"pull-request-reviewer-assignment-service/internal/repository"
+	"strings"
+	"time"
 )
 
+// известные статусы Pull Request
+var prStatuses = []string{"OPEN", "MERGED", "CLOSED"}
+
+// ограничения количества авторов в статистике авторов
+const (
+	defaultAuthorStatsLimit = 10
+	maxAuthorStatsLimit     = 100
+)
+
+// ограничения количества PR в списке самых нестабильных PR статистики перестановок
+const (
+	defaultChurnTopLimit = 10
+	maxChurnTopLimit     = 100
+)
+
+// параметры SLA назначения ревьюверов по умолчанию: полный комплект из двух ревьюверов за 5 минут
+const (
+	defaultSLAThreshold = 2
+	defaultSLAWithin    = 5 * time.Minute
+)
+
+// границы корзин возраста PR по умолчанию: меньше суток, 1-3 дня, 3-7 дней и больше недели
+var defaultAgingBoundaries = []time.Duration{24 * time.Hour, 72 * time.Hour, 168 * time.Hour}
+
+// максимальное количество границ корзин возраста PR
+const maxAgingBoundaries = 20
+
 // предоставляет логику для работы со статистикой назначений
 type StatsService struct {
-	repo repository.StatsRepository
+	repo          repository.StatsRepository
+	eventRepo     repository.AssignmentEventRepository
+	migrationRepo repository.MigrationRepository
 }
 
 // создает и возвращает новый экземпляр StatsService
-// принимает: репозиторий статистики для внедрения зависимости
+// принимает: репозитории статистики, журнала назначений и учета миграций для внедрения зависимостей
 // возвращает: указатель на созданный StatsService
-func NewStatsService(repo repository.StatsRepository) *StatsService {
+func NewStatsService(repo repository.StatsRepository, eventRepo repository.AssignmentEventRepository,
+	migrationRepo repository.MigrationRepository) *StatsService {
 	return &StatsService{
-		repo: repo,
+		repo:          repo,
+		eventRepo:     eventRepo,
+		migrationRepo: migrationRepo,
 	}
 }
 
 // возвращает агрегированную статистику по всем назначениям на код-ревью
-// принимает: не принимает параметров, использует данные из репозитория статистики
+// принимает: слайс идентификаторов пользователей, исключаемых из пользовательской статистики и топа ревьюверов
 // возвращает: указатель на StatsResponse с полной статистикой или ошибку получения данных
-func (s *StatsService) GetReviewStats() (*models.StatsResponse, error) {
-	userStats, err := s.repo.GetUserAssignmentStats()
+func (s *StatsService) GetReviewStats(excludeUserIDs []string) (*models.StatsResponse, error) {
+	userStats, err := s.repo.GetUserAssignmentStats(excludeUserIDs)
 	if err != nil {
 		return nil, err
 	}
@@ -52,3 +90,374 @@ func (s *StatsService) GetReviewStats() (*models.StatsResponse, error) {
 		TopReviewers:      topReviewers,
 	}, nil
 }
+
+// восстанавливает журнал назначений по текущим назначениям ревьюверов
+// принимает: ничего
+// возвращает: количество созданных событий ASSIGN или ошибку; повторный вызов не создает дубликатов
+func (s *StatsService) BackfillAssignmentEvents() (*models.BackfillResponse, error) {
+	created, err := s.eventRepo.BackfillAssignEvents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to backfill assignment events: %w", err)
+	}
+
+	log.Printf("Backfilled %d assignment events", created)
+	return &models.BackfillResponse{CreatedEvents: created}, nil
+}
+
+// возвращает примененные миграции базы данных для проверки деплоя
+// принимает: ничего
+// возвращает: указатель на AppliedMigrationsResponse в порядке версий или ошибку получения данных
+func (s *StatsService) GetAppliedMigrations() (*models.AppliedMigrationsResponse, error) {
+	migrations, err := s.migrationRepo.GetAppliedMigrations()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+
+	return &models.AppliedMigrationsResponse{Migrations: migrations}, nil
+}
+
+// возвращает страницу активных пользователей, которые ни разу не назначались ревьюверами
+// принимает: название команды для фильтрации (пустая строка - все команды) и страницу
+// возвращает: указатель на UnassignedUsersResponse или ошибку получения данных
+func (s *StatsService) GetUnassignedUsers(teamName string, page models.Pagination) (*models.UnassignedUsersResponse, error) {
+	users, total, err := s.repo.GetUnassignedUsers(teamName, page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unassigned users: %w", err)
+	}
+
+	return &models.UnassignedUsersResponse{Users: users, Pagination: listPage(page, total, len(users))}, nil
+}
+
+// возвращает страницу открытых PR, у которых не осталось ни одного активного ревьювера
+// принимает: страницу
+// возвращает: указатель на StuckPRsResponse или ошибку получения данных
+func (s *StatsService) GetStuckPRs(page models.Pagination) (*models.StuckPRsResponse, error) {
+	prs, total, err := s.repo.GetStuckPRs(page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stuck PRs: %w", err)
+	}
+
+	return &models.StuckPRsResponse{PullRequests: prs, Pagination: listPage(page, total, len(prs))}, nil
+}
+
+// передает активных пользователей, которые ни разу не назначались ревьюверами, по одному без буферизации всего списка
+// принимает: контекст запроса, название команды для фильтрации (пустая строка - все команды) и обработчик пользователя
+// возвращает: ошибку получения данных или ошибку обработчика
+func (s *StatsService) StreamUnassignedUsers(ctx context.Context, teamName string, fn func(models.UnassignedUser) error) error {
+	if err := s.repo.EachUnassignedUser(ctx, teamName, fn); err != nil {
+		return fmt.Errorf("failed to stream unassigned users: %w", err)
+	}
+	return nil
+}
+
+// передает открытые PR без активных ревьюверов по одному без буферизации всего списка
+// принимает: контекст запроса и обработчик PR
+// возвращает: ошибку получения данных или ошибку обработчика
+func (s *StatsService) StreamStuckPRs(ctx context.Context, fn func(models.StuckPR) error) error {
+	if err := s.repo.EachStuckPR(ctx, fn); err != nil {
+		return fmt.Errorf("failed to stream stuck PRs: %w", err)
+	}
+	return nil
+}
+
+// передает события журнала назначений за период обработчику по одному в порядке записи, не собирая их в памяти
+// принимает: контекст запроса, период выгрузки [from, to) и обработчик события
+// возвращает: ошибку INVALID_REQUEST если from не раньше to, ошибку получения данных или ошибку обработчика
+func (s *StatsService) StreamAssignmentEvents(ctx context.Context, filter models.EventExportFilter,
+	fn func(models.ExportedAssignmentEvent) error) error {
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return NewServiceError("INVALID_REQUEST", "from must be before to")
+	}
+	if err := s.eventRepo.EachAssignmentEvent(ctx, filter, fn); err != nil {
+		return fmt.Errorf("failed to stream assignment events: %w", err)
+	}
+	return nil
+}
+
+// возвращает количество назначений ревьюверов страницы по статусам PR
+// принимает: идентификатор ревьювера и название команды для фильтрации (пустые строки - без фильтра) и страницу ревьюверов
+// возвращает: указатель на StatusStatsResponse, где у каждого ревьювера есть все известные статусы, или ошибку
+func (s *StatsService) GetAssignmentsByStatus(userID, teamName string, page models.Pagination) (*models.StatusStatsResponse, error) {
+	reviewers, total, err := s.repo.GetAssignmentCountsByStatus(userID, teamName, page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assignments by status: %w", err)
+	}
+
+	// статусы без назначений возвращаем с нулем, чтобы клиентам не приходилось проверять наличие ключа
+	for _, reviewer := range reviewers {
+		for _, status := range prStatuses {
+			if _, ok := reviewer.ByStatus[status]; !ok {
+				reviewer.ByStatus[status] = 0
+			}
+		}
+	}
+
+	return &models.StatusStatsResponse{Reviewers: reviewers, Pagination: listPage(page, total, len(reviewers))}, nil
+}
+
+// возвращает авторов с наибольшим количеством созданных PR
+// принимает: фильтр по статусу, периоду создания и количеству авторов (0 - значение по умолчанию)
+// возвращает: указатель на AuthorStatsResponse с авторами по убыванию количества PR или ошибку валидации/получения данных
+func (s *StatsService) GetAuthorStats(filter models.AuthorStatsFilter) (*models.AuthorStatsResponse, error) {
+	if filter.Status != "" && !contains(prStatuses, filter.Status) {
+		return nil, NewServiceError("INVALID_REQUEST", "status must be one of "+strings.Join(prStatuses, ", "))
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, NewServiceError("INVALID_REQUEST", "from must be before to")
+	}
+	if filter.Limit < 0 || filter.Limit > maxAuthorStatsLimit {
+		return nil, NewServiceError("INVALID_REQUEST", fmt.Sprintf("limit must be between 1 and %d", maxAuthorStatsLimit))
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultAuthorStatsLimit
+	}
+
+	authors, err := s.repo.GetAuthorPRCounts(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get author stats: %w", err)
+	}
+
+	return &models.AuthorStatsResponse{Authors: authors}, nil
+}
+
+// возвращает количество PR по корзинам возраста, считая возраст от создания PR до текущего момента
+// принимает: фильтр по статусу (по умолчанию OPEN), команде, границам корзин (по умолчанию 1d, 3d, 7d) и признаку разбивки по командам
+// возвращает: указатель на AgingResponse со всеми корзинами (включая пустые) или ошибку валидации/получения данных
+func (s *StatsService) GetPRAging(filter models.AgingFilter) (*models.AgingResponse, error) {
+	if filter.Status == "" {
+		filter.Status = "OPEN"
+	}
+	if !contains(prStatuses, filter.Status) {
+		return nil, NewServiceError("INVALID_REQUEST", "status must be one of "+strings.Join(prStatuses, ", "))
+	}
+	if len(filter.Boundaries) == 0 {
+		filter.Boundaries = defaultAgingBoundaries
+	}
+	if len(filter.Boundaries) > maxAgingBoundaries {
+		return nil, NewServiceError("INVALID_REQUEST", fmt.Sprintf("at most %d bucket boundaries are allowed", maxAgingBoundaries))
+	}
+	for i, boundary := range filter.Boundaries {
+		if boundary <= 0 || (i > 0 && boundary <= filter.Boundaries[i-1]) {
+			return nil, NewServiceError("INVALID_REQUEST", "buckets must be positive and strictly increasing")
+		}
+	}
+
+	counts, err := s.repo.GetPRAgeBucketCounts(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PR aging: %w", err)
+	}
+
+	labels := agingLabels(filter.Boundaries)
+	response := &models.AgingResponse{Status: filter.Status, Buckets: emptyAgeBuckets(labels)}
+	if filter.ByTeam {
+		response.Teams = make(map[string][]models.AgeBucket)
+	}
+	for _, count := range counts {
+		response.Buckets[count.Bucket].Count += count.Count
+		response.Total += count.Count
+		if filter.ByTeam {
+			if _, ok := response.Teams[count.TeamName]; !ok {
+				response.Teams[count.TeamName] = emptyAgeBuckets(labels)
+			}
+			response.Teams[count.TeamName][count.Bucket].Count += count.Count
+		}
+	}
+
+	return response, nil
+}
+
+// возвращает статистику перестановок ревьюверов по событиям REASSIGN журнала назначений
+// принимает: фильтр по периоду создания PR, команде автора PR и количеству PR в списке самых нестабильных (0 - по умолчанию)
+// возвращает: указатель на ChurnResponse с общим и средним количеством замен и PR с наибольшим количеством замен
+// или ошибку валидации/получения данных
+func (s *StatsService) GetReassignmentChurn(filter models.ChurnFilter) (*models.ChurnResponse, error) {
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, NewServiceError("INVALID_REQUEST", "from must be before to")
+	}
+	if filter.Limit < 0 || filter.Limit > maxChurnTopLimit {
+		return nil, NewServiceError("INVALID_REQUEST", fmt.Sprintf("limit must be between 1 and %d", maxChurnTopLimit))
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultChurnTopLimit
+	}
+
+	totals, err := s.repo.GetChurnTotals(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get churn totals: %w", err)
+	}
+	topPRs, err := s.repo.GetTopChurnPRs(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get top churn PRs: %w", err)
+	}
+
+	response := &models.ChurnResponse{
+		TotalReassignments: totals.Reassignments,
+		ReassignedPRs:      totals.ReassignedPRs,
+		CreatedPRs:         totals.CreatedPRs,
+		TopPRs:             topPRs,
+	}
+	if totals.CreatedPRs > 0 {
+		response.AvgReassignmentsPerPR = math.Round(float64(totals.Reassignments)/float64(totals.CreatedPRs)*100) / 100
+	}
+	return response, nil
+}
+
+// возвращает долю созданных за период PR, которые получили нужное количество ревьюверов не позже заданного времени после создания
+// принимает: фильтр по периоду создания PR, команде автора, количеству ревьюверов и допустимому времени (0 - по умолчанию)
+// возвращает: указатель на AssignmentSLAResponse, где PR с еще не истекшим сроком не учитываются в доле, или ошибку валидации/получения данных
+func (s *StatsService) GetAssignmentSLA(filter models.AssignmentSLAFilter) (*models.AssignmentSLAResponse, error) {
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, NewServiceError("INVALID_REQUEST", "from must be before to")
+	}
+	if filter.Threshold < 0 || filter.Within < 0 {
+		return nil, NewServiceError("INVALID_REQUEST", "threshold and within must be positive")
+	}
+	if filter.Threshold == 0 {
+		filter.Threshold = defaultSLAThreshold
+	}
+	if filter.Within == 0 {
+		filter.Within = defaultSLAWithin
+	}
+
+	counts, err := s.repo.GetAssignmentSLACounts(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assignment SLA counts: %w", err)
+	}
+
+	response := &models.AssignmentSLAResponse{
+		Threshold:  filter.Threshold,
+		Within:     filter.Within.String(),
+		TotalPRs:   counts.Met + counts.Missed,
+		MetPRs:     counts.Met,
+		MissedPRs:  counts.Missed,
+		PendingPRs: counts.Pending,
+	}
+	if response.TotalPRs > 0 {
+		response.Compliance = math.Round(float64(counts.Met)/float64(response.TotalPRs)*10000) / 10000
+	}
+	return response, nil
+}
+
+// возвращает страницу пар ревьюверов, которые ревьюили одни и те же PR, и количество таких PR
+// принимает: фильтр по периоду создания PR и команде автора и страницу
+// возвращает: указатель на ReviewerPairsResponse с парами по убыванию количества совместных ревью или ошибку
+func (s *StatsService) GetReviewerPairs(filter models.PairFilter, page models.Pagination) (*models.ReviewerPairsResponse, error) {
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, NewServiceError("INVALID_REQUEST", "from must be before to")
+	}
+
+	pairs, total, err := s.repo.GetReviewerPairs(filter, page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reviewer pairs: %w", err)
+	}
+	return &models.ReviewerPairsResponse{Pairs: pairs, Pagination: listPage(page, total, len(pairs))}, nil
+}
+
+// формирует подписи корзин возраста по их границам, например "<1d", "1d-3d", ">=7d"
+// принимает: границы корзин по возрастанию
+// возвращает: слайс подписей длиной на одну больше количества границ
+func agingLabels(boundaries []time.Duration) []string {
+	labels := make([]string, 0, len(boundaries)+1)
+	labels = append(labels, "<"+formatAge(boundaries[0]))
+	for i := 1; i < len(boundaries); i++ {
+		labels = append(labels, formatAge(boundaries[i-1])+"-"+formatAge(boundaries[i]))
+	}
+	return append(labels, ">="+formatAge(boundaries[len(boundaries)-1]))
+}
+
+// форматирует границу корзины возраста: целое число дней как "3d", целое число часов как "12h", иначе как длительность Go
+// принимает: длительность
+// возвращает: строковое представление
+func formatAge(age time.Duration) string {
+	const day = 24 * time.Hour
+	switch {
+	case age%day == 0:
+		return fmt.Sprintf("%dd", age/day)
+	case age%time.Hour == 0:
+		return fmt.Sprintf("%dh", age/time.Hour)
+	default:
+		return age.String()
+	}
+}
+
+// создает корзины возраста с нулевыми количествами
+// принимает: подписи корзин
+// возвращает: слайс корзин
+func emptyAgeBuckets(labels []string) []models.AgeBucket {
+	buckets := make([]models.AgeBucket, len(labels))
+	for i, label := range labels {
+		buckets[i].Label = label
+	}
+	return buckets
+}
+
+// сравнивает две команды: активные участники, назначения, нагрузка открытыми PR, средняя нагрузка на активного
+// участника и равномерность назначений, с разницей показателей команды b относительно команды a
+// принимает: названия двух команд
+// возвращает: указатель на TeamComparisonResponse или ошибку NOT_FOUND, если одной из команд нет
+func (s *StatsService) CompareTeams(teamA, teamB string) (*models.TeamComparisonResponse, error) {
+	a, err := s.teamComparisonStats(teamA)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.teamComparisonStats(teamB)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TeamComparisonResponse{
+		A: *a,
+		B: *b,
+		Delta: models.TeamComparisonDelta{
+			ActiveMembers:    b.ActiveMembers - a.ActiveMembers,
+			TotalAssignments: b.TotalAssignments - a.TotalAssignments,
+			OpenPRLoad:       b.OpenPRLoad - a.OpenPRLoad,
+			AvgLoadPerMember: math.Round((b.AvgLoadPerMember-a.AvgLoadPerMember)*100) / 100,
+			FairnessScore:    math.Round((b.FairnessScore-a.FairnessScore)*10000) / 10000,
+		},
+	}, nil
+}
+
+// собирает показатели команды для сравнения по нагрузке ее участников
+// принимает: название команды
+// возвращает: указатель на TeamComparisonStats или ошибку NOT_FOUND/получения данных
+func (s *StatsService) teamComparisonStats(teamName string) (*models.TeamComparisonStats, error) {
+	loads, exists, err := s.repo.GetTeamMemberLoads(teamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team member loads: %w", err)
+	}
+	if !exists {
+		return nil, NewServiceError("NOT_FOUND", "team not found: "+teamName)
+	}
+
+	stats := &models.TeamComparisonStats{TeamName: teamName}
+	var activeAssignments []int
+	for _, load := range loads {
+		stats.TotalAssignments += load.TotalAssignments
+		stats.OpenPRLoad += load.OpenAssignments
+		if load.IsActive {
+			stats.ActiveMembers++
+			activeAssignments = append(activeAssignments, load.TotalAssignments)
+		}
+	}
+	if stats.ActiveMembers > 0 {
+		stats.AvgLoadPerMember = math.Round(float64(stats.OpenPRLoad)/float64(stats.ActiveMembers)*100) / 100
+	}
+	stats.FairnessScore = math.Round(jainFairness(activeAssignments)*10000) / 10000
+	return stats, nil
+}
+
+// считает индекс равномерности Джейна (сумма)^2 / (n * сумма квадратов)
+// принимает: количества назначений участников
+// возвращает: значение от 1/n до 1 (1 - поровну); 1 если назначений нет
+func jainFairness(counts []int) float64 {
+	var sum, sumSquares float64
+	for _, count := range counts {
+		sum += float64(count)
+		sumSquares += float64(count) * float64(count)
+	}
+	if sumSquares == 0 {
+		return 1
+	}
+	return sum * sum / (float64(len(counts)) * sumSquares)
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// стратегии выбора ревьюверов
+const (
+	// случайный выбор среди кандидатов
+	StrategyRandom = "random"
+	// выбор кандидатов с наименьшим количеством открытых ревью, при равенстве - случайно
+	StrategyLeastLoaded = "least_loaded"
+)
+
+// источник случайности для выбора ревьюверов, подменяется для воспроизводимых результатов
+type RandomSource interface {
+	Intn(n int) int
+	Shuffle(n int, swap func(i, j int))
+}
+
+// источник случайности на основе глобального генератора math/rand, безопасен для конкурентного использования
+type globalRandom struct{}
+
+// возвращает случайное число в диапазоне [0, n)
+// принимает: верхнюю границу диапазона
+// возвращает: случайное неотрицательное число меньше n
+func (globalRandom) Intn(n int) int {
+	return rand.Intn(n)
+}
+
+// перемешивает n элементов с помощью переданной функции обмена
+// принимает: количество элементов и функцию обмена элементов местами
+// возвращает: ничего, порядок меняется через функцию swap
+func (globalRandom) Shuffle(n int, swap func(i, j int)) {
+	rand.Shuffle(n, swap)
+}
+
+// проверяет что стратегия выбора ревьюверов поддерживается
+// принимает: название стратегии
+// возвращает: true если стратегия известна
+func isKnownStrategy(strategy string) bool {
+	return strategy == StrategyRandom || strategy == StrategyLeastLoaded
+}
+
+// выбирает ревьюверов из кандидатов согласно стратегии
+// принимает: кандидатов, количество ревьюверов, стратегию, текущую нагрузку кандидатов и источник случайности
+// возвращает: слайс выбранных ревьюверов длиной не больше count
+func pickReviewers(candidates []string, count int, strategy string, loads map[string]int, rng RandomSource) []string {
+	count = min(count, len(candidates))
+	if count <= 0 {
+		return []string{}
+	}
+
+	// перемешиваем кандидатов, для least_loaded это случайный выбор среди равных по нагрузке
+	shuffled := make([]string, len(candidates))
+	copy(shuffled, candidates)
+	rng.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+
+	if strategy == StrategyLeastLoaded {
+		sort.SliceStable(shuffled, func(i, j int) bool {
+			return loads[shuffled[i]] < loads[shuffled[j]]
+		})
+	}
+
+	selected := make([]string, count)
+	copy(selected, shuffled[:count])
+	return selected
+}
+
+// выбирает ревьюверов по уровням предпочтения: сначала из первого уровня, недостающих - из следующих
+// принимает: уровни кандидатов по убыванию предпочтения, количество ревьюверов, стратегию, нагрузку и источник случайности
+// возвращает: слайс выбранных ревьюверов без повторов длиной не больше count
+func pickTiered(tiers [][]string, count int, strategy string, loads map[string]int, rng RandomSource) []string {
+	selected := []string{}
+	chosen := make(map[string]bool)
+	for _, tier := range tiers {
+		if len(selected) >= count {
+			break
+		}
+
+		var remaining []string
+		for _, candidate := range tier {
+			if !chosen[candidate] {
+				remaining = append(remaining, candidate)
+			}
+		}
+
+		for _, reviewerID := range pickReviewers(remaining, count-len(selected), strategy, loads, rng) {
+			chosen[reviewerID] = true
+			selected = append(selected, reviewerID)
+		}
+	}
+	return selected
+}
+
+// выбирает ревьюверов по уровням предпочтения так, чтобы из каждой группы ревьюверов был выбран не больше чем один участник;
+// если с этим ограничением кандидатов не хватает, недостающие добираются из пропущенных участников уже выбранных групп
+// принимает: уровни кандидатов, количество ревьюверов, стратегию, нагрузку, группы кандидатов и источник случайности
+// возвращает: слайс выбранных ревьюверов длиной не больше count и признак ослабления ограничения
+func pickOnePerGroup(tiers [][]string, count int, strategy string, loads map[string]int, groups map[string]string,
+	rng RandomSource) ([]string, bool) {
+	// упорядочиваем всех кандидатов так же, как их выбирал бы pickTiered
+	total := 0
+	for _, tier := range tiers {
+		total += len(tier)
+	}
+	ordered := pickTiered(tiers, total, strategy, loads, rng)
+
+	selected := []string{}
+	var skipped []string
+	usedGroups := make(map[string]bool)
+	for _, candidate := range ordered {
+		if len(selected) >= count {
+			break
+		}
+		groupID, grouped := groups[candidate]
+		if grouped && usedGroups[groupID] {
+			skipped = append(skipped, candidate)
+			continue
+		}
+		if grouped {
+			usedGroups[groupID] = true
+		}
+		selected = append(selected, candidate)
+	}
+
+	relaxed := false
+	for _, candidate := range skipped {
+		if len(selected) >= count {
+			break
+		}
+		selected = append(selected, candidate)
+		relaxed = true
+	}
+	return selected, relaxed
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"pull-request-reviewer-assignment-service/internal/models"
+	"sort"
+)
+
+// перераспределяет ревьюверов открытых PR между активными участниками команды, выравнивая нагрузку
+// замены применяются одной транзакцией: при отмене контекста до нее ничего не меняется
+// принимает: контекст запроса и название команды
+// возвращает: объект TeamRebalanceResponse с заменами и нагрузкой до и после или ошибку
+func (s *UserService) RebalanceTeam(ctx context.Context, teamName string) (*models.TeamRebalanceResponse, error) {
+	log.Printf("Rebalancing reviewers of team %s", teamName)
+
+	if err := s.checkAssignmentNotPaused(); err != nil {
+		return nil, err
+	}
+
+	teamExists, err := s.teamRepo.TeamExists(teamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check team existence: %w", err)
+	}
+	if !teamExists {
+		return nil, NewServiceError("NOT_FOUND", "team not found")
+	}
+
+	activeUsers, err := s.userRepo.GetActiveUsersByTeam(teamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active users: %w", err)
+	}
+
+	members := make([]string, 0, len(activeUsers))
+	for _, user := range activeUsers {
+		members = append(members, user.UserID)
+	}
+	sort.Strings(members)
+
+	prs, err := s.collectOpenPRs(ctx, members)
+	if err != nil {
+		return nil, err
+	}
+	if ctx.Err() != nil {
+		return cancelledRebalance(teamName), nil
+	}
+
+	loads := make(map[string]int, len(members))
+	for _, member := range members {
+		loads[member] = 0
+	}
+	for _, pr := range prs {
+		for _, reviewerID := range pr.AssignedReviewers {
+			if _, ok := loads[reviewerID]; ok {
+				loads[reviewerID]++
+			}
+		}
+	}
+
+	response := &models.TeamRebalanceResponse{
+		TeamName:   teamName,
+		LoadBefore: copyLoads(loads),
+	}
+	response.Changes = planRebalance(members, prs, loads)
+	response.LoadAfter = loads
+
+	if ctx.Err() != nil {
+		return cancelledRebalance(teamName), nil
+	}
+	if len(response.Changes) > 0 {
+		plan := &models.TeamSyncPlan{Replacements: response.Changes}
+		if err := s.teamRepo.UpdateTeamMembers(teamName, plan); err != nil {
+			log.Printf("Failed to rebalance team %s: %v", teamName, err)
+			if errors.Is(err, models.ErrReviewerNotAssigned) {
+				return nil, errReviewersChanged()
+			}
+			return nil, fmt.Errorf("failed to apply rebalance: %w", err)
+		}
+	}
+
+	log.Printf("Team %s rebalanced with %d changes", teamName, len(response.Changes))
+	return response, nil
+}
+
+// возвращает ответ выравнивания, отмененного до применения замен
+// принимает: название команды
+// возвращает: объект TeamRebalanceResponse без замен с признаком отмены
+func cancelledRebalance(teamName string) *models.TeamRebalanceResponse {
+	log.Printf("Rebalance of team %s cancelled, no changes applied", teamName)
+	return &models.TeamRebalanceResponse{
+		TeamName:   teamName,
+		Changes:    []models.ReviewerReplacement{},
+		LoadBefore: map[string]int{},
+		LoadAfter:  map[string]int{},
+		Cancelled:  true,
+	}
+}
+
+// собирает открытые PR, где ревьюверами назначены участники команды; после отмены контекста
+// перестает запрашивать PR следующих участников
+// принимает: контекст запроса и идентификаторы участников
+// возвращает: слайс PR без повторов, отсортированный по идентификатору, или ошибку
+func (s *UserService) collectOpenPRs(ctx context.Context, members []string) ([]*models.PullRequest, error) {
+	seen := make(map[string]bool)
+	var prs []*models.PullRequest
+	for _, userID := range members {
+		if ctx.Err() != nil {
+			break
+		}
+		openPRs, err := s.getOpenPRsWithReviewer(userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get open PRs for user %s: %w", userID, err)
+		}
+		for _, pr := range openPRs {
+			if !seen[pr.PullRequestID] {
+				seen[pr.PullRequestID] = true
+				prs = append(prs, pr)
+			}
+		}
+	}
+
+	sort.Slice(prs, func(i, j int) bool { return prs[i].PullRequestID < prs[j].PullRequestID })
+	return prs, nil
+}
+
+// жадно переносит ревью с самых загруженных участников на наименее загруженных,
+// пока разница нагрузок больше одного ревью и есть допустимый перенос
+// принимает: участников команды, открытые PR и текущую нагрузку (обновляется на месте)
+// возвращает: список замен ревьюверов в порядке применения
+func planRebalance(members []string, prs []*models.PullRequest, loads map[string]int) []models.ReviewerReplacement {
+	changes := []models.ReviewerReplacement{}
+
+	for {
+		byLoad := append([]string(nil), members...)
+		sort.SliceStable(byLoad, func(i, j int) bool { return loads[byLoad[i]] < loads[byLoad[j]] })
+
+		change, ok := findRebalanceMove(byLoad, prs, loads)
+		if !ok {
+			return changes
+		}
+		changes = append(changes, change)
+	}
+}
+
+// ищет и применяет к PR и нагрузке один перенос ревью, уменьшающий разброс нагрузки
+// принимает: участников по возрастанию нагрузки, открытые PR и текущую нагрузку
+// возвращает: выполненную замену и true, или false если перенос невозможен
+func findRebalanceMove(byLoad []string, prs []*models.PullRequest, loads map[string]int) (models.ReviewerReplacement, bool) {
+	for i := len(byLoad) - 1; i >= 0; i-- {
+		from := byLoad[i]
+		for _, to := range byLoad {
+			// перенос не уменьшает разброс
+			if loads[from]-loads[to] <= 1 {
+				break
+			}
+			for _, pr := range prs {
+				if !contains(pr.AssignedReviewers, from) || pr.AuthorID == to || contains(pr.AssignedReviewers, to) {
+					continue
+				}
+				pr.AssignedReviewers = replaceOrRemove(pr.AssignedReviewers, from, to)
+				loads[from]--
+				loads[to]++
+				return models.ReviewerReplacement{PRID: pr.PullRequestID, OldReviewerID: from, NewReviewerID: to}, true
+			}
+		}
+	}
+	return models.ReviewerReplacement{}, false
+}
+
+// копирует нагрузку участников
+// принимает: нагрузку по участникам
+// возвращает: независимую копию
+func copyLoads(loads map[string]int) map[string]int {
+	copied := make(map[string]int, len(loads))
+	for userID, load := range loads {
+		copied[userID] = load
+	}
+	return copied
+}
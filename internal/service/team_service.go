@@ -1,8 +1,10 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"pull-request-reviewer-assignment-service/internal/events"
 	"pull-request-reviewer-assignment-service/internal/models"
 	"pull-request-reviewer-assignment-service/internal/repository"
 )
@@ -11,6 +13,10 @@ import (
 type TeamService struct {
 	teamRepo repository.TeamRepository
 	userRepo repository.UserRepository
+	// сервис пользователей для переназначения ревью выбывших участников в UpdateTeamMembers
+	userService *UserService
+	// хосты, на которые команды могут направить события через SetWebhook (пусто - адреса команд отключены)
+	webhookHosts []string
 }
 
 // создает и возвращает новый экземпляр TeamService
@@ -23,32 +29,40 @@ func NewTeamService(teamRepo repository.TeamRepository, userRepo repository.User
 	}
 }
 
+// подключает сервис пользователей, через который UpdateTeamMembers переназначает ревью выбывших участников
+// принимает: указатель на UserService
+// возвращает: ничего
+func (s *TeamService) SetUserService(userService *UserService) {
+	s.userService = userService
+}
+
+// задает хосты, на которые команды могут направить события назначения через SetWebhook
+// принимает: список разрешенных хостов без порта (пустой список отключает адреса команд)
+// возвращает: ничего
+func (s *TeamService) SetWebhookHosts(hosts []string) {
+	s.webhookHosts = hosts
+}
+
 // создает новую команду и всех её участников после валидации данных
 // принимает: указатель на объект Team с данными команды и списком участников
 // возвращает: ошибку если команда уже существует или данные участников невалидны
 func (s *TeamService) CreateTeam(team *models.Team) error {
 	log.Printf("Creating team: %s with %d members", team.TeamName, len(team.Members))
 
-	// проверяем существование команды
-	exists, err := s.teamRepo.TeamExists(team.TeamName)
+	errs, err := s.ValidateTeam(team, 0)
 	if err != nil {
-		log.Printf("Failed to check team existence: %v", err)
-		return fmt.Errorf("failed to check team existence: %w", err)
-	}
-	if exists {
-		log.Printf("Team already exists: %s", team.TeamName)
-		return NewServiceError("TEAM_EXISTS", "team_name already exists")
+		return err
 	}
-
-	// валидация участников
-	for i, member := range team.Members {
-		if member.UserID == "" {
-			return NewServiceError("INVALID_REQUEST", fmt.Sprintf("user_id is required for member %d", i))
-		}
-		if member.Username == "" {
-			return NewServiceError("INVALID_REQUEST", fmt.Sprintf("username is required for member %d", i))
+	for _, fieldErr := range errs {
+		if fieldErr.Code == "TEAM_EXISTS" {
+			log.Printf("Team already exists: %s", team.TeamName)
+			return NewServiceError("TEAM_EXISTS", fieldErr.Message)
 		}
 	}
+	if len(errs) > 0 {
+		log.Printf("Team validation failed: %s, errors: %v", team.TeamName, errs)
+		return NewServiceError("INVALID_REQUEST", errs[0].Message)
+	}
 
 	log.Printf("Team validation passed, creating team: %s", team.TeamName)
 
@@ -62,6 +76,66 @@ func (s *TeamService) CreateTeam(team *models.Team) error {
 	return nil
 }
 
+// проверяет данные новой команды так же, как CreateTeam, но ничего не сохраняет и собирает все ошибки сразу
+// принимает: указатель на объект Team и максимальное количество участников (0 - без ограничения)
+// возвращает: слайс ошибок по полям (пустой если команду можно создать) или ошибку обращения к хранилищу
+func (s *TeamService) ValidateTeam(team *models.Team, maxMembers int) ([]models.FieldError, error) {
+	errs := []models.FieldError{}
+	add := func(field, code, message string) {
+		errs = append(errs, models.FieldError{Field: field, Code: code, Message: message})
+	}
+
+	if team.TeamName == "" {
+		add("team_name", models.ValidationFailedCode, "team_name is required")
+	} else {
+		exists, err := s.teamRepo.TeamExists(team.TeamName)
+		if err != nil {
+			log.Printf("Failed to check team existence: %v", err)
+			return nil, fmt.Errorf("failed to check team existence: %w", err)
+		}
+		if exists {
+			add("team_name", "TEAM_EXISTS", "team_name already exists")
+		}
+	}
+
+	if len(team.Members) == 0 {
+		add("members", models.ValidationFailedCode, "team must have at least one member")
+	}
+	if maxMembers > 0 && len(team.Members) > maxMembers {
+		add("members", models.ValidationFailedCode, fmt.Sprintf("too many items in members: limit is %d", maxMembers))
+	}
+
+	// валидация участников
+	seen := make(map[string]bool, len(team.Members))
+	for i, member := range team.Members {
+		if member.UserID == "" {
+			add(fmt.Sprintf("members[%d].user_id", i), models.ValidationFailedCode,
+				fmt.Sprintf("user_id is required for member %d", i))
+		}
+		if member.Username == "" {
+			add(fmt.Sprintf("members[%d].username", i), models.ValidationFailedCode,
+				fmt.Sprintf("username is required for member %d", i))
+		}
+		if member.UserID == "" {
+			continue
+		}
+		if seen[member.UserID] {
+			add(fmt.Sprintf("members[%d].user_id", i), models.ValidationFailedCode,
+				fmt.Sprintf("duplicate member %s", member.UserID))
+			continue
+		}
+		seen[member.UserID] = true
+
+		// пользователь с таким идентификатором может состоять в другой команде
+		if user, err := s.userRepo.GetUser(member.UserID); err == nil {
+			add(fmt.Sprintf("members[%d].user_id", i), models.ValidationFailedCode,
+				fmt.Sprintf("user %s already belongs to team %s", member.UserID, user.TeamName))
+		}
+	}
+
+	return errs, nil
+}
+
 // возвращает полную информацию о команде включая список всех участников
 // принимает: строку с названием команды для поиска в репозитории
 // возвращает: указатель на объект Team с данными или ошибку если команда не найдена
@@ -77,3 +151,124 @@ func (s *TeamService) GetTeam(teamName string) (*models.Team, error) {
 	log.Printf("Team found: %s with %d members", teamName, len(team.Members))
 	return team, nil
 }
+
+// проверяет существование команды
+// принимает: строку с названием команды
+// возвращает: ошибку NOT_FOUND если команды нет или ошибку обращения к репозиторию
+func (s *TeamService) ensureTeamExists(teamName string) error {
+	exists, err := s.teamRepo.TeamExists(teamName)
+	if err != nil {
+		return fmt.Errorf("failed to check team existence: %w", err)
+	}
+	if !exists {
+		log.Printf("Team not found: %s", teamName)
+		return NewServiceError("NOT_FOUND", "team not found")
+	}
+	return nil
+}
+
+// возвращает команду с участниками для внутренних проверок других сервисов
+// принимает: название команды
+// возвращает: указатель на Team или ошибку получения команды без преобразования в NOT_FOUND
+func (s *TeamService) loadTeam(teamName string) (*models.Team, error) {
+	team, err := s.teamRepo.GetTeam(teamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team: %w", err)
+	}
+	return team, nil
+}
+
+// возвращает стратегию выбора ревьюверов, закрепленную за командой
+// принимает: название команды
+// возвращает: название стратегии (пустая строка - не задана) или ошибку получения настроек команды
+func (s *TeamService) assignmentStrategy(teamName string) (string, error) {
+	strategy, err := s.teamRepo.GetAssignmentStrategy(teamName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get team assignment strategy: %w", err)
+	}
+	return strategy, nil
+}
+
+// закрепляет за командой стратегию выбора ревьюверов, которая важнее глобальной ASSIGNMENT_STRATEGY
+// принимает: название команды и стратегию (пустая строка - вернуться к глобальной)
+// возвращает: ошибку INVALID_REQUEST для неизвестной стратегии, NOT_FOUND если команды нет
+func (s *TeamService) SetAssignmentStrategy(teamName, strategy string) error {
+	if strategy != "" && !isKnownStrategy(strategy) {
+		return NewServiceError("INVALID_REQUEST", fmt.Sprintf("unknown strategy: %s", strategy))
+	}
+	if err := s.ensureTeamExists(teamName); err != nil {
+		return err
+	}
+
+	if err := s.teamRepo.SetAssignmentStrategy(teamName, strategy); err != nil {
+		return fmt.Errorf("failed to set team assignment strategy: %w", err)
+	}
+
+	log.Printf("Assignment strategy of team %s set to %q", teamName, strategy)
+	return nil
+}
+
+// удаляет команду вместе с ее участниками и их ревью, а при deletePRs - и с закрытыми или замерженными PR участников
+// (вместе с ревью в них, в том числе ревьюверов других команд)
+// принимает: название команды и флаг удаления PR авторства участников
+// возвращает: объект DeleteTeamResponse с количеством удаленных участников, PR и ревью или ошибку NOT_FOUND, TEAM_HAS_OPEN_PRS если участник является автором
+// или ревьювером открытого PR, TEAM_HAS_PRS если участники - авторы PR, а deletePRs не задан
+func (s *TeamService) DeleteTeam(teamName string, deletePRs bool) (*models.DeleteTeamResponse, error) {
+	log.Printf("Deleting team: %s", teamName)
+
+	if err := s.ensureTeamExists(teamName); err != nil {
+		return nil, err
+	}
+
+	response, deleted, err := s.teamRepo.DeleteTeam(teamName, deletePRs)
+	if errors.Is(err, models.ErrTeamNotFound) {
+		// команду удалили параллельным запросом после проверки выше
+		return nil, NewServiceError("NOT_FOUND", "team not found")
+	}
+	if errors.Is(err, models.ErrTeamHasPRs) {
+		log.Printf("Team %s not deleted: members authored PRs and delete_prs is not set", teamName)
+		return nil, NewServiceError("TEAM_HAS_PRS", "team members authored PRs, set delete_prs to delete them with the team")
+	}
+	if err != nil {
+		log.Printf("Failed to delete team %s: %v", teamName, err)
+		return nil, fmt.Errorf("failed to delete team: %w", err)
+	}
+	if !deleted {
+		log.Printf("Team %s not deleted: members have open PRs", teamName)
+		return nil, NewServiceError("TEAM_HAS_OPEN_PRS", "team members author or review open PRs")
+	}
+
+	log.Printf("Team %s deleted with %d members, %d PRs and %d reviews",
+		teamName, response.DeletedMembers, response.DeletedPRs, response.DeletedReviews)
+	return response, nil
+}
+
+// устанавливает адрес, на который отправляются уведомления о назначениях по PR авторов команды
+// принимает: название команды и адрес http(s) на одном из разрешенных хостов (пустая строка - вернуться к глобальному адресу)
+// возвращает: ошибку INVALID_REQUEST если адреса команд отключены или адрес некорректен либо не на разрешенном хосте,
+// NOT_FOUND если команды нет
+func (s *TeamService) SetWebhook(teamName, webhookURL string) error {
+	if webhookURL != "" {
+		// сервис сам отправляет запросы на адрес команды, поэтому произвольные адреса, включая внутренние, не принимаются
+		if len(s.webhookHosts) == 0 {
+			return NewServiceError("INVALID_REQUEST", "team webhooks are disabled: TEAM_WEBHOOK_HOSTS is not set")
+		}
+		if !events.WebhookAllowed(webhookURL, s.webhookHosts) {
+			return NewServiceError("INVALID_REQUEST", "webhook_url must be an absolute http(s) URL on one of the allowed hosts")
+		}
+	}
+	if err := s.ensureTeamExists(teamName); err != nil {
+		return err
+	}
+
+	if err := s.teamRepo.SetWebhook(teamName, webhookURL); err != nil {
+		return fmt.Errorf("failed to set team webhook: %w", err)
+	}
+
+	if webhookURL == "" {
+		log.Printf("Webhook of team %s removed, using global endpoint", teamName)
+	} else {
+		log.Printf("Webhook of team %s set", teamName)
+	}
+	return nil
+}
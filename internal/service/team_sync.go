@@ -0,0 +1,294 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"pull-request-reviewer-assignment-service/internal/models"
+	"pull-request-reviewer-assignment-service/internal/repository"
+	"sort"
+)
+
+// приводит состав команды к желаемому списку участников и возвращает примененные изменения
+// принимает: название команды и желаемый список участников
+// возвращает: объект TeamSyncResponse с изменениями и итоговым составом или ошибку валидации/применения
+func (s *UserService) SyncTeam(teamName string, desired []models.TeamMember) (*models.TeamSyncResponse, error) {
+	log.Printf("Syncing team %s to %d desired members", teamName, len(desired))
+	return s.updateTeamMembers(teamName, desired, true)
+}
+
+// добавляет и обновляет перечисленных участников команды и при deactivateMissing деактивирует остальных активных;
+// изменения состава и замены выбывших ревьюверов в открытых PR применяются в одной транзакции
+// принимает: название команды, участников и флаг деактивации неперечисленных участников
+// возвращает: объект TeamSyncResponse с изменениями и итоговым составом или ошибку валидации/применения
+func (s *UserService) updateTeamMembers(teamName string, members []models.TeamMember,
+	deactivateMissing bool) (*models.TeamSyncResponse, error) {
+	teamExists, err := s.teamRepo.TeamExists(teamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check team existence: %w", err)
+	}
+	if !teamExists {
+		return nil, NewServiceError("NOT_FOUND", "team not found")
+	}
+
+	current, err := s.teamRepo.GetTeam(teamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team: %w", err)
+	}
+
+	plan, response, err := s.planTeamSync(teamName, current.Members, members, deactivateMissing)
+	if err != nil {
+		return nil, err
+	}
+	if len(plan.Replacements) > 0 {
+		if err := s.checkAssignmentNotPaused(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.teamRepo.UpdateTeamMembers(teamName, plan); err != nil {
+		log.Printf("Failed to update members of team %s: %v", teamName, err)
+		if errors.Is(err, models.ErrReviewerNotAssigned) {
+			return nil, errReviewersChanged()
+		}
+		return nil, fmt.Errorf("failed to update team members: %w", err)
+	}
+
+	team, err := s.teamRepo.GetTeam(teamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team: %w", err)
+	}
+	response.Team = team
+
+	// выбывший ревьювер без замены снимается с PR
+	affectedPRs := make([]string, 0, len(plan.Replacements))
+	for _, replacement := range plan.Replacements {
+		affectedPRs = append(affectedPRs, replacement.PRID)
+	}
+	s.checkStaffing(affectedPRs)
+
+	log.Printf("Team %s updated: %d added, %d removed, %d activated, %d deactivated, %d PRs reassigned",
+		teamName, len(response.Added), len(response.Removed), len(response.Activated),
+		len(response.Deactivated), len(response.ReassignedPRs))
+	return response, nil
+}
+
+// вычисляет изменения состава команды и замены ревьюверов для выбывших участников
+// принимает: название команды, текущий и желаемый списки участников и флаг деактивации отсутствующих в желаемом списке
+// возвращает: план изменений, заготовку ответа или ошибку валидации
+func (s *UserService) planTeamSync(teamName string, current, desired []models.TeamMember,
+	deactivateMissing bool) (*models.TeamSyncPlan, *models.TeamSyncResponse, error) {
+	plan := &models.TeamSyncPlan{}
+	response := &models.TeamSyncResponse{
+		TeamName:      teamName,
+		Added:         []string{},
+		Removed:       []string{},
+		Activated:     []string{},
+		Deactivated:   []string{},
+		Renamed:       []string{},
+		ReassignedPRs: []models.ReassignedPR{},
+	}
+
+	currentByID := make(map[string]models.TeamMember, len(current))
+	for _, member := range current {
+		currentByID[member.UserID] = member
+	}
+
+	// итоговая активность участников после синхронизации; неперечисленные без deactivateMissing не меняются
+	finalActive := make(map[string]bool, len(current)+len(desired))
+	for _, member := range current {
+		finalActive[member.UserID] = member.IsActive
+	}
+	desiredIDs := make(map[string]bool, len(desired))
+	// выбывающие ревьюверы: деактивированные в желаемом списке и отсутствующие в нем
+	var removed []string
+
+	for i, member := range desired {
+		if err := validateTeamMember(i, member, desiredIDs); err != nil {
+			return nil, nil, err
+		}
+		desiredIDs[member.UserID] = true
+		finalActive[member.UserID] = member.IsActive
+
+		existing, ok := currentByID[member.UserID]
+		if !ok {
+			if err := checkNotInOtherTeam(s.userRepo, member.UserID); err != nil {
+				return nil, nil, err
+			}
+			plan.Added = append(plan.Added, member)
+			response.Added = append(response.Added, member.UserID)
+			continue
+		}
+
+		if existing.Username == member.Username && existing.IsActive == member.IsActive {
+			continue
+		}
+		plan.Updated = append(plan.Updated, member)
+		if existing.Username != member.Username {
+			response.Renamed = append(response.Renamed, member.UserID)
+		}
+		if !existing.IsActive && member.IsActive {
+			response.Activated = append(response.Activated, member.UserID)
+		}
+		if existing.IsActive && !member.IsActive {
+			response.Deactivated = append(response.Deactivated, member.UserID)
+			removed = append(removed, member.UserID)
+		}
+	}
+
+	// отсутствующие в желаемом списке активные участники выбывают: деактивируются с переназначением ревью
+	for _, member := range current {
+		if !deactivateMissing || desiredIDs[member.UserID] || !member.IsActive {
+			continue
+		}
+		member.IsActive = false
+		finalActive[member.UserID] = false
+		plan.Updated = append(plan.Updated, member)
+		removed = append(removed, member.UserID)
+		response.Removed = append(response.Removed, member.UserID)
+	}
+
+	if err := s.checkFinalActiveMembers(teamName, finalActive); err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.planReplacements(plan, response, removed, finalActive); err != nil {
+		return nil, nil, err
+	}
+
+	return plan, response, nil
+}
+
+// проверяет обязательные поля участника из списка и отсутствие повторов
+// принимает: позицию участника в списке, участника и уже встреченные идентификаторы
+// возвращает: ошибку INVALID_REQUEST или nil
+func validateTeamMember(i int, member models.TeamMember, seen map[string]bool) error {
+	if member.UserID == "" {
+		return NewServiceError("INVALID_REQUEST", fmt.Sprintf("user_id is required for member %d", i))
+	}
+	if member.Username == "" {
+		return NewServiceError("INVALID_REQUEST", fmt.Sprintf("username is required for member %d", i))
+	}
+	if seen[member.UserID] {
+		return NewServiceError("INVALID_REQUEST", fmt.Sprintf("duplicate member %s", member.UserID))
+	}
+	return nil
+}
+
+// проверяет что новый участник команды не состоит в другой команде: пользователь всегда принадлежит одной команде
+// принимает: репозиторий пользователей и идентификатор нового участника
+// возвращает: ошибку INVALID_REQUEST если пользователь уже существует или nil
+func checkNotInOtherTeam(userRepo repository.UserRepository, userID string) error {
+	if user, err := userRepo.GetUser(userID); err == nil {
+		return NewServiceError("INVALID_REQUEST",
+			fmt.Sprintf("user %s already belongs to team %s", userID, user.TeamName))
+	}
+	return nil
+}
+
+// проверяет что после синхронизации в команде останется не меньше MinActivePerTeam активных участников
+// принимает: название команды и итоговую активность участников
+// возвращает: ошибку TEAM_TOO_SMALL если порог будет нарушен или nil
+func (s *UserService) checkFinalActiveMembers(teamName string, finalActive map[string]bool) error {
+	if s.cfg.MinActivePerTeam <= 0 {
+		return nil
+	}
+
+	activeCount := 0
+	for _, active := range finalActive {
+		if active {
+			activeCount++
+		}
+	}
+	if activeCount < s.cfg.MinActivePerTeam {
+		return NewServiceError("TEAM_TOO_SMALL",
+			fmt.Sprintf("at least %d active members must remain in team %s", s.cfg.MinActivePerTeam, teamName))
+	}
+	return nil
+}
+
+// подбирает замены выбывшим ревьюверам в их открытых PR среди итоговых активных участников команды
+// принимает: план и ответ для заполнения, выбывших участников и итоговую активность участников
+// возвращает: ошибку получения PR
+func (s *UserService) planReplacements(plan *models.TeamSyncPlan, response *models.TeamSyncResponse,
+	removed []string, finalActive map[string]bool) error {
+	// текущие и планируемые ревьюверы по PR, чтобы учесть несколько выбывших в одном PR
+	oldReviewers := make(map[string][]string)
+	newReviewers := make(map[string][]string)
+	var prOrder []string
+
+	for _, userID := range removed {
+		openPRs, err := s.getOpenPRsWithReviewer(userID)
+		if err != nil {
+			return fmt.Errorf("failed to get open PRs for user %s: %w", userID, err)
+		}
+
+		for _, pr := range openPRs {
+			if _, seen := newReviewers[pr.PullRequestID]; !seen {
+				oldReviewers[pr.PullRequestID] = pr.AssignedReviewers
+				newReviewers[pr.PullRequestID] = append([]string(nil), pr.AssignedReviewers...)
+				prOrder = append(prOrder, pr.PullRequestID)
+			}
+			reviewers := newReviewers[pr.PullRequestID]
+
+			// выбираем первого подходящего кандидата, как при массовой деактивации
+			replacement := ""
+			for _, member := range sortedActive(finalActive) {
+				if member != pr.AuthorID && !contains(reviewers, member) {
+					replacement = member
+					break
+				}
+			}
+
+			plan.Replacements = append(plan.Replacements, models.ReviewerReplacement{
+				PRID: pr.PullRequestID, OldReviewerID: userID, NewReviewerID: replacement,
+			})
+			newReviewers[pr.PullRequestID] = replaceOrRemove(reviewers, userID, replacement)
+		}
+	}
+
+	for _, prID := range prOrder {
+		response.ReassignedPRs = append(response.ReassignedPRs, models.ReassignedPR{
+			PRID:         prID,
+			OldReviewers: oldReviewers[prID],
+			NewReviewers: newReviewers[prID],
+		})
+	}
+	return nil
+}
+
+// возвращает идентификаторы активных участников в детерминированном порядке
+// принимает: итоговую активность участников
+// возвращает: отсортированный слайс идентификаторов активных участников
+func sortedActive(finalActive map[string]bool) []string {
+	var active []string
+	for userID, isActive := range finalActive {
+		if isActive {
+			active = append(active, userID)
+		}
+	}
+	sort.Strings(active)
+	return active
+}
+
+// заменяет ревьювера в списке или удаляет его, если замена не найдена
+// принимает: список ревьюверов, старого ревьювера и нового (пустая строка - удалить)
+// возвращает: новый список ревьюверов
+func replaceOrRemove(reviewers []string, old, new string) []string {
+	result := make([]string, 0, len(reviewers))
+	for _, reviewer := range reviewers {
+		if reviewer != old {
+			result = append(result, reviewer)
+		} else if new != "" {
+			result = append(result, new)
+		}
+	}
+	return result
+}
+
+// возвращает ошибку для замены, которая опоздала: ревьювер уже снят с PR параллельным запросом
+// принимает: ничего
+// возвращает: ошибку NOT_ASSIGNED с просьбой повторить запрос
+func errReviewersChanged() error {
+	return NewServiceError("NOT_ASSIGNED", "PR reviewers changed while the request was processed, retry the request")
+}
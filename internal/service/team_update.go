@@ -0,0 +1,23 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"pull-request-reviewer-assignment-service/internal/models"
+)
+
+// частично обновляет состав команды: добавляет новых участников и обновляет имя и активность перечисленных,
+// а при deactivateMissing деактивирует активных участников, отсутствующих в списке (пользователь всегда принадлежит команде,
+// поэтому удаление не выполняется). Изменения состава и замены выбывших ревьюверов в открытых PR применяются
+// в одной транзакции тем же планом, что и /team/sync
+// принимает: название команды, участников для добавления или обновления и флаг деактивации отсутствующих участников
+// возвращает: объект TeamSyncResponse с изменениями и итоговым составом или ошибку NOT_FOUND/INVALID_REQUEST/TEAM_TOO_SMALL
+func (s *TeamService) UpdateTeamMembers(teamName string, members []models.TeamMember,
+	deactivateMissing bool) (*models.TeamSyncResponse, error) {
+	log.Printf("Updating %d members of team %s (deactivate missing: %t)", len(members), teamName, deactivateMissing)
+
+	if s.userService == nil {
+		return nil, fmt.Errorf("user service is not configured for team updates")
+	}
+	return s.userService.updateTeamMembers(teamName, members, deactivateMissing)
+}
@@ -1,10 +1,12 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"pull-request-reviewer-assignment-service/internal/models"
 	"pull-request-reviewer-assignment-service/internal/repository"
+	"strings"
 	"time"
 )
 
@@ -14,25 +16,174 @@ type UserService struct {
 	prRepo     repository.PRRepository
 	teamRepo   repository.TeamRepository
 	reviewRepo repository.ReviewRepository
+	cfg        Config
+	// пауза назначения ревьюверов, общая с сервисом PR (nil - пауза не подключена)
+	pause *AssignmentPause
+	// сервис PR, публикующий PR_UNDERSTAFFED после переназначений (nil - события не публикуются)
+	prService *PRService
 }
 
 // создает и возвращает новый экземпляр UserService
-// принимает: репозитории пользователей, PR, команд и ревью для внедрения зависимостей
+// принимает: репозитории пользователей, PR, команд и ревью для внедрения зависимостей и настройки сервиса
 // возвращает: указатель на созданный UserService
 func NewUserService(userRepo repository.UserRepository, prRepo repository.PRRepository,
-	teamRepo repository.TeamRepository, reviewRepo repository.ReviewRepository) *UserService {
+	teamRepo repository.TeamRepository, reviewRepo repository.ReviewRepository, cfg Config) *UserService {
 	return &UserService{
 		userRepo:   userRepo,
 		prRepo:     prRepo,
 		teamRepo:   teamRepo,
 		reviewRepo: reviewRepo,
+		cfg:        cfg,
 	}
 }
 
+// подключает сервис PR, который проверяет укомплектованность PR после деактивации их ревьюверов
+// принимает: указатель на PRService
+// возвращает: ничего
+func (s *UserService) SetPRService(prService *PRService) {
+	s.prService = prService
+}
+
+// публикует PR_UNDERSTAFFED для PR, у которых после деактивации ревьюверов не хватает активных ревьюверов
+// принимает: идентификаторы затронутых PR (повторы проверяются один раз)
+// возвращает: ничего
+func (s *UserService) checkStaffing(prIDs []string) {
+	if s.prService == nil {
+		return
+	}
+	checked := make(map[string]bool, len(prIDs))
+	for _, prID := range prIDs {
+		if !checked[prID] {
+			checked[prID] = true
+			s.prService.checkStaffing(prID)
+		}
+	}
+}
+
+// создает пользователя в существующей команде, не пересоздавая команду
+// принимает: объект User с идентификатором, именем, названием команды и флагом активности
+// возвращает: указатель на созданный User или ошибку INVALID_REQUEST, NOT_FOUND если команды нет, USER_EXISTS
+func (s *UserService) CreateUser(user models.User) (*models.User, error) {
+	log.Printf("Creating user %s in team %s", user.UserID, user.TeamName)
+
+	if user.UserID == "" {
+		return nil, NewServiceError("INVALID_REQUEST", "user_id is required")
+	}
+	if user.Username == "" {
+		return nil, NewServiceError("INVALID_REQUEST", "username is required")
+	}
+	if user.TeamName == "" {
+		return nil, NewServiceError("INVALID_REQUEST", "team_name is required")
+	}
+
+	teamExists, err := s.teamRepo.TeamExists(user.TeamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check team existence: %w", err)
+	}
+	if !teamExists {
+		return nil, NewServiceError("NOT_FOUND", "team not found")
+	}
+
+	exists, err := s.userRepo.UserExists(user.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check user existence: %w", err)
+	}
+	if exists {
+		log.Printf("User already exists: %s", user.UserID)
+		return nil, NewServiceError("USER_EXISTS", "user id already exists")
+	}
+
+	if err := s.userRepo.CreateUser(&user); err != nil {
+		log.Printf("Failed to create user %s: %v", user.UserID, err)
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	log.Printf("User created successfully: %s", user.UserID)
+	return &user, nil
+}
+
+// переводит пользователя в другую команду. Перед переводом пользователь снимается с открытых PR, автор которых
+// не состоит в новой команде: заменой выбирается активный участник команды автора. Если замены нет, пользователь
+// остается ревьювером такого PR, это логируется
+// принимает: идентификатор пользователя и название новой команды
+// возвращает: объект TransferUserResponse с обновленным пользователем и переназначенными PR
+// или ошибку NOT_FOUND/TEAM_TOO_SMALL/сохранения
+func (s *UserService) TransferUser(userID, newTeamName string) (*models.TransferUserResponse, error) {
+	log.Printf("Transferring user %s to team %s", userID, newTeamName)
+
+	user, err := s.userRepo.GetUser(userID)
+	if err != nil {
+		log.Printf("User not found: %s, error: %v", userID, err)
+		return nil, NewServiceError("NOT_FOUND", "user not found")
+	}
+
+	teamExists, err := s.teamRepo.TeamExists(newTeamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check team existence: %w", err)
+	}
+	if !teamExists {
+		return nil, NewServiceError("NOT_FOUND", "team not found")
+	}
+
+	response := &models.TransferUserResponse{PreviousTeam: user.TeamName, ReassignedPRs: []string{}}
+	if user.TeamName == newTeamName {
+		response.User = user
+		return response, nil
+	}
+
+	// активный участник покидает прежнюю команду так же, как при деактивации
+	if user.IsActive {
+		if err := s.checkMinActiveMembers(user.TeamName, 1); err != nil {
+			return nil, err
+		}
+	}
+
+	openPRs, err := s.getOpenPRsWithReviewer(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get open PRs for user %s: %w", userID, err)
+	}
+	if len(openPRs) > 0 {
+		if err := s.checkAssignmentNotPaused(); err != nil {
+			return nil, err
+		}
+	}
+	members := newActiveMembersCache(s.userRepo)
+	excluded := map[string]bool{userID: true}
+	for _, pr := range openPRs {
+		author, err := s.userRepo.GetUser(pr.AuthorID)
+		if err != nil {
+			log.Printf("Failed to get author %s of PR %s: %v", pr.AuthorID, pr.PullRequestID, err)
+			continue
+		}
+		if author.TeamName == newTeamName {
+			continue
+		}
+		reassignedPR, err := s.reassignReviewerInPR(pr.PullRequestID, userID, author.TeamName, members, excluded)
+		if err != nil {
+			log.Printf("Warning: user %s stays reviewer of PR %s after transfer: %v", userID, pr.PullRequestID, err)
+			continue
+		}
+		if reassignedPR != nil {
+			response.ReassignedPRs = append(response.ReassignedPRs, reassignedPR.PRID)
+		}
+	}
+
+	user.TeamName = newTeamName
+	if err := s.userRepo.UpdateUser(user); err != nil {
+		log.Printf("Failed to transfer user %s: %v", userID, err)
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+	response.User = user
+
+	log.Printf("User %s transferred from %s to %s, %d PRs reassigned",
+		userID, response.PreviousTeam, newTeamName, len(response.ReassignedPRs))
+	return response, nil
+}
+
 // изменяет статус активности пользователя и сохраняет изменения в базе данных
-// принимает: идентификатор пользователя и булево значение для установки активности
+// принимает: идентификатор пользователя, булево значение для установки активности и инициатора изменения для журнала
 // возвращает: обновленный объект User или ошибку если пользователь не найден
-func (s *UserService) SetUserActive(userID string, isActive bool) (*models.User, error) {
+func (s *UserService) SetUserActive(userID string, isActive bool, actor string) (*models.User, error) {
 	log.Printf("Setting user activity: %s -> %t", userID, isActive)
 
 	// получаем пользователя
@@ -42,14 +193,28 @@ func (s *UserService) SetUserActive(userID string, isActive bool) (*models.User,
 		return nil, NewServiceError("NOT_FOUND", "user not found")
 	}
 
-	// обновляем активность
-	user.IsActive = isActive
+	// проверяем что деактивация не оставит команду без минимального числа активных участников
+	if user.IsActive && !isActive {
+		if err := s.checkMinActiveMembers(user.TeamName, 1); err != nil {
+			return nil, err
+		}
+	}
 
-	// сохраняем изменения
-	if err := s.userRepo.UpdateUser(user); err != nil {
+	// обновляем активность и записываем изменение в журнал
+	if err := s.userRepo.SetUserActive(userID, isActive, actor); err != nil {
 		log.Printf("Failed to update user: %s, error: %v", userID, err)
 		return nil, fmt.Errorf("failed to update user: %w", err)
 	}
+	user.IsActive = isActive
+
+	// ревью деактивированного пользователя не переназначаются, его PR могут остаться без нужного числа ревьюверов
+	if !isActive {
+		openPRs, err := s.getOpenPRsWithReviewer(userID)
+		if err != nil {
+			log.Printf("Failed to get open PRs for user %s: %v", userID, err)
+		}
+		s.checkStaffing(prIDsOf(openPRs))
+	}
 
 	log.Printf("User activity updated: %s -> %t", userID, isActive)
 	return user, nil
@@ -66,43 +231,157 @@ func (s *UserService) GetUser(userID string) (*models.User, error) {
 	return user, nil
 }
 
-// возвращает список Pull Request назначенных пользователю на ревью если пользователь активен
-// принимает: строку с идентификатором пользователя для поиска назначенных PR
-// возвращает: слайс сокращенных объектов PullRequestShort или ошибку если пользователь не найден
-func (s *UserService) GetUserReviewPRs(userID string) ([]*models.PullRequestShort, error) {
+// возвращает страницу Pull Request назначенных пользователю на ревью если пользователь активен
+// принимает: идентификатор пользователя и страницу (limit меньше 1 - все PR)
+// возвращает: сокращенные объекты PullRequestShort страницы, страницу с общим количеством или ошибку если пользователь не найден
+func (s *UserService) GetUserReviewPRs(userID string, page models.Pagination) ([]*models.PullRequestShort, models.Pagination, error) {
 	log.Printf("Getting PRs for user review: %s", userID)
 
 	// проверяем существование пользователя и его активность
 	user, err := s.userRepo.GetUser(userID)
 	if err != nil {
 		log.Printf("User not found: %s, error: %v", userID, err)
-		return nil, NewServiceError("NOT_FOUND", "user not found")
+		return nil, page, NewServiceError("NOT_FOUND", "user not found")
 	}
 
 	// проверяем что пользователь активен
 	if !user.IsActive {
 		log.Printf("User %s is inactive, returning empty PR list", userID)
-		return []*models.PullRequestShort{}, nil
+		return []*models.PullRequestShort{}, page, nil
 	}
 
 	// получаем PR из репозитория
-	prs, err := s.prRepo.GetPRsByReviewer(userID)
+	prs, total, err := s.prRepo.GetPRsByReviewer(userID, page)
 	if err != nil {
 		log.Printf("Failed to get PRs for user: %s, error: %v", userID, err)
-		return nil, fmt.Errorf("failed to get user PRs: %w", err)
+		return nil, page, fmt.Errorf("failed to get user PRs: %w", err)
+	}
+
+	log.Printf("Found %d of %d PRs for user: %s", len(prs), total, userID)
+	return prs, listPage(page, total, len(prs)), nil
+}
+
+// возвращает страницу открытых Pull Request, которые ждут ревью пользователя дольше указанного времени
+// принимает: идентификатор пользователя, минимальный возраст PR и страницу
+// возвращает: PR страницы от самого старого к самому новому, страницу с общим количеством или ошибку если пользователь не найден
+func (s *UserService) GetBlockingPRs(userID string, olderThan time.Duration, page models.Pagination) ([]*models.BlockingPR, models.Pagination, error) {
+	log.Printf("Getting PRs blocked by reviewer %s for longer than %v", userID, olderThan)
+
+	if _, err := s.userRepo.GetUser(userID); err != nil {
+		log.Printf("User not found: %s, error: %v", userID, err)
+		return nil, page, NewServiceError("NOT_FOUND", "user not found")
+	}
+
+	prs, total, err := s.prRepo.GetOpenPRsByReviewerCreatedBefore(userID, time.Now().Add(-olderThan), page)
+	if err != nil {
+		log.Printf("Failed to get blocking PRs for user: %s, error: %v", userID, err)
+		return nil, page, fmt.Errorf("failed to get blocking PRs: %w", err)
+	}
+
+	log.Printf("Found %d PRs blocked by reviewer %s", total, userID)
+	return prs, listPage(page, total, len(prs)), nil
+}
+
+// возвращает страницу ревьюверов Pull Request автора и количество его PR у каждого из них
+// принимает: идентификатор автора, статус PR для фильтрации (пустая строка - все статусы) и страницу
+// возвращает: ревьюверов страницы от самого загруженного PR автора, страницу с общим количеством или ошибку если автор
+// не найден или статус неизвестен
+func (s *UserService) GetAuthorReviewers(authorID, status string, page models.Pagination) ([]models.AuthorReviewer, models.Pagination, error) {
+	log.Printf("Getting reviewers of PRs authored by %s (status %q)", authorID, status)
+
+	if status != "" && !contains(prStatuses, status) {
+		return nil, page, NewServiceError("INVALID_REQUEST", "status must be one of "+strings.Join(prStatuses, ", "))
+	}
+
+	if _, err := s.userRepo.GetUser(authorID); err != nil {
+		log.Printf("User not found: %s, error: %v", authorID, err)
+		return nil, page, NewServiceError("NOT_FOUND", "user not found")
+	}
+
+	reviewers, total, err := s.prRepo.GetReviewersByAuthor(authorID, status, page)
+	if err != nil {
+		log.Printf("Failed to get reviewers of author: %s, error: %v", authorID, err)
+		return nil, page, fmt.Errorf("failed to get author reviewers: %w", err)
+	}
+
+	log.Printf("Found %d reviewers of PRs authored by %s", total, authorID)
+	return reviewers, listPage(page, total, len(reviewers)), nil
+}
+
+// возвращает страницу истории изменений активности пользователя
+// принимает: идентификатор пользователя, границы периода [from, to) (nil - без ограничения) и страницу
+// возвращает: события ACTIVATE/DEACTIVATE страницы в хронологическом порядке, страницу с общим количеством
+// или ошибку NOT_FOUND/INVALID_REQUEST
+func (s *UserService) GetStatusHistory(userID string, from, to *time.Time, page models.Pagination) ([]models.UserStatusEvent, models.Pagination, error) {
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, page, NewServiceError("INVALID_REQUEST", "from must be before to")
+	}
+
+	if _, err := s.userRepo.GetUser(userID); err != nil {
+		log.Printf("User not found: %s, error: %v", userID, err)
+		return nil, page, NewServiceError("NOT_FOUND", "user not found")
+	}
+
+	events, total, err := s.userRepo.GetStatusEvents(userID, from, to, page)
+	if err != nil {
+		log.Printf("Failed to get status history of user: %s, error: %v", userID, err)
+		return nil, page, fmt.Errorf("failed to get status history: %w", err)
+	}
+	return events, listPage(page, total, len(events)), nil
+}
+
+// восстанавливает по журналу членства список команд, в которых состоял пользователь
+// принимает: идентификатор пользователя
+// возвращает: слайс периодов членства от ранних к поздним или ошибку NOT_FOUND/получения журнала
+func (s *UserService) GetTeamHistory(userID string) ([]models.TeamMembership, error) {
+	if _, err := s.userRepo.GetUser(userID); err != nil {
+		log.Printf("User not found: %s, error: %v", userID, err)
+		return nil, NewServiceError("NOT_FOUND", "user not found")
+	}
+
+	events, err := s.userRepo.GetTeamMembershipEvents(userID)
+	if err != nil {
+		log.Printf("Failed to get team history of user: %s, error: %v", userID, err)
+		return nil, fmt.Errorf("failed to get team history: %w", err)
 	}
 
-	log.Printf("Found %d PRs for user: %s", len(prs), userID)
-	return prs, nil
+	history := make([]models.TeamMembership, 0)
+	open := -1
+	for _, event := range events {
+		leftAt := event.CreatedAt
+		switch event.EventType {
+		case models.TeamMembershipJoin:
+			// вступление в новую команду без записанного выхода завершает предыдущее членство
+			if open >= 0 {
+				history[open].LeftAt = &leftAt
+			}
+			history = append(history, models.TeamMembership{TeamName: event.TeamName, JoinedAt: event.CreatedAt})
+			open = len(history) - 1
+		case models.TeamMembershipLeave:
+			if open >= 0 && history[open].TeamName == event.TeamName {
+				history[open].LeftAt = &leftAt
+				open = -1
+			}
+		}
+	}
+	return history, nil
 }
 
-// массово деактивирует пользователей команды и переназначает их открытые PR на других ревьюверов
-// принимает: название команды и список идентификаторов пользователей для деактивации
+// массово деактивирует пользователей команды и переназначает их открытые PR на других ревьюверов;
+// пользователи обрабатываются по одному (деактивация и замены в его PR) без общей транзакции и без пула BulkConcurrency,
+// потому что следующие замены учитывают уже выбранных; после отмены контекста текущий пользователь дообрабатывается,
+// а остальные не трогаются
+// принимает: контекст запроса, название команды, список идентификаторов пользователей для деактивации и инициатора для журнала
 // возвращает: объект BulkDeactivateResponse со статистикой операции или ошибку выполнения
-func (s *UserService) BulkDeactivateUsers(teamName string, userIDs []string) (*models.BulkDeactivateResponse, error) {
+func (s *UserService) BulkDeactivateUsers(ctx context.Context, teamName string, userIDs []string,
+	actor string) (*models.BulkDeactivateResponse, error) {
 	startTime := time.Now()
 	log.Printf("Starting bulk deactivation for team %s, users: %v", teamName, userIDs)
 
+	if err := s.checkAssignmentNotPaused(); err != nil {
+		return nil, err
+	}
+
 	teamExists, err := s.teamRepo.TeamExists(teamName)
 	if err != nil {
 		return nil, NewServiceError("INTERNAL_ERROR", err.Error())
@@ -111,29 +390,50 @@ func (s *UserService) BulkDeactivateUsers(teamName string, userIDs []string) (*m
 		return nil, NewServiceError("NOT_FOUND", "team not found")
 	}
 
-	deactivatedUsers := make([]string, 0)
+	// считаем сколько активных участников команды будет деактивировано
+	toDeactivate := make(map[string]bool)
+	var teamUsers []string
 	for _, userID := range userIDs {
 		user, err := s.userRepo.GetUser(userID)
-		if err != nil {
+		if err != nil || user.TeamName != teamName {
 			continue
 		}
+		teamUsers = append(teamUsers, userID)
+		if user.IsActive {
+			toDeactivate[userID] = true
+		}
+	}
+	if err := s.checkMinActiveMembers(teamName, len(toDeactivate)); err != nil {
+		return nil, err
+	}
 
-		if user.TeamName != teamName {
-			continue
+	// состав команды запрашивается один раз на все замены, а не для каждого PR;
+	// участники из запроса не становятся заменой, даже если их очередь еще не дошла
+	members := newActiveMembersCache(s.userRepo)
+	excluded := make(map[string]bool, len(teamUsers))
+	for _, userID := range teamUsers {
+		excluded[userID] = true
+	}
+
+	deactivatedUsers := make([]string, 0)
+	reassignedPRs := make([]models.ReassignedPR, 0)
+	var affectedPRs []string
+	cancelled := false
+	for _, userID := range teamUsers {
+		if ctx.Err() != nil {
+			log.Printf("Bulk deactivation cancelled after %d of %d users: %v", len(deactivatedUsers), len(teamUsers), ctx.Err())
+			cancelled = true
+			break
 		}
 
-		user.IsActive = false
-		if err := s.userRepo.UpdateUser(user); err != nil {
+		if err := s.userRepo.SetUserActive(userID, false, actor); err != nil {
 			return nil, NewServiceError("INTERNAL_ERROR", err.Error())
 		}
+		members.removeMember(teamName, userID)
 
 		deactivatedUsers = append(deactivatedUsers, userID)
 		log.Printf("User deactivated: %s", userID)
-	}
-
-	reassignedPRs := make([]models.ReassignedPR, 0)
 
-	for _, userID := range deactivatedUsers {
 		openPRs, err := s.getOpenPRsWithReviewer(userID)
 		if err != nil {
 			log.Printf("Failed to get open PRs for user %s: %v", userID, err)
@@ -141,9 +441,10 @@ func (s *UserService) BulkDeactivateUsers(teamName string, userIDs []string) (*m
 		}
 
 		log.Printf("User %s has %d open PRs for reassignment", userID, len(openPRs))
+		affectedPRs = append(affectedPRs, prIDsOf(openPRs)...)
 
 		for _, pr := range openPRs {
-			reassignedPR, err := s.reassignReviewerInPR(pr.PullRequestID, userID, teamName)
+			reassignedPR, err := s.reassignReviewerInPR(pr.PullRequestID, userID, teamName, members, excluded)
 			if err != nil {
 				log.Printf("Failed to reassign PR %s: %v", pr.PullRequestID, err)
 				continue
@@ -156,6 +457,8 @@ func (s *UserService) BulkDeactivateUsers(teamName string, userIDs []string) (*m
 		}
 	}
 
+	s.checkStaffing(affectedPRs)
+
 	// проверяем время выполнения
 	executionTime := time.Since(startTime)
 	log.Printf("Bulk deactivation completed in %v", executionTime)
@@ -169,15 +472,39 @@ func (s *UserService) BulkDeactivateUsers(teamName string, userIDs []string) (*m
 		ReassignedPRs:    reassignedPRs,
 		TotalProcessed:   len(deactivatedUsers),
 		ReassignedCount:  len(reassignedPRs),
+		Cancelled:        cancelled,
 	}, nil
 }
 
+// проверяет что после деактивации в команде останется не меньше MinActivePerTeam активных участников
+// принимает: название команды и количество активных участников, которых планируется деактивировать
+// возвращает: ошибку TEAM_TOO_SMALL если порог будет нарушен или nil
+func (s *UserService) checkMinActiveMembers(teamName string, deactivateCount int) error {
+	if s.cfg.MinActivePerTeam <= 0 || deactivateCount == 0 {
+		return nil
+	}
+
+	activeUsers, err := s.userRepo.GetActiveUsersByTeam(teamName)
+	if err != nil {
+		return fmt.Errorf("failed to get active users: %w", err)
+	}
+
+	remaining := len(activeUsers) - deactivateCount
+	if remaining < s.cfg.MinActivePerTeam {
+		log.Printf("Deactivation rejected for team %s: %d active would remain, minimum is %d",
+			teamName, remaining, s.cfg.MinActivePerTeam)
+		return NewServiceError("TEAM_TOO_SMALL",
+			fmt.Sprintf("at least %d active members must remain in team %s", s.cfg.MinActivePerTeam, teamName))
+	}
+	return nil
+}
+
 // возвращает список открытых Pull Request где пользователь назначен ревьювером
 // принимает: идентификатор пользователя для поиска назначенных открытых PR
 // возвращает: слайс полных объектов PullRequest или ошибку выполнения запроса
 func (s *UserService) getOpenPRsWithReviewer(userID string) ([]*models.PullRequest, error) {
 	// Получаем все PR пользователя
-	prShorts, err := s.prRepo.GetPRsByReviewer(userID)
+	prShorts, _, err := s.prRepo.GetPRsByReviewer(userID, models.Pagination{})
 	if err != nil {
 		return nil, err
 	}
@@ -198,11 +525,18 @@ func (s *UserService) getOpenPRsWithReviewer(userID string) ([]*models.PullReque
 }
 
 // переназначает одного ревьювера на другого активного пользователя из той же команды в Pull Request
-// принимает: идентификатор PR, идентификатор старого ревьювера и название команды для поиска замены
+// принимает: идентификатор PR, идентификатор старого ревьювера, название команды для поиска замены,
+// снимок активных участников и пользователей, которых нельзя выбирать заменой
 // возвращает: объект ReassignedPR с информацией о переназначении или ошибку выполнения операции
-func (s *UserService) reassignReviewerInPR(prID, oldReviewerID, teamName string) (*models.ReassignedPR, error) {
+func (s *UserService) reassignReviewerInPR(prID, oldReviewerID, teamName string,
+	members *activeMembersCache, excluded map[string]bool) (*models.ReassignedPR, error) {
 	log.Printf("Reassigning reviewer in PR %s: %s -> ?", prID, oldReviewerID)
 
+	// пауза могла быть включена, пока операция уже шла
+	if err := s.checkAssignmentNotPaused(); err != nil {
+		return nil, err
+	}
+
 	// получаем текущих ревьюверов
 	currentReviewers, err := s.reviewRepo.GetAssignedReviewers(prID)
 	if err != nil {
@@ -220,8 +554,24 @@ func (s *UserService) reassignReviewerInPR(prID, oldReviewerID, teamName string)
 		return nil, fmt.Errorf("failed to get PR: %w", err)
 	}
 
+	// единственного ревьювера из обязательной команды автора заменяет участник той же обязательной команды
+	if len(s.cfg.RequiredReviewerTeams) > 0 {
+		author, err := s.userRepo.GetUser(pr.AuthorID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get author: %w", err)
+		}
+		requiredTeam, err := requiredReplacementTeam(s.cfg, s.teamRepo, author.TeamName, currentReviewers, oldReviewerID)
+		if err != nil {
+			return nil, err
+		}
+		if requiredTeam != "" && requiredTeam != teamName {
+			log.Printf("Reviewer %s is the only one from required team %s in PR %s, replacing from that team", oldReviewerID, requiredTeam, prID)
+			teamName = requiredTeam
+		}
+	}
+
 	// находим активных пользователей команды для замены
-	availableUsers, err := s.userRepo.GetActiveUsersByTeam(teamName)
+	availableUsers, err := members.get(teamName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get active users: %w", err)
 	}
@@ -231,6 +581,7 @@ func (s *UserService) reassignReviewerInPR(prID, oldReviewerID, teamName string)
 	for _, user := range availableUsers {
 		if user.UserID != pr.AuthorID &&
 			user.UserID != oldReviewerID &&
+			!excluded[user.UserID] &&
 			!contains(currentReviewers, user.UserID) {
 			candidates = append(candidates, user.UserID)
 		}
@@ -244,7 +595,7 @@ func (s *UserService) reassignReviewerInPR(prID, oldReviewerID, teamName string)
 	newReviewerID := candidates[0]
 
 	// выполняем замену
-	if err := s.reviewRepo.ReplaceReviewer(prID, oldReviewerID, newReviewerID); err != nil {
+	if err := s.reviewRepo.ReplaceReviewerIfOpen(prID, oldReviewerID, newReviewerID); err != nil {
 		return nil, fmt.Errorf("failed to replace reviewer: %w", err)
 	}
 
@@ -267,6 +618,17 @@ func (s *UserService) reassignReviewerInPR(prID, oldReviewerID, teamName string)
 	}, nil
 }
 
+// возвращает идентификаторы Pull Request
+// принимает: слайс PR
+// возвращает: слайс идентификаторов в том же порядке
+func prIDsOf(prs []*models.PullRequest) []string {
+	ids := make([]string, 0, len(prs))
+	for _, pr := range prs {
+		ids = append(ids, pr.PullRequestID)
+	}
+	return ids
+}
+
 // вспомогательная функция для проверки наличия элемента в слайсе
 // принимает: слайс строк и строку для поиска в этом слайсе
 // возвращает: true если строка найдена в слайсе, иначе false
@@ -278,3 +640,130 @@ func contains(slice []string, item string) bool {
 	}
 	return false
 }
+
+// заменяет список компонентов, в которых пользователь является экспертом
+// принимает: идентификатор пользователя и список компонентов
+// возвращает: объект UserExpertise с сохраненными компонентами или ошибку валидации/сохранения
+func (s *UserService) SetComponentExpertise(userID string, components []string) (*models.UserExpertise, error) {
+	normalized := []string{}
+	seen := make(map[string]bool)
+	for _, component := range components {
+		component = strings.TrimSpace(component)
+		if component == "" {
+			return nil, NewServiceError("INVALID_REQUEST", "components must not contain empty values")
+		}
+		if !seen[component] {
+			seen[component] = true
+			normalized = append(normalized, component)
+		}
+	}
+
+	exists, err := s.userRepo.UserExists(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check user existence: %w", err)
+	}
+	if !exists {
+		return nil, NewServiceError("NOT_FOUND", "user not found")
+	}
+
+	if err := s.userRepo.SetComponentExpertise(userID, normalized); err != nil {
+		log.Printf("Failed to set expertise for user %s: %v", userID, err)
+		return nil, fmt.Errorf("failed to set expertise: %w", err)
+	}
+
+	log.Printf("Expertise set for user %s: %v", userID, normalized)
+	return &models.UserExpertise{UserID: userID, Components: normalized}, nil
+}
+
+// включает пользователя в группу ревьюверов, заменяя предыдущую; пустая группа исключает пользователя из групп
+// принимает: идентификатор пользователя и идентификатор группы
+// возвращает: объект UserReviewerGroup с сохраненной группой или ошибку NOT_FOUND/сохранения
+func (s *UserService) SetReviewerGroup(userID, groupID string) (*models.UserReviewerGroup, error) {
+	groupID = strings.TrimSpace(groupID)
+
+	exists, err := s.userRepo.UserExists(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check user existence: %w", err)
+	}
+	if !exists {
+		return nil, NewServiceError("NOT_FOUND", "user not found")
+	}
+
+	if err := s.userRepo.SetReviewerGroup(userID, groupID); err != nil {
+		log.Printf("Failed to set reviewer group for user %s: %v", userID, err)
+		return nil, fmt.Errorf("failed to set reviewer group: %w", err)
+	}
+
+	log.Printf("Reviewer group set for user %s: %q", userID, groupID)
+	return &models.UserReviewerGroup{UserID: userID, GroupID: groupID}, nil
+}
+
+// сохраняет недельную емкость ревью пользователя: при автоматическом назначении он пропускается, если за скользящие
+// 7 дней ему уже назначено столько PR, пока хватает других кандидатов; nil снимает ограничение
+// принимает: идентификатор пользователя и емкость (nil - без ограничения)
+// возвращает: объект UserWeeklyCapacity с сохраненной емкостью или ошибку INVALID_REQUEST/NOT_FOUND/сохранения
+func (s *UserService) SetWeeklyCapacity(userID string, capacity *int) (*models.UserWeeklyCapacity, error) {
+	if capacity != nil && *capacity < 0 {
+		return nil, NewServiceError("INVALID_REQUEST", "weekly_review_capacity must not be negative")
+	}
+
+	exists, err := s.userRepo.UserExists(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check user existence: %w", err)
+	}
+	if !exists {
+		return nil, NewServiceError("NOT_FOUND", "user not found")
+	}
+
+	if err := s.userRepo.SetWeeklyCapacity(userID, capacity); err != nil {
+		log.Printf("Failed to set weekly capacity for user %s: %v", userID, err)
+		return nil, fmt.Errorf("failed to set weekly capacity: %w", err)
+	}
+
+	log.Printf("Weekly review capacity set for user %s", userID)
+	return &models.UserWeeklyCapacity{UserID: userID, WeeklyReviewCapacity: capacity}, nil
+}
+
+// сохраняет окно отказа пользователя от автоматического назначения ревьювером;
+// до времени until пользователь не выбирается при создании PR и замене ревьювера, прошедшее время завершает отказ
+// принимает: идентификатор пользователя, время окончания отказа и причину
+// возвращает: объект UserOptOut с сохраненным окном или ошибку NOT_FOUND/сохранения
+func (s *UserService) SetOptOut(userID string, until time.Time, reason string) (*models.UserOptOut, error) {
+	exists, err := s.userRepo.UserExists(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check user existence: %w", err)
+	}
+	if !exists {
+		return nil, NewServiceError("NOT_FOUND", "user not found")
+	}
+
+	until = until.UTC()
+	reason = strings.TrimSpace(reason)
+	if err := s.userRepo.SetOptOut(userID, until, reason); err != nil {
+		log.Printf("Failed to set opt-out for user %s: %v", userID, err)
+		return nil, fmt.Errorf("failed to set opt-out: %w", err)
+	}
+
+	log.Printf("User %s opted out of auto-assignment until %v: %q", userID, until, reason)
+	return &models.UserOptOut{UserID: userID, OptedOut: until.After(time.Now()), Until: &until, Reason: reason}, nil
+}
+
+// возвращает текущее окно отказа пользователя от автоматического назначения ревьювером
+// принимает: идентификатор пользователя
+// возвращает: объект UserOptOut (opted_out показывает, действует ли отказ сейчас) или ошибку NOT_FOUND/получения данных
+func (s *UserService) GetOptOut(userID string) (*models.UserOptOut, error) {
+	exists, err := s.userRepo.UserExists(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check user existence: %w", err)
+	}
+	if !exists {
+		return nil, NewServiceError("NOT_FOUND", "user not found")
+	}
+
+	optOut, err := s.userRepo.GetOptOut(userID)
+	if err != nil {
+		log.Printf("Failed to get opt-out for user %s: %v", userID, err)
+		return nil, fmt.Errorf("failed to get opt-out: %w", err)
+	}
+	return optOut, nil
+}
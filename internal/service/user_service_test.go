@@ -0,0 +1,787 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"pull-request-reviewer-assignment-service/internal/models"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestUserService(repo *fakeRepo, cfg Config) *UserService {
+	return NewUserService(repo, repo, repo, repo, cfg)
+}
+
+func TestBulkDeactivateRespectsMinActivePerTeam(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "u1", "u2", "u3", "u4")
+	service := newTestUserService(repo, Config{MinActivePerTeam: 2})
+
+	// деактивация до порога разрешена
+	response, err := service.BulkDeactivateUsers(context.Background(), "backend", []string{"u1", "u2"}, "")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"u1", "u2"}, response.DeactivatedUsers)
+
+	// еще одна деактивация опустит команду ниже порога
+	_, err = service.BulkDeactivateUsers(context.Background(), "backend", []string{"u3"}, "")
+	require.Error(t, err)
+	serviceErr, ok := err.(*ServiceError)
+	require.True(t, ok)
+	assert.Equal(t, "TEAM_TOO_SMALL", serviceErr.Code)
+	assert.Contains(t, serviceErr.Message, "at least 2")
+
+	user, err := repo.GetUser("u3")
+	require.NoError(t, err)
+	assert.True(t, user.IsActive, "отклоненная деактивация не должна менять пользователя")
+}
+
+func TestSetUserActiveRespectsMinActivePerTeam(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "u1", "u2")
+	service := newTestUserService(repo, Config{MinActivePerTeam: 1})
+
+	_, err := service.SetUserActive("u1", false, "")
+	require.NoError(t, err)
+
+	_, err = service.SetUserActive("u2", false, "")
+	require.Error(t, err)
+	assert.Equal(t, "TEAM_TOO_SMALL", err.(*ServiceError).Code)
+
+	// активация и повторная деактивация неактивного пользователя не ограничиваются
+	_, err = service.SetUserActive("u1", false, "")
+	assert.NoError(t, err)
+}
+
+func TestMinActivePerTeamZeroDisablesCheck(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "u1", "u2")
+	service := newTestUserService(repo, Config{})
+
+	response, err := service.BulkDeactivateUsers(context.Background(), "backend", []string{"u1", "u2"}, "")
+	require.NoError(t, err)
+	assert.Len(t, response.DeactivatedUsers, 2)
+}
+
+func TestStatusHistoryRecordsActivityChanges(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "u1", "u2", "u3")
+	service := newTestUserService(repo, Config{})
+
+	_, err := service.SetUserActive("u1", false, "alice")
+	require.NoError(t, err)
+	// повторная установка того же статуса не создает события
+	_, err = service.SetUserActive("u1", false, "alice")
+	require.NoError(t, err)
+	_, err = service.SetUserActive("u1", true, "bob")
+	require.NoError(t, err)
+	_, err = service.BulkDeactivateUsers(context.Background(), "backend", []string{"u1"}, "ops")
+	require.NoError(t, err)
+
+	events, _, err := service.GetStatusHistory("u1", nil, nil, models.Pagination{Limit: 10})
+	require.NoError(t, err)
+
+	var sequence []string
+	for _, event := range events {
+		sequence = append(sequence, event.EventType+" by "+event.Actor)
+	}
+	assert.Equal(t, []string{"DEACTIVATE by alice", "ACTIVATE by bob", "DEACTIVATE by ops"}, sequence)
+
+	_, _, err = service.GetStatusHistory("missing", nil, nil, models.Pagination{Limit: 10})
+	var serviceErr *ServiceError
+	require.ErrorAs(t, err, &serviceErr)
+	assert.Equal(t, "NOT_FOUND", serviceErr.Code)
+}
+
+func TestTeamHistoryListsTeamsInOrder(t *testing.T) {
+	repo := newFakeRepo()
+	service := newTestUserService(repo, Config{})
+	require.NoError(t, repo.CreateTeam(&models.Team{
+		TeamName: "backend", Members: []models.TeamMember{{UserID: "u1", Username: "u1", IsActive: true}},
+	}))
+
+	// переход в другую команду: выход из backend и вступление в frontend
+	joinedBackend := repo.membershipEvents["u1"][0].CreatedAt
+	movedAt := joinedBackend.Add(time.Hour)
+	repo.addTeam("frontend", "u1")
+	repo.membershipEvents["u1"] = append(repo.membershipEvents["u1"],
+		models.TeamMembershipEvent{TeamName: "backend", EventType: models.TeamMembershipLeave, CreatedAt: movedAt},
+		models.TeamMembershipEvent{TeamName: "frontend", EventType: models.TeamMembershipJoin, CreatedAt: movedAt},
+	)
+
+	history, err := service.GetTeamHistory("u1")
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, "backend", history[0].TeamName)
+	assert.Equal(t, joinedBackend, history[0].JoinedAt)
+	require.NotNil(t, history[0].LeftAt)
+	assert.Equal(t, movedAt, *history[0].LeftAt)
+	assert.Equal(t, "frontend", history[1].TeamName)
+	assert.Equal(t, movedAt, history[1].JoinedAt)
+	assert.Nil(t, history[1].LeftAt)
+
+	_, err = service.GetTeamHistory("missing")
+	var serviceErr *ServiceError
+	require.ErrorAs(t, err, &serviceErr)
+	assert.Equal(t, "NOT_FOUND", serviceErr.Code)
+}
+
+// хранилище пользователей, которое мержит PR при выборе замены,
+// воспроизводя мерж между чтением открытых PR и заменой ревьювера при деактивации
+type mergeBeforeSwapRepo struct {
+	*fakeRepo
+	prID string
+}
+
+func (r mergeBeforeSwapRepo) GetActiveUsersByTeam(teamName string) ([]*models.User, error) {
+	r.mergePRAt(r.prID, time.Now())
+	return r.fakeRepo.GetActiveUsersByTeam(teamName)
+}
+
+// хранилище пользователей, считающее запросы активных участников по командам
+type countingMembersRepo struct {
+	*fakeRepo
+	queries map[string]int
+}
+
+func (r countingMembersRepo) GetActiveUsersByTeam(teamName string) ([]*models.User, error) {
+	r.queries[teamName]++
+	return r.fakeRepo.GetActiveUsersByTeam(teamName)
+}
+
+func TestBulkDeactivateQueriesActiveMembersOncePerTeam(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3", "u4")
+	for i := 0; i < 5; i++ {
+		repo.addPR(fmt.Sprintf("pr-%d", i), "author", "u1", "u2")
+	}
+	counting := countingMembersRepo{fakeRepo: repo, queries: make(map[string]int)}
+	service := NewUserService(counting, repo, repo, repo, Config{})
+
+	response, err := service.BulkDeactivateUsers(context.Background(), "backend", []string{"u1", "u2"}, "ops")
+	require.NoError(t, err)
+	assert.Equal(t, 1, counting.queries["backend"])
+
+	// снимок взят после деактивации: замены не достаются деактивированным участникам
+	assert.Len(t, response.ReassignedPRs, 10)
+	for prID, reviewers := range repo.reviewers {
+		assert.NotContains(t, reviewers, "u1", prID)
+		assert.NotContains(t, reviewers, "u2", prID)
+	}
+}
+
+func TestBulkDeactivateSkipsPRMergedBeforeSwap(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2")
+	repo.addPR("pr-1", "author", "u1")
+	service := NewUserService(mergeBeforeSwapRepo{repo, "pr-1"}, repo, repo, repo, Config{})
+
+	response, err := service.BulkDeactivateUsers(context.Background(), "backend", []string{"u1"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u1"}, response.DeactivatedUsers)
+	assert.Zero(t, response.ReassignedCount)
+
+	pr, err := repo.GetPR("pr-1")
+	require.NoError(t, err)
+	assert.Equal(t, "MERGED", pr.Status)
+	assert.Equal(t, []string{"u1"}, pr.AssignedReviewers)
+}
+
+func TestCreateUserValidatesInput(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "u1")
+	service := newTestUserService(repo, Config{})
+
+	cases := []struct {
+		name string
+		user models.User
+		code string
+	}{
+		{"missing user_id", models.User{Username: "New", TeamName: "backend"}, "INVALID_REQUEST"},
+		{"missing username", models.User{UserID: "u2", TeamName: "backend"}, "INVALID_REQUEST"},
+		{"missing team_name", models.User{UserID: "u2", Username: "New"}, "INVALID_REQUEST"},
+		{"unknown team", models.User{UserID: "u2", Username: "New", TeamName: "frontend"}, "NOT_FOUND"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := service.CreateUser(tc.user)
+			require.Error(t, err)
+			assert.Equal(t, tc.code, err.(*ServiceError).Code)
+		})
+	}
+	assert.NotContains(t, repo.users, "u2")
+}
+
+func TestCreateUserJoinsTeamAndRejectsDuplicate(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "u1")
+	repo.addTeam("frontend", "f1")
+	service := newTestUserService(repo, Config{})
+
+	user, err := service.CreateUser(models.User{UserID: "u2", Username: "New Hire", TeamName: "backend", IsActive: true})
+	require.NoError(t, err)
+	assert.Equal(t, "backend", user.TeamName)
+
+	active, err := repo.GetActiveUsersByTeam("backend")
+	require.NoError(t, err)
+	assert.Len(t, active, 2)
+	history, err := repo.GetTeamMembershipEvents("u2")
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, "backend", history[0].TeamName)
+
+	// повторное создание, в том числе в другой команде, отклоняется и не меняет пользователя
+	_, err = service.CreateUser(models.User{UserID: "u2", Username: "Other", TeamName: "frontend", IsActive: true})
+	require.Error(t, err)
+	assert.Equal(t, "USER_EXISTS", err.(*ServiceError).Code)
+	assert.Equal(t, "New Hire", repo.users["u2"].Username)
+	assert.Equal(t, "backend", repo.users["u2"].TeamName)
+}
+
+func TestTransferUserReassignsReviewsOutsideNewTeam(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "mover", "u2", "u3")
+	repo.addTeam("frontend", "f1", "f2")
+	repo.addPR("pr-backend", "author", "mover", "u2")
+	repo.addPR("pr-frontend", "f1", "mover")
+	repo.addPR("pr-merged", "author", "mover")
+	repo.mergePRAt("pr-merged", time.Now())
+	service := newTestUserService(repo, Config{})
+
+	response, err := service.TransferUser("mover", "frontend")
+	require.NoError(t, err)
+	assert.Equal(t, "backend", response.PreviousTeam)
+	assert.Equal(t, "frontend", response.User.TeamName)
+	assert.Equal(t, []string{"pr-backend"}, response.ReassignedPRs)
+
+	// в PR автора из прежней команды ревьювер заменен, в PR новой команды и в замерженном PR остается
+	assert.ElementsMatch(t, []string{"u2", "u3"}, repo.reviewers["pr-backend"])
+	assert.Equal(t, []string{"mover"}, repo.reviewers["pr-frontend"])
+	assert.Equal(t, []string{"mover"}, repo.reviewers["pr-merged"])
+	assert.Equal(t, "frontend", repo.users["mover"].TeamName)
+
+	history, err := repo.GetTeamMembershipEvents("mover")
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, []string{models.TeamMembershipLeave, "backend"}, []string{history[0].EventType, history[0].TeamName})
+	assert.Equal(t, []string{models.TeamMembershipJoin, "frontend"}, []string{history[1].EventType, history[1].TeamName})
+}
+
+func TestTransferUserReturnsNotFound(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "u1")
+	service := newTestUserService(repo, Config{})
+
+	_, err := service.TransferUser("missing", "backend")
+	require.Error(t, err)
+	assert.Equal(t, "NOT_FOUND", err.(*ServiceError).Code)
+
+	_, err = service.TransferUser("u1", "frontend")
+	require.Error(t, err)
+	assert.Equal(t, "NOT_FOUND", err.(*ServiceError).Code)
+	assert.Equal(t, "backend", repo.users["u1"].TeamName)
+}
+
+func TestSyncTeamAddsRemovesAndFlipsMembers(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3")
+	repo.addPR("pr-1", "author", "u1", "u2")
+	service := newTestUserService(repo, Config{})
+
+	response, err := service.SyncTeam("backend", []models.TeamMember{
+		{UserID: "author", Username: "author", IsActive: true},
+		{UserID: "u2", Username: "u2", IsActive: true},
+		{UserID: "u3", Username: "u3", IsActive: false},
+		{UserID: "u4", Username: "u4", IsActive: true},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"u4"}, response.Added)
+	assert.Equal(t, []string{"u1"}, response.Removed)
+	assert.Equal(t, []string{"u3"}, response.Deactivated)
+	assert.Empty(t, response.Activated)
+
+	// выбывший u1 заменен единственным подходящим активным участником u4
+	require.Len(t, response.ReassignedPRs, 1)
+	assert.Equal(t, "pr-1", response.ReassignedPRs[0].PRID)
+	assert.ElementsMatch(t, []string{"u2", "u4"}, response.ReassignedPRs[0].NewReviewers)
+
+	reviewers, err := repo.GetAssignedReviewers("pr-1")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"u2", "u4"}, reviewers)
+
+	removed, err := repo.GetUser("u1")
+	require.NoError(t, err)
+	assert.False(t, removed.IsActive)
+	require.Len(t, response.Team.Members, 5)
+}
+
+func TestSyncTeamReplacesReviewerFlippedInactive(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3")
+	repo.addPR("pr-1", "author", "u1")
+	service := newTestUserService(repo, Config{})
+
+	// u1 остается в списке, но становится неактивным и не должен остаться ревьювером
+	response, err := service.SyncTeam("backend", []models.TeamMember{
+		{UserID: "author", Username: "author", IsActive: true},
+		{UserID: "u1", Username: "u1", IsActive: false},
+		{UserID: "u2", Username: "u2", IsActive: true},
+		{UserID: "u3", Username: "u3", IsActive: true},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"u1"}, response.Deactivated)
+	assert.Empty(t, response.Removed)
+	require.Len(t, response.ReassignedPRs, 1)
+	assert.Equal(t, []string{"u2"}, response.ReassignedPRs[0].NewReviewers)
+	assert.Equal(t, []string{"u2"}, repo.reviewers["pr-1"])
+	assert.False(t, repo.users["u1"].IsActive)
+}
+
+func TestSyncTeamSkipsPRMergedBeforeWrite(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3")
+	repo.addPR("pr-1", "author", "u1")
+	service := newTestUserService(repo, Config{})
+
+	repo.beforeSync = func() { repo.prs["pr-1"].Status = "MERGED" }
+	_, err := service.SyncTeam("backend", []models.TeamMember{
+		{UserID: "author", Username: "author", IsActive: true},
+		{UserID: "u2", Username: "u2", IsActive: true},
+		{UserID: "u3", Username: "u3", IsActive: true},
+	})
+	require.NoError(t, err)
+
+	// смерженный PR сохраняет ревьюверов, а состав команды все равно обновлен
+	assert.Equal(t, []string{"u1"}, repo.reviewers["pr-1"])
+	assert.False(t, repo.users["u1"].IsActive)
+}
+
+func TestSyncTeamConflictsWhenReviewerRemovedBeforeWrite(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3")
+	repo.addPR("pr-1", "author", "u1")
+	service := newTestUserService(repo, Config{})
+
+	// параллельная замена уже сняла u1 с PR
+	repo.beforeSync = func() { repo.reviewers["pr-1"] = []string{"u3"} }
+	_, err := service.SyncTeam("backend", []models.TeamMember{
+		{UserID: "author", Username: "author", IsActive: true},
+		{UserID: "u2", Username: "u2", IsActive: true},
+		{UserID: "u3", Username: "u3", IsActive: true},
+	})
+	var serviceErr *ServiceError
+	require.ErrorAs(t, err, &serviceErr)
+	assert.Equal(t, "NOT_ASSIGNED", serviceErr.Code)
+
+	assert.Equal(t, []string{"u3"}, repo.reviewers["pr-1"])
+	assert.True(t, repo.users["u1"].IsActive)
+}
+
+func TestSyncTeamRejectsMemberOfAnotherTeam(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "u1")
+	repo.addTeam("frontend", "f1")
+	service := newTestUserService(repo, Config{})
+
+	_, err := service.SyncTeam("backend", []models.TeamMember{
+		{UserID: "u1", Username: "u1", IsActive: true},
+		{UserID: "f1", Username: "f1", IsActive: true},
+	})
+	require.Error(t, err)
+	assert.Equal(t, "INVALID_REQUEST", err.(*ServiceError).Code)
+
+	user, err := repo.GetUser("f1")
+	require.NoError(t, err)
+	assert.Equal(t, "frontend", user.TeamName)
+}
+
+func TestUpdateTeamMembersUpsertsAndDeactivatesMissing(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3")
+	repo.addPR("pr-1", "author", "u1", "u2")
+	teamService := NewTeamService(repo, repo)
+	teamService.SetUserService(newTestUserService(repo, Config{}))
+
+	// без deactivate_missing неперечисленные участники не меняются
+	response, err := teamService.UpdateTeamMembers("backend", []models.TeamMember{
+		{UserID: "u2", Username: "Uwe", IsActive: true},
+		{UserID: "u4", Username: "u4", IsActive: true},
+	}, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u4"}, response.Added)
+	assert.Equal(t, []string{"u2"}, response.Renamed)
+	assert.Empty(t, response.Removed)
+	assert.Empty(t, response.ReassignedPRs)
+	require.Len(t, response.Team.Members, 5)
+	assert.True(t, repo.users["u1"].IsActive)
+	assert.Equal(t, "Uwe", repo.users["u2"].Username)
+
+	response, err = teamService.UpdateTeamMembers("backend", []models.TeamMember{
+		{UserID: "author", Username: "author", IsActive: true},
+		{UserID: "u2", Username: "Uwe", IsActive: true},
+		{UserID: "u4", Username: "u4", IsActive: true},
+	}, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u1", "u3"}, response.Removed)
+	assert.False(t, repo.users["u1"].IsActive)
+	assert.False(t, repo.users["u3"].IsActive)
+
+	// выбывший ревьювер u1 заменен единственным подходящим активным участником u4
+	require.Len(t, response.ReassignedPRs, 1)
+	assert.Equal(t, "pr-1", response.ReassignedPRs[0].PRID)
+	reviewers, err := repo.GetAssignedReviewers("pr-1")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"u2", "u4"}, reviewers)
+}
+
+func TestUpdateTeamMembersRespectsMinActivePerTeam(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "u1", "u2")
+	repo.addTeam("frontend", "f1")
+	teamService := NewTeamService(repo, repo)
+	teamService.SetUserService(newTestUserService(repo, Config{MinActivePerTeam: 2}))
+
+	_, err := teamService.UpdateTeamMembers("backend", []models.TeamMember{{UserID: "u1", Username: "u1", IsActive: true}}, true)
+	require.Error(t, err)
+	assert.Equal(t, "TEAM_TOO_SMALL", err.(*ServiceError).Code)
+	assert.True(t, repo.users["u2"].IsActive)
+
+	_, err = teamService.UpdateTeamMembers("backend", []models.TeamMember{{UserID: "f1", Username: "f1", IsActive: true}}, false)
+	require.Error(t, err)
+	assert.Equal(t, "INVALID_REQUEST", err.(*ServiceError).Code)
+	assert.Equal(t, "frontend", repo.users["f1"].TeamName)
+
+	_, err = teamService.UpdateTeamMembers("missing", nil, true)
+	require.Error(t, err)
+	assert.Equal(t, "NOT_FOUND", err.(*ServiceError).Code)
+}
+
+func TestDeleteTeamRefusesWhileMembersHaveOpenPRs(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1")
+	repo.addTeam("frontend", "f1", "f2")
+	repo.addPR("pr-author", "author", "f1")
+	repo.addPR("pr-review", "f1", "u1")
+	service := NewTeamService(repo, repo)
+
+	// участник backend является автором открытого PR
+	_, err := service.DeleteTeam("backend", true)
+	require.Error(t, err)
+	assert.Equal(t, "TEAM_HAS_OPEN_PRS", err.(*ServiceError).Code)
+
+	// после мержа участник все еще ревьюер открытого PR другой команды
+	repo.mergePRAt("pr-author", time.Now())
+	_, err = service.DeleteTeam("backend", true)
+	require.Error(t, err)
+	assert.Equal(t, "TEAM_HAS_OPEN_PRS", err.(*ServiceError).Code)
+	assert.True(t, repo.teams["backend"])
+
+	repo.mergePRAt("pr-review", time.Now())
+
+	// без delete_prs замерженный PR участника запрещает удаление, ничего не меняется
+	_, err = service.DeleteTeam("backend", false)
+	require.Error(t, err)
+	assert.Equal(t, "TEAM_HAS_PRS", err.(*ServiceError).Code)
+	assert.True(t, repo.teams["backend"])
+	assert.Contains(t, repo.prs, "pr-author")
+
+	response, err := service.DeleteTeam("backend", true)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), response.DeletedMembers)
+	// удален PR участника с его ревьювером и ревью u1 в PR frontend
+	assert.Equal(t, int64(1), response.DeletedPRs)
+	assert.Equal(t, int64(2), response.DeletedReviews)
+	assert.False(t, repo.teams["backend"])
+	assert.NotContains(t, repo.users, "u1")
+	assert.NotContains(t, repo.prs, "pr-author")
+	assert.Empty(t, repo.reviewers["pr-review"])
+
+	_, err = service.DeleteTeam("backend", true)
+	require.Error(t, err)
+	assert.Equal(t, "NOT_FOUND", err.(*ServiceError).Code)
+}
+
+func TestDeleteTeamWithoutPRsKeepsOtherTeamsPRs(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "u1", "u2")
+	repo.addTeam("frontend", "f1", "f2")
+	repo.addPR("pr-frontend", "f1", "u1", "f2")
+	repo.mergePRAt("pr-frontend", time.Now())
+	service := NewTeamService(repo, repo)
+
+	response, err := service.DeleteTeam("backend", false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), response.DeletedMembers)
+	assert.Zero(t, response.DeletedPRs)
+	// удалено только ревью участника, PR frontend и ревью f2 сохранены
+	assert.Equal(t, int64(1), response.DeletedReviews)
+	assert.Contains(t, repo.prs, "pr-frontend")
+	assert.Equal(t, []string{"f2"}, repo.reviewers["pr-frontend"])
+}
+
+func TestDeleteTeamReportsTeamDeletedConcurrentlyAsNotFound(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "u1")
+	service := NewTeamService(deletedTeamRepo{repo}, repo)
+
+	_, err := service.DeleteTeam("backend", true)
+	var serviceErr *ServiceError
+	require.ErrorAs(t, err, &serviceErr)
+	assert.Equal(t, "NOT_FOUND", serviceErr.Code)
+}
+
+// хранилище, в котором команду удаляют параллельно между проверкой существования и удалением
+type deletedTeamRepo struct {
+	*fakeRepo
+}
+
+func (r deletedTeamRepo) DeleteTeam(string, bool) (*models.DeleteTeamResponse, bool, error) {
+	return nil, false, models.ErrTeamNotFound
+}
+
+// разница между максимальной и минимальной нагрузкой участников
+func loadSpread(loads map[string]int) int {
+	values := make([]int, 0, len(loads))
+	for _, load := range loads {
+		values = append(values, load)
+	}
+	sort.Ints(values)
+	return values[len(values)-1] - values[0]
+}
+
+func TestRebalanceTeamFlattensSkewedLoad(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3", "u4")
+	for i := 1; i <= 4; i++ {
+		repo.addPR(fmt.Sprintf("pr-%d", i), "author", "u1", "u2")
+	}
+	// u3 не может стать ревьювером собственного PR
+	repo.addPR("pr-u3", "u3", "u1")
+	service := newTestUserService(repo, Config{})
+
+	response, err := service.RebalanceTeam(context.Background(), "backend")
+	require.NoError(t, err)
+
+	assert.Equal(t, 5, response.LoadBefore["u1"])
+	assert.Equal(t, 5, loadSpread(response.LoadBefore))
+	assert.LessOrEqual(t, loadSpread(response.LoadAfter), 1)
+	assert.NotEmpty(t, response.Changes)
+
+	// изменения применены в хранилище и не нарушают ограничений
+	actual := make(map[string]int)
+	for prID, pr := range repo.prs {
+		reviewers, err := repo.GetAssignedReviewers(prID)
+		require.NoError(t, err)
+		assert.NotContains(t, reviewers, pr.AuthorID)
+		assert.Len(t, reviewers, len(uniqueStrings(reviewers)))
+		for _, reviewerID := range reviewers {
+			actual[reviewerID]++
+		}
+	}
+	for userID, load := range response.LoadAfter {
+		assert.Equal(t, load, actual[userID], userID)
+	}
+}
+
+func TestRebalanceTeamBalancedTeamHasNoChanges(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2")
+	repo.addPR("pr-1", "author", "u1", "u2")
+	service := newTestUserService(repo, Config{})
+
+	response, err := service.RebalanceTeam(context.Background(), "backend")
+	require.NoError(t, err)
+	assert.Empty(t, response.Changes)
+
+	_, err = service.RebalanceTeam(context.Background(), "missing")
+	require.Error(t, err)
+	assert.Equal(t, "NOT_FOUND", err.(*ServiceError).Code)
+}
+
+// хранилище пользователей, отменяющее контекст операции при деактивации указанного пользователя
+type cancelOnDeactivateRepo struct {
+	*fakeRepo
+	userID string
+	cancel context.CancelFunc
+}
+
+func (r cancelOnDeactivateRepo) SetUserActive(userID string, isActive bool, actor string) error {
+	if userID == r.userID {
+		r.cancel()
+	}
+	return r.fakeRepo.SetUserActive(userID, isActive, actor)
+}
+
+func TestBulkDeactivateStopsAfterCurrentUserOnCancel(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3", "u4")
+	repo.addPR("pr-1", "author", "u1")
+	repo.addPR("pr-2", "author", "u2")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	service := NewUserService(cancelOnDeactivateRepo{fakeRepo: repo, userID: "u1", cancel: cancel}, repo, repo, repo, Config{})
+
+	response, err := service.BulkDeactivateUsers(ctx, "backend", []string{"u1", "u2", "u3"}, "ops")
+	require.NoError(t, err)
+	assert.True(t, response.Cancelled)
+	assert.Equal(t, []string{"u1"}, response.DeactivatedUsers)
+
+	// текущий пользователь обработан полностью: деактивирован и заменен в своих PR
+	assert.False(t, repo.users["u1"].IsActive)
+	assert.NotContains(t, repo.reviewers["pr-1"], "u1")
+	// остальные не тронуты и не стали заменой
+	assert.True(t, repo.users["u2"].IsActive)
+	assert.True(t, repo.users["u3"].IsActive)
+	assert.Equal(t, []string{"u2"}, repo.reviewers["pr-2"])
+	assert.Equal(t, []string{"u4"}, repo.reviewers["pr-1"])
+}
+
+func TestBulkSetActiveReassignsOnlyDeactivatedUsers(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3")
+	repo.deactivateAt("u3", time.Now().Add(-time.Hour))
+	repo.addPR("pr-1", "author", "u1")
+	repo.addPR("pr-2", "author", "u2", "u3")
+	service := newTestUserService(repo, Config{})
+
+	response, err := service.BulkSetActive(context.Background(), []models.UserActiveChange{
+		{UserID: "u3", IsActive: true},
+		{UserID: "u1", IsActive: false},
+		{UserID: "u2", IsActive: true},
+		{UserID: "ghost", IsActive: false},
+	}, "ops")
+	require.NoError(t, err)
+
+	statuses := make([]string, 0, len(response.Results))
+	for _, result := range response.Results {
+		statuses = append(statuses, result.Status)
+	}
+	assert.Equal(t, []string{BulkSetActiveUpdated, BulkSetActiveUpdated, BulkSetActiveUnchanged, BulkSetActiveFailed}, statuses)
+	assert.Equal(t, 1, response.Activated)
+	assert.Equal(t, 1, response.Deactivated)
+
+	assert.True(t, repo.users["u3"].IsActive)
+	assert.False(t, repo.users["u1"].IsActive)
+
+	// переназначаются только ревью деактивированного u1, а активированный в том же запросе u3 уже может стать заменой
+	assert.Equal(t, 1, response.ReassignedCount)
+	assert.Empty(t, response.Results[0].ReassignedPRs)
+	require.Len(t, response.Results[1].ReassignedPRs, 1)
+	assert.Equal(t, "pr-1", response.Results[1].ReassignedPRs[0].PRID)
+	assert.NotContains(t, repo.reviewers["pr-1"], "u1")
+	assert.Equal(t, []string{"u2", "u3"}, repo.reviewers["pr-2"])
+}
+
+func TestRebalanceTeamAppliesNothingOnCancel(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3")
+	repo.addPR("pr-1", "author", "u1")
+	repo.addPR("pr-2", "author", "u1")
+	repo.addPR("pr-3", "author", "u1")
+	service := newTestUserService(repo, Config{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	response, err := service.RebalanceTeam(ctx, "backend")
+	require.NoError(t, err)
+	assert.True(t, response.Cancelled)
+	assert.Empty(t, response.Changes)
+	for _, prID := range []string{"pr-1", "pr-2", "pr-3"} {
+		assert.Equal(t, []string{"u1"}, repo.reviewers[prID], prID)
+	}
+}
+
+func uniqueStrings(values []string) map[string]bool {
+	unique := make(map[string]bool, len(values))
+	for _, value := range values {
+		unique[value] = true
+	}
+	return unique
+}
+
+func TestGetAuthorReviewersCountsPRsPerReviewer(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "author", "u1", "u2", "u3")
+	repo.addPR("pr-1", "author", "u1", "u2")
+	repo.addPR("pr-2", "author", "u1", "u3")
+	repo.addPR("pr-3", "author", "u1", "u2")
+	repo.addPR("pr-other", "u2", "u1", "u3")
+	repo.mergePRAt("pr-3", time.Now())
+	service := newTestUserService(repo, Config{})
+
+	reviewers, _, err := service.GetAuthorReviewers("author", "", models.Pagination{})
+	require.NoError(t, err)
+	assert.Equal(t, []models.AuthorReviewer{
+		{UserID: "u1", Username: "u1", PRCount: 3},
+		{UserID: "u2", Username: "u2", PRCount: 2},
+		{UserID: "u3", Username: "u3", PRCount: 1},
+	}, reviewers)
+
+	reviewers, _, err = service.GetAuthorReviewers("author", "OPEN", models.Pagination{})
+	require.NoError(t, err)
+	assert.Equal(t, []models.AuthorReviewer{
+		{UserID: "u1", Username: "u1", PRCount: 2},
+		{UserID: "u2", Username: "u2", PRCount: 1},
+		{UserID: "u3", Username: "u3", PRCount: 1},
+	}, reviewers)
+
+	reviewers, page, err := service.GetAuthorReviewers("author", "", models.Pagination{Limit: 2, Offset: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []models.AuthorReviewer{
+		{UserID: "u2", Username: "u2", PRCount: 2},
+		{UserID: "u3", Username: "u3", PRCount: 1},
+	}, reviewers)
+	assert.Equal(t, models.Pagination{Limit: 2, Offset: 1, Total: 3}, page)
+}
+
+func TestSetComponentExpertiseNormalizesComponents(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "u1")
+	service := newTestUserService(repo, Config{})
+
+	expertise, err := service.SetComponentExpertise("u1", []string{" billing", "api", "billing"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"billing", "api"}, expertise.Components)
+	assert.Equal(t, []string{"billing", "api"}, repo.expertise["u1"])
+
+	_, err = service.SetComponentExpertise("missing", []string{"api"})
+	require.Error(t, err)
+	assert.Equal(t, "NOT_FOUND", err.(*ServiceError).Code)
+}
+
+func TestGetLoadComparison(t *testing.T) {
+	repo := newFakeRepo()
+	repo.addTeam("backend", "u1", "u2", "u3", "u4", "u5")
+	repo.addTeam("platform", "other")
+	repo.addPR("pr-1", "other", "u2", "u3", "u4", "u5")
+	repo.addPR("pr-2", "other", "u3", "u4", "u5")
+	repo.addPR("pr-3", "other", "u5")
+	repo.addPR("pr-4", "other", "u5")
+	repo.addPR("pr-5", "other", "u1")
+	repo.mergePRAt("pr-5", time.Now())
+	service := newTestUserService(repo, Config{})
+
+	// нагрузка команды: 0, 1, 2, 2, 4
+	comparison, err := service.GetLoadComparison("u3")
+	require.NoError(t, err)
+	assert.Equal(t, "backend", comparison.TeamName)
+	assert.Equal(t, 2, comparison.OpenReviews)
+	assert.Equal(t, 5, comparison.TeamSize)
+	assert.InDelta(t, 1.8, comparison.TeamAverage, 1e-9)
+	assert.Equal(t, 2.0, comparison.TeamMedian)
+	assert.InDelta(t, 60.0, comparison.PercentileRank, 1e-9)
+
+	comparison, err = service.GetLoadComparison("u5")
+	require.NoError(t, err)
+	assert.InDelta(t, 90.0, comparison.PercentileRank, 1e-9)
+
+	_, err = service.GetLoadComparison("missing")
+	var serviceErr *ServiceError
+	require.ErrorAs(t, err, &serviceErr)
+	assert.Equal(t, "NOT_FOUND", serviceErr.Code)
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"pull-request-reviewer-assignment-service/internal/repository"
+	"time"
+)
+
+// скользящее окно, в котором считается недельная емкость ревью
+const weeklyCapacityWindow = 7 * 24 * time.Hour
+
+// возвращает оставшуюся недельную емкость пользователей с заданным ограничением
+// принимает: репозитории пользователей и ревью, идентификаторы пользователей и текущий момент
+// возвращает: отображение идентификатора в количество PR, которые еще можно назначить (пользователи без ограничения отсутствуют), или ошибку
+func remainingWeeklyCapacity(userRepo repository.UserRepository, reviewRepo repository.ReviewRepository, userIDs []string,
+	now time.Time) (map[string]int, error) {
+	capacities, err := userRepo.GetWeeklyCapacities(userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get weekly capacities: %w", err)
+	}
+	if len(capacities) == 0 {
+		return capacities, nil
+	}
+
+	capped := make([]string, 0, len(capacities))
+	for userID := range capacities {
+		capped = append(capped, userID)
+	}
+	counts, err := reviewRepo.GetAssignmentCountsSince(capped, now.Add(-weeklyCapacityWindow))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get weekly assignment counts: %w", err)
+	}
+
+	remaining := make(map[string]int, len(capacities))
+	for userID, capacity := range capacities {
+		remaining[userID] = max(capacity-counts[userID], 0)
+	}
+	return remaining, nil
+}
+
+// ставит перед уровнями кандидатов их части без пользователей, исчерпавших недельную емкость ревью;
+// исходные уровни остаются в конце, чтобы при нехватке кандидатов ограничение ослаблялось
+// принимает: уровни кандидатов, всех кандидатов и нужное количество ревьюверов
+// возвращает: уровни кандидатов с учетом емкости или ошибку получения данных
+func (s *PRService) weeklyCapacityTiers(tiers [][]string, candidateUserIDs []string, reviewerCount int) ([][]string, error) {
+	remaining, err := remainingWeeklyCapacity(s.userRepo, s.reviewRepo, candidateUserIDs, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	var exhausted []string
+	for _, userID := range candidateUserIDs {
+		if capacity, ok := remaining[userID]; ok && capacity == 0 {
+			exhausted = append(exhausted, userID)
+		}
+	}
+	if len(exhausted) == 0 {
+		return tiers, nil
+	}
+
+	log.Printf("Skipping candidates at weekly review capacity: %v", exhausted)
+	withinCapacity := make([][]string, 0, 2*len(tiers))
+	for _, tier := range tiers {
+		var available []string
+		for _, candidate := range tier {
+			if !contains(exhausted, candidate) {
+				available = append(available, candidate)
+			}
+		}
+		withinCapacity = append(withinCapacity, available)
+	}
+
+	if available := len(candidateUserIDs) - len(exhausted); available < reviewerCount {
+		log.Printf("Warning: only %d of %d needed reviewers are within weekly review capacity, assigning past the cap",
+			available, min(reviewerCount, len(candidateUserIDs)))
+	}
+	return append(withinCapacity, tiers...), nil
+}
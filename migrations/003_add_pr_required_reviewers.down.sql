@@ -0,0 +1,2 @@
+-- Удаление требуемого количества ревьюверов
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS required_reviewers;
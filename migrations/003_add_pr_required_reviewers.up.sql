@@ -0,0 +1,2 @@
+-- Требуемое количество ревьюверов для конкретного PR (NULL - используется значение по умолчанию)
+ALTER TABLE pull_requests ADD COLUMN IF NOT EXISTS required_reviewers INTEGER NULL CHECK (required_reviewers > 0);
@@ -0,0 +1,2 @@
+-- Удаление журнала событий назначения
+DROP TABLE IF EXISTS assignment_events;
@@ -0,0 +1,17 @@
+-- Журнал событий назначения ревьюверов
+CREATE TABLE IF NOT EXISTS assignment_events (
+    event_id BIGSERIAL PRIMARY KEY,
+    pull_request_id VARCHAR(100) NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    event_type VARCHAR(20) NOT NULL CHECK (event_type IN ('ASSIGN', 'REASSIGN')),
+    -- назначенный ревьювер (для REASSIGN - новый)
+    reviewer_id VARCHAR(100) NOT NULL,
+    -- замененный ревьювер для REASSIGN
+    previous_reviewer_id VARCHAR(100) NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Для восстановления истории PR по времени
+CREATE INDEX IF NOT EXISTS idx_assignment_events_pr_time ON assignment_events(pull_request_id, created_at);
+
+-- Для выборок событий за период
+CREATE INDEX IF NOT EXISTS idx_assignment_events_time ON assignment_events(created_at);
@@ -0,0 +1,2 @@
+-- Удаление размера изменений PR
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS lines_changed;
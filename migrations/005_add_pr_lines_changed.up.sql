@@ -0,0 +1,2 @@
+-- Размер изменений PR в строках (NULL - не указан)
+ALTER TABLE pull_requests ADD COLUMN IF NOT EXISTS lines_changed INTEGER NULL CHECK (lines_changed >= 0);
@@ -0,0 +1,3 @@
+-- Удаление экспертизы пользователей и компонентов PR
+DROP TABLE IF EXISTS user_component_expertise;
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS component_tags;
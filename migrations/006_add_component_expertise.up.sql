@@ -0,0 +1,12 @@
+-- Компоненты, которые затрагивает PR (для выбора ревьюверов по владению кодом)
+ALTER TABLE pull_requests ADD COLUMN IF NOT EXISTS component_tags TEXT[] NOT NULL DEFAULT '{}';
+
+-- Экспертиза пользователей по компонентам
+CREATE TABLE IF NOT EXISTS user_component_expertise (
+    user_id VARCHAR(100) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    component VARCHAR(100) NOT NULL,
+    PRIMARY KEY (user_id, component)
+);
+
+-- Для поиска экспертов по компоненту
+CREATE INDEX IF NOT EXISTS idx_user_component_expertise_component ON user_component_expertise(component);
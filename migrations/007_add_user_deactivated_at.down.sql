@@ -0,0 +1,3 @@
+-- Удаление времени деактивации пользователей
+DROP INDEX IF EXISTS idx_users_team_deactivated_at;
+ALTER TABLE users DROP COLUMN IF EXISTS deactivated_at;
@@ -0,0 +1,5 @@
+-- Время деактивации пользователя (для резервного назначения недавно деактивированных ревьюверов)
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deactivated_at TIMESTAMP WITH TIME ZONE NULL;
+
+-- Для поиска недавно деактивированных участников команды
+CREATE INDEX IF NOT EXISTS idx_users_team_deactivated_at ON users(team_name, deactivated_at) WHERE is_active = false;
@@ -0,0 +1,2 @@
+-- Удаление журнала изменений активности пользователей
+DROP TABLE IF EXISTS user_status_events;
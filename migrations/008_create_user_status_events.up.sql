@@ -0,0 +1,12 @@
+-- Журнал изменений активности пользователей
+CREATE TABLE IF NOT EXISTS user_status_events (
+    event_id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(100) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    event_type VARCHAR(20) NOT NULL CHECK (event_type IN ('ACTIVATE', 'DEACTIVATE')),
+    -- кто изменил статус (заголовок X-Actor запроса), NULL если неизвестно
+    actor VARCHAR(100) NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Для получения истории пользователя по времени
+CREATE INDEX IF NOT EXISTS idx_user_status_events_user_time ON user_status_events(user_id, created_at);
@@ -0,0 +1,2 @@
+-- Удаление стратегии выбора ревьюверов команды
+ALTER TABLE teams DROP COLUMN IF EXISTS assignment_strategy;
@@ -0,0 +1,2 @@
+-- Стратегия выбора ревьюверов, закрепленная за командой (NULL - глобальная ASSIGNMENT_STRATEGY)
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS assignment_strategy VARCHAR(50) NULL;
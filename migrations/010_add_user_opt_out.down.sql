@@ -0,0 +1,3 @@
+-- Удаление окна отказа от автоматического назначения
+ALTER TABLE users DROP COLUMN IF EXISTS opt_out_reason;
+ALTER TABLE users DROP COLUMN IF EXISTS opt_out_until;
@@ -0,0 +1,3 @@
+-- Окно отказа пользователя от автоматического назначения ревьювером (NULL - не отказывался)
+ALTER TABLE users ADD COLUMN IF NOT EXISTS opt_out_until TIMESTAMP WITH TIME ZONE NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS opt_out_reason TEXT NULL;
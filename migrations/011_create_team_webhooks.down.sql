@@ -0,0 +1,2 @@
+-- Удаление адресов уведомлений команд
+DROP TABLE IF EXISTS team_webhooks;
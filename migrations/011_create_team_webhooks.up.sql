@@ -0,0 +1,6 @@
+-- Адрес для уведомлений о назначениях по PR авторов команды (нет записи - глобальный EVENTS_ENDPOINT)
+CREATE TABLE IF NOT EXISTS team_webhooks (
+    team_name VARCHAR(100) PRIMARY KEY REFERENCES teams(team_name) ON DELETE CASCADE,
+    webhook_url TEXT NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
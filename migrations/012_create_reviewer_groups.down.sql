@@ -0,0 +1,2 @@
+-- Удаление групп ревьюверов
+DROP TABLE IF EXISTS reviewer_groups;
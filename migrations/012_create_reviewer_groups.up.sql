@@ -0,0 +1,5 @@
+-- Группы ревьюверов (например, подкоманды): при ONE_REVIEWER_PER_GROUP на PR назначается не больше одного участника группы
+CREATE TABLE IF NOT EXISTS reviewer_groups (
+    user_id VARCHAR(100) PRIMARY KEY REFERENCES users(user_id) ON DELETE CASCADE,
+    group_id VARCHAR(100) NOT NULL
+);
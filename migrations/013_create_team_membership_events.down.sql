@@ -0,0 +1,2 @@
+-- Удаление журнала членства в командах
+DROP TABLE IF EXISTS team_membership_events;
@@ -0,0 +1,19 @@
+-- Журнал членства пользователей в командах: вступление (JOIN) и выход (LEAVE)
+CREATE TABLE IF NOT EXISTS team_membership_events (
+    event_id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(100) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    -- без внешнего ключа: история сохраняется и для команд, которых больше нет
+    team_name VARCHAR(100) NOT NULL,
+    event_type VARCHAR(10) NOT NULL CHECK (event_type IN ('JOIN', 'LEAVE')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Для получения истории пользователя по времени
+CREATE INDEX IF NOT EXISTS idx_team_membership_events_user_time ON team_membership_events(user_id, created_at);
+
+-- Текущее членство существующих пользователей считается начавшимся при их создании;
+-- пользователи, у которых история уже есть, пропускаются, чтобы повторный запуск не дублировал события
+INSERT INTO team_membership_events (user_id, team_name, event_type, created_at)
+SELECT user_id, team_name, 'JOIN', COALESCE(created_at, NOW())
+FROM users
+WHERE NOT EXISTS (SELECT 1 FROM team_membership_events e WHERE e.user_id = users.user_id);
@@ -0,0 +1,2 @@
+-- Удаление недельной емкости ревью
+ALTER TABLE users DROP COLUMN IF EXISTS weekly_review_capacity;
@@ -0,0 +1,2 @@
+-- Недельная емкость ревью пользователя: сколько PR ему можно назначить за скользящие 7 дней (NULL - без ограничения)
+ALTER TABLE users ADD COLUMN IF NOT EXISTS weekly_review_capacity INTEGER NULL;
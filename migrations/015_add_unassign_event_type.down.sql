@@ -0,0 +1,5 @@
+-- Удаление событий UNASSIGN и возврат исходного ограничения типов событий
+DELETE FROM assignment_events WHERE event_type = 'UNASSIGN';
+ALTER TABLE assignment_events DROP CONSTRAINT IF EXISTS assignment_events_event_type_check;
+ALTER TABLE assignment_events ADD CONSTRAINT assignment_events_event_type_check
+    CHECK (event_type IN ('ASSIGN', 'REASSIGN'));
@@ -0,0 +1,4 @@
+-- Событие UNASSIGN: ревьювер снят с PR без замены, нужно для восстановления состава ревьюверов по журналу
+ALTER TABLE assignment_events DROP CONSTRAINT IF EXISTS assignment_events_event_type_check;
+ALTER TABLE assignment_events ADD CONSTRAINT assignment_events_event_type_check
+    CHECK (event_type IN ('ASSIGN', 'REASSIGN', 'UNASSIGN'));
@@ -0,0 +1,12 @@
+-- Закрытые PR нельзя выразить без статуса CLOSED: перевод в MERGED придумал бы мержи и исказил статистику,
+-- а в OPEN - вернул бы их в работу, поэтому откат отказывается выполняться, пока закрытые PR существуют
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pull_requests WHERE status = 'CLOSED') THEN
+        RAISE EXCEPTION 'cannot roll back migration 016: closed pull requests exist, merge, reopen or delete them first';
+    END IF;
+END $$;
+ALTER TABLE pull_requests DROP CONSTRAINT IF EXISTS pull_requests_status_check;
+ALTER TABLE pull_requests ADD CONSTRAINT pull_requests_status_check
+    CHECK (status IN ('OPEN', 'MERGED'));
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS closed_at;
@@ -16,19 +16,57 @@ const (
 	testDBName     = "pr_reviewer_e2e"
 )
 
-// CleanTestDatabase полностью очищает тестовую БД
-func CleanTestDatabase() error {
+// openTestDatabase открывает подключение к тестовой БД
+func openTestDatabase() (*sql.DB, error) {
 	connStr := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
 		testDBHost, testDBPort, testDBUser, testDBPassword, testDBName)
 
 	db, err := sql.Open("postgres", connStr)
 	if err != nil {
-		return fmt.Errorf("failed to connect to test database: %w", err)
+		return nil, fmt.Errorf("failed to connect to test database: %w", err)
+	}
+	return db, nil
+}
+
+// ExecTestDatabase выполняет SQL запрос в тестовой БД, используется для подготовки данных
+func ExecTestDatabase(query string, args ...interface{}) error {
+	db, err := openTestDatabase()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(query, args...); err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+	return nil
+}
+
+// CountTestDatabase возвращает результат запроса COUNT в тестовой БД
+func CountTestDatabase(query string, args ...interface{}) (int, error) {
+	db, err := openTestDatabase()
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	var count int
+	if err := db.QueryRow(query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to execute query: %w", err)
+	}
+	return count, nil
+}
+
+// CleanTestDatabase полностью очищает тестовую БД
+func CleanTestDatabase() error {
+	db, err := openTestDatabase()
+	if err != nil {
+		return err
 	}
 	defer db.Close()
 
 	// Очищаем таблицы в правильном порядке из-за foreign keys
-	tables := []string{"pr_reviewers", "pull_requests", "users", "teams"}
+	tables := []string{"assignment_events", "user_status_events", "team_membership_events", "user_component_expertise", "pr_reviewers", "pull_requests", "users", "team_webhooks", "reviewer_groups", "teams"}
 	for _, table := range tables {
 		_, err := db.Exec(fmt.Sprintf("TRUNCATE TABLE %s CASCADE", table))
 		if err != nil {
@@ -0,0 +1,94 @@
+package e2e
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// извлекает код и сообщение ошибки из тела ответа
+func (suite *E2ETestSuite) parseError(body []byte) (string, string) {
+	var response struct {
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	suite.Require().NoError(json.Unmarshal(body, &response), string(body))
+	return response.Error.Code, response.Error.Message
+}
+
+func (suite *E2ETestSuite) Test_ArrayBodyWhereObjectExpected() {
+	// === 1. Массив вместо объекта команды ===
+	teams := []map[string]interface{}{
+		{
+			"team_name": "e2e-array-team",
+			"members":   activeMembers("arr", 1),
+		},
+	}
+
+	statusCode, body, err := suite.makeRequest("POST", "/team/add", teams)
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusBadRequest, statusCode)
+	code, message := suite.parseError(body)
+	suite.Equal("INVALID_REQUEST", code)
+	suite.Equal("expected a JSON object, got an array", message)
+
+	// === 2. Тот же формат ошибки для других ручек ===
+	statusCode, body, err = suite.makeRequest("POST", "/pullRequest/merge", []string{"e2e-pr"})
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusBadRequest, statusCode)
+	_, message = suite.parseError(body)
+	suite.Equal("expected a JSON object, got an array", message)
+
+	// === 3. Неверный тип поля ===
+	statusCode, body, err = suite.makeRequest("POST", "/team/add", map[string]interface{}{
+		"team_name": "e2e-array-team",
+		"members":   map[string]interface{}{"user_id": "arr-1"},
+	})
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusBadRequest, statusCode)
+	_, message = suite.parseError(body)
+	suite.Equal("invalid type for field members: expected array, got object", message)
+}
+
+func (suite *E2ETestSuite) Test_UnknownRouteReturnsJSONNotFound() {
+	resp, err := suite.client.Get(baseURL + "/pullRequest/doesNotExist")
+	suite.Require().NoError(err)
+	defer resp.Body.Close()
+
+	suite.Equal(http.StatusNotFound, resp.StatusCode)
+	suite.Equal("application/json", resp.Header.Get("Content-Type"))
+
+	body, err := io.ReadAll(resp.Body)
+	suite.Require().NoError(err)
+	code, _ := suite.parseError(body)
+	suite.Equal("NOT_FOUND", code)
+}
+
+func (suite *E2ETestSuite) Test_ValidationReportsAllMissingFields() {
+	statusCode, body, err := suite.makeRequest("POST", "/pullRequest/create", map[string]string{
+		"pull_request_name": "Missing ids",
+	})
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusBadRequest, statusCode)
+
+	var response struct {
+		Error struct {
+			Code    string `json:"code"`
+			Details []struct {
+				Field string `json:"field"`
+				Code  string `json:"code"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	suite.Require().NoError(json.Unmarshal(body, &response))
+	suite.Equal("INVALID_REQUEST", response.Error.Code)
+
+	var fields []string
+	for _, detail := range response.Error.Details {
+		suite.Equal("VALIDATION_FAILED", detail.Code)
+		fields = append(fields, detail.Field)
+	}
+	suite.Equal([]string{"pull_request_id", "author_id"}, fields)
+}
@@ -0,0 +1,53 @@
+package e2e
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// создает команду через API и проверяет успешность ответа
+func (suite *E2ETestSuite) createTeam(teamName string, members []map[string]interface{}) {
+	team := map[string]interface{}{
+		"team_name": teamName,
+		"members":   members,
+	}
+
+	statusCode, body, err := suite.makeRequest("POST", "/team/add", team)
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode, "Создание команды %s: %s", teamName, string(body))
+}
+
+// формирует список активных участников команды с идентификаторами вида prefix-1..prefix-N
+func activeMembers(prefix string, count int) []map[string]interface{} {
+	members := make([]map[string]interface{}, 0, count)
+	for i := 1; i <= count; i++ {
+		members = append(members, map[string]interface{}{
+			"user_id":   fmt.Sprintf("%s-%d", prefix, i),
+			"username":  fmt.Sprintf("User %s %d", prefix, i),
+			"is_active": true,
+		})
+	}
+	return members
+}
+
+// создает PR через API и возвращает объект pr из ответа
+func (suite *E2ETestSuite) createPR(request map[string]interface{}) map[string]interface{} {
+	statusCode, body, err := suite.makeRequest("POST", "/pullRequest/create", request)
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusCreated, statusCode, "Создание PR: %s", string(body))
+
+	var response map[string]interface{}
+	suite.Require().NoError(json.Unmarshal(body, &response))
+	return response["pr"].(map[string]interface{})
+}
+
+// извлекает список строк из JSON массива
+func toStrings(value interface{}) []string {
+	items, _ := value.([]interface{})
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		result = append(result, item.(string))
+	}
+	return result
+}
@@ -0,0 +1,568 @@
+package e2e
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+func (suite *E2ETestSuite) Test_CreatePRWithRequiredReviewers() {
+	// автор и четыре потенциальных ревьювера
+	suite.createTeam("e2e-required-reviewers", activeMembers("req", 5))
+
+	// === 1. Запрошено 3 ревьювера, команда может их предоставить ===
+	pr := suite.createPR(map[string]interface{}{
+		"pull_request_id":    "e2e-required-3",
+		"pull_request_name":  "Critical security fix",
+		"author_id":          "req-1",
+		"required_reviewers": 3,
+	})
+	reviewers := toStrings(pr["assigned_reviewers"])
+	suite.Len(reviewers, 3, "Должно быть назначено 3 ревьювера")
+	suite.NotContains(reviewers, "req-1", "Автор не может быть ревьювером")
+	suite.EqualValues(3, pr["required_reviewers"])
+
+	// === 2. Запрошено больше, чем доступно кандидатов - ограничиваем ===
+	pr = suite.createPR(map[string]interface{}{
+		"pull_request_id":    "e2e-required-10",
+		"pull_request_name":  "Database migration",
+		"author_id":          "req-1",
+		"required_reviewers": 10,
+	})
+	suite.Len(toStrings(pr["assigned_reviewers"]), 4, "Назначаются все доступные кандидаты")
+
+	// === 3. Без параметра используется значение по умолчанию ===
+	pr = suite.createPR(map[string]interface{}{
+		"pull_request_id":   "e2e-required-default",
+		"pull_request_name": "Regular change",
+		"author_id":         "req-1",
+	})
+	suite.Len(toStrings(pr["assigned_reviewers"]), 2)
+	suite.NotContains(pr, "required_reviewers")
+
+	// === 4. Невалидное значение ===
+	statusCode, _, err := suite.makeRequest("POST", "/pullRequest/create", map[string]interface{}{
+		"pull_request_id":    "e2e-required-invalid",
+		"pull_request_name":  "Invalid",
+		"author_id":          "req-1",
+		"required_reviewers": 0,
+	})
+	suite.NoError(err)
+	suite.Equal(http.StatusBadRequest, statusCode)
+}
+
+func (suite *E2ETestSuite) Test_CreateDuplicatePR() {
+	suite.createTeam("e2e-duplicate", activeMembers("dup", 4))
+	request := map[string]interface{}{
+		"pull_request_id":   "e2e-duplicate-pr",
+		"pull_request_name": "Retry me",
+		"author_id":         "dup-1",
+	}
+	created := suite.createPR(request)
+
+	// === 1. По умолчанию дубликат - конфликт ===
+	statusCode, body, err := suite.makeRequest("POST", "/pullRequest/create", request)
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusConflict, statusCode)
+	code, _ := suite.parseError(body)
+	suite.Equal("PR_EXISTS", code)
+
+	// === 2. get_if_exists=true возвращает существующий PR с текущими ревьюверами ===
+	statusCode, body, err = suite.makeRequest("POST", "/pullRequest/reassign", map[string]string{
+		"pull_request_id": "e2e-duplicate-pr",
+		"old_user_id":     toStrings(created["assigned_reviewers"])[0],
+	})
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode, string(body))
+
+	var reassigned map[string]interface{}
+	suite.Require().NoError(json.Unmarshal(body, &reassigned))
+	currentReviewers := toStrings(reassigned["pr"].(map[string]interface{})["assigned_reviewers"])
+
+	request["pull_request_name"] = "Different name is ignored"
+	statusCode, body, err = suite.makeRequest("POST", "/pullRequest/create?get_if_exists=true", request)
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode, string(body))
+
+	var response map[string]interface{}
+	suite.Require().NoError(json.Unmarshal(body, &response))
+	existing := response["pr"].(map[string]interface{})
+	suite.Equal("Retry me", existing["pull_request_name"])
+	suite.ElementsMatch(currentReviewers, toStrings(existing["assigned_reviewers"]))
+
+	// === 3. Новый PR с get_if_exists=true создается как обычно ===
+	request["pull_request_id"] = "e2e-duplicate-new"
+	statusCode, _, err = suite.makeRequest("POST", "/pullRequest/create?get_if_exists=true", request)
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusCreated, statusCode)
+}
+
+func (suite *E2ETestSuite) Test_BatchGetPRs() {
+	suite.createTeam("e2e-batch", activeMembers("batch", 3))
+	created := suite.createPR(map[string]interface{}{
+		"pull_request_id":   "e2e-batch-1",
+		"pull_request_name": "Board item",
+		"author_id":         "batch-1",
+	})
+
+	statusCode, body, err := suite.makeRequest("POST", "/pullRequest/batchGet", map[string]interface{}{
+		"pull_request_ids": []string{"e2e-batch-1", "e2e-batch-missing"},
+	})
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode, string(body))
+
+	var response struct {
+		PullRequests map[string]map[string]interface{} `json:"pull_requests"`
+	}
+	suite.Require().NoError(json.Unmarshal(body, &response))
+	suite.Require().Len(response.PullRequests, 2)
+	suite.Nil(response.PullRequests["e2e-batch-missing"])
+	suite.ElementsMatch(toStrings(created["assigned_reviewers"]),
+		toStrings(response.PullRequests["e2e-batch-1"]["assigned_reviewers"]))
+
+	statusCode, _, err = suite.makeRequest("POST", "/pullRequest/batchGet", map[string]interface{}{
+		"pull_request_ids": []string{},
+	})
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusBadRequest, statusCode)
+}
+
+func (suite *E2ETestSuite) Test_PurgeMergedPRs() {
+	suite.createTeam("e2e-purge", activeMembers("purge", 3))
+	for _, prID := range []string{"purge-old", "purge-recent", "purge-open"} {
+		suite.createPR(map[string]interface{}{
+			"pull_request_id":   prID,
+			"pull_request_name": prID,
+			"author_id":         "purge-1",
+		})
+	}
+	for _, prID := range []string{"purge-old", "purge-recent"} {
+		statusCode, _, err := suite.makeRequest("POST", "/pullRequest/merge", map[string]string{"pull_request_id": prID})
+		suite.Require().NoError(err)
+		suite.Require().Equal(http.StatusOK, statusCode)
+	}
+	suite.Require().NoError(ExecTestDatabase(
+		"UPDATE pull_requests SET merged_at = NOW() - INTERVAL '90 days', created_at = NOW() - INTERVAL '100 days' WHERE pull_request_id = 'purge-old'"))
+	suite.Require().NoError(ExecTestDatabase(
+		"UPDATE pull_requests SET created_at = NOW() - INTERVAL '100 days' WHERE pull_request_id = 'purge-open'"))
+
+	statusCode, body, err := suite.makeRequest("POST", "/admin/purge", map[string]string{"older_than": "720h"})
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode, string(body))
+
+	var response struct {
+		PurgedCount int `json:"purged_count"`
+	}
+	suite.Require().NoError(json.Unmarshal(body, &response))
+	suite.Equal(1, response.PurgedCount)
+
+	remaining, err := CountTestDatabase("SELECT COUNT(*) FROM pull_requests WHERE pull_request_id LIKE 'purge-%'")
+	suite.Require().NoError(err)
+	suite.Equal(2, remaining)
+
+	orphanReviewers, err := CountTestDatabase("SELECT COUNT(*) FROM pr_reviewers WHERE pull_request_id = 'purge-old'")
+	suite.Require().NoError(err)
+	suite.Equal(0, orphanReviewers)
+
+	statusCode, _, err = suite.makeRequest("POST", "/admin/purge", map[string]string{"older_than": "forever"})
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusBadRequest, statusCode)
+}
+
+func (suite *E2ETestSuite) Test_ConcurrentMergeAndReassign() {
+	suite.createTeam("e2e-race", activeMembers("race", 5))
+
+	for i := 0; i < 10; i++ {
+		prID := fmt.Sprintf("e2e-race-%d", i)
+		created := suite.createPR(map[string]interface{}{
+			"pull_request_id":   prID,
+			"pull_request_name": "Race",
+			"author_id":         "race-1",
+		})
+		originalReviewers := toStrings(created["assigned_reviewers"])
+
+		// === 1. Мерж и переназначение одного PR одновременно ===
+		var wg sync.WaitGroup
+		var mergeStatus, reassignStatus int
+		var reassignBody []byte
+		var mergeErr, reassignErr error
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			mergeStatus, _, mergeErr = suite.makeRequest("POST", "/pullRequest/merge", map[string]string{
+				"pull_request_id": prID,
+			})
+		}()
+		go func() {
+			defer wg.Done()
+			reassignStatus, reassignBody, reassignErr = suite.makeRequest("POST", "/pullRequest/reassign", map[string]string{
+				"pull_request_id": prID,
+				"old_user_id":     originalReviewers[0],
+			})
+		}()
+		wg.Wait()
+
+		suite.Require().NoError(mergeErr)
+		suite.Require().NoError(reassignErr)
+		suite.Equal(http.StatusOK, mergeStatus)
+
+		// === 2. Итоговое состояние согласовано с ответом переназначения ===
+		statusCode, body, err := suite.makeRequest("POST", "/pullRequest/batchGet", map[string]interface{}{
+			"pull_request_ids": []string{prID},
+		})
+		suite.Require().NoError(err)
+		suite.Require().Equal(http.StatusOK, statusCode, string(body))
+
+		var response struct {
+			PullRequests map[string]map[string]interface{} `json:"pull_requests"`
+		}
+		suite.Require().NoError(json.Unmarshal(body, &response))
+		final := response.PullRequests[prID]
+		suite.Equal("MERGED", final["status"])
+
+		switch reassignStatus {
+		case http.StatusOK:
+			// переназначение успело до мержа
+			var reassigned map[string]interface{}
+			suite.Require().NoError(json.Unmarshal(reassignBody, &reassigned))
+			expected := toStrings(reassigned["pr"].(map[string]interface{})["assigned_reviewers"])
+			suite.ElementsMatch(expected, toStrings(final["assigned_reviewers"]))
+		case http.StatusConflict:
+			// мерж выиграл гонку - ревьюверы не изменились
+			code, _ := suite.parseError(reassignBody)
+			suite.Equal("PR_MERGED", code)
+			suite.ElementsMatch(originalReviewers, toStrings(final["assigned_reviewers"]))
+		default:
+			suite.Failf("unexpected reassign status", "status %d: %s", reassignStatus, string(reassignBody))
+		}
+	}
+}
+
+func (suite *E2ETestSuite) Test_AddReviewerUpToCap() {
+	// лимит MAX_REVIEWERS_PER_PR по умолчанию - 10 ревьюверов
+	const maxReviewers = 10
+	suite.createTeam("e2e-add-reviewer", activeMembers("addrev", maxReviewers+2))
+	pr := suite.createPR(map[string]interface{}{
+		"pull_request_id":   "e2e-add-reviewer-pr",
+		"pull_request_name": "Add reviewer",
+		"author_id":         "addrev-1",
+	})
+	assigned := toStrings(pr["assigned_reviewers"])
+
+	addReviewer := func(userID string) (int, []byte) {
+		statusCode, body, err := suite.makeRequest("POST", "/pullRequest/addReviewer", map[string]string{
+			"pull_request_id": "e2e-add-reviewer-pr",
+			"user_id":         userID,
+		})
+		suite.Require().NoError(err)
+		return statusCode, body
+	}
+
+	// повторное добавление уже назначенного ревьювера
+	statusCode, _ := addReviewer(assigned[0])
+	suite.Equal(http.StatusConflict, statusCode)
+
+	isAssigned := make(map[string]bool)
+	for _, userID := range assigned {
+		isAssigned[userID] = true
+	}
+	var rest []string
+	for i := 2; i <= maxReviewers+2; i++ {
+		if userID := fmt.Sprintf("addrev-%d", i); !isAssigned[userID] {
+			rest = append(rest, userID)
+		}
+	}
+	for len(assigned) < maxReviewers {
+		statusCode, body := addReviewer(rest[0])
+		suite.Require().Equal(http.StatusOK, statusCode, string(body))
+		assigned, rest = append(assigned, rest[0]), rest[1:]
+	}
+
+	statusCode, body := addReviewer(rest[0])
+	suite.Equal(http.StatusBadRequest, statusCode)
+	code, message := suite.parseError(body)
+	suite.Equal("INVALID_REQUEST", code)
+	suite.Contains(message, "10 reviewers")
+
+	count, err := CountTestDatabase("SELECT COUNT(*) FROM pr_reviewers WHERE pull_request_id = $1", "e2e-add-reviewer-pr")
+	suite.Require().NoError(err)
+	suite.Equal(maxReviewers, count)
+}
+
+func (suite *E2ETestSuite) Test_GetPRExpandReviewers() {
+	suite.createTeam("e2e-expand", activeMembers("expand", 3))
+	suite.createPR(map[string]interface{}{
+		"pull_request_id":   "e2e-expand-pr",
+		"pull_request_name": "Expand",
+		"author_id":         "expand-1",
+	})
+	// expand-3 назначен раньше expand-2
+	suite.Require().NoError(ExecTestDatabase(`
+		UPDATE pr_reviewers SET assigned_at = NOW() - CASE reviewer_id WHEN 'expand-3' THEN INTERVAL '2 days' ELSE INTERVAL '1 day' END
+		WHERE pull_request_id = 'e2e-expand-pr'
+	`))
+
+	var response struct {
+		PR map[string]json.RawMessage `json:"pr"`
+	}
+
+	// === 1. Без expand ревьюверы со временем не возвращаются ===
+	statusCode, body, err := suite.makeGetRequest("/pullRequest/get?pull_request_id=e2e-expand-pr")
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode, string(body))
+	suite.Require().NoError(json.Unmarshal(body, &response))
+	suite.NotContains(response.PR, "reviewers")
+
+	// === 2. С expand=reviewers - по возрастанию времени назначения ===
+	statusCode, body, err = suite.makeGetRequest("/pullRequest/get?pull_request_id=e2e-expand-pr&expand=reviewers")
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode, string(body))
+	suite.Require().NoError(json.Unmarshal(body, &response))
+
+	var reviewers []struct {
+		UserID     string     `json:"user_id"`
+		AssignedAt *time.Time `json:"assigned_at"`
+	}
+	suite.Require().NoError(json.Unmarshal(response.PR["reviewers"], &reviewers))
+	suite.Require().Len(reviewers, 2)
+	suite.Equal("expand-3", reviewers[0].UserID)
+	suite.Equal("expand-2", reviewers[1].UserID)
+	suite.Require().NotNil(reviewers[0].AssignedAt)
+	suite.Require().NotNil(reviewers[1].AssignedAt)
+	suite.WithinDuration(time.Now().Add(-48*time.Hour), *reviewers[0].AssignedAt, time.Minute)
+	suite.True(reviewers[0].AssignedAt.Before(*reviewers[1].AssignedAt))
+
+	// === 3. Неизвестный expand и несуществующий PR ===
+	statusCode, _, err = suite.makeGetRequest("/pullRequest/get?pull_request_id=e2e-expand-pr&expand=author")
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusBadRequest, statusCode)
+
+	statusCode, _, err = suite.makeGetRequest("/pullRequest/get?pull_request_id=e2e-expand-missing")
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusNotFound, statusCode)
+}
+
+func (suite *E2ETestSuite) Test_TeamAuthoredPRs() {
+	suite.createTeam("e2e-authored-a", activeMembers("authored-a", 3))
+	suite.createTeam("e2e-authored-b", activeMembers("authored-b", 3))
+	for _, pr := range []struct{ id, author string }{
+		{"e2e-authored-a-1", "authored-a-1"},
+		{"e2e-authored-a-2", "authored-a-2"},
+		{"e2e-authored-b-1", "authored-b-1"},
+	} {
+		suite.createPR(map[string]interface{}{"pull_request_id": pr.id, "pull_request_name": pr.id, "author_id": pr.author})
+	}
+	statusCode, _, err := suite.makeRequest("POST", "/pullRequest/merge", map[string]interface{}{"pull_request_id": "e2e-authored-a-1"})
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode)
+
+	var response struct {
+		PullRequests []struct {
+			PullRequestID     string   `json:"pull_request_id"`
+			AssignedReviewers []string `json:"assigned_reviewers"`
+		} `json:"pull_requests"`
+		Pagination struct {
+			Total int `json:"total"`
+		} `json:"pagination"`
+	}
+	prIDs := func() []string {
+		ids := make([]string, 0, len(response.PullRequests))
+		for _, pr := range response.PullRequests {
+			ids = append(ids, pr.PullRequestID)
+		}
+		return ids
+	}
+
+	// === 1. Только PR авторов команды, с ревьюверами ===
+	statusCode, body, err := suite.makeGetRequest("/team/authoredPRs?team_name=e2e-authored-a")
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode, string(body))
+	suite.Require().NoError(json.Unmarshal(body, &response))
+	suite.ElementsMatch([]string{"e2e-authored-a-1", "e2e-authored-a-2"}, prIDs())
+	suite.Equal(2, response.Pagination.Total)
+	for _, pr := range response.PullRequests {
+		suite.Len(pr.AssignedReviewers, 2)
+	}
+
+	// === 2. Фильтр по статусу ===
+	statusCode, body, err = suite.makeGetRequest("/team/authoredPRs?team_name=e2e-authored-a&status=OPEN")
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode, string(body))
+	suite.Require().NoError(json.Unmarshal(body, &response))
+	suite.Equal([]string{"e2e-authored-a-2"}, prIDs())
+
+	// === 3. Несуществующая команда ===
+	statusCode, _, err = suite.makeGetRequest("/team/authoredPRs?team_name=e2e-authored-missing")
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusNotFound, statusCode)
+}
+
+func (suite *E2ETestSuite) Test_ClosePR() {
+	suite.createTeam("e2e-close", activeMembers("close", 3))
+	suite.createPR(map[string]interface{}{"pull_request_id": "e2e-close-1", "pull_request_name": "Close", "author_id": "close-1"})
+	suite.createPR(map[string]interface{}{"pull_request_id": "e2e-close-2", "pull_request_name": "Merge", "author_id": "close-1"})
+
+	var response struct {
+		PR struct {
+			Status   string     `json:"status"`
+			ClosedAt *time.Time `json:"closedAt"`
+			MergedAt *time.Time `json:"mergedAt"`
+		} `json:"pr"`
+	}
+
+	// === 1. Закрытие открытого PR ===
+	statusCode, body, err := suite.makeRequest("POST", "/pullRequest/close", map[string]string{"pull_request_id": "e2e-close-1"})
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode, string(body))
+	suite.Require().NoError(json.Unmarshal(body, &response))
+	suite.Equal("CLOSED", response.PR.Status)
+	suite.Require().NotNil(response.PR.ClosedAt)
+	suite.Nil(response.PR.MergedAt)
+	closedAt := *response.PR.ClosedAt
+
+	// === 2. Повторное закрытие возвращает сохраненное состояние ===
+	statusCode, body, err = suite.makeRequest("POST", "/pullRequest/close", map[string]string{"pull_request_id": "e2e-close-1"})
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode, string(body))
+	suite.Require().NoError(json.Unmarshal(body, &response))
+	suite.Equal("CLOSED", response.PR.Status)
+	suite.Require().NotNil(response.PR.ClosedAt)
+	suite.WithinDuration(closedAt, *response.PR.ClosedAt, time.Millisecond)
+
+	// === 3. Замерженный PR закрыть нельзя ===
+	statusCode, _, err = suite.makeRequest("POST", "/pullRequest/merge", map[string]string{"pull_request_id": "e2e-close-2"})
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode)
+	statusCode, _, err = suite.makeRequest("POST", "/pullRequest/close", map[string]string{"pull_request_id": "e2e-close-2"})
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusConflict, statusCode)
+
+	// === 4. Фильтр по статусу CLOSED ===
+	statusCode, body, err = suite.makeGetRequest("/team/authoredPRs?team_name=e2e-close&status=CLOSED")
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode, string(body))
+	suite.Contains(string(body), "e2e-close-1")
+	suite.NotContains(string(body), "e2e-close-2")
+}
+
+func (suite *E2ETestSuite) Test_ListPRs() {
+	suite.createTeam("e2e-list", activeMembers("list", 3))
+	for _, prID := range []string{"e2e-list-1", "e2e-list-2", "e2e-list-3"} {
+		suite.createPR(map[string]interface{}{"pull_request_id": prID, "pull_request_name": prID, "author_id": "list-1"})
+	}
+	statusCode, _, err := suite.makeRequest("POST", "/pullRequest/merge", map[string]string{"pull_request_id": "e2e-list-1"})
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode)
+
+	var response struct {
+		PullRequests []struct {
+			PullRequestID string `json:"pull_request_id"`
+			Status        string `json:"status"`
+		} `json:"pull_requests"`
+		Pagination struct {
+			Limit   int  `json:"limit"`
+			Total   int  `json:"total"`
+			HasMore bool `json:"has_more"`
+		} `json:"pagination"`
+	}
+
+	// === 1. Фильтр по автору и статусу, страница по одному PR от новых к старым ===
+	statusCode, body, err := suite.makeGetRequest("/pullRequests/list?author_id=list-1&status=OPEN&limit=1")
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode, string(body))
+	suite.Require().NoError(json.Unmarshal(body, &response))
+	suite.Require().Len(response.PullRequests, 1)
+	suite.Equal("e2e-list-3", response.PullRequests[0].PullRequestID)
+	suite.Equal(2, response.Pagination.Total)
+	suite.True(response.Pagination.HasMore)
+
+	// === 2. Без статуса - все PR автора, limit по умолчанию ===
+	statusCode, body, err = suite.makeGetRequest("/pullRequests/list?author_id=list-1")
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode, string(body))
+	suite.Require().NoError(json.Unmarshal(body, &response))
+	suite.Len(response.PullRequests, 3)
+	suite.Equal(50, response.Pagination.Limit)
+
+	// === 3. Неизвестный статус и limit больше 200 ===
+	for _, query := range []string{"status=DRAFT", "limit=201"} {
+		statusCode, _, err = suite.makeGetRequest("/pullRequests/list?" + query)
+		suite.Require().NoError(err)
+		suite.Equal(http.StatusBadRequest, statusCode, query)
+	}
+}
+
+func (suite *E2ETestSuite) Test_ReviewersAt() {
+	suite.createTeam("e2e-history", activeMembers("history", 5))
+	pr := suite.createPR(map[string]interface{}{
+		"pull_request_id":   "e2e-history-pr",
+		"pull_request_name": "History",
+		"author_id":         "history-1",
+	})
+	// создание PR записало события ASSIGN для назначенных ревьюверов
+	assigned, err := CountTestDatabase(
+		"SELECT COUNT(*) FROM assignment_events WHERE pull_request_id = 'e2e-history-pr' AND event_type = 'ASSIGN'")
+	suite.Require().NoError(err)
+	suite.Equal(len(toStrings(pr["assigned_reviewers"])), assigned)
+
+	// заменяем журнал известной последовательностью событий
+	created := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	suite.Require().NoError(ExecTestDatabase(
+		"UPDATE pull_requests SET created_at = $1 WHERE pull_request_id = 'e2e-history-pr'", created))
+	suite.Require().NoError(ExecTestDatabase("DELETE FROM assignment_events WHERE pull_request_id = 'e2e-history-pr'"))
+	events := []struct {
+		eventType, reviewerID, previousID string
+		minutes                           int
+	}{
+		{"ASSIGN", "history-2", "", 0},
+		{"ASSIGN", "history-3", "", 0},
+		{"REASSIGN", "history-4", "history-2", 10},
+		{"REASSIGN", "history-5", "history-3", 20},
+		{"UNASSIGN", "history-4", "", 30},
+	}
+	for _, event := range events {
+		suite.Require().NoError(ExecTestDatabase(`
+			INSERT INTO assignment_events (pull_request_id, event_type, reviewer_id, previous_reviewer_id, created_at)
+			VALUES ('e2e-history-pr', $1, $2, NULLIF($3, ''), $4)
+		`, event.eventType, event.reviewerID, event.previousID, created.Add(time.Duration(event.minutes)*time.Minute)))
+	}
+
+	type reviewersAt struct {
+		Reviewers []string `json:"reviewers"`
+		Added     []string `json:"added"`
+		Removed   []string `json:"removed"`
+	}
+	fetch := func(query string) reviewersAt {
+		statusCode, body, err := suite.makeGetRequest("/pullRequest/reviewersAt?pull_request_id=e2e-history-pr&" + query)
+		suite.Require().NoError(err)
+		suite.Require().Equal(http.StatusOK, statusCode, string(body))
+
+		var response reviewersAt
+		suite.Require().NoError(json.Unmarshal(body, &response))
+		return response
+	}
+
+	suite.Equal([]string{}, fetch("at=2025-03-01T09:59:00Z").Reviewers)
+	suite.Equal([]string{"history-2", "history-3"}, fetch("at=2025-03-01T10:00:00Z").Reviewers)
+	suite.Equal([]string{"history-3", "history-4"}, fetch("at=2025-03-01T10:15:00Z").Reviewers)
+	suite.Equal([]string{"history-4", "history-5"}, fetch("at=2025-03-01T10:25:00Z").Reviewers)
+	suite.Equal([]string{"history-5"}, fetch("at=2025-03-02").Reviewers)
+
+	delta := fetch("since=2025-03-01T10:05:00Z&at=2025-03-01T10:25:00Z")
+	suite.Equal([]string{"history-4", "history-5"}, delta.Added)
+	suite.Equal([]string{"history-2", "history-3"}, delta.Removed)
+
+	// === Ошибки ===
+	for query, expected := range map[string]int{
+		"pull_request_id=e2e-history-pr":                                http.StatusBadRequest,
+		"pull_request_id=e2e-history-pr&at=yesterday":                   http.StatusBadRequest,
+		"pull_request_id=e2e-history-pr&at=2025-03-01&since=2025-03-02": http.StatusBadRequest,
+		"pull_request_id=missing&at=2025-03-01":                         http.StatusNotFound,
+	} {
+		statusCode, _, err := suite.makeGetRequest("/pullRequest/reviewersAt?" + query)
+		suite.Require().NoError(err)
+		suite.Equal(expected, statusCode, query)
+	}
+}
@@ -0,0 +1,737 @@
+package e2e
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+func (suite *E2ETestSuite) Test_StatsExcludeUsers() {
+	// автор-бот и два ревьювера, оба попадут на PR
+	suite.createTeam("e2e-stats-bots", []map[string]interface{}{
+		{"user_id": "stats-bot", "username": "Release Bot", "is_active": true},
+		{"user_id": "stats-rev-1", "username": "Reviewer One", "is_active": true},
+		{"user_id": "stats-rev-2", "username": "Reviewer Two", "is_active": true},
+	})
+	suite.createPR(map[string]interface{}{
+		"pull_request_id":   "e2e-stats-pr-1",
+		"pull_request_name": "Bump dependencies",
+		"author_id":         "stats-bot",
+	})
+
+	statusCode, body, err := suite.makeGetRequest("/stats/review-assignments?exclude_users=stats-bot,%20stats-rev-1")
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode)
+
+	var stats struct {
+		TotalAssignments  int64 `json:"total_assignments"`
+		AssignmentsByUser []struct {
+			UserID          string `json:"user_id"`
+			AssignmentCount int64  `json:"assignment_count"`
+		} `json:"assignments_by_user"`
+		TopReviewers []struct {
+			UserID string `json:"user_id"`
+		} `json:"top_reviewers"`
+	}
+	suite.Require().NoError(json.Unmarshal(body, &stats))
+
+	excluded := map[string]bool{"stats-bot": true, "stats-rev-1": true}
+	for _, stat := range stats.AssignmentsByUser {
+		suite.False(excluded[stat.UserID], "Исключенный пользователь %s в assignments_by_user", stat.UserID)
+	}
+	for _, stat := range stats.TopReviewers {
+		suite.False(excluded[stat.UserID], "Исключенный пользователь %s в top_reviewers", stat.UserID)
+	}
+
+	// оставшийся ревьювер учитывается, а общий счетчик не включает исключенных
+	found := false
+	for _, stat := range stats.AssignmentsByUser {
+		if stat.UserID == "stats-rev-2" {
+			found = true
+			suite.Equal(int64(1), stat.AssignmentCount)
+		}
+	}
+	suite.True(found, "stats-rev-2 должен остаться в статистике")
+	suite.Equal(int64(1), stats.TotalAssignments)
+}
+
+func (suite *E2ETestSuite) Test_BackfillAssignmentEvents() {
+	suite.createTeam("backfill-team", activeMembers("backfill", 4))
+	for _, prID := range []string{"backfill-pr-1", "backfill-pr-2"} {
+		suite.createPR(map[string]interface{}{
+			"pull_request_id":   prID,
+			"pull_request_name": prID,
+			"author_id":         "backfill-1",
+		})
+	}
+
+	// имитируем данные, появившиеся до ведения журнала
+	suite.Require().NoError(ExecTestDatabase("DELETE FROM assignment_events"))
+	reviewerRows, err := CountTestDatabase("SELECT COUNT(*) FROM pr_reviewers")
+	suite.Require().NoError(err)
+	suite.Require().Equal(4, reviewerRows)
+
+	statusCode, body, err := suite.makeRequest("POST", "/admin/backfillStats", nil)
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode, string(body))
+
+	var response struct {
+		CreatedEvents int `json:"created_events"`
+	}
+	suite.Require().NoError(json.Unmarshal(body, &response))
+	suite.Equal(reviewerRows, response.CreatedEvents)
+
+	// для каждого назначения ровно одно событие ASSIGN со временем назначения
+	matching, err := CountTestDatabase(`
+		SELECT COUNT(*) FROM assignment_events e
+		JOIN pr_reviewers rev ON rev.pull_request_id = e.pull_request_id AND rev.reviewer_id = e.reviewer_id
+		WHERE e.event_type = 'ASSIGN' AND e.created_at = rev.assigned_at`)
+	suite.Require().NoError(err)
+	suite.Equal(reviewerRows, matching)
+
+	// повторный вызов идемпотентен
+	statusCode, body, err = suite.makeRequest("POST", "/admin/backfillStats", nil)
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode)
+	suite.Require().NoError(json.Unmarshal(body, &response))
+	suite.Equal(0, response.CreatedEvents)
+
+	total, err := CountTestDatabase("SELECT COUNT(*) FROM assignment_events")
+	suite.Require().NoError(err)
+	suite.Equal(reviewerRows, total)
+}
+
+func (suite *E2ETestSuite) Test_UnassignedUsers() {
+	// в команде из 4 человек PR автора получает 2 ревьюверов, еще один участник остается без назначений
+	suite.createTeam("e2e-unassigned", activeMembers("unassigned", 4))
+	suite.createTeam("e2e-unassigned-other", []map[string]interface{}{
+		{"user_id": "unassigned-other", "username": "Other", "is_active": true},
+		{"user_id": "unassigned-inactive", "username": "Inactive", "is_active": false},
+	})
+	created := suite.createPR(map[string]interface{}{
+		"pull_request_id":   "e2e-unassigned-pr",
+		"pull_request_name": "Coverage",
+		"author_id":         "unassigned-1",
+	})
+	reviewers := toStrings(created["assigned_reviewers"])
+	suite.Require().Len(reviewers, 2)
+
+	var expected []string
+	for _, userID := range []string{"unassigned-1", "unassigned-2", "unassigned-3", "unassigned-4"} {
+		if userID != reviewers[0] && userID != reviewers[1] {
+			expected = append(expected, userID)
+		}
+	}
+
+	// === 1. Фильтр по команде ===
+	statusCode, body, err := suite.makeGetRequest("/stats/unassigned?team_name=e2e-unassigned")
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode, string(body))
+
+	var response struct {
+		Users []struct {
+			UserID   string `json:"user_id"`
+			TeamName string `json:"team_name"`
+		} `json:"users"`
+	}
+	suite.Require().NoError(json.Unmarshal(body, &response))
+	var userIDs []string
+	for _, user := range response.Users {
+		suite.Equal("e2e-unassigned", user.TeamName)
+		userIDs = append(userIDs, user.UserID)
+	}
+	suite.Equal(expected, userIDs)
+
+	// === 2. Без фильтра - все команды, неактивные не возвращаются ===
+	statusCode, body, err = suite.makeGetRequest("/stats/unassigned")
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode, string(body))
+	suite.Require().NoError(json.Unmarshal(body, &response))
+	userIDs = nil
+	for _, user := range response.Users {
+		userIDs = append(userIDs, user.UserID)
+	}
+	suite.Contains(userIDs, "unassigned-other")
+	suite.NotContains(userIDs, "unassigned-inactive")
+	for _, reviewer := range reviewers {
+		suite.NotContains(userIDs, reviewer)
+	}
+}
+
+func (suite *E2ETestSuite) Test_StuckPRs() {
+	// в команде из двух человек у PR ровно один ревьювер
+	suite.createTeam("e2e-stuck", activeMembers("stuck", 2))
+	suite.createPR(map[string]interface{}{
+		"pull_request_id":   "e2e-stuck-pr",
+		"pull_request_name": "Orphaned",
+		"author_id":         "stuck-1",
+	})
+	suite.createPR(map[string]interface{}{
+		"pull_request_id":   "e2e-stuck-healthy",
+		"pull_request_name": "Healthy",
+		"author_id":         "stuck-2",
+	})
+	// PR автора без коллег создается совсем без ревьюверов
+	suite.createTeam("e2e-stuck-solo", activeMembers("stuck-solo", 1))
+	suite.createPR(map[string]interface{}{
+		"pull_request_id":   "e2e-stuck-solo-pr",
+		"pull_request_name": "Alone",
+		"author_id":         "stuck-solo-1",
+	})
+
+	// единственный ревьювер первого PR уходит в неактивные без переназначения
+	suite.Require().NoError(ExecTestDatabase("UPDATE users SET is_active = false WHERE user_id = $1", "stuck-2"))
+
+	statusCode, body, err := suite.makeGetRequest("/stats/stuck")
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode, string(body))
+
+	var response struct {
+		PullRequests []struct {
+			PullRequestID     string   `json:"pull_request_id"`
+			AssignedReviewers []string `json:"assigned_reviewers"`
+		} `json:"pull_requests"`
+	}
+	suite.Require().NoError(json.Unmarshal(body, &response))
+
+	stuck := map[string][]string{}
+	for _, pr := range response.PullRequests {
+		stuck[pr.PullRequestID] = pr.AssignedReviewers
+	}
+	suite.Equal([]string{"stuck-2"}, stuck["e2e-stuck-pr"])
+	suite.Contains(stuck, "e2e-stuck-solo-pr")
+	suite.Empty(stuck["e2e-stuck-solo-pr"])
+	// у второго PR ревьювер stuck-1 активен
+	suite.NotContains(stuck, "e2e-stuck-healthy")
+}
+
+func (suite *E2ETestSuite) Test_StuckPRsNDJSON() {
+	// у каждого из трех PR единственный ревьювер, который затем уходит в неактивные
+	suite.createTeam("e2e-stream", activeMembers("stream", 2))
+	prIDs := []string{"e2e-stream-pr-1", "e2e-stream-pr-2", "e2e-stream-pr-3"}
+	for _, prID := range prIDs {
+		suite.createPR(map[string]interface{}{
+			"pull_request_id":   prID,
+			"pull_request_name": "Streamed",
+			"author_id":         "stream-1",
+		})
+	}
+	suite.Require().NoError(ExecTestDatabase("UPDATE users SET is_active = false WHERE user_id = $1", "stream-2"))
+
+	req, err := http.NewRequest(http.MethodGet, baseURL+"/stats/stuck?limit=1", nil)
+	suite.Require().NoError(err)
+	req.Header.Set("Accept", "application/x-ndjson")
+	resp, err := suite.client.Do(req)
+	suite.Require().NoError(err)
+	defer resp.Body.Close()
+	suite.Require().Equal(http.StatusOK, resp.StatusCode)
+	suite.Equal("application/x-ndjson", resp.Header.Get("Content-Type"))
+
+	// каждая строка - отдельный PR, limit к потоку не применяется
+	var streamed []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var pr struct {
+			PullRequestID     string   `json:"pull_request_id"`
+			AssignedReviewers []string `json:"assigned_reviewers"`
+		}
+		suite.Require().NoError(json.Unmarshal(scanner.Bytes(), &pr), scanner.Text())
+		if strings.HasPrefix(pr.PullRequestID, "e2e-stream-") {
+			suite.Equal([]string{"stream-2"}, pr.AssignedReviewers)
+			streamed = append(streamed, pr.PullRequestID)
+		}
+	}
+	suite.Require().NoError(scanner.Err())
+	suite.Equal(prIDs, streamed)
+}
+
+func (suite *E2ETestSuite) Test_ExportAssignmentEvents() {
+	suite.createTeam("e2e-export", activeMembers("export", 3))
+	suite.createPR(map[string]interface{}{"pull_request_id": "e2e-export-pr", "pull_request_name": "Exported", "author_id": "export-1"})
+
+	// журнал PR заменяется известными событиями: 600 событий по секунде начиная с 2001-01-01,
+	// больше одной порции курсора, и одно событие вне периода
+	suite.Require().NoError(ExecTestDatabase("DELETE FROM assignment_events WHERE pull_request_id = 'e2e-export-pr'"))
+	suite.Require().NoError(ExecTestDatabase(`
+		INSERT INTO assignment_events (pull_request_id, event_type, reviewer_id, created_at)
+		SELECT 'e2e-export-pr', 'ASSIGN', 'export-' || (n % 2 + 2), TIMESTAMPTZ '2001-01-01 00:00:00+00' + n * INTERVAL '1 second'
+		FROM generate_series(599, 0, -1) AS n
+	`))
+	suite.Require().NoError(ExecTestDatabase(`
+		INSERT INTO assignment_events (pull_request_id, event_type, reviewer_id, previous_reviewer_id, created_at)
+		VALUES ('e2e-export-pr', 'REASSIGN', 'export-3', 'export-2', TIMESTAMPTZ '2001-01-03 00:00:00+00')
+	`))
+
+	export := func(query string) []time.Time {
+		resp, err := suite.client.Get(baseURL + "/export/events.ndjson" + query)
+		suite.Require().NoError(err)
+		defer resp.Body.Close()
+		suite.Require().Equal(http.StatusOK, resp.StatusCode)
+		suite.Equal("application/x-ndjson", resp.Header.Get("Content-Type"))
+
+		var times []time.Time
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			var event struct {
+				PullRequestID string    `json:"pull_request_id"`
+				EventType     string    `json:"event_type"`
+				CreatedAt     time.Time `json:"created_at"`
+			}
+			suite.Require().NoError(json.Unmarshal(scanner.Bytes(), &event), scanner.Text())
+			if event.PullRequestID == "e2e-export-pr" {
+				times = append(times, event.CreatedAt)
+			}
+		}
+		suite.Require().NoError(scanner.Err())
+		return times
+	}
+	start := time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// === 1. Все события периода по возрастанию времени ===
+	times := export("?from=2001-01-01&to=2001-01-02")
+	suite.Require().Len(times, 600)
+	for i, createdAt := range times {
+		suite.True(createdAt.Equal(start.Add(time.Duration(i)*time.Second)), "event %d at %s", i, createdAt)
+	}
+
+	// === 2. Инкрементальная выгрузка с from ===
+	times = export("?from=2001-01-01T00:09:00Z&to=2001-01-02")
+	suite.Require().Len(times, 60)
+	suite.True(times[0].Equal(start.Add(9 * time.Minute)))
+
+	// === 3. Событие вне периода попадает только без верхней границы ===
+	times = export("?from=2001-01-02")
+	suite.Require().Len(times, 1)
+	suite.True(times[0].Equal(start.Add(48 * time.Hour)))
+
+	// === 4. Неверный период ===
+	statusCode, _, err := suite.makeGetRequest("/export/events.ndjson?from=2001-01-02&to=2001-01-01")
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusBadRequest, statusCode)
+}
+
+func (suite *E2ETestSuite) Test_PRAging() {
+	suite.createTeam("e2e-aging", activeMembers("aging", 3))
+	ages := map[string]string{
+		"e2e-aging-fresh":  "2 hours",
+		"e2e-aging-day":    "30 hours",
+		"e2e-aging-days":   "2 days",
+		"e2e-aging-week":   "5 days",
+		"e2e-aging-old":    "10 days",
+		"e2e-aging-merged": "10 days",
+	}
+	for prID, age := range ages {
+		suite.createPR(map[string]interface{}{
+			"pull_request_id":   prID,
+			"pull_request_name": "Aging",
+			"author_id":         "aging-1",
+		})
+		suite.Require().NoError(ExecTestDatabase(
+			"UPDATE pull_requests SET created_at = NOW() - $2::interval WHERE pull_request_id = $1", prID, age))
+	}
+	statusCode, body, err := suite.makeRequest("POST", "/pullRequest/merge", map[string]interface{}{
+		"pull_request_id": "e2e-aging-merged",
+	})
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode, string(body))
+
+	type bucket struct {
+		Label string `json:"label"`
+		Count int    `json:"count"`
+	}
+	var response struct {
+		Status  string              `json:"status"`
+		Total   int                 `json:"total"`
+		Buckets []bucket            `json:"buckets"`
+		Teams   map[string][]bucket `json:"teams"`
+	}
+
+	// === 1. Границы по умолчанию, замерженный PR не учитывается ===
+	statusCode, body, err = suite.makeGetRequest("/stats/aging?team_name=e2e-aging&by_team=true")
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode, string(body))
+	suite.Require().NoError(json.Unmarshal(body, &response))
+
+	expected := []bucket{{"<1d", 1}, {"1d-3d", 2}, {"3d-7d", 1}, {">=7d", 1}}
+	suite.Equal("OPEN", response.Status)
+	suite.Equal(5, response.Total)
+	suite.Equal(expected, response.Buckets)
+	suite.Equal(expected, response.Teams["e2e-aging"])
+
+	// === 2. Свои границы ===
+	statusCode, body, err = suite.makeGetRequest("/stats/aging?team_name=e2e-aging&buckets=12h,3d")
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode, string(body))
+	response.Teams = nil
+	suite.Require().NoError(json.Unmarshal(body, &response))
+	suite.Equal([]bucket{{"<12h", 1}, {"12h-3d", 2}, {">=3d", 2}}, response.Buckets)
+	suite.Nil(response.Teams)
+
+	// === 3. Границы не по возрастанию ===
+	statusCode, _, err = suite.makeGetRequest("/stats/aging?buckets=3d,1d")
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusBadRequest, statusCode)
+}
+
+func (suite *E2ETestSuite) Test_AssignmentsByStatus() {
+	// в команде из 3 человек у PR автора оба остальных участника становятся ревьюверами
+	suite.createTeam("e2e-by-status", activeMembers("bystatus", 3))
+	for _, prID := range []string{"e2e-by-status-1", "e2e-by-status-2", "e2e-by-status-3"} {
+		suite.createPR(map[string]interface{}{
+			"pull_request_id":   prID,
+			"pull_request_name": prID,
+			"author_id":         "bystatus-1",
+		})
+	}
+	statusCode, _, err := suite.makeRequest("POST", "/pullRequest/merge", map[string]string{
+		"pull_request_id": "e2e-by-status-1",
+	})
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode)
+
+	statusCode, body, err := suite.makeGetRequest("/stats/byStatus?team_name=e2e-by-status")
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode, string(body))
+
+	var response struct {
+		Reviewers []struct {
+			UserID   string           `json:"user_id"`
+			ByStatus map[string]int64 `json:"by_status"`
+			Total    int64            `json:"total"`
+		} `json:"reviewers"`
+	}
+	suite.Require().NoError(json.Unmarshal(body, &response))
+	suite.Require().Len(response.Reviewers, 2)
+	for i, userID := range []string{"bystatus-2", "bystatus-3"} {
+		reviewer := response.Reviewers[i]
+		suite.Equal(userID, reviewer.UserID)
+		suite.Equal(int64(2), reviewer.ByStatus["OPEN"])
+		suite.Equal(int64(1), reviewer.ByStatus["MERGED"])
+		suite.Equal(int64(3), reviewer.Total)
+	}
+
+	// фильтр по ревьюверу
+	statusCode, body, err = suite.makeGetRequest("/stats/byStatus?user_id=bystatus-2")
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode)
+	suite.Require().NoError(json.Unmarshal(body, &response))
+	suite.Require().Len(response.Reviewers, 1)
+	suite.Equal("bystatus-2", response.Reviewers[0].UserID)
+
+	// потоковой выдачи у статистики по статусам нет: Accept: application/x-ndjson получает обычный JSON
+	req, err := http.NewRequest(http.MethodGet, baseURL+"/stats/byStatus?team_name=e2e-by-status", nil)
+	suite.Require().NoError(err)
+	req.Header.Set("Accept", "application/x-ndjson")
+	resp, err := suite.client.Do(req)
+	suite.Require().NoError(err)
+	defer resp.Body.Close()
+	suite.Require().Equal(http.StatusOK, resp.StatusCode)
+	suite.Equal("application/json", resp.Header.Get("Content-Type"))
+	response.Reviewers = nil
+	suite.Require().NoError(json.NewDecoder(resp.Body).Decode(&response))
+	suite.Len(response.Reviewers, 2)
+}
+
+func (suite *E2ETestSuite) Test_AuthorStats() {
+	suite.createTeam("e2e-authors", activeMembers("authors", 3))
+
+	// authors-1: 3 PR, authors-2: 2 PR, authors-3: 1 PR; по одному PR каждого автора создано в прошлом году
+	for author, count := range map[string]int{"authors-1": 3, "authors-2": 2, "authors-3": 1} {
+		for i := 1; i <= count; i++ {
+			prID := fmt.Sprintf("e2e-%s-pr-%d", author, i)
+			suite.createPR(map[string]interface{}{
+				"pull_request_id":   prID,
+				"pull_request_name": prID,
+				"author_id":         author,
+			})
+		}
+		suite.Require().NoError(ExecTestDatabase(
+			"UPDATE pull_requests SET created_at = $1 WHERE pull_request_id = $2",
+			time.Now().AddDate(-1, 0, 0), fmt.Sprintf("e2e-%s-pr-1", author)))
+	}
+
+	type authorCount struct {
+		AuthorID string `json:"author_id"`
+		PRCount  int64  `json:"pr_count"`
+	}
+	fetch := func(query string) []authorCount {
+		statusCode, body, err := suite.makeGetRequest("/stats/authors?" + query)
+		suite.Require().NoError(err)
+		suite.Require().Equal(http.StatusOK, statusCode, string(body))
+
+		var response struct {
+			Authors []authorCount `json:"authors"`
+		}
+		suite.Require().NoError(json.Unmarshal(body, &response))
+		return response.Authors
+	}
+
+	suite.Equal([]authorCount{{"authors-1", 3}, {"authors-2", 2}, {"authors-3", 1}}, fetch(""))
+	suite.Equal([]authorCount{{"authors-1", 3}}, fetch("limit=1"))
+
+	// за последний месяц PR прошлого года не учитываются, у authors-3 PR не остается
+	from := time.Now().AddDate(0, -1, 0).UTC().Format(time.DateOnly)
+	suite.Equal([]authorCount{{"authors-1", 2}, {"authors-2", 1}}, fetch("from="+from))
+
+	statusCode, _, err := suite.makeGetRequest("/stats/authors?from=yesterday")
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusBadRequest, statusCode)
+}
+
+func (suite *E2ETestSuite) Test_ReassignmentChurn() {
+	suite.createTeam("e2e-churn", activeMembers("churn", 4))
+	for i := 1; i <= 3; i++ {
+		suite.createPR(map[string]interface{}{
+			"pull_request_id":   fmt.Sprintf("e2e-churn-%d", i),
+			"pull_request_name": "Churn",
+			"author_id":         "churn-1",
+		})
+	}
+
+	// третий PR создан год назад, но его замена свежая: в окно последнего месяца не попадает ни он, ни замена
+	suite.Require().NoError(ExecTestDatabase(
+		"UPDATE pull_requests SET created_at = $1 WHERE pull_request_id = 'e2e-churn-3'", time.Now().AddDate(-1, 0, 0)))
+
+	// три замены в первом PR, две во втором и одна в третьем
+	for _, prID := range []string{"e2e-churn-1", "e2e-churn-1", "e2e-churn-1", "e2e-churn-2", "e2e-churn-2", "e2e-churn-3"} {
+		suite.Require().NoError(ExecTestDatabase(`
+			INSERT INTO assignment_events (pull_request_id, event_type, reviewer_id, previous_reviewer_id)
+			VALUES ($1, 'REASSIGN', 'churn-2', 'churn-3')
+		`, prID))
+	}
+
+	type churnPR struct {
+		PullRequestID string `json:"pull_request_id"`
+		Reassignments int64  `json:"reassignments"`
+	}
+	type churnResponse struct {
+		TotalReassignments    int64     `json:"total_reassignments"`
+		ReassignedPRs         int64     `json:"reassigned_prs"`
+		CreatedPRs            int64     `json:"created_prs"`
+		AvgReassignmentsPerPR float64   `json:"avg_reassignments_per_pr"`
+		TopPRs                []churnPR `json:"top_prs"`
+	}
+	fetch := func(query string) churnResponse {
+		statusCode, body, err := suite.makeGetRequest("/stats/churn?team_name=e2e-churn" + query)
+		suite.Require().NoError(err)
+		suite.Require().Equal(http.StatusOK, statusCode, string(body))
+
+		var response churnResponse
+		suite.Require().NoError(json.Unmarshal(body, &response))
+		return response
+	}
+
+	// === 1. Весь период ===
+	response := fetch("")
+	suite.Equal(int64(6), response.TotalReassignments)
+	suite.Equal(int64(3), response.ReassignedPRs)
+	suite.Equal(int64(3), response.CreatedPRs)
+	suite.InDelta(2.0, response.AvgReassignmentsPerPR, 0.001)
+	suite.Equal([]churnPR{{"e2e-churn-1", 3}, {"e2e-churn-2", 2}, {"e2e-churn-3", 1}}, response.TopPRs)
+
+	// === 2. Последний месяц и ограничение списка: замены и PR из одного окна ===
+	from := time.Now().AddDate(0, -1, 0).UTC().Format(time.DateOnly)
+	response = fetch("&limit=1&from=" + from)
+	suite.Equal(int64(5), response.TotalReassignments)
+	suite.Equal(int64(2), response.ReassignedPRs)
+	suite.Equal(int64(2), response.CreatedPRs)
+	suite.InDelta(2.5, response.AvgReassignmentsPerPR, 0.001)
+	suite.Equal([]churnPR{{"e2e-churn-1", 3}}, response.TopPRs)
+
+	// === 3. Некорректный период ===
+	statusCode, _, err := suite.makeGetRequest("/stats/churn?from=2024-02-01&to=2024-01-01")
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusBadRequest, statusCode)
+}
+
+func (suite *E2ETestSuite) Test_ReviewerPairs() {
+	suite.createTeam("e2e-pairs", activeMembers("pairs", 5))
+
+	// pairs-2 и pairs-3 вместе в трех PR, pairs-2 и pairs-4 - в одном, последний PR создан год назад
+	reviewers := map[string][]string{
+		"e2e-pairs-1": {"pairs-2", "pairs-3"},
+		"e2e-pairs-2": {"pairs-2", "pairs-3", "pairs-4"},
+		"e2e-pairs-3": {"pairs-3", "pairs-2"},
+		"e2e-pairs-4": {"pairs-5"},
+		"e2e-pairs-5": {"pairs-4", "pairs-5"},
+	}
+	for prID, prReviewers := range reviewers {
+		suite.createPR(map[string]interface{}{
+			"pull_request_id":   prID,
+			"pull_request_name": "Pairs",
+			"author_id":         "pairs-1",
+		})
+		suite.Require().NoError(ExecTestDatabase("DELETE FROM pr_reviewers WHERE pull_request_id = $1", prID))
+		for _, reviewerID := range prReviewers {
+			suite.Require().NoError(ExecTestDatabase(
+				"INSERT INTO pr_reviewers (pull_request_id, reviewer_id) VALUES ($1, $2)", prID, reviewerID))
+		}
+	}
+	suite.Require().NoError(ExecTestDatabase(
+		"UPDATE pull_requests SET created_at = $1 WHERE pull_request_id = 'e2e-pairs-5'", time.Now().AddDate(-1, 0, 0)))
+
+	type pair struct {
+		ReviewerA string `json:"reviewer_a"`
+		ReviewerB string `json:"reviewer_b"`
+		CoReviews int64  `json:"co_reviews"`
+	}
+	fetch := func(query string) []pair {
+		statusCode, body, err := suite.makeGetRequest("/stats/pairs?team_name=e2e-pairs" + query)
+		suite.Require().NoError(err)
+		suite.Require().Equal(http.StatusOK, statusCode, string(body))
+
+		var response struct {
+			Pairs []pair `json:"pairs"`
+		}
+		suite.Require().NoError(json.Unmarshal(body, &response))
+		return response.Pairs
+	}
+
+	suite.Equal([]pair{
+		{"pairs-2", "pairs-3", 3},
+		{"pairs-2", "pairs-4", 1},
+		{"pairs-3", "pairs-4", 1},
+		{"pairs-4", "pairs-5", 1},
+	}, fetch(""))
+
+	// за последний месяц PR прошлого года не учитывается
+	from := time.Now().AddDate(0, -1, 0).UTC().Format(time.DateOnly)
+	suite.Equal([]pair{
+		{"pairs-2", "pairs-3", 3},
+		{"pairs-2", "pairs-4", 1},
+		{"pairs-3", "pairs-4", 1},
+	}, fetch("&from="+from))
+
+	statusCode, _, err := suite.makeGetRequest("/stats/pairs?from=2024-02-01&to=2024-01-01")
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusBadRequest, statusCode)
+}
+
+func (suite *E2ETestSuite) Test_AssignmentSLA() {
+	suite.createTeam("e2e-sla", activeMembers("sla", 3))
+
+	// задержки назначения ревьюверов от создания PR; последний PR создан только что
+	delays := map[string][]string{
+		"e2e-sla-met":       {"1 minute", "2 minutes"},
+		"e2e-sla-late":      {"1 minute", "30 minutes"},
+		"e2e-sla-short":     {"1 minute"},
+		"e2e-sla-pending":   {"0 seconds"},
+		"e2e-sla-year-late": {"1 hour", "1 hour"},
+	}
+	for prID, prDelays := range delays {
+		suite.createPR(map[string]interface{}{
+			"pull_request_id":   prID,
+			"pull_request_name": "SLA",
+			"author_id":         "sla-1",
+		})
+		if prID != "e2e-sla-pending" {
+			suite.Require().NoError(ExecTestDatabase(
+				"UPDATE pull_requests SET created_at = NOW() - INTERVAL '1 day' WHERE pull_request_id = $1", prID))
+		}
+		suite.Require().NoError(ExecTestDatabase("DELETE FROM pr_reviewers WHERE pull_request_id = $1", prID))
+		for i, delay := range prDelays {
+			suite.Require().NoError(ExecTestDatabase(`
+				INSERT INTO pr_reviewers (pull_request_id, reviewer_id, assigned_at)
+				SELECT pull_request_id, $2, created_at + $3::interval FROM pull_requests WHERE pull_request_id = $1
+			`, prID, fmt.Sprintf("sla-%d", i+2), delay))
+		}
+	}
+	suite.Require().NoError(ExecTestDatabase(
+		"UPDATE pull_requests SET created_at = created_at - INTERVAL '1 year' WHERE pull_request_id = 'e2e-sla-year-late'"))
+	suite.Require().NoError(ExecTestDatabase(
+		"UPDATE pr_reviewers SET assigned_at = assigned_at - INTERVAL '1 year' WHERE pull_request_id = 'e2e-sla-year-late'"))
+
+	type slaResponse struct {
+		Threshold  int     `json:"threshold"`
+		Within     string  `json:"within"`
+		TotalPRs   int64   `json:"total_prs"`
+		MetPRs     int64   `json:"met_prs"`
+		MissedPRs  int64   `json:"missed_prs"`
+		PendingPRs int64   `json:"pending_prs"`
+		Compliance float64 `json:"compliance"`
+	}
+	fetch := func(query string) slaResponse {
+		statusCode, body, err := suite.makeGetRequest("/stats/assignmentSLA?team_name=e2e-sla" + query)
+		suite.Require().NoError(err)
+		suite.Require().Equal(http.StatusOK, statusCode, string(body))
+
+		var response slaResponse
+		suite.Require().NoError(json.Unmarshal(body, &response))
+		return response
+	}
+
+	// === 1. Два ревьювера за 5 минут за последний месяц: вовремя только e2e-sla-met ===
+	from := time.Now().AddDate(0, -1, 0).UTC().Format(time.DateOnly)
+	response := fetch("&threshold=2&within=5m&from=" + from)
+	suite.Equal(slaResponse{
+		Threshold: 2, Within: "5m0s", TotalPRs: 3, MetPRs: 1, MissedPRs: 2, PendingPRs: 1, Compliance: 0.3333,
+	}, response)
+
+	// === 2. Один ревьювер за 5 минут за весь период: опоздал только PR прошлого года ===
+	response = fetch("&threshold=1&within=5m")
+	suite.Equal(int64(4), response.MetPRs)
+	suite.Equal(int64(1), response.MissedPRs)
+	suite.Equal(int64(0), response.PendingPRs)
+	suite.InDelta(0.8, response.Compliance, 0.0001)
+
+	// === 3. Некорректные параметры ===
+	for _, query := range []string{"threshold=0", "threshold=two", "within=-5m", "within=soon", "from=2024-02-01&to=2024-01-01"} {
+		statusCode, _, err := suite.makeGetRequest("/stats/assignmentSLA?" + query)
+		suite.Require().NoError(err)
+		suite.Equal(http.StatusBadRequest, statusCode, query)
+	}
+}
+
+func (suite *E2ETestSuite) Test_CompareTeams() {
+	// команда a: автор и два ревьювера, каждый получает оба PR; один PR затем мержится
+	suite.createTeam("e2e-compare-a", activeMembers("compare-a", 3))
+	for _, prID := range []string{"e2e-compare-a-pr-1", "e2e-compare-a-pr-2"} {
+		suite.createPR(map[string]interface{}{"pull_request_id": prID, "pull_request_name": prID, "author_id": "compare-a-1"})
+	}
+	statusCode, body, err := suite.makeRequest("POST", "/pullRequest/merge", map[string]interface{}{"pull_request_id": "e2e-compare-a-pr-2"})
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode, string(body))
+
+	// команда b: единственный ревьювер на одном открытом PR
+	suite.createTeam("e2e-compare-b", activeMembers("compare-b", 2))
+	suite.createPR(map[string]interface{}{"pull_request_id": "e2e-compare-b-pr-1", "pull_request_name": "B", "author_id": "compare-b-1"})
+
+	statusCode, body, err = suite.makeGetRequest("/stats/compareTeams?a=e2e-compare-a&b=e2e-compare-b")
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode, string(body))
+
+	type teamStats struct {
+		TeamName         string  `json:"team_name"`
+		ActiveMembers    int     `json:"active_members"`
+		TotalAssignments int     `json:"total_assignments"`
+		OpenPRLoad       int     `json:"open_pr_load"`
+		AvgLoadPerMember float64 `json:"avg_load_per_member"`
+		FairnessScore    float64 `json:"fairness_score"`
+	}
+	var response struct {
+		A     teamStats `json:"a"`
+		B     teamStats `json:"b"`
+		Delta teamStats `json:"delta"`
+	}
+	suite.Require().NoError(json.Unmarshal(body, &response))
+
+	// назначения активных участников a: 0, 2, 2 -> индекс Джейна 16 / (3 * 8)
+	suite.Equal(teamStats{TeamName: "e2e-compare-a", ActiveMembers: 3, TotalAssignments: 4, OpenPRLoad: 2,
+		AvgLoadPerMember: 0.67, FairnessScore: 0.6667}, response.A)
+	// назначения активных участников b: 0, 1 -> индекс Джейна 1 / (2 * 1)
+	suite.Equal(teamStats{TeamName: "e2e-compare-b", ActiveMembers: 2, TotalAssignments: 1, OpenPRLoad: 1,
+		AvgLoadPerMember: 0.5, FairnessScore: 0.5}, response.B)
+	suite.Equal(teamStats{ActiveMembers: -1, TotalAssignments: -3, OpenPRLoad: -1,
+		AvgLoadPerMember: -0.17, FairnessScore: -0.1667}, response.Delta)
+
+	statusCode, _, err = suite.makeGetRequest("/stats/compareTeams?a=e2e-compare-a&b=e2e-compare-missing")
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusNotFound, statusCode)
+
+	statusCode, _, err = suite.makeGetRequest("/stats/compareTeams?a=e2e-compare-a")
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusBadRequest, statusCode)
+}
@@ -0,0 +1,563 @@
+package e2e
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+func (suite *E2ETestSuite) Test_BlockingPRs() {
+	suite.createTeam("blocking-team", activeMembers("blocking", 2))
+
+	// единственный кандидат в ревьюверы - blocking-2
+	ages := map[string]time.Duration{
+		"blocking-fresh":   time.Hour,
+		"blocking-day-old": 30 * time.Hour,
+		"blocking-old":     72 * time.Hour,
+		"blocking-merged":  96 * time.Hour,
+	}
+	for prID, age := range ages {
+		suite.createPR(map[string]interface{}{
+			"pull_request_id":   prID,
+			"pull_request_name": prID,
+			"author_id":         "blocking-1",
+		})
+		suite.Require().NoError(ExecTestDatabase(
+			"UPDATE pull_requests SET created_at = $1 WHERE pull_request_id = $2", time.Now().Add(-age), prID))
+	}
+
+	statusCode, _, err := suite.makeRequest("POST", "/pullRequest/merge", map[string]string{"pull_request_id": "blocking-merged"})
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode)
+
+	statusCode, body, err := suite.makeGetRequest("/users/blocking?user_id=blocking-2&older_than=24h")
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode, string(body))
+
+	var response struct {
+		PullRequests []struct {
+			PullRequestID string `json:"pull_request_id"`
+		} `json:"pull_requests"`
+	}
+	suite.Require().NoError(json.Unmarshal(body, &response))
+
+	var ids []string
+	for _, pr := range response.PullRequests {
+		ids = append(ids, pr.PullRequestID)
+	}
+	suite.Equal([]string{"blocking-old", "blocking-day-old"}, ids, "Только открытые PR старше порога, от самого старого")
+
+	// по умолчанию порог 24 часа
+	statusCode, body, err = suite.makeGetRequest("/users/blocking?user_id=blocking-2")
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode)
+	suite.Require().NoError(json.Unmarshal(body, &response))
+	suite.Len(response.PullRequests, 2)
+
+	statusCode, _, err = suite.makeGetRequest("/users/blocking?user_id=blocking-2&older_than=yesterday")
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusBadRequest, statusCode)
+
+	statusCode, _, err = suite.makeGetRequest("/users/blocking?user_id=blocking-missing")
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusNotFound, statusCode)
+}
+
+func (suite *E2ETestSuite) Test_MyReviewers() {
+	suite.createTeam("myrev-team", activeMembers("myrev", 4))
+
+	// фиксируем пересекающиеся наборы ревьюверов вместо случайного назначения
+	assignments := map[string][]string{
+		"myrev-pr-1": {"myrev-2", "myrev-3"},
+		"myrev-pr-2": {"myrev-2", "myrev-4"},
+		"myrev-pr-3": {"myrev-2", "myrev-3"},
+	}
+	for prID, reviewers := range assignments {
+		suite.createPR(map[string]interface{}{
+			"pull_request_id":   prID,
+			"pull_request_name": prID,
+			"author_id":         "myrev-1",
+		})
+		suite.Require().NoError(ExecTestDatabase("DELETE FROM pr_reviewers WHERE pull_request_id = $1", prID))
+		for _, reviewerID := range reviewers {
+			suite.Require().NoError(ExecTestDatabase(
+				"INSERT INTO pr_reviewers (pull_request_id, reviewer_id) VALUES ($1, $2)", prID, reviewerID))
+		}
+	}
+
+	statusCode, _, err := suite.makeRequest("POST", "/pullRequest/merge", map[string]string{"pull_request_id": "myrev-pr-3"})
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode)
+
+	type reviewerCount struct {
+		UserID  string `json:"user_id"`
+		PRCount int    `json:"pr_count"`
+	}
+	fetch := func(query string) []reviewerCount {
+		statusCode, body, err := suite.makeGetRequest("/users/myReviewers?" + query)
+		suite.Require().NoError(err)
+		suite.Require().Equal(http.StatusOK, statusCode, string(body))
+
+		var response struct {
+			Reviewers []reviewerCount `json:"reviewers"`
+		}
+		suite.Require().NoError(json.Unmarshal(body, &response))
+		return response.Reviewers
+	}
+
+	suite.Equal([]reviewerCount{{"myrev-2", 3}, {"myrev-3", 2}, {"myrev-4", 1}}, fetch("author_id=myrev-1"))
+	suite.Equal([]reviewerCount{{"myrev-2", 2}, {"myrev-3", 1}, {"myrev-4", 1}}, fetch("author_id=myrev-1&status=OPEN"))
+
+	statusCode, _, err = suite.makeGetRequest("/users/myReviewers?author_id=myrev-1&status=DRAFT")
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusBadRequest, statusCode)
+
+	statusCode, _, err = suite.makeGetRequest("/users/myReviewers?author_id=myrev-missing")
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusNotFound, statusCode)
+}
+
+func (suite *E2ETestSuite) Test_StatusHistory() {
+	suite.createTeam("history-team", activeMembers("history", 3))
+
+	for _, isActive := range []bool{false, true, false} {
+		statusCode, body, err := suite.makeRequest("POST", "/users/setIsActive", map[string]interface{}{
+			"user_id":   "history-1",
+			"is_active": isActive,
+		})
+		suite.Require().NoError(err)
+		suite.Require().Equal(http.StatusOK, statusCode, string(body))
+	}
+
+	statusCode, body, err := suite.makeGetRequest("/users/statusHistory?user_id=history-1")
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode, string(body))
+
+	var response struct {
+		Events []struct {
+			EventType string    `json:"event_type"`
+			CreatedAt time.Time `json:"created_at"`
+		} `json:"events"`
+	}
+	suite.Require().NoError(json.Unmarshal(body, &response))
+
+	var sequence []string
+	for _, event := range response.Events {
+		sequence = append(sequence, event.EventType)
+	}
+	suite.Equal([]string{"DEACTIVATE", "ACTIVATE", "DEACTIVATE"}, sequence)
+
+	// события до начала периода не попадают в ответ
+	tomorrow := time.Now().AddDate(0, 0, 1).UTC().Format(time.DateOnly)
+	statusCode, body, err = suite.makeGetRequest("/users/statusHistory?user_id=history-1&from=" + tomorrow)
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode)
+	suite.Require().NoError(json.Unmarshal(body, &response))
+	suite.Empty(response.Events)
+
+	statusCode, _, err = suite.makeGetRequest("/users/statusHistory?user_id=history-missing")
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusNotFound, statusCode)
+}
+
+func (suite *E2ETestSuite) Test_TeamSync() {
+	suite.createTeam("sync-team", activeMembers("sync", 4))
+	pr := suite.createPR(map[string]interface{}{
+		"pull_request_id":    "sync-pr",
+		"pull_request_name":  "Sync PR",
+		"author_id":          "sync-1",
+		"required_reviewers": 3,
+	})
+	suite.Require().ElementsMatch([]string{"sync-2", "sync-3", "sync-4"}, toStrings(pr["assigned_reviewers"]))
+
+	// добавляем sync-5, убираем sync-2, выключаем sync-4
+	request := map[string]interface{}{
+		"team_name": "sync-team",
+		"members": []map[string]interface{}{
+			{"user_id": "sync-1", "username": "User sync 1", "is_active": true},
+			{"user_id": "sync-3", "username": "User sync 3", "is_active": true},
+			{"user_id": "sync-4", "username": "User sync 4", "is_active": false},
+			{"user_id": "sync-5", "username": "User sync 5", "is_active": true},
+		},
+	}
+	statusCode, body, err := suite.makeRequest("POST", "/team/sync", request)
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode, string(body))
+
+	var response map[string]interface{}
+	suite.Require().NoError(json.Unmarshal(body, &response))
+	suite.Equal([]string{"sync-5"}, toStrings(response["added"]))
+	suite.Equal([]string{"sync-2"}, toStrings(response["removed"]))
+	suite.Equal([]string{"sync-4"}, toStrings(response["deactivated"]))
+
+	reassigned := response["reassigned_prs"].([]interface{})
+	suite.Require().Len(reassigned, 1)
+	newReviewers := toStrings(reassigned[0].(map[string]interface{})["new_reviewers"])
+	suite.NotContains(newReviewers, "sync-2")
+	suite.Contains(newReviewers, "sync-5")
+
+	// повторная синхронизация тем же списком ничего не меняет
+	statusCode, body, err = suite.makeRequest("POST", "/team/sync", request)
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode)
+	suite.Require().NoError(json.Unmarshal(body, &response))
+	suite.Empty(response["added"])
+	suite.Empty(response["removed"])
+	suite.Empty(response["reassigned_prs"])
+}
+
+func (suite *E2ETestSuite) Test_TeamUpdate() {
+	suite.createTeam("update-team", activeMembers("update", 3))
+	pr := suite.createPR(map[string]interface{}{
+		"pull_request_id":   "update-pr",
+		"pull_request_name": "Update PR",
+		"author_id":         "update-1",
+	})
+	suite.Require().ElementsMatch([]string{"update-2", "update-3"}, toStrings(pr["assigned_reviewers"]))
+
+	// добавляем update-4 и переименовываем update-3, остальные участники не перечислены и не меняются
+	statusCode, body, err := suite.makeRequest("POST", "/team/update", map[string]interface{}{
+		"team_name": "update-team",
+		"members": []map[string]interface{}{
+			{"user_id": "update-3", "username": "Renamed", "is_active": true},
+			{"user_id": "update-4", "username": "User update 4", "is_active": true},
+		},
+	})
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode, string(body))
+
+	var response map[string]interface{}
+	suite.Require().NoError(json.Unmarshal(body, &response))
+	suite.Equal([]string{"update-4"}, toStrings(response["added"]))
+	suite.Equal([]string{"update-3"}, toStrings(response["renamed"]))
+	suite.Empty(response["removed"])
+
+	// с deactivate_missing неперечисленный ревьювер update-2 выбывает, его ревью достается update-4
+	statusCode, body, err = suite.makeRequest("POST", "/team/update", map[string]interface{}{
+		"team_name": "update-team",
+		"members": []map[string]interface{}{
+			{"user_id": "update-1", "username": "User update 1", "is_active": true},
+			{"user_id": "update-3", "username": "Renamed", "is_active": true},
+			{"user_id": "update-4", "username": "User update 4", "is_active": true},
+		},
+		"deactivate_missing": true,
+	})
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode, string(body))
+
+	response = nil
+	suite.Require().NoError(json.Unmarshal(body, &response))
+	suite.Equal([]string{"update-2"}, toStrings(response["removed"]))
+	reassigned := response["reassigned_prs"].([]interface{})
+	suite.Require().Len(reassigned, 1)
+	suite.ElementsMatch([]string{"update-3", "update-4"}, toStrings(reassigned[0].(map[string]interface{})["new_reviewers"]))
+
+	statusCode, _, err = suite.makeRequest("POST", "/team/update", map[string]interface{}{
+		"team_name": "update-missing",
+		"members":   []map[string]interface{}{{"user_id": "update-9", "username": "User update 9", "is_active": true}},
+	})
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusNotFound, statusCode)
+}
+
+func (suite *E2ETestSuite) Test_DeleteTeam() {
+	suite.createTeam("delete-team", activeMembers("delete", 2))
+	suite.createTeam("delete-other", activeMembers("delete-other", 2))
+	suite.createPR(map[string]interface{}{
+		"pull_request_id":   "delete-merged",
+		"pull_request_name": "Merged",
+		"author_id":         "delete-1",
+	})
+	statusCode, body, err := suite.makeRequest("POST", "/pullRequest/merge", map[string]string{"pull_request_id": "delete-merged"})
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode, string(body))
+
+	// delete-2 ревьюит открытый PR другой команды
+	suite.createPR(map[string]interface{}{
+		"pull_request_id":   "delete-open",
+		"pull_request_name": "Open",
+		"author_id":         "delete-other-1",
+	})
+	suite.Require().NoError(ExecTestDatabase(
+		"INSERT INTO pr_reviewers (pull_request_id, reviewer_id) VALUES ('delete-open', 'delete-2')"))
+
+	statusCode, body, err = suite.makeRequest("POST", "/team/delete", map[string]string{"team_name": "delete-team"})
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusConflict, statusCode, string(body))
+	suite.Contains(string(body), "TEAM_HAS_OPEN_PRS")
+
+	statusCode, _, err = suite.makeRequest("POST", "/pullRequest/merge", map[string]string{"pull_request_id": "delete-open"})
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode)
+
+	// замерженный PR delete-1 удаляется только по явному delete_prs
+	statusCode, body, err = suite.makeRequest("POST", "/team/delete", map[string]string{"team_name": "delete-team"})
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusConflict, statusCode, string(body))
+	suite.Contains(string(body), "TEAM_HAS_PRS")
+
+	statusCode, body, err = suite.makeRequest("POST", "/team/delete", map[string]interface{}{"team_name": "delete-team", "delete_prs": true})
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode, string(body))
+
+	var response map[string]interface{}
+	suite.Require().NoError(json.Unmarshal(body, &response))
+	suite.Equal(float64(2), response["deleted_members"])
+	suite.Equal(float64(1), response["deleted_prs"])
+
+	for _, query := range []string{
+		"SELECT COUNT(*) FROM teams WHERE team_name = 'delete-team'",
+		"SELECT COUNT(*) FROM users WHERE team_name = 'delete-team'",
+		"SELECT COUNT(*) FROM pull_requests WHERE pull_request_id = 'delete-merged'",
+	} {
+		remaining, err := CountTestDatabase(query)
+		suite.Require().NoError(err)
+		suite.Zero(remaining, query)
+	}
+
+	statusCode, _, err = suite.makeRequest("POST", "/team/delete", map[string]string{"team_name": "delete-team"})
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusNotFound, statusCode)
+}
+
+func (suite *E2ETestSuite) Test_CreateUser() {
+	suite.createTeam("create-user-team", activeMembers("create-user", 1))
+
+	request := map[string]interface{}{"user_id": "create-user-2", "username": "New Hire", "team_name": "create-user-team"}
+	statusCode, body, err := suite.makeRequest("POST", "/users/create", request)
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusCreated, statusCode, string(body))
+
+	var response struct {
+		User map[string]interface{} `json:"user"`
+	}
+	suite.Require().NoError(json.Unmarshal(body, &response))
+	suite.Equal(true, response.User["is_active"])
+
+	// новый участник сразу доступен для назначения
+	pr := suite.createPR(map[string]interface{}{
+		"pull_request_id":   "create-user-pr",
+		"pull_request_name": "First review",
+		"author_id":         "create-user-1",
+	})
+	suite.Equal([]string{"create-user-2"}, toStrings(pr["assigned_reviewers"]))
+
+	joins, err := CountTestDatabase(
+		"SELECT COUNT(*) FROM team_membership_events WHERE user_id = 'create-user-2' AND event_type = 'JOIN'")
+	suite.Require().NoError(err)
+	suite.Equal(1, joins)
+
+	statusCode, body, err = suite.makeRequest("POST", "/users/create", request)
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusConflict, statusCode)
+	suite.Contains(string(body), "USER_EXISTS")
+
+	statusCode, _, err = suite.makeRequest("POST", "/users/create",
+		map[string]interface{}{"user_id": "create-user-3", "username": "Nobody", "team_name": "create-user-missing"})
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusNotFound, statusCode)
+
+	statusCode, _, err = suite.makeRequest("POST", "/users/create", map[string]interface{}{"user_id": "create-user-3"})
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusBadRequest, statusCode)
+}
+
+func (suite *E2ETestSuite) Test_TransferUser() {
+	suite.createTeam("transfer-from", activeMembers("transfer-from", 3))
+	suite.createTeam("transfer-to", activeMembers("transfer-to", 1))
+	pr := suite.createPR(map[string]interface{}{
+		"pull_request_id":   "transfer-pr",
+		"pull_request_name": "Before transfer",
+		"author_id":         "transfer-from-1",
+	})
+	suite.Require().ElementsMatch([]string{"transfer-from-2", "transfer-from-3"}, toStrings(pr["assigned_reviewers"]))
+
+	statusCode, body, err := suite.makeRequest("POST", "/users/transfer",
+		map[string]string{"user_id": "transfer-from-2", "team_name": "transfer-to"})
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode, string(body))
+
+	var response struct {
+		User          map[string]interface{} `json:"user"`
+		PreviousTeam  string                 `json:"previous_team"`
+		ReassignedPRs []string               `json:"reassigned_prs"`
+	}
+	suite.Require().NoError(json.Unmarshal(body, &response))
+	suite.Equal("transfer-to", response.User["team_name"])
+	suite.Equal("transfer-from", response.PreviousTeam)
+	// в команде автора не осталось свободных кандидатов, поэтому переведенный пользователь остается ревьювером
+	suite.Empty(response.ReassignedPRs)
+
+	statusCode, body, err = suite.makeGetRequest("/users/teamHistory?user_id=transfer-from-2")
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode, string(body))
+	suite.Contains(string(body), "transfer-from")
+	suite.Contains(string(body), "transfer-to")
+
+	// с новым участником команды автора ревью переводится на него
+	statusCode, body, err = suite.makeRequest("POST", "/users/create",
+		map[string]string{"user_id": "transfer-from-4", "username": "Newcomer", "team_name": "transfer-from"})
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusCreated, statusCode, string(body))
+	statusCode, body, err = suite.makeRequest("POST", "/users/transfer",
+		map[string]string{"user_id": "transfer-from-3", "team_name": "transfer-to"})
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode, string(body))
+	suite.Require().NoError(json.Unmarshal(body, &response))
+	suite.Equal([]string{"transfer-pr"}, response.ReassignedPRs)
+
+	statusCode, _, err = suite.makeRequest("POST", "/users/transfer",
+		map[string]string{"user_id": "transfer-from-3", "team_name": "transfer-missing"})
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusNotFound, statusCode)
+}
+
+func (suite *E2ETestSuite) Test_TeamRebalance() {
+	// === 1. Создаем перекос: все ревью у двух участников ===
+	suite.createTeam("e2e-rebalance", activeMembers("rebalance", 5))
+	for i := 1; i <= 4; i++ {
+		suite.createPR(map[string]interface{}{
+			"pull_request_id":   fmt.Sprintf("e2e-rebalance-%d", i),
+			"pull_request_name": "Skewed",
+			"author_id":         "rebalance-1",
+		})
+	}
+	suite.Require().NoError(ExecTestDatabase(
+		"DELETE FROM pr_reviewers WHERE pull_request_id LIKE 'e2e-rebalance-%'"))
+	suite.Require().NoError(ExecTestDatabase(`
+		INSERT INTO pr_reviewers (pull_request_id, reviewer_id)
+		SELECT pull_request_id, reviewer_id
+		FROM pull_requests, unnest(ARRAY['rebalance-2', 'rebalance-3']) AS reviewer_id
+		WHERE pull_request_id LIKE 'e2e-rebalance-%'
+	`))
+
+	// === 2. Выравниваем нагрузку ===
+	statusCode, body, err := suite.makeRequest("POST", "/team/rebalance", map[string]string{"team_name": "e2e-rebalance"})
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode, string(body))
+
+	var response struct {
+		Changes    []map[string]string `json:"changes"`
+		LoadBefore map[string]int      `json:"load_before"`
+		LoadAfter  map[string]int      `json:"load_after"`
+	}
+	suite.Require().NoError(json.Unmarshal(body, &response))
+	suite.Equal(4, response.LoadBefore["rebalance-2"])
+	suite.Equal(4, response.LoadBefore["rebalance-3"])
+	suite.NotEmpty(response.Changes)
+
+	// автор не может ревьюить свои PR, поэтому нагрузка выравнивается между четырьмя ревьюверами
+	for _, userID := range []string{"rebalance-2", "rebalance-3", "rebalance-4", "rebalance-5"} {
+		suite.Equal(2, response.LoadAfter[userID], userID)
+		count, err := CountTestDatabase("SELECT COUNT(*) FROM pr_reviewers WHERE reviewer_id = $1", userID)
+		suite.Require().NoError(err)
+		suite.Equal(2, count, userID)
+	}
+
+	// === 3. Неизвестная команда ===
+	statusCode, _, err = suite.makeRequest("POST", "/team/rebalance", map[string]string{"team_name": "e2e-rebalance-missing"})
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusNotFound, statusCode)
+}
+
+func (suite *E2ETestSuite) Test_UserOptOut() {
+	suite.createTeam("e2e-optout", activeMembers("optout", 4))
+
+	// === 1. Участник отказывается от назначения на сутки ===
+	until := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	statusCode, body, err := suite.makeRequest("POST", "/users/optOut", map[string]interface{}{
+		"user_id": "optout-2",
+		"until":   until.Format(time.RFC3339),
+		"reason":  "focus time",
+	})
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode, string(body))
+
+	statusCode, body, err = suite.makeGetRequest("/users/optOut?user_id=optout-2")
+	suite.Require().NoError(err)
+	suite.Require().Equal(http.StatusOK, statusCode, string(body))
+
+	var response struct {
+		OptOut struct {
+			OptedOut bool      `json:"opted_out"`
+			Until    time.Time `json:"until"`
+			Reason   string    `json:"reason"`
+		} `json:"opt_out"`
+	}
+	suite.Require().NoError(json.Unmarshal(body, &response))
+	suite.True(response.OptOut.OptedOut)
+	suite.True(until.Equal(response.OptOut.Until))
+	suite.Equal("focus time", response.OptOut.Reason)
+
+	// === 2. Отказавшийся участник не назначается ===
+	for i := 1; i <= 3; i++ {
+		pr := suite.createPR(map[string]interface{}{
+			"pull_request_id":   fmt.Sprintf("e2e-optout-%d", i),
+			"pull_request_name": "Focus",
+			"author_id":         "optout-1",
+		})
+		suite.NotContains(toStrings(pr["assigned_reviewers"]), "optout-2")
+	}
+
+	// === 3. Без until запрос отклоняется, неизвестный пользователь не найден ===
+	statusCode, _, err = suite.makeRequest("POST", "/users/optOut", map[string]interface{}{"user_id": "optout-2"})
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusBadRequest, statusCode)
+
+	statusCode, _, err = suite.makeGetRequest("/users/optOut?user_id=optout-missing")
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusNotFound, statusCode)
+}
+
+func (suite *E2ETestSuite) Test_ReviewListPagination() {
+	suite.createTeam("e2e-paging", activeMembers("paging", 2))
+	for i := 1; i <= 3; i++ {
+		suite.createPR(map[string]interface{}{
+			"pull_request_id":   fmt.Sprintf("e2e-paging-%d", i),
+			"pull_request_name": "Paging",
+			"author_id":         "paging-1",
+		})
+	}
+
+	type page struct {
+		PullRequests []map[string]interface{} `json:"pull_requests"`
+		Pagination   struct {
+			Limit   int  `json:"limit"`
+			Offset  int  `json:"offset"`
+			Total   int  `json:"total"`
+			HasMore bool `json:"has_more"`
+		} `json:"pagination"`
+	}
+	fetch := func(query string) page {
+		statusCode, body, err := suite.makeGetRequest("/users/getReview?user_id=paging-2" + query)
+		suite.Require().NoError(err)
+		suite.Require().Equal(http.StatusOK, statusCode, string(body))
+
+		var response page
+		suite.Require().NoError(json.Unmarshal(body, &response))
+		return response
+	}
+
+	// единственный ревьювер команды получает все три PR
+	response := fetch("&limit=2")
+	suite.Len(response.PullRequests, 2)
+	suite.Equal(3, response.Pagination.Total)
+	suite.True(response.Pagination.HasMore)
+
+	response = fetch("&limit=2&offset=2")
+	suite.Len(response.PullRequests, 1)
+	suite.Equal(2, response.Pagination.Offset)
+	suite.False(response.Pagination.HasMore)
+
+	// без limit и offset список отдается целиком и без метаданных страницы, как в api/openapi.yml
+	response = fetch("")
+	suite.Len(response.PullRequests, 3)
+	suite.Zero(response.Pagination.Limit)
+
+	statusCode, body, err := suite.makeGetRequest("/users/getReview?user_id=paging-2&limit=0&offset=-1")
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusBadRequest, statusCode)
+	code, _ := suite.parseError(body)
+	suite.Equal("INVALID_REQUEST", code)
+}